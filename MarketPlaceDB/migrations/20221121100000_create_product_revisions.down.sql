@@ -0,0 +1 @@
+DROP TABLE IF EXISTS public.ProductRevision;
\ No newline at end of file
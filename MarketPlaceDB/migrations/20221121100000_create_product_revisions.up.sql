@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS public.ProductRevision(
+    ProductRevision_ID bigserial not null primary key,
+    Product_ID bigint not null,
+    Revision int not null,
+    Revision_Action varchar(20) not null,
+    Snapshot jsonb not null,
+    User_ID bigint not null references public.users(id),
+    Created_At timestamp not null default now(),
+    unique (Product_ID, Revision)
+);
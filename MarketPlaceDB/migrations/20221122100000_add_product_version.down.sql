@@ -0,0 +1 @@
+ALTER TABLE public.Product DROP COLUMN IF EXISTS Version;
@@ -0,0 +1 @@
+ALTER TABLE public.Product ADD COLUMN IF NOT EXISTS Version int not null default 1;
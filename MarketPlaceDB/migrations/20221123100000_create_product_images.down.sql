@@ -0,0 +1 @@
+DROP TABLE IF EXISTS public.ProductImage;
\ No newline at end of file
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS public.ProductImage(
+    ProductImage_ID bigserial not null primary key,
+    Product_ID bigint not null references public.Product(Product_ID) on delete cascade,
+    User_ID bigint not null references public.users(id),
+    File_Key varchar(500) not null,
+    Thumbnail_Key varchar(500) not null,
+    Content_Type varchar(100) not null,
+    File_Size bigint not null,
+    Width int not null,
+    Height int not null,
+    Position int not null,
+    Created_At timestamp not null default now()
+);
+
+CREATE INDEX IF NOT EXISTS ProductImage_Product_ID_idx ON public.ProductImage(Product_ID, Position);
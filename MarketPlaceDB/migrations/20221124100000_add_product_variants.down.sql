@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS Product_Parent_Product_ID_idx;
+
+ALTER TABLE public.Product DROP COLUMN IF EXISTS Variant_Attributes;
+ALTER TABLE public.Product DROP COLUMN IF EXISTS Parent_Product_ID;
@@ -0,0 +1,4 @@
+ALTER TABLE public.Product ADD COLUMN IF NOT EXISTS Parent_Product_ID bigint REFERENCES public.Product(Product_ID);
+ALTER TABLE public.Product ADD COLUMN IF NOT EXISTS Variant_Attributes jsonb;
+
+CREATE INDEX IF NOT EXISTS Product_Parent_Product_ID_idx ON public.Product(Parent_Product_ID);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS public.ProductAttribute;
+DROP TABLE IF EXISTS public.CategoryAttribute;
@@ -0,0 +1,28 @@
+CREATE TABLE IF NOT EXISTS public.CategoryAttribute(
+    CategoryAttribute_ID bigserial not null primary key,
+    Category_ID bigint not null references public.Category(Category_ID),
+    Code varchar(100) not null,
+    Attribute_Name varchar(200) not null,
+    Attribute_Type varchar(20) not null,
+    Unit varchar(50) not null default '',
+    Required boolean not null default false,
+    Allowed_Values jsonb,
+    Active boolean not null
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS CategoryAttribute_Category_ID_Code_idx ON public.CategoryAttribute(Category_ID, Code) WHERE Active;
+
+CREATE TABLE IF NOT EXISTS public.ProductAttribute(
+    Product_ID bigint not null references public.Product(Product_ID) on delete cascade,
+    Code varchar(100) not null,
+    Attribute_Value varchar(1000) not null,
+    primary key (Product_ID, Code)
+);
+
+INSERT INTO public.CategoryAttribute(
+    category_id, code, attribute_name, attribute_type, unit, required, allowed_values, active)
+    VALUES
+    (2, 'fabric', 'Ткань', 'string', '', true, null, true)
+    ,(2, 'size', 'Размер', 'enum', '', false, '["XS", "S", "M", "L", "XL", "XXL"]', true)
+    ,(3, 'size', 'Размер', 'number', '', true, null, true)
+    ,(115, 'screen_size', 'Диагональ экрана', 'number', 'дюйм', true, null, true);
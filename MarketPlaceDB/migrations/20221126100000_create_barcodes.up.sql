@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS public.Barcode(
+    Barcode_ID bigserial not null primary key,
+    Product_ID bigint not null references public.Product(Product_ID) on delete cascade,
+    User_ID bigint not null references public.users(id),
+    Code varchar(13) not null,
+    Barcode_Type varchar(10) not null,
+    Internal boolean not null default false,
+    Created_At timestamp not null default now()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS Barcode_User_ID_Code_idx ON public.Barcode(User_ID, Code);
+CREATE INDEX IF NOT EXISTS Barcode_Product_ID_idx ON public.Barcode(Product_ID);
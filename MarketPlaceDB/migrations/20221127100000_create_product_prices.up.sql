@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS public.ProductPrice(
+    Price_ID bigserial not null primary key,
+    Product_ID bigint not null references public.Product(Product_ID) on delete cascade,
+    MarketPlace_ID bigint not null references public.MarketPlace(MarketPlace_ID),
+    User_ID bigint not null references public.users(id),
+    List_Price numeric(12, 2) not null,
+    Discount_Price numeric(12, 2) not null default 0,
+    Min_Price numeric(12, 2) not null default 0,
+    Landed_Cost numeric(12, 2) not null default 0,
+    Override boolean not null default false,
+    Created_At timestamp not null default now()
+);
+
+CREATE INDEX IF NOT EXISTS ProductPrice_Product_ID_MarketPlace_ID_idx ON public.ProductPrice(Product_ID, MarketPlace_ID, Price_ID DESC);
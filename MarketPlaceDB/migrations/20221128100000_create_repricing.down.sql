@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS public.RepricingLog;
+DROP TABLE IF EXISTS public.PriceProposal;
+DROP TABLE IF EXISTS public.CompetitorPrice;
+DROP TABLE IF EXISTS public.RepricingRule;
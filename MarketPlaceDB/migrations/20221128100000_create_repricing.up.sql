@@ -0,0 +1,57 @@
+CREATE TABLE IF NOT EXISTS public.RepricingRule(
+    Rule_ID bigserial not null primary key,
+    User_ID bigint not null references public.users(id),
+    Rule_Name varchar(200) not null,
+    Product_ID bigint references public.Product(Product_ID) on delete cascade,
+    MarketPlace_ID bigint references public.MarketPlace(MarketPlace_ID),
+    Strategy varchar(50) not null,
+    Target_Margin_Percent numeric(5, 2) not null default 0,
+    Competitor_Offset numeric(12, 2) not null default 0,
+    Floor_Price numeric(12, 2) not null default 0,
+    Ceiling_Price numeric(12, 2) not null default 0,
+    Active boolean not null default true,
+    Created_At timestamp not null default now()
+);
+
+CREATE INDEX IF NOT EXISTS RepricingRule_User_ID_idx ON public.RepricingRule(User_ID) WHERE Active;
+
+CREATE TABLE IF NOT EXISTS public.CompetitorPrice(
+    Product_ID bigint not null references public.Product(Product_ID) on delete cascade,
+    MarketPlace_ID bigint not null references public.MarketPlace(MarketPlace_ID),
+    User_ID bigint not null references public.users(id),
+    Price numeric(12, 2) not null,
+    Imported_At timestamp not null default now(),
+    primary key (Product_ID, MarketPlace_ID)
+);
+
+CREATE TABLE IF NOT EXISTS public.PriceProposal(
+    Proposal_ID bigserial not null primary key,
+    User_ID bigint not null references public.users(id),
+    Rule_ID bigint not null references public.RepricingRule(Rule_ID) on delete cascade,
+    Product_ID bigint not null references public.Product(Product_ID) on delete cascade,
+    MarketPlace_ID bigint not null references public.MarketPlace(MarketPlace_ID),
+    Current_Price numeric(12, 2) not null,
+    Proposed_Price numeric(12, 2) not null,
+    Reason varchar(500) not null default '',
+    Status varchar(20) not null,
+    Error varchar(500) not null default '',
+    Created_At timestamp not null default now(),
+    Decided_At timestamp
+);
+
+CREATE INDEX IF NOT EXISTS PriceProposal_User_ID_Status_idx ON public.PriceProposal(User_ID, Status);
+
+CREATE TABLE IF NOT EXISTS public.RepricingLog(
+    Log_ID bigserial not null primary key,
+    User_ID bigint not null references public.users(id),
+    Proposal_ID bigint not null references public.PriceProposal(Proposal_ID) on delete cascade,
+    Rule_ID bigint not null,
+    Product_ID bigint not null references public.Product(Product_ID) on delete cascade,
+    MarketPlace_ID bigint not null references public.MarketPlace(MarketPlace_ID),
+    Price_ID bigint not null references public.ProductPrice(Price_ID) on delete cascade,
+    Old_Price numeric(12, 2) not null,
+    New_Price numeric(12, 2) not null,
+    Created_At timestamp not null default now()
+);
+
+CREATE INDEX IF NOT EXISTS RepricingLog_User_ID_idx ON public.RepricingLog(User_ID);
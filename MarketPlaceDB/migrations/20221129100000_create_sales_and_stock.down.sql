@@ -0,0 +1,6 @@
+ALTER TABLE public.SupplyOrder DROP COLUMN IF EXISTS SupplyOrderStatus_ID;
+ALTER TABLE public.Supplier DROP COLUMN IF EXISTS Lead_Time_Days;
+DELETE FROM public.SupplyOrderStatus WHERE SupplyOrderStatus_ID BETWEEN 1 AND 5;
+DELETE FROM public.Currency WHERE Currency_ID BETWEEN 1 AND 3;
+DROP TABLE IF EXISTS public.ProductStock;
+DROP TABLE IF EXISTS public.Sale;
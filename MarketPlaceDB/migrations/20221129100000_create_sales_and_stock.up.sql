@@ -0,0 +1,36 @@
+CREATE TABLE IF NOT EXISTS public.Sale(
+    Product_ID bigint not null references public.Product(Product_ID) on delete cascade,
+    MarketPlace_ID bigint not null references public.MarketPlace(MarketPlace_ID),
+    Sale_Date date not null,
+    User_ID bigint not null references public.users(id),
+    Quantity integer not null,
+    primary key (Product_ID, MarketPlace_ID, Sale_Date)
+);
+
+CREATE INDEX IF NOT EXISTS Sale_User_ID_Sale_Date_idx ON public.Sale(User_ID, Sale_Date);
+
+CREATE TABLE IF NOT EXISTS public.ProductStock(
+    Product_ID bigint not null primary key references public.Product(Product_ID) on delete cascade,
+    User_ID bigint not null references public.users(id),
+    Quantity integer not null,
+    Updated_At timestamp not null default now()
+);
+
+ALTER TABLE public.Supplier ADD COLUMN IF NOT EXISTS Lead_Time_Days integer not null default 14;
+ALTER TABLE public.SupplyOrder ADD COLUMN IF NOT EXISTS SupplyOrderStatus_ID bigint references public.SupplyOrderStatus(SupplyOrderStatus_ID);
+
+INSERT INTO public.SupplyOrderStatus(
+    SupplyOrderStatus_ID, SupplyOrderStatus_Name)
+    VALUES (1, 'draft'),
+           (2, 'ordered'),
+           (3, 'shipped'),
+           (4, 'received'),
+           (5, 'cancelled')
+    ON CONFLICT DO NOTHING;
+
+INSERT INTO public.Currency(
+    Currency_ID, Currency_Name, Currency_Code)
+    VALUES (1, 'Российский рубль', 'RUB'),
+           (2, 'Доллар США', 'USD'),
+           (3, 'Китайский юань', 'CNY')
+    ON CONFLICT DO NOTHING;
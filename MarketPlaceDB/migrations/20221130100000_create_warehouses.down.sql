@@ -0,0 +1,16 @@
+DROP TABLE IF EXISTS public.StockTransferItem;
+DROP TABLE IF EXISTS public.StockTransfer;
+
+CREATE TEMPORARY TABLE ProductStockTotal AS
+    SELECT Product_ID, User_ID, sum(Quantity)::integer AS Quantity, max(Updated_At) AS Updated_At
+    FROM public.ProductStock GROUP BY Product_ID, User_ID;
+
+DELETE FROM public.ProductStock;
+ALTER TABLE public.ProductStock DROP CONSTRAINT IF EXISTS productstock_pkey;
+ALTER TABLE public.ProductStock DROP COLUMN IF EXISTS Warehouse_ID;
+INSERT INTO public.ProductStock(Product_ID, User_ID, Quantity, Updated_At)
+    SELECT Product_ID, User_ID, Quantity, Updated_At FROM ProductStockTotal;
+ALTER TABLE public.ProductStock ADD PRIMARY KEY (Product_ID);
+DROP TABLE ProductStockTotal;
+
+DROP TABLE IF EXISTS public.Warehouse;
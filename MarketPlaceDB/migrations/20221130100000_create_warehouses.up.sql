@@ -0,0 +1,41 @@
+CREATE TABLE IF NOT EXISTS public.Warehouse(
+    Warehouse_ID bigserial not null primary key,
+    User_ID bigint not null references public.users(id),
+    Warehouse_Name varchar(200) not null,
+    Warehouse_Type varchar(20) not null,
+    MarketPlace_ID bigint references public.MarketPlace(MarketPlace_ID),
+    Address varchar(500) not null default '',
+    Active boolean not null default true
+);
+
+CREATE INDEX IF NOT EXISTS Warehouse_User_ID_idx ON public.Warehouse(User_ID) WHERE Active;
+
+-- stock saved before warehouses existed is moved to an own warehouse of the seller
+INSERT INTO public.Warehouse(User_ID, Warehouse_Name, Warehouse_Type)
+    SELECT DISTINCT User_ID, 'Основной склад', 'own' FROM public.ProductStock;
+
+ALTER TABLE public.ProductStock ADD COLUMN IF NOT EXISTS Warehouse_ID bigint references public.Warehouse(Warehouse_ID);
+UPDATE public.ProductStock s SET Warehouse_ID = w.Warehouse_ID FROM public.Warehouse w WHERE w.User_ID = s.User_ID;
+ALTER TABLE public.ProductStock ALTER COLUMN Warehouse_ID SET NOT NULL;
+ALTER TABLE public.ProductStock DROP CONSTRAINT IF EXISTS productstock_pkey;
+ALTER TABLE public.ProductStock ADD PRIMARY KEY (Warehouse_ID, Product_ID);
+
+CREATE TABLE IF NOT EXISTS public.StockTransfer(
+    Transfer_ID bigserial not null primary key,
+    User_ID bigint not null references public.users(id),
+    From_Warehouse_ID bigint not null references public.Warehouse(Warehouse_ID),
+    To_Warehouse_ID bigint not null references public.Warehouse(Warehouse_ID),
+    Status varchar(20) not null,
+    Created_At timestamp not null default now(),
+    Shipped_At timestamp,
+    Received_At timestamp
+);
+
+CREATE INDEX IF NOT EXISTS StockTransfer_User_ID_Status_idx ON public.StockTransfer(User_ID, Status);
+
+CREATE TABLE IF NOT EXISTS public.StockTransferItem(
+    Transfer_ID bigint not null references public.StockTransfer(Transfer_ID) on delete cascade,
+    Product_ID bigint not null references public.Product(Product_ID),
+    Quantity integer not null,
+    primary key (Transfer_ID, Product_ID)
+);
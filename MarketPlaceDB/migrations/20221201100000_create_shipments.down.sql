@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS public.ShipmentBoxItem;
+DROP TABLE IF EXISTS public.ShipmentBox;
+DROP TABLE IF EXISTS public.Shipment;
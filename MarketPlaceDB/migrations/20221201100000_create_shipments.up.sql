@@ -0,0 +1,31 @@
+CREATE TABLE IF NOT EXISTS public.Shipment(
+    Shipment_ID bigserial not null primary key,
+    User_ID bigint not null references public.users(id),
+    Warehouse_ID bigint not null references public.Warehouse(Warehouse_ID),
+    MarketPlace_ID bigint not null references public.MarketPlace(MarketPlace_ID),
+    Planned_Date date not null,
+    Status varchar(20) not null,
+    Created_At timestamp not null default now(),
+    Updated_At timestamp not null default now()
+);
+
+CREATE INDEX IF NOT EXISTS Shipment_User_ID_Status_idx ON public.Shipment(User_ID, Status);
+
+CREATE TABLE IF NOT EXISTS public.ShipmentBox(
+    Shipment_ID bigint not null references public.Shipment(Shipment_ID) on delete cascade,
+    Box_Number integer not null,
+    Pallet_Number integer not null default 0,
+    Length integer not null,
+    Width integer not null,
+    Height integer not null,
+    primary key (Shipment_ID, Box_Number)
+);
+
+CREATE TABLE IF NOT EXISTS public.ShipmentBoxItem(
+    Shipment_ID bigint not null,
+    Box_Number integer not null,
+    Product_ID bigint not null references public.Product(Product_ID),
+    Quantity integer not null,
+    primary key (Shipment_ID, Box_Number, Product_ID),
+    foreign key (Shipment_ID, Box_Number) references public.ShipmentBox(Shipment_ID, Box_Number) on delete cascade
+);
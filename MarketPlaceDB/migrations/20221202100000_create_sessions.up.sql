@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS public.Session(
+    Session_ID varchar(64) not null primary key,
+    User_ID bigint not null references public.users(id) on delete cascade,
+    Created_At timestamp not null default now(),
+    Expires_At timestamp not null
+);
+
+CREATE INDEX IF NOT EXISTS Session_User_ID_idx ON public.Session(User_ID);
+CREATE INDEX IF NOT EXISTS Session_Expires_At_idx ON public.Session(Expires_At);
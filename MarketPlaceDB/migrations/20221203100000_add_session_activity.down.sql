@@ -0,0 +1,3 @@
+ALTER TABLE public.Session DROP COLUMN IF EXISTS IP;
+ALTER TABLE public.Session DROP COLUMN IF EXISTS User_Agent;
+ALTER TABLE public.Session DROP COLUMN IF EXISTS Last_Seen_At;
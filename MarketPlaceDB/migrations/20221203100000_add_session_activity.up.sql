@@ -0,0 +1,3 @@
+ALTER TABLE public.Session ADD COLUMN IF NOT EXISTS Last_Seen_At timestamp not null default now();
+ALTER TABLE public.Session ADD COLUMN IF NOT EXISTS User_Agent varchar(255) not null default '';
+ALTER TABLE public.Session ADD COLUMN IF NOT EXISTS IP varchar(45) not null default '';
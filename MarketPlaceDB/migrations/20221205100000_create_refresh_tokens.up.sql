@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS public.RefreshToken(
+    Token_ID varchar(36) not null primary key,
+    User_ID bigint not null references public.users(id) on delete cascade,
+    Token_Hash varchar(64) not null unique,
+    Session_Hash varchar(64) not null default '',
+    Created_At timestamp not null default now(),
+    Expires_At timestamp not null,
+    Revoked_At timestamp null,
+    Replaced_By varchar(36) not null default ''
+);
+
+CREATE INDEX IF NOT EXISTS RefreshToken_User_ID_idx ON public.RefreshToken(User_ID);
+CREATE INDEX IF NOT EXISTS RefreshToken_Session_Hash_idx ON public.RefreshToken(Session_Hash) WHERE Session_Hash <> '';
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS public.APIKey(
+    API_Key_ID bigserial not null primary key,
+    User_ID bigint not null references public.users(id) on delete cascade,
+    Key_Name varchar(100) not null,
+    Prefix varchar(16) not null,
+    Key_Hash varchar(64) not null unique,
+    Scopes text[] not null,
+    Expires_At timestamp null,
+    Last_Used_At timestamp null,
+    Created_At timestamp not null default now()
+);
+
+CREATE INDEX IF NOT EXISTS APIKey_User_ID_idx ON public.APIKey(User_ID);
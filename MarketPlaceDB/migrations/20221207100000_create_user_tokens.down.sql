@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS public.UserToken;
+ALTER TABLE public.users DROP COLUMN IF EXISTS emailVerified;
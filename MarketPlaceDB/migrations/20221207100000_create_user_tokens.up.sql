@@ -0,0 +1,16 @@
+ALTER TABLE public.users ADD COLUMN IF NOT EXISTS emailVerified boolean not null default false;
+-- users registered before the verification flow keep signing in
+UPDATE public.users SET emailVerified = true;
+
+CREATE TABLE IF NOT EXISTS public.UserToken(
+    Token_Hash varchar(64) not null primary key,
+    User_ID bigint not null references public.users(id) on delete cascade,
+    Purpose varchar(20) not null,
+    Email varchar not null,
+    Created_At timestamp not null default now(),
+    Expires_At timestamp not null,
+    Used_At timestamp null
+);
+
+CREATE INDEX IF NOT EXISTS UserToken_User_ID_idx ON public.UserToken(User_ID, Purpose);
+CREATE INDEX IF NOT EXISTS UserToken_Expires_At_idx ON public.UserToken(Expires_At);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS public.SignInFailure;
+DROP TABLE IF EXISTS public.AttemptCounter;
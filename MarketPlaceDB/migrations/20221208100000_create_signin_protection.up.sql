@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS public.AttemptCounter(
+    Counter_Key varchar(300) not null primary key,
+    Failures int not null,
+    Last_Failure_At timestamp not null
+);
+
+CREATE INDEX IF NOT EXISTS AttemptCounter_Last_Failure_At_idx ON public.AttemptCounter(Last_Failure_At);
+
+-- the audit log outlives the users, so User_ID has no foreign key and is 0 for unknown emails
+CREATE TABLE IF NOT EXISTS public.SignInFailure(
+    Failure_ID bigserial not null primary key,
+    User_ID bigint not null default 0,
+    Email varchar(255) not null,
+    IP varchar(45) not null,
+    User_Agent varchar(255) not null default '',
+    Reason varchar(20) not null,
+    Created_At timestamp not null default now()
+);
+
+CREATE INDEX IF NOT EXISTS SignInFailure_Email_idx ON public.SignInFailure(Email, Created_At);
+CREATE INDEX IF NOT EXISTS SignInFailure_IP_idx ON public.SignInFailure(IP, Created_At);
+CREATE INDEX IF NOT EXISTS SignInFailure_Created_At_idx ON public.SignInFailure(Created_At);
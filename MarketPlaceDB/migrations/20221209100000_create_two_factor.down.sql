@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS public.RecoveryCode;
+DROP TABLE IF EXISTS public.TwoFactor;
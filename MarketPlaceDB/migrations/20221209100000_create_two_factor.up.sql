@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS public.TwoFactor(
+    User_ID bigint not null primary key references public.users(id) on delete cascade,
+    Secret varchar(64) not null,
+    Last_Step bigint not null default 0,
+    Created_At timestamp not null default now(),
+    Enabled_At timestamp null
+);
+
+CREATE TABLE IF NOT EXISTS public.RecoveryCode(
+    Recovery_Code_ID bigserial not null primary key,
+    User_ID bigint not null references public.users(id) on delete cascade,
+    Code_Hash varchar(64) not null,
+    Used_At timestamp null,
+    UNIQUE (User_ID, Code_Hash)
+);
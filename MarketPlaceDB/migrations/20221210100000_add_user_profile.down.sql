@@ -0,0 +1,5 @@
+ALTER TABLE public.users DROP COLUMN IF EXISTS locale;
+ALTER TABLE public.users DROP COLUMN IF EXISTS baseCurrency;
+ALTER TABLE public.users DROP COLUMN IF EXISTS timezone;
+ALTER TABLE public.users DROP COLUMN IF EXISTS companyName;
+ALTER TABLE public.users DROP COLUMN IF EXISTS displayName;
@@ -0,0 +1,5 @@
+ALTER TABLE public.users ADD COLUMN IF NOT EXISTS displayName varchar(100) not null default '';
+ALTER TABLE public.users ADD COLUMN IF NOT EXISTS companyName varchar(200) not null default '';
+ALTER TABLE public.users ADD COLUMN IF NOT EXISTS timezone varchar(64) not null default 'Europe/Moscow';
+ALTER TABLE public.users ADD COLUMN IF NOT EXISTS baseCurrency varchar(3) not null default 'RUB';
+ALTER TABLE public.users ADD COLUMN IF NOT EXISTS locale varchar(35) not null default 'ru-RU';
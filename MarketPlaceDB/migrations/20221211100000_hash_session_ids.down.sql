@@ -0,0 +1,2 @@
+-- hashed session ids can not be turned back into plain ones
+DELETE FROM public.Session;
@@ -0,0 +1,2 @@
+-- session ids are stored hashed, sessions saved with plain ids can not be found anymore
+DELETE FROM public.Session;
@@ -3,34 +3,28 @@ package apiserver
 import (
 	"context"
 	"database/sql"
-	"log"
 	"net/http"
 	"time"
 
 	"github.com/VladimirBlinov/AuthService/pkg/authservice"
 	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/handler"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/pkg/filestorage"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/pkg/health"
 	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/service"
 	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store/sqlstore"
 	"github.com/gorilla/sessions"
 	"github.com/sirupsen/logrus"
-	"google.golang.org/grpc"
 )
 
 type ApiServer struct {
 	httpServer *http.Server
+	stopJobs   context.CancelFunc
 }
 
 func (s *ApiServer) Start(config *Config) error {
-	grcpConn, err := grpc.Dial(
-		"127.0.0.1:8081",
-		grpc.WithInsecure(),
-	)
-	if err != nil {
-		log.Fatalf("cant connect to grpc")
-	}
-	defer grcpConn.Close()
-
-	sessManager := authservice.NewAuthServiceClient(grcpConn)
+	var jobs context.Context
+	jobs, s.stopJobs = context.WithCancel(context.Background())
+	defer s.stopJobs()
 
 	db, err := newDB(config.DataBaseURL)
 	if err != nil {
@@ -43,10 +37,126 @@ func (s *ApiServer) Start(config *Config) error {
 		}
 	}(db)
 
+	sessionTimings, err := config.sessionTimings()
+	if err != nil {
+		return err
+	}
+
+	sessionCookie, err := config.SessionCookie.cookie(sessionTimings.ttl)
+	if err != nil {
+		return err
+	}
+
 	store := sqlstore.New(db)
+	readiness := health.NewChecker()
+
+	var sessManager authservice.AuthServiceClient
+	if config.SessionBackend == sessionBackendGRPC {
+		grcpConn, err := connectAuthService(jobs, &config.AuthService, readiness)
+		if err != nil {
+			return err
+		}
+		defer grcpConn.Close()
+
+		sessManager = authservice.NewAuthServiceClient(grcpConn)
+	} else {
+		localSessions, err := newLocalSessions(config.SessionBackend, sessionTimings.ttl, store)
+		if err != nil {
+			return err
+		}
+
+		sessManager = localSessions
+		if sessionTimings.cleanup > 0 {
+			go runCleanup(jobs, "session", localSessions.DeleteExpired, sessionTimings.cleanup, logrus.StandardLogger())
+		}
+	}
+
+	fileStorage, err := filestorage.NewLocalStorage(config.MediaDir, config.MediaURL)
+	if err != nil {
+		return err
+	}
+
+	tariffs, err := service.LoadTariffs(config.TariffsDir)
+	if err != nil {
+		return err
+	}
+
+	tokens, err := config.Tokens.option()
+	if err != nil {
+		return err
+	}
+
+	mail, err := config.Mail.option(logrus.StandardLogger())
+	if err != nil {
+		return err
+	}
+
+	signInProtection, err := config.SignInProtection.option()
+	if err != nil {
+		return err
+	}
+
+	trustedProxies, err := config.trustedProxies()
+	if err != nil {
+		return err
+	}
+
+	passwordPolicy, err := config.PasswordPolicy.option()
+	if err != nil {
+		return err
+	}
+
+	passwordHashing, err := config.PasswordHashing.option()
+	if err != nil {
+		return err
+	}
+
 	sessionStore := sessions.NewCookieStore([]byte(config.SessionKey))
-	services := service.NewService(store)
-	handlers := handler.NewHandler(services, sessionStore, sessManager)
+	services := service.NewService(
+		store,
+		service.WithFileStorage(fileStorage),
+		service.WithImageLimits(config.MaxImageSize, config.ThumbnailSize),
+		service.WithBarcodePrefix(config.BarcodePrefix),
+		service.WithLabelSize(config.LabelSize),
+		service.WithTariffs(tariffs),
+		tokens,
+		mail,
+		signInProtection,
+		service.WithTwoFactor(config.TwoFactor.Issuer, config.TwoFactor.EnforceAdmin),
+		passwordPolicy,
+		passwordHashing,
+	)
+	repricingInterval, err := time.ParseDuration(config.RepricingInterval)
+	if err != nil {
+		return err
+	}
+
+	if repricingInterval > 0 {
+		go runRepricing(jobs, services.RepricingService, repricingInterval, logrus.StandardLogger())
+	}
+	if services.TokenService.Enabled() && sessionTimings.cleanup > 0 {
+		go runCleanup(jobs, "refresh token", services.TokenService.DeleteExpired, sessionTimings.cleanup, logrus.StandardLogger())
+	}
+	// user tokens also hold two-factor sign-in challenges, they are cleaned up without a mailer too
+	if sessionTimings.cleanup > 0 {
+		go runCleanup(jobs, "user token", services.AuthService.DeleteExpiredTokens, sessionTimings.cleanup, logrus.StandardLogger())
+	}
+	if sessionTimings.cleanup > 0 {
+		go runCleanup(jobs, "sign-in counter", services.LockoutService.DeleteExpired, sessionTimings.cleanup, logrus.StandardLogger())
+	}
+
+	handlers := handler.NewHandler(
+		services,
+		sessionStore,
+		sessManager,
+		handler.WithReadiness(readiness),
+		handler.WithSessionCookie(sessionCookie),
+		handler.WithSessionTimeouts(handler.SessionTimeouts{
+			Idle:     sessionTimings.idle,
+			Absolute: sessionTimings.ttl,
+		}),
+		handler.WithTrustedProxies(trustedProxies),
+	)
 	handlers.InitHandler()
 
 	s.httpServer = &http.Server{
@@ -73,5 +183,8 @@ func newDB(databaseURL string) (*sql.DB, error) {
 }
 
 func (s *ApiServer) ShutDown(ctx context.Context) error {
+	if s.stopJobs != nil {
+		s.stopJobs()
+	}
 	return s.httpServer.Shutdown(ctx)
 }
@@ -0,0 +1,185 @@
+package apiserver
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/pkg/health"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/keepalive"
+)
+
+const authServiceComponent = "auth_service"
+
+// authServiceTimings are the parsed durations of AuthServiceConfig
+type authServiceTimings struct {
+	dial             time.Duration
+	keepaliveTime    time.Duration
+	keepaliveTimeout time.Duration
+	healthInterval   time.Duration
+}
+
+func (c *AuthServiceConfig) timings() (*authServiceTimings, error) {
+	t := &authServiceTimings{}
+	for name, d := range map[string]struct {
+		value  string
+		target *time.Duration
+	}{
+		"dial_timeout":          {c.DialTimeout, &t.dial},
+		"keepalive_time":        {c.KeepaliveTime, &t.keepaliveTime},
+		"keepalive_timeout":     {c.KeepaliveTimeout, &t.keepaliveTimeout},
+		"health_check_interval": {c.HealthCheckInterval, &t.healthInterval},
+	} {
+		v, err := time.ParseDuration(d.value)
+		if err != nil {
+			return nil, fmt.Errorf("auth_service.%s: %w", name, err)
+		}
+		*d.target = v
+	}
+
+	if t.dial <= 0 {
+		return nil, errors.New("auth_service.dial_timeout must be positive")
+	}
+
+	return t, nil
+}
+
+// connectAuthService dials the session manager, waits for it to serve and keeps watching
+// its health in the readiness checker until ctx is done
+func connectAuthService(ctx context.Context, c *AuthServiceConfig, readiness *health.Checker) (*grpc.ClientConn, error) {
+	t, err := c.timings()
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := dialAuthService(c, t)
+	if err != nil {
+		return nil, fmt.Errorf("auth service %s: %w", c.Addr, err)
+	}
+
+	client := healthpb.NewHealthClient(conn)
+	if err = checkAuthService(ctx, client, c.HealthService, t.dial, true); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("auth service %s is not available: %w", c.Addr, err)
+	}
+	readiness.Set(authServiceComponent, nil)
+
+	if t.healthInterval > 0 {
+		go watchAuthService(ctx, client, c.HealthService, t.healthInterval, t.dial, readiness, logrus.StandardLogger())
+	}
+
+	return conn, nil
+}
+
+// dialAuthService connects to the session manager, the connection is established lazily
+// and the startup health check waits for it
+func dialAuthService(c *AuthServiceConfig, t *authServiceTimings) (*grpc.ClientConn, error) {
+	creds, err := authServiceCredentials(c)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []grpc.DialOption{grpc.WithTransportCredentials(creds)}
+	if t.keepaliveTime > 0 {
+		opts = append(opts, grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:    t.keepaliveTime,
+			Timeout: t.keepaliveTimeout,
+		}))
+	}
+
+	return grpc.Dial(c.Addr, opts...)
+}
+
+func authServiceCredentials(c *AuthServiceConfig) (credentials.TransportCredentials, error) {
+	if !c.TLS {
+		return insecure.NewCredentials(), nil
+	}
+
+	tlsConfig := &tls.Config{
+		ServerName: c.ServerName,
+		MinVersion: tls.VersionTLS12,
+	}
+
+	if c.CAFile != "" {
+		ca, err := os.ReadFile(c.CAFile)
+		if err != nil {
+			return nil, err
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("no certificates found in %s", c.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if c.CertFile != "" || c.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return credentials.NewTLS(tlsConfig), nil
+}
+
+// checkAuthService asks the health service of the session manager whether it is serving.
+// With wait the check waits for the connection until the timeout instead of failing fast.
+func checkAuthService(ctx context.Context, client healthpb.HealthClient, service string, timeout time.Duration, wait bool) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	resp, err := client.Check(ctx, &healthpb.HealthCheckRequest{Service: service}, grpc.WaitForReady(wait))
+	if err != nil {
+		return err
+	}
+
+	if resp.Status != healthpb.HealthCheckResponse_SERVING {
+		return fmt.Errorf("auth service is %s", resp.Status)
+	}
+
+	return nil
+}
+
+// watchAuthService checks the session manager every interval until ctx is done and keeps
+// the result in the readiness checker. Only changes of the state are logged.
+func watchAuthService(
+	ctx context.Context,
+	client healthpb.HealthClient,
+	service string,
+	interval time.Duration,
+	timeout time.Duration,
+	readiness *health.Checker,
+	logger *logrus.Logger,
+) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	ready := true
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			err := checkAuthService(ctx, client, service, timeout, false)
+			readiness.Set(authServiceComponent, err)
+
+			if err != nil && ready {
+				logger.Errorf("auth service health check: %s", err.Error())
+			} else if err == nil && !ready {
+				logger.Info("auth service is serving again")
+			}
+			ready = err == nil
+		}
+	}
+}
@@ -1,15 +1,227 @@
 package apiserver
 
+import "github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+
 type Config struct {
-	BindAddr    string `toml:"bind_addr"`
-	LogLevel    string `toml:"log_level"`
-	DataBaseURL string `toml:"database_url"`
-	SessionKey  string `toml:"session_key"`
+	BindAddr      string `toml:"bind_addr"`
+	LogLevel      string `toml:"log_level"`
+	DataBaseURL   string `toml:"database_url"`
+	SessionKey    string `toml:"session_key"`
+	MediaDir      string `toml:"media_dir"`
+	MediaURL      string `toml:"media_url"`
+	MaxImageSize  int64  `toml:"max_image_size"`
+	ThumbnailSize int    `toml:"thumbnail_size"`
+	BarcodePrefix string `toml:"barcode_prefix"`
+	LabelSize     string `toml:"label_size"`
+	TariffsDir    string `toml:"tariffs_dir"`
+	// RepricingInterval is the period of the repricing job as a duration like "1h", "0" disables the job
+	RepricingInterval string `toml:"repricing_interval"`
+
+	// SessionBackend selects the session manager: "postgres" and "memory" keep sessions in the API
+	// server itself, "grpc" is the external auth service. With "grpc" the API server knows only the
+	// session ids, so the session timeouts are not enforced and the session list and the sign-out
+	// of all sessions are not available.
+	SessionBackend string `toml:"session_backend"`
+	// SessionTTL is the absolute session timeout and the lifetime of the session cookie,
+	// SessionIdleTimeout ends sessions not used for the time. The timeouts are enforced for
+	// the postgres and memory backends, "0" disables the idle timeout or the cleanup of expired
+	// sessions and refresh tokens.
+	SessionTTL             string              `toml:"session_ttl"`
+	SessionIdleTimeout     string              `toml:"session_idle_timeout"`
+	SessionCleanupInterval string              `toml:"session_cleanup_interval"`
+	SessionCookie          SessionCookieConfig `toml:"session_cookie"`
+
+	AuthService AuthServiceConfig `toml:"auth_service"`
+	Tokens      TokenConfig       `toml:"tokens"`
+	Mail        MailConfig        `toml:"mail"`
+
+	// TrustedProxies are addresses or CIDR ranges of reverse proxies in front of the server, for their
+	// requests the client address is taken from X-Forwarded-For. It must be set behind a proxy,
+	// otherwise all clients share the proxy address and the address lockout of sign-in protection
+	// locks out everyone.
+	TrustedProxies   []string               `toml:"trusted_proxies"`
+	SignInProtection SignInProtectionConfig `toml:"signin_protection"`
+	TwoFactor        TwoFactorConfig        `toml:"two_factor"`
+	PasswordPolicy   PasswordPolicyConfig   `toml:"password_policy"`
+	PasswordHashing  PasswordHashingConfig  `toml:"password_hashing"`
+}
+
+// PasswordPolicyConfig holds the rules of new passwords. Lengths are in characters, zero MaxLength
+// has no limit, but bcrypt uses only the first 72 bytes. CharacterClasses is how many of lowercase
+// letters, uppercase letters, digits and symbols a password must mix. BreachedList is a file of
+// passwords users can't choose, one password or its SHA-1 hash in hex per line.
+type PasswordPolicyConfig struct {
+	MinLength        int    `toml:"min_length"`
+	MaxLength        int    `toml:"max_length"`
+	CharacterClasses int    `toml:"character_classes"`
+	BreachedList     string `toml:"breached_list"`
+}
+
+// PasswordHashingConfig selects "bcrypt" or "argon2id" for new password hashes, Argon2Memory is
+// in KiB. Existing hashes are replaced on sign-in when the algorithm or its parameters change.
+type PasswordHashingConfig struct {
+	Algorithm     string `toml:"algorithm"`
+	BcryptCost    int    `toml:"bcrypt_cost"`
+	Argon2Time    uint32 `toml:"argon2_time"`
+	Argon2Memory  uint32 `toml:"argon2_memory"`
+	Argon2Threads int    `toml:"argon2_threads"`
+}
+
+// TwoFactorConfig holds the issuer name authenticator apps show for the account, with
+// EnforceAdmin administrators have to enable two-factor authentication to use admin endpoints
+type TwoFactorConfig struct {
+	Issuer       string `toml:"issuer"`
+	EnforceAdmin bool   `toml:"enforce_admin"`
+}
+
+// SignInProtectionConfig limits failed sign-ins per account and per client address. CounterStore
+// is "postgres" or "memory", the memory store suits a single API server. Counters start over
+// after ResetAfter without failures, the audit log of failed sign-ins is kept for AuditRetention.
+type SignInProtectionConfig struct {
+	CounterStore   string        `toml:"counter_store"`
+	ResetAfter     string        `toml:"reset_after"`
+	AuditRetention string        `toml:"audit_retention"`
+	Account        LockoutConfig `toml:"account"`
+	IP             LockoutConfig `toml:"ip"`
+}
+
+// LockoutConfig allows FreeAttempts failures, then doubles the delay before the next attempt from
+// BaseDelay up to MaxDelay and locks sign-in for LockoutDuration after LockAfter failures.
+// Zero LockAfter disables the lockout.
+type LockoutConfig struct {
+	FreeAttempts    int    `toml:"free_attempts"`
+	BaseDelay       string `toml:"base_delay"`
+	MaxDelay        string `toml:"max_delay"`
+	LockAfter       int    `toml:"lock_after"`
+	LockoutDuration string `toml:"lockout_duration"`
+}
+
+// MailConfig selects how verification and password reset emails are sent: "smtp" sends them
+// through SMTPAddr, "file" appends them to File and "log" writes them to the log, which suits
+// development only. With the empty default backend users are verified on registration and
+// passwords can't be reset. AppURL is the address of the web app the emailed links point to.
+type MailConfig struct {
+	Backend  string `toml:"backend"`
+	From     string `toml:"from"`
+	AppURL   string `toml:"app_url"`
+	File     string `toml:"file"`
+	SMTPAddr string `toml:"smtp_addr"`
+	Username string `toml:"username"`
+	Password string `toml:"password"`
+}
+
+// SessionCookieConfig holds attributes of the session id cookie, SameSite is "lax", "strict" or "none".
+// Secure must be set when the API is served over HTTPS.
+type SessionCookieConfig struct {
+	Path     string `toml:"path"`
+	Domain   string `toml:"domain"`
+	Secure   bool   `toml:"secure"`
+	HttpOnly bool   `toml:"http_only"`
+	SameSite string `toml:"same_site"`
+}
+
+// TokenConfig enables JWT access and refresh tokens when JWKSFile is set. The JWKS holds private
+// keys, tokens are signed with SigningKeyID or the first private key and verified with every key,
+// so keys are rotated by adding a new key, moving SigningKeyID to it and dropping the old key
+// once the access tokens it signed have expired.
+type TokenConfig struct {
+	JWKSFile     string `toml:"jwks_file"`
+	SigningKeyID string `toml:"signing_key_id"`
+	Issuer       string `toml:"issuer"`
+	AccessTTL    string `toml:"access_ttl"`
+	RefreshTTL   string `toml:"refresh_ttl"`
+}
+
+// AuthServiceConfig describes the connection to the gRPC session manager. With TLS the server
+// certificate is checked against CAFile or the system roots, CertFile and KeyFile enable mTLS.
+// Durations are written like "5s".
+type AuthServiceConfig struct {
+	Addr                string `toml:"addr"`
+	TLS                 bool   `toml:"tls"`
+	CAFile              string `toml:"ca_file"`
+	CertFile            string `toml:"cert_file"`
+	KeyFile             string `toml:"key_file"`
+	ServerName          string `toml:"server_name"`
+	DialTimeout         string `toml:"dial_timeout"`
+	KeepaliveTime       string `toml:"keepalive_time"`
+	KeepaliveTimeout    string `toml:"keepalive_timeout"`
+	HealthCheckInterval string `toml:"health_check_interval"`
+	// HealthService is the service name sent in health checks, empty checks the server as a whole
+	HealthService string `toml:"health_service"`
 }
 
 func NewConfig() *Config {
 	return &Config{
-		BindAddr: "8080",
-		LogLevel: "debug",
+		BindAddr:               "8080",
+		LogLevel:               "debug",
+		MediaDir:               "media",
+		MediaURL:               "/api/v1/media",
+		MaxImageSize:           10 << 20,
+		ThumbnailSize:          300,
+		BarcodePrefix:          "200",
+		LabelSize:              "58x40",
+		TariffsDir:             "configs/tariffs",
+		RepricingInterval:      "1h",
+		SessionBackend:         sessionBackendPostgres,
+		SessionTTL:             "720h",
+		SessionIdleTimeout:     "72h",
+		SessionCleanupInterval: "1h",
+		SessionCookie: SessionCookieConfig{
+			Path:     "/",
+			HttpOnly: true,
+			SameSite: "lax",
+		},
+		Tokens: TokenConfig{
+			Issuer:     "marketplace",
+			AccessTTL:  "15m",
+			RefreshTTL: "720h",
+		},
+		Mail: MailConfig{
+			From:   "Marketplace <noreply@localhost>",
+			AppURL: "http://localhost:3000",
+		},
+		SignInProtection: SignInProtectionConfig{
+			CounterStore:   counterStorePostgres,
+			ResetAfter:     "24h",
+			AuditRetention: "2160h",
+			Account: LockoutConfig{
+				FreeAttempts:    3,
+				BaseDelay:       "1s",
+				MaxDelay:        "5m",
+				LockAfter:       10,
+				LockoutDuration: "30m",
+			},
+			IP: LockoutConfig{
+				FreeAttempts:    20,
+				BaseDelay:       "1s",
+				MaxDelay:        "5m",
+				LockAfter:       100,
+				LockoutDuration: "1h",
+			},
+		},
+		TwoFactor: TwoFactorConfig{
+			Issuer:       "Marketplace",
+			EnforceAdmin: true,
+		},
+		PasswordPolicy: PasswordPolicyConfig{
+			MinLength:        8,
+			MaxLength:        72,
+			CharacterClasses: 1,
+			BreachedList:     "configs/breached_passwords.txt",
+		},
+		PasswordHashing: PasswordHashingConfig{
+			Algorithm:     model.PasswordHashBcrypt,
+			BcryptCost:    12,
+			Argon2Time:    3,
+			Argon2Memory:  64 * 1024,
+			Argon2Threads: 2,
+		},
+		AuthService: AuthServiceConfig{
+			Addr:                "127.0.0.1:8081",
+			DialTimeout:         "5s",
+			KeepaliveTime:       "30s",
+			KeepaliveTimeout:    "10s",
+			HealthCheckInterval: "10s",
+		},
 	}
 }
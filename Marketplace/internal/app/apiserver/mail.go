@@ -0,0 +1,49 @@
+package apiserver
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/pkg/mailer"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/service"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	mailBackendSMTP = "smtp"
+	mailBackendFile = "file"
+	mailBackendLog  = "log"
+)
+
+// option returns the service option of the mailer, the log backend writes messages with the logger
+func (c *MailConfig) option(logger *logrus.Logger) (service.Option, error) {
+	if c.Backend == "" {
+		return service.WithMailer(nil, ""), nil
+	}
+
+	if u, err := url.Parse(c.AppURL); err != nil || u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("mail.app_url must be an absolute URL, got %q", c.AppURL)
+	}
+
+	var m mailer.Mailer
+	switch c.Backend {
+	case mailBackendSMTP:
+		smtpMailer, err := mailer.NewSMTPMailer(c.SMTPAddr, c.Username, c.Password, c.From)
+		if err != nil {
+			return nil, fmt.Errorf("mail: %w", err)
+		}
+		m = smtpMailer
+	case mailBackendFile:
+		fileMailer, err := mailer.NewFileMailer(c.File, c.From)
+		if err != nil {
+			return nil, fmt.Errorf("mail.file: %w", err)
+		}
+		m = fileMailer
+	case mailBackendLog:
+		m = mailer.NewLocalMailer(logger.Writer(), c.From)
+	default:
+		return nil, fmt.Errorf("unknown mail backend %q", c.Backend)
+	}
+
+	return service.WithMailer(m, c.AppURL), nil
+}
@@ -0,0 +1,55 @@
+package apiserver
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/service"
+)
+
+// option returns the service option of the password policy, the breached password list is read once on start
+func (c *PasswordPolicyConfig) option() (service.Option, error) {
+	if c.MinLength < 1 || c.MaxLength > 0 && c.MaxLength < c.MinLength {
+		return nil, errors.New("password_policy.min_length must be positive and not longer than password_policy.max_length")
+	}
+
+	if c.CharacterClasses < 0 || c.CharacterClasses > 4 {
+		return nil, errors.New("password_policy.character_classes must be from 0 to 4")
+	}
+
+	policy := model.PasswordPolicy{
+		MinLength:        c.MinLength,
+		MaxLength:        c.MaxLength,
+		CharacterClasses: c.CharacterClasses,
+	}
+
+	if c.BreachedList != "" {
+		breached, err := service.LoadBreachedPasswords(c.BreachedList)
+		if err != nil {
+			return nil, fmt.Errorf("password_policy.breached_list: %w", err)
+		}
+		policy.Breached = breached
+	}
+
+	return service.WithPasswordPolicy(policy), nil
+}
+
+func (c *PasswordHashingConfig) option() (service.Option, error) {
+	if c.Argon2Threads < 0 || c.Argon2Threads > 255 {
+		return nil, errors.New("password_hashing.argon2_threads must be from 1 to 255")
+	}
+
+	hasher := model.PasswordHasher{
+		Algorithm:     c.Algorithm,
+		BcryptCost:    c.BcryptCost,
+		Argon2Time:    c.Argon2Time,
+		Argon2Memory:  c.Argon2Memory,
+		Argon2Threads: uint8(c.Argon2Threads),
+	}
+	if err := hasher.Validate(); err != nil {
+		return nil, fmt.Errorf("password_hashing: %w", err)
+	}
+
+	return service.WithPasswordHasher(hasher), nil
+}
@@ -0,0 +1,33 @@
+package apiserver
+
+import (
+	"context"
+	"time"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/service"
+	"github.com/sirupsen/logrus"
+)
+
+// runRepricing proposes prices by the repricing rules of all sellers every interval until ctx is done
+func runRepricing(ctx context.Context, s *service.RepricingService, interval time.Duration, logger *logrus.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case at := <-ticker.C:
+			count, err := s.Run(at)
+			if errs, ok := err.(service.RepricingErrors); ok {
+				for _, userErr := range errs {
+					logger.Errorf("repricing: %s", userErr.Error())
+				}
+			} else if err != nil {
+				logger.Errorf("repricing: %s", err.Error())
+				continue
+			}
+			logger.Infof("repricing: %d prices proposed", count)
+		}
+	}
+}
@@ -5,9 +5,17 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"mime/multipart"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"regexp"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/VladimirBlinov/AuthService/pkg/authservice"
 	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/handler"
@@ -15,11 +23,22 @@ import (
 
 	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
 	authservicefake "github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/pkg/authservice/fake"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/pkg/filestorage"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/pkg/health"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/pkg/jwt"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/pkg/mailer"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/pkg/totp"
 	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/service"
 	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store/teststore"
 	"github.com/gorilla/securecookie"
 	"github.com/gorilla/sessions"
+	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	grpchealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/test/bufconn"
 )
 
 var sessManager authservice.AuthServiceClient
@@ -465,6 +484,7 @@ func TestServer_HandleProductUpdate(t *testing.T) {
 				"description":     p.Description,
 				"wildberries_sku": 2222222222,
 				"ozon_sku":        1111111111,
+				"version":         1,
 			},
 			serviceCookieValue: sessionS.ID,
 			coockieValue: map[interface{}]interface{}{
@@ -488,6 +508,7 @@ func TestServer_HandleProductUpdate(t *testing.T) {
 				"description":     p1.Description,
 				"wildberries_sku": 2222222222,
 				"ozon_sku":        1111111111,
+				"version":         2,
 			},
 			serviceCookieValue: sessionS.ID,
 			coockieValue: map[interface{}]interface{}{
@@ -495,6 +516,21 @@ func TestServer_HandleProductUpdate(t *testing.T) {
 			},
 			expectedCode: http.StatusCreated,
 		},
+		{
+			name:    "without_version",
+			context: u,
+			payload: map[string]interface{}{
+				"product_name": p.ProductName,
+				"category_id":  p.CategoryID,
+				"material_id":  p.MaterialID,
+				"description":  "unchecked description",
+			},
+			serviceCookieValue: sessionS.ID,
+			coockieValue: map[interface{}]interface{}{
+				"user_id": u.ID,
+			},
+			expectedCode: http.StatusPreconditionRequired,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -789,3 +825,2724 @@ func TestServer_HandleSignIn(t *testing.T) {
 		})
 	}
 }
+
+func TestServer_HandleProductRevisions(t *testing.T) {
+	store := teststore.New()
+	srvc := service.NewService(store)
+	u := model.TestUser(t)
+	store.User().Create(u)
+
+	p := model.TestProduct(t)
+	p.UserID = u.ID
+	mpiList := &model.MarketPlaceItemsList{}
+	mpiList.GetMPIList(p)
+	store.Product().Create(p, mpiList)
+
+	p1 := *p
+	p1.Description = "new description"
+	srvc.ProductService.UpdateProduct(p.ProductID, &p1)
+
+	other := model.TestUser(t)
+	other.Email = "other@test.org"
+	store.User().Create(other)
+	otherProduct := model.TestProduct(t)
+	otherProduct.UserID = other.ID
+	otherList := &model.MarketPlaceItemsList{}
+	otherList.GetMPIList(otherProduct)
+	store.Product().Create(otherProduct, otherList)
+
+	sessManager := authservicefake.NewAuthServiceClientFake()
+	sessionS, _ := sessManager.Create(context.Background(), &authservice.Session{
+		UserID: int32(u.ID),
+	})
+
+	secretKey := []byte("secret_key")
+	handlers := handler.NewHandler(srvc, sessions.NewCookieStore(secretKey), sessManager)
+	handlers.InitHandler()
+	sc := securecookie.New(secretKey, nil)
+
+	testCases := []struct {
+		name               string
+		method             string
+		url                string
+		context            *model.User
+		serviceCookieValue string
+		coockieValue       map[interface{}]interface{}
+		expectedCode       int
+	}{
+		{
+			name:               "list",
+			method:             http.MethodGet,
+			url:                fmt.Sprintf("/api/v1/private/product/product/%v/revisions", p.ProductID),
+			context:            u,
+			serviceCookieValue: sessionS.ID,
+			coockieValue: map[interface{}]interface{}{
+				"user_id": u.ID,
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:               "list_not_found",
+			method:             http.MethodGet,
+			url:                fmt.Sprintf("/api/v1/private/product/product/%v/revisions", p.ProductID+1),
+			context:            u,
+			serviceCookieValue: sessionS.ID,
+			coockieValue: map[interface{}]interface{}{
+				"user_id": u.ID,
+			},
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:               "diff",
+			method:             http.MethodGet,
+			url:                fmt.Sprintf("/api/v1/private/product/product/%v/revisions/diff?from=1&to=2", p.ProductID),
+			context:            u,
+			serviceCookieValue: sessionS.ID,
+			coockieValue: map[interface{}]interface{}{
+				"user_id": u.ID,
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:               "diff_without_params",
+			method:             http.MethodGet,
+			url:                fmt.Sprintf("/api/v1/private/product/product/%v/revisions/diff", p.ProductID),
+			context:            u,
+			serviceCookieValue: sessionS.ID,
+			coockieValue: map[interface{}]interface{}{
+				"user_id": u.ID,
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:               "rollback",
+			method:             http.MethodPost,
+			url:                fmt.Sprintf("/api/v1/private/product/product/%v/revisions/1/rollback", p.ProductID),
+			context:            u,
+			serviceCookieValue: sessionS.ID,
+			coockieValue: map[interface{}]interface{}{
+				"user_id": u.ID,
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:               "rollback_unknown_revision",
+			method:             http.MethodPost,
+			url:                fmt.Sprintf("/api/v1/private/product/product/%v/revisions/10/rollback", p.ProductID),
+			context:            u,
+			serviceCookieValue: sessionS.ID,
+			coockieValue: map[interface{}]interface{}{
+				"user_id": u.ID,
+			},
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:               "list_other_user",
+			method:             http.MethodGet,
+			url:                fmt.Sprintf("/api/v1/private/product/product/%v/revisions", otherProduct.ProductID),
+			context:            u,
+			serviceCookieValue: sessionS.ID,
+			coockieValue: map[interface{}]interface{}{
+				"user_id": u.ID,
+			},
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:               "diff_other_user",
+			method:             http.MethodGet,
+			url:                fmt.Sprintf("/api/v1/private/product/product/%v/revisions/diff?from=1&to=1", otherProduct.ProductID),
+			context:            u,
+			serviceCookieValue: sessionS.ID,
+			coockieValue: map[interface{}]interface{}{
+				"user_id": u.ID,
+			},
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:               "rollback_other_user",
+			method:             http.MethodPost,
+			url:                fmt.Sprintf("/api/v1/private/product/product/%v/revisions/1/rollback", otherProduct.ProductID),
+			context:            u,
+			serviceCookieValue: sessionS.ID,
+			coockieValue: map[interface{}]interface{}{
+				"user_id": u.ID,
+			},
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			req, _ := http.NewRequest(tc.method, tc.url, nil)
+			coockieStr, _ := sc.Encode(handler.SessionName, tc.coockieValue)
+			req.Header.Set("Cookie", fmt.Sprintf("%s=%s", handler.SessionName, coockieStr))
+			req.Header.Add("Cookie", fmt.Sprintf("%s=%s", handler.SessionIDKey, tc.serviceCookieValue))
+			ctx := context.WithValue(req.Context(), handler.CtxKeyUser, tc.context)
+			handlers.Router.ServeHTTP(rec, req.WithContext(ctx))
+			assert.Equal(t, tc.expectedCode, rec.Code)
+		})
+	}
+
+	rolledBack, _ := store.Product().GetProductById(p.ProductID)
+	assert.Equal(t, "описание", rolledBack.Description)
+
+	notTaken, _ := store.Product().GetProductById(otherProduct.ProductID)
+	assert.Equal(t, other.ID, notTaken.UserID)
+}
+
+func TestServer_HandleProductUpdateVersion(t *testing.T) {
+	store := teststore.New()
+	srvc := service.NewService(store)
+	u := model.TestUser(t)
+	store.User().Create(u)
+
+	p := model.TestProduct(t)
+	p.UserID = u.ID
+	mpiList := &model.MarketPlaceItemsList{}
+	mpiList.GetMPIList(p)
+	store.Product().Create(p, mpiList)
+
+	sessManager := authservicefake.NewAuthServiceClientFake()
+	sessionS, _ := sessManager.Create(context.Background(), &authservice.Session{
+		UserID: int32(u.ID),
+	})
+
+	secretKey := []byte("secret_key")
+	handlers := handler.NewHandler(srvc, sessions.NewCookieStore(secretKey), sessManager)
+	handlers.InitHandler()
+	sc := securecookie.New(secretKey, nil)
+
+	payload := func(version int) map[string]interface{} {
+		return map[string]interface{}{
+			"product_name": p.ProductName,
+			"category_id":  p.CategoryID,
+			"material_id":  p.MaterialID,
+			"description":  "new description",
+			"version":      version,
+		}
+	}
+
+	testCases := []struct {
+		name         string
+		payload      interface{}
+		ifMatch      string
+		expectedCode int
+		expectedETag string
+	}{
+		{
+			name:         "valid_if_match",
+			payload:      payload(0),
+			ifMatch:      `"1"`,
+			expectedCode: http.StatusCreated,
+			expectedETag: `"2"`,
+		},
+		{
+			name:         "stale_if_match",
+			payload:      payload(0),
+			ifMatch:      `"1"`,
+			expectedCode: http.StatusPreconditionFailed,
+			expectedETag: `"2"`,
+		},
+		{
+			name:         "invalid_if_match",
+			payload:      payload(0),
+			ifMatch:      `"abc"`,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "stale_body_version",
+			payload:      payload(1),
+			expectedCode: http.StatusConflict,
+			expectedETag: `"2"`,
+		},
+		{
+			name:         "valid_body_version",
+			payload:      payload(2),
+			expectedCode: http.StatusCreated,
+			expectedETag: `"3"`,
+		},
+		{
+			name:         "without_version",
+			payload:      payload(0),
+			expectedCode: http.StatusPreconditionRequired,
+		},
+		{
+			name:         "any_if_match",
+			payload:      payload(0),
+			ifMatch:      "*",
+			expectedCode: http.StatusPreconditionRequired,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			b := &bytes.Buffer{}
+			json.NewEncoder(b).Encode(tc.payload)
+			req, _ := http.NewRequest(http.MethodPut, fmt.Sprintf("/api/v1/private/product/product/%v", p.ProductID), b)
+			coockieStr, _ := sc.Encode(handler.SessionName, map[interface{}]interface{}{"user_id": u.ID})
+			req.Header.Set("Cookie", fmt.Sprintf("%s=%s", handler.SessionName, coockieStr))
+			req.Header.Add("Cookie", fmt.Sprintf("%s=%s", handler.SessionIDKey, sessionS.ID))
+			if tc.ifMatch != "" {
+				req.Header.Set("If-Match", tc.ifMatch)
+			}
+			ctx := context.WithValue(req.Context(), handler.CtxKeyUser, u)
+			handlers.Router.ServeHTTP(rec, req.WithContext(ctx))
+			assert.Equal(t, tc.expectedCode, rec.Code)
+			assert.Equal(t, tc.expectedETag, rec.Header().Get("ETag"))
+		})
+	}
+}
+
+func TestServer_HandleProductPatch(t *testing.T) {
+	store := teststore.New()
+	srvc := service.NewService(store)
+	u := model.TestUser(t)
+	store.User().Create(u)
+
+	p := model.TestProduct(t)
+	p.UserID = u.ID
+	mpiList := &model.MarketPlaceItemsList{}
+	mpiList.GetMPIList(p)
+	store.Product().Create(p, mpiList)
+
+	other := model.TestUser(t)
+	other.Email = "other@test.org"
+	store.User().Create(other)
+	otherProduct := model.TestProduct(t)
+	otherProduct.UserID = other.ID
+	store.Product().Create(otherProduct, &model.MarketPlaceItemsList{})
+
+	sessManager := authservicefake.NewAuthServiceClientFake()
+	sessionS, _ := sessManager.Create(context.Background(), &authservice.Session{
+		UserID: int32(u.ID),
+	})
+
+	secretKey := []byte("secret_key")
+	handlers := handler.NewHandler(srvc, sessions.NewCookieStore(secretKey), sessManager)
+	handlers.InitHandler()
+	sc := securecookie.New(secretKey, nil)
+
+	testCases := []struct {
+		name         string
+		productId    int
+		payload      string
+		contentType  string
+		ifMatch      string
+		expectedCode int
+	}{
+		{
+			name:         "valid",
+			payload:      `{"description": "new description"}`,
+			contentType:  "application/merge-patch+json",
+			ifMatch:      `"1"`,
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "valid_sku",
+			payload:      `{"ozon_sku": 5555555}`,
+			contentType:  "application/merge-patch+json",
+			ifMatch:      `"2"`,
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "stale_if_match",
+			payload:      `{"description": "stale"}`,
+			contentType:  "application/merge-patch+json",
+			ifMatch:      `"1"`,
+			expectedCode: http.StatusPreconditionFailed,
+		},
+		{
+			name:         "without_version",
+			payload:      `{"description": "unchecked"}`,
+			contentType:  "application/merge-patch+json",
+			expectedCode: http.StatusPreconditionRequired,
+		},
+		{
+			name:         "stale_body_version",
+			payload:      `{"description": "stale", "version": 2}`,
+			contentType:  "application/merge-patch+json",
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name:         "invalid_product",
+			payload:      `{"product_name": null, "version": 3}`,
+			contentType:  "application/merge-patch+json",
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:         "other_user",
+			productId:    otherProduct.ProductID,
+			payload:      `{"description": "taken over"}`,
+			contentType:  "application/merge-patch+json",
+			ifMatch:      `"1"`,
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "not_object",
+			payload:      `["description"]`,
+			contentType:  "application/merge-patch+json",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "unsupported_content_type",
+			payload:      `{"description": "text"}`,
+			contentType:  "text/plain",
+			expectedCode: http.StatusUnsupportedMediaType,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			productId := tc.productId
+			if productId == 0 {
+				productId = p.ProductID
+			}
+			rec := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodPatch, fmt.Sprintf("/api/v1/private/product/product/%v", productId), bytes.NewBufferString(tc.payload))
+			coockieStr, _ := sc.Encode(handler.SessionName, map[interface{}]interface{}{"user_id": u.ID})
+			req.Header.Set("Cookie", fmt.Sprintf("%s=%s", handler.SessionName, coockieStr))
+			req.Header.Add("Cookie", fmt.Sprintf("%s=%s", handler.SessionIDKey, sessionS.ID))
+			req.Header.Set("Content-Type", tc.contentType)
+			if tc.ifMatch != "" {
+				req.Header.Set("If-Match", tc.ifMatch)
+			}
+			ctx := context.WithValue(req.Context(), handler.CtxKeyUser, u)
+			handlers.Router.ServeHTTP(rec, req.WithContext(ctx))
+			assert.Equal(t, tc.expectedCode, rec.Code)
+		})
+	}
+
+	patched, _ := store.Product().GetProductById(p.ProductID)
+	assert.Equal(t, "new description", patched.Description)
+	assert.Equal(t, 5555555, patched.OzonSKU)
+	assert.Equal(t, 24345325, patched.WildberriesSKU)
+	assert.Equal(t, 105, patched.CategoryID)
+	assert.True(t, patched.Active)
+
+	otherPatched, _ := store.Product().GetProductById(otherProduct.ProductID)
+	assert.Equal(t, other.ID, otherPatched.UserID)
+	assert.NotEqual(t, "taken over", otherPatched.Description)
+}
+
+func testMultipartImage(t *testing.T, field string, content []byte) (*bytes.Buffer, string) {
+	t.Helper()
+
+	b := &bytes.Buffer{}
+	mw := multipart.NewWriter(b)
+	fw, _ := mw.CreateFormFile(field, "image.png")
+	fw.Write(content)
+	mw.Close()
+
+	return b, mw.FormDataContentType()
+}
+
+func TestServer_HandleProductImages(t *testing.T) {
+	store := teststore.New()
+	fileStorage, _ := filestorage.NewLocalStorage(t.TempDir(), "/api/v1/media")
+	srvc := service.NewService(store, service.WithFileStorage(fileStorage), service.WithImageLimits(1<<20, 50))
+	u := model.TestUser(t)
+	store.User().Create(u)
+
+	p := model.TestProduct(t)
+	p.UserID = u.ID
+	mpiList := &model.MarketPlaceItemsList{}
+	mpiList.GetMPIList(p)
+	store.Product().Create(p, mpiList)
+
+	sessManager := authservicefake.NewAuthServiceClientFake()
+	sessionS, _ := sessManager.Create(context.Background(), &authservice.Session{
+		UserID: int32(u.ID),
+	})
+
+	secretKey := []byte("secret_key")
+	handlers := handler.NewHandler(srvc, sessions.NewCookieStore(secretKey), sessManager)
+	handlers.InitHandler()
+	sc := securecookie.New(secretKey, nil)
+
+	img := image.NewRGBA(image.Rect(0, 0, 200, 100))
+	pngBuf := &bytes.Buffer{}
+	png.Encode(pngBuf, img)
+
+	serve := func(method string, url string, body *bytes.Buffer, contentType string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		if body == nil {
+			body = &bytes.Buffer{}
+		}
+		req, _ := http.NewRequest(method, url, body)
+		coockieStr, _ := sc.Encode(handler.SessionName, map[interface{}]interface{}{"user_id": u.ID})
+		req.Header.Set("Cookie", fmt.Sprintf("%s=%s", handler.SessionName, coockieStr))
+		req.Header.Add("Cookie", fmt.Sprintf("%s=%s", handler.SessionIDKey, sessionS.ID))
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		ctx := context.WithValue(req.Context(), handler.CtxKeyUser, u)
+		handlers.Router.ServeHTTP(rec, req.WithContext(ctx))
+		return rec
+	}
+
+	imagesURL := fmt.Sprintf("/api/v1/private/product/product/%v/images", p.ProductID)
+
+	uploads := []struct {
+		name         string
+		field        string
+		content      []byte
+		expectedCode int
+	}{
+		{
+			name:         "valid",
+			field:        "image",
+			content:      pngBuf.Bytes(),
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name:         "valid_second",
+			field:        "image",
+			content:      pngBuf.Bytes(),
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name:         "not_image",
+			field:        "image",
+			content:      []byte("plain text"),
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:         "missing_field",
+			field:        "file",
+			content:      pngBuf.Bytes(),
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	uploaded := make([]*model.ProductImage, 0)
+	for _, tc := range uploads {
+		t.Run(tc.name, func(t *testing.T) {
+			body, contentType := testMultipartImage(t, tc.field, tc.content)
+			rec := serve(http.MethodPost, imagesURL, body, contentType)
+			assert.Equal(t, tc.expectedCode, rec.Code)
+			if rec.Code == http.StatusCreated {
+				i := &model.ProductImage{}
+				json.NewDecoder(rec.Body).Decode(i)
+				assert.Equal(t, 200, i.Width)
+				assert.NotEmpty(t, i.ThumbnailURL)
+				uploaded = append(uploaded, i)
+			}
+		})
+	}
+
+	assert.Equal(t, 2, len(uploaded))
+
+	rec := serve(http.MethodPost, imagesURL, bytes.NewBufferString("not a form"), "multipart/form-data")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	body, contentType := testMultipartImage(t, "image", make([]byte, 3<<20))
+	rec = serve(http.MethodPost, imagesURL, body, contentType)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+
+	rec = serve(http.MethodGet, uploaded[0].ThumbnailURL, nil, "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "image/png", rec.Header().Get("Content-Type"))
+
+	b := &bytes.Buffer{}
+	json.NewEncoder(b).Encode(map[string]interface{}{"image_ids": []int{uploaded[1].ImageID, uploaded[0].ImageID}})
+	rec = serve(http.MethodPut, imagesURL+"/order", b, "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	b = &bytes.Buffer{}
+	json.NewEncoder(b).Encode(map[string]interface{}{"image_ids": []int{uploaded[1].ImageID}})
+	rec = serve(http.MethodPut, imagesURL+"/order", b, "")
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+
+	rec = serve(http.MethodGet, fmt.Sprintf("/api/v1/private/product/product/%v", p.ProductID), nil, "")
+	product := &model.Product{}
+	json.NewDecoder(rec.Body).Decode(product)
+	assert.Equal(t, 2, len(product.Images))
+	assert.Equal(t, uploaded[1].ImageID, product.Images[0].ImageID)
+
+	rec = serve(http.MethodDelete, fmt.Sprintf("%s/%v", imagesURL, uploaded[0].ImageID), nil, "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = serve(http.MethodGet, uploaded[0].URL, nil, "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestServer_HandleProductVariants(t *testing.T) {
+	store := teststore.New()
+	srvc := service.NewService(store)
+	u := model.TestUser(t)
+	store.User().Create(u)
+
+	p := model.TestProduct(t)
+	p.UserID = u.ID
+	p.CategoryID = 2
+	mpiList := &model.MarketPlaceItemsList{}
+	mpiList.GetMPIList(p)
+	store.Product().Create(p, mpiList)
+
+	other := model.TestUser(t)
+	other.Email = "other@test.org"
+	store.User().Create(other)
+	otherProduct := model.TestProduct(t)
+	otherProduct.UserID = other.ID
+	store.Product().Create(otherProduct, &model.MarketPlaceItemsList{})
+
+	sessManager := authservicefake.NewAuthServiceClientFake()
+	sessionS, _ := sessManager.Create(context.Background(), &authservice.Session{
+		UserID: int32(u.ID),
+	})
+
+	secretKey := []byte("secret_key")
+	handlers := handler.NewHandler(srvc, sessions.NewCookieStore(secretKey), sessManager)
+	handlers.InitHandler()
+	sc := securecookie.New(secretKey, nil)
+
+	serve := func(method string, url string, body interface{}) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		b := &bytes.Buffer{}
+		if body != nil {
+			json.NewEncoder(b).Encode(body)
+		}
+		req, _ := http.NewRequest(method, url, b)
+		coockieStr, _ := sc.Encode(handler.SessionName, map[interface{}]interface{}{"user_id": u.ID})
+		req.Header.Set("Cookie", fmt.Sprintf("%s=%s", handler.SessionName, coockieStr))
+		req.Header.Add("Cookie", fmt.Sprintf("%s=%s", handler.SessionIDKey, sessionS.ID))
+		ctx := context.WithValue(req.Context(), handler.CtxKeyUser, u)
+		handlers.Router.ServeHTTP(rec, req.WithContext(ctx))
+		return rec
+	}
+
+	variantsURL := fmt.Sprintf("/api/v1/private/product/product/%v/variants", p.ProductID)
+
+	testCases := []struct {
+		name         string
+		payload      interface{}
+		expectedCode int
+	}{
+		{
+			name: "valid",
+			payload: map[string]interface{}{
+				"weight":             550,
+				"wildberries_sku":    24345326,
+				"variant_attributes": map[string]string{"size": "XL"},
+			},
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name: "no attributes",
+			payload: map[string]interface{}{
+				"weight": 550,
+			},
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name: "other category",
+			payload: map[string]interface{}{
+				"category_id":        3,
+				"variant_attributes": map[string]string{"size": "L"},
+			},
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:         "invalid",
+			payload:      "invalid",
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := serve(http.MethodPost, variantsURL, tc.payload)
+			assert.Equal(t, tc.expectedCode, rec.Code)
+		})
+	}
+
+	rec := serve(http.MethodGet, variantsURL, nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var variants []*model.Product
+	json.NewDecoder(rec.Body).Decode(&variants)
+	assert.Len(t, variants, 1)
+	assert.Equal(t, 2, variants[0].CategoryID)
+	assert.Equal(t, p.ProductName, variants[0].ProductName)
+
+	rec = serve(http.MethodGet, fmt.Sprintf("/api/v1/private/product/product/%v/variants", otherProduct.ProductID), nil)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Equal(t, 24345326, variants[0].WildberriesSKU)
+
+	rec = serve(http.MethodGet, "/api/v1/private/product/product?group=variants", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var grouped []*model.Product
+	json.NewDecoder(rec.Body).Decode(&grouped)
+	assert.Len(t, grouped, 1)
+	assert.Len(t, grouped[0].Variants, 1)
+
+	rec = serve(http.MethodPatch, fmt.Sprintf("/api/v1/private/product/product/%v", p.ProductID), map[string]interface{}{"category_id": 3, "version": p.Version})
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+
+	rec = serve(http.MethodDelete, fmt.Sprintf("/api/v1/private/product/product/%v", p.ProductID), nil)
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+}
+
+func TestServer_HandleCategoryAttributes(t *testing.T) {
+	store := teststore.New()
+	srvc := service.NewService(store)
+	u := model.TestUser(t)
+	store.User().Create(u)
+	admin := model.TestAdminUser(t)
+	admin.Email = "admin@test.org"
+	store.User().Create(admin)
+
+	store.Product().CreateCategory(&model.Category{CategoryName: "Одежда", Active: true})
+	store.Product().CreateCategory(&model.Category{CategoryName: "Платья", ParentCategoryID: 1, Active: true})
+
+	sessManager := authservicefake.NewAuthServiceClientFake()
+	sessionS, _ := sessManager.Create(context.Background(), &authservice.Session{
+		UserID: int32(u.ID),
+	})
+	adminSession, _ := sessManager.Create(context.Background(), &authservice.Session{
+		UserID: int32(admin.ID),
+	})
+	sessionIDs := map[int]string{u.ID: sessionS.ID, admin.ID: adminSession.ID}
+
+	secretKey := []byte("secret_key")
+	handlers := handler.NewHandler(srvc, sessions.NewCookieStore(secretKey), sessManager)
+	handlers.InitHandler()
+	sc := securecookie.New(secretKey, nil)
+
+	serve := func(user *model.User, method string, url string, body interface{}) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		b := &bytes.Buffer{}
+		if body != nil {
+			json.NewEncoder(b).Encode(body)
+		}
+		req, _ := http.NewRequest(method, url, b)
+		coockieStr, _ := sc.Encode(handler.SessionName, map[interface{}]interface{}{"user_id": user.ID})
+		req.Header.Set("Cookie", fmt.Sprintf("%s=%s", handler.SessionName, coockieStr))
+		req.Header.Add("Cookie", fmt.Sprintf("%s=%s", handler.SessionIDKey, sessionIDs[user.ID]))
+		ctx := context.WithValue(req.Context(), handler.CtxKeyUser, user)
+		handlers.Router.ServeHTTP(rec, req.WithContext(ctx))
+		return rec
+	}
+
+	fabric := map[string]interface{}{
+		"code":     "fabric",
+		"name":     "Ткань",
+		"type":     model.AttributeTypeEnum,
+		"required": true,
+		"allowed_values": []string{
+			"cotton", "wool",
+		},
+	}
+
+	rec := serve(u, http.MethodPost, "/api/v1/private/product/category/1/attributes", fabric)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+
+	rec = serve(admin, http.MethodPost, "/api/v1/private/product/category/1/attributes", fabric)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+
+	rec = serve(admin, http.MethodPost, "/api/v1/private/product/category/1/attributes", map[string]interface{}{"code": "fabric"})
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+
+	rec = serve(u, http.MethodGet, "/api/v1/private/product/category/2/attributes", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var schema []*model.CategoryAttribute
+	json.NewDecoder(rec.Body).Decode(&schema)
+	assert.Len(t, schema, 1)
+	assert.Equal(t, "fabric", schema[0].Code)
+
+	testCases := []struct {
+		name         string
+		attributes   map[string]string
+		expectedCode int
+	}{
+		{
+			name:         "required missing",
+			attributes:   nil,
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:         "not allowed value",
+			attributes:   map[string]string{"fabric": "silk"},
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:         "unknown attribute",
+			attributes:   map[string]string{"fabric": "wool", "screen_size": "6"},
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:         "valid",
+			attributes:   map[string]string{"fabric": "wool"},
+			expectedCode: http.StatusCreated,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := model.TestProduct(t)
+			p.CategoryID = 2
+			p.Attributes = tc.attributes
+			rec := serve(u, http.MethodPost, "/api/v1/private/product/product", p)
+			assert.Equal(t, tc.expectedCode, rec.Code)
+		})
+	}
+
+	rec = serve(u, http.MethodGet, "/api/v1/private/product/product/1", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	p := &model.Product{}
+	json.NewDecoder(rec.Body).Decode(p)
+	assert.Equal(t, map[string]string{"fabric": "wool"}, p.Attributes)
+
+	rec = serve(admin, http.MethodDelete, "/api/v1/private/product/category/2/attributes/1", nil)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = serve(admin, http.MethodDelete, "/api/v1/private/product/category/1/attributes/1", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestServer_HandleProductBarcodes(t *testing.T) {
+	store := teststore.New()
+	srvc := service.NewService(store, service.WithBarcodePrefix("290"))
+	u := model.TestUser(t)
+	store.User().Create(u)
+
+	p := model.TestProduct(t)
+	p.UserID = u.ID
+	mpiList := &model.MarketPlaceItemsList{}
+	mpiList.GetMPIList(p)
+	store.Product().Create(p, mpiList)
+
+	sessManager := authservicefake.NewAuthServiceClientFake()
+	sessionS, _ := sessManager.Create(context.Background(), &authservice.Session{
+		UserID: int32(u.ID),
+	})
+
+	secretKey := []byte("secret_key")
+	handlers := handler.NewHandler(srvc, sessions.NewCookieStore(secretKey), sessManager)
+	handlers.InitHandler()
+	sc := securecookie.New(secretKey, nil)
+
+	serve := func(method string, url string, body interface{}) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		b := &bytes.Buffer{}
+		if body != nil {
+			json.NewEncoder(b).Encode(body)
+		}
+		req, _ := http.NewRequest(method, url, b)
+		coockieStr, _ := sc.Encode(handler.SessionName, map[interface{}]interface{}{"user_id": u.ID})
+		req.Header.Set("Cookie", fmt.Sprintf("%s=%s", handler.SessionName, coockieStr))
+		req.Header.Add("Cookie", fmt.Sprintf("%s=%s", handler.SessionIDKey, sessionS.ID))
+		ctx := context.WithValue(req.Context(), handler.CtxKeyUser, u)
+		handlers.Router.ServeHTTP(rec, req.WithContext(ctx))
+		return rec
+	}
+
+	barcodesURL := fmt.Sprintf("/api/v1/private/product/product/%v/barcodes", p.ProductID)
+
+	testCases := []struct {
+		name         string
+		payload      interface{}
+		expectedCode int
+	}{
+		{
+			name:         "valid",
+			payload:      map[string]string{"code": "4006381333931"},
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name:         "duplicate",
+			payload:      map[string]string{"code": "4006381333931"},
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:         "wrong check digit",
+			payload:      map[string]string{"code": "96385075"},
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:         "invalid",
+			payload:      "invalid",
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := serve(http.MethodPost, barcodesURL, tc.payload)
+			assert.Equal(t, tc.expectedCode, rec.Code)
+		})
+	}
+
+	rec := serve(http.MethodPost, barcodesURL+"/generate", nil)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	generated := &model.Barcode{}
+	json.NewDecoder(rec.Body).Decode(generated)
+	assert.Equal(t, "2900000000018", generated.Code)
+	assert.True(t, generated.Internal)
+
+	rec = serve(http.MethodGet, barcodesURL, nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var barcodes []*model.Barcode
+	json.NewDecoder(rec.Body).Decode(&barcodes)
+	assert.Len(t, barcodes, 2)
+
+	rec = serve(http.MethodGet, "/api/v1/private/product/barcode/2900000000018", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	found := &model.Product{}
+	json.NewDecoder(rec.Body).Decode(found)
+	assert.Equal(t, p.ProductID, found.ProductID)
+
+	rec = serve(http.MethodDelete, fmt.Sprintf("%s/%v", barcodesURL, generated.BarcodeID), nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = serve(http.MethodGet, "/api/v1/private/product/barcode/2900000000018", nil)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestServer_HandleProductLabels(t *testing.T) {
+	store := teststore.New()
+	srvc := service.NewService(store)
+	u := model.TestUser(t)
+	store.User().Create(u)
+
+	p := model.TestProduct(t)
+	p.UserID = u.ID
+	mpiList := &model.MarketPlaceItemsList{}
+	mpiList.GetMPIList(p)
+	store.Product().Create(p, mpiList)
+
+	noBarcode := model.TestProduct(t)
+	noBarcode.UserID = u.ID
+	store.Product().Create(noBarcode, &model.MarketPlaceItemsList{})
+
+	b := model.TestBarcode(t)
+	b.ProductID = p.ProductID
+	b.UserID = u.ID
+	store.Barcode().Create(b)
+
+	sessManager := authservicefake.NewAuthServiceClientFake()
+	sessionS, _ := sessManager.Create(context.Background(), &authservice.Session{
+		UserID: int32(u.ID),
+	})
+
+	secretKey := []byte("secret_key")
+	handlers := handler.NewHandler(srvc, sessions.NewCookieStore(secretKey), sessManager)
+	handlers.InitHandler()
+	sc := securecookie.New(secretKey, nil)
+
+	testCases := []struct {
+		name         string
+		payload      interface{}
+		expectedCode int
+	}{
+		{
+			name: "valid",
+			payload: map[string]interface{}{
+				"items": []map[string]interface{}{{"product_id": p.ProductID, "quantity": 3}},
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name: "custom size on sheet with code",
+			payload: map[string]interface{}{
+				"items":     []map[string]interface{}{{"product_id": noBarcode.ProductID, "quantity": 2, "code": "TRAY-1"}},
+				"width_mm":  70,
+				"height_mm": 37,
+				"sheet":     true,
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name: "no barcode",
+			payload: map[string]interface{}{
+				"items": []map[string]interface{}{{"product_id": noBarcode.ProductID, "quantity": 1}},
+			},
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name: "unknown size",
+			payload: map[string]interface{}{
+				"items": []map[string]interface{}{{"product_id": p.ProductID, "quantity": 1}},
+				"size":  "10x10",
+			},
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name: "too many labels",
+			payload: map[string]interface{}{
+				"items": []map[string]interface{}{{"product_id": p.ProductID, "quantity": 1001}},
+			},
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:         "no items",
+			payload:      map[string]interface{}{},
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:         "invalid",
+			payload:      "invalid",
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			b := &bytes.Buffer{}
+			json.NewEncoder(b).Encode(tc.payload)
+			req, _ := http.NewRequest(http.MethodPost, "/api/v1/private/product/labels", b)
+			coockieStr, _ := sc.Encode(handler.SessionName, map[interface{}]interface{}{"user_id": u.ID})
+			req.Header.Set("Cookie", fmt.Sprintf("%s=%s", handler.SessionName, coockieStr))
+			req.Header.Add("Cookie", fmt.Sprintf("%s=%s", handler.SessionIDKey, sessionS.ID))
+			ctx := context.WithValue(req.Context(), handler.CtxKeyUser, u)
+			handlers.Router.ServeHTTP(rec, req.WithContext(ctx))
+			assert.Equal(t, tc.expectedCode, rec.Code)
+			if tc.expectedCode == http.StatusOK {
+				assert.Equal(t, "application/pdf", rec.Header().Get("Content-Type"))
+				assert.True(t, bytes.HasPrefix(rec.Body.Bytes(), []byte("%PDF-")))
+			}
+		})
+	}
+}
+
+func TestServer_HandleProductGetLogistics(t *testing.T) {
+	store := teststore.New()
+	srvc := service.NewService(store)
+	u := model.TestUser(t)
+	store.User().Create(u)
+
+	p := model.TestProduct(t)
+	p.UserID = u.ID
+	p.Lenght = 1300
+	mpiList := &model.MarketPlaceItemsList{}
+	mpiList.GetMPIList(p)
+	store.Product().Create(p, mpiList)
+
+	sessManager := authservicefake.NewAuthServiceClientFake()
+	sessionS, _ := sessManager.Create(context.Background(), &authservice.Session{
+		UserID: int32(u.ID),
+	})
+
+	secretKey := []byte("secret_key")
+	handlers := handler.NewHandler(srvc, sessions.NewCookieStore(secretKey), sessManager)
+	handlers.InitHandler()
+	sc := securecookie.New(secretKey, nil)
+
+	rec := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("/api/v1/private/product/product/%v", p.ProductID), nil)
+	coockieStr, _ := sc.Encode(handler.SessionName, map[interface{}]interface{}{"user_id": u.ID})
+	req.Header.Set("Cookie", fmt.Sprintf("%s=%s", handler.SessionName, coockieStr))
+	req.Header.Add("Cookie", fmt.Sprintf("%s=%s", handler.SessionIDKey, sessionS.ID))
+	ctx := context.WithValue(req.Context(), handler.CtxKeyUser, u)
+	handlers.Router.ServeHTTP(rec, req.WithContext(ctx))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	product := &model.Product{}
+	json.NewDecoder(rec.Body).Decode(product)
+	if assert.NotNil(t, product.Logistics) {
+		assert.Equal(t, 5.85, product.Logistics.VolumeLiters)
+		assert.Len(t, product.Logistics.MarketPlaces, 2)
+		assert.True(t, product.Logistics.MarketPlaces[0].Oversized)
+	}
+}
+
+func TestServer_HandleProductFees(t *testing.T) {
+	tariffs, err := service.LoadTariffs("../../../configs/tariffs")
+	assert.NoError(t, err)
+	assert.Len(t, tariffs, 2)
+
+	store := teststore.New()
+	srvc := service.NewService(store, service.WithTariffs(tariffs))
+	u := model.TestUser(t)
+	store.User().Create(u)
+
+	p := model.TestProduct(t)
+	p.UserID = u.ID
+	p.CategoryID = 2
+	mpiList := &model.MarketPlaceItemsList{}
+	mpiList.GetMPIList(p)
+	store.Product().Create(p, mpiList)
+
+	sessManager := authservicefake.NewAuthServiceClientFake()
+	sessionS, _ := sessManager.Create(context.Background(), &authservice.Session{
+		UserID: int32(u.ID),
+	})
+
+	secretKey := []byte("secret_key")
+	handlers := handler.NewHandler(srvc, sessions.NewCookieStore(secretKey), sessManager)
+	handlers.InitHandler()
+	sc := securecookie.New(secretKey, nil)
+
+	testCases := []struct {
+		name         string
+		query        string
+		expectedCode int
+	}{
+		{
+			name:         "valid",
+			query:        "price=1000&date=2022-12-01",
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "no price",
+			query:        "",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "invalid date",
+			query:        "price=1000&date=01.12.2022",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "negative price",
+			query:        "price=-5",
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:         "before tariffs",
+			query:        "price=1000&date=2020-01-01",
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("/api/v1/private/product/product/%v/fees?%s", p.ProductID, tc.query), nil)
+			coockieStr, _ := sc.Encode(handler.SessionName, map[interface{}]interface{}{"user_id": u.ID})
+			req.Header.Set("Cookie", fmt.Sprintf("%s=%s", handler.SessionName, coockieStr))
+			req.Header.Add("Cookie", fmt.Sprintf("%s=%s", handler.SessionIDKey, sessionS.ID))
+			ctx := context.WithValue(req.Context(), handler.CtxKeyUser, u)
+			handlers.Router.ServeHTTP(rec, req.WithContext(ctx))
+			assert.Equal(t, tc.expectedCode, rec.Code)
+
+			if tc.expectedCode == http.StatusOK {
+				fees := make([]*model.FeeEstimate, 0)
+				json.NewDecoder(rec.Body).Decode(&fees)
+				if assert.Len(t, fees, 2) {
+					assert.Equal(t, "ozon", fees[0].MarketPlace)
+					assert.Equal(t, 120.0, fees[0].Commission)
+					assert.Equal(t, 53.0, fees[0].Logistics)
+					assert.Equal(t, 827.0, fees[0].Payout)
+					assert.Equal(t, "wildberries", fees[1].MarketPlace)
+					assert.Equal(t, 150.0, fees[1].Commission)
+				}
+			}
+		})
+	}
+}
+
+func TestServer_HandleProductPrices(t *testing.T) {
+	tariffs, _ := service.LoadTariffs("../../../configs/tariffs")
+	store := teststore.New()
+	srvc := service.NewService(store, service.WithTariffs(tariffs))
+	u := model.TestUser(t)
+	store.User().Create(u)
+
+	p := model.TestProduct(t)
+	p.UserID = u.ID
+	p.CategoryID = 2
+	mpiList := &model.MarketPlaceItemsList{}
+	mpiList.GetMPIList(p)
+	store.Product().Create(p, mpiList)
+
+	other := model.TestProduct(t)
+	other.UserID = u.ID + 1
+	store.Product().Create(other, mpiList)
+
+	sessManager := authservicefake.NewAuthServiceClientFake()
+	sessionS, _ := sessManager.Create(context.Background(), &authservice.Session{
+		UserID: int32(u.ID),
+	})
+
+	secretKey := []byte("secret_key")
+	handlers := handler.NewHandler(srvc, sessions.NewCookieStore(secretKey), sessManager)
+	handlers.InitHandler()
+	sc := securecookie.New(secretKey, nil)
+
+	serve := func(method string, url string, body interface{}) *httptest.ResponseRecorder {
+		b := &bytes.Buffer{}
+		if body != nil {
+			json.NewEncoder(b).Encode(body)
+		}
+
+		rec := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, url, b)
+		coockieStr, _ := sc.Encode(handler.SessionName, map[interface{}]interface{}{"user_id": u.ID})
+		req.Header.Set("Cookie", fmt.Sprintf("%s=%s", handler.SessionName, coockieStr))
+		req.Header.Add("Cookie", fmt.Sprintf("%s=%s", handler.SessionIDKey, sessionS.ID))
+		ctx := context.WithValue(req.Context(), handler.CtxKeyUser, u)
+		handlers.Router.ServeHTTP(rec, req.WithContext(ctx))
+		return rec
+	}
+
+	price := func(marketPlaceId int, landedCost float64, override bool) map[string]interface{} {
+		return map[string]interface{}{
+			"product_id":     p.ProductID,
+			"marketplace_id": marketPlaceId,
+			"list_price":     1200,
+			"discount_price": 1000,
+			"min_price":      900,
+			"landed_cost":    landedCost,
+			"override":       override,
+		}
+	}
+
+	testCases := []struct {
+		name         string
+		payload      interface{}
+		expectedCode int
+	}{
+		{
+			name:         "valid",
+			payload:      []interface{}{price(model.MarketPlaceOzon, 300, false), price(model.MarketPlaceWildberries, 300, false)},
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name:         "below landed cost and fees",
+			payload:      []interface{}{price(model.MarketPlaceOzon, 800, false)},
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:         "override",
+			payload:      []interface{}{price(model.MarketPlaceOzon, 800, true)},
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name:         "duplicate",
+			payload:      []interface{}{price(model.MarketPlaceOzon, 300, false), price(model.MarketPlaceOzon, 300, false)},
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name: "other user product",
+			payload: []interface{}{map[string]interface{}{
+				"product_id":     other.ProductID,
+				"marketplace_id": model.MarketPlaceOzon,
+				"list_price":     1000,
+			}},
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:         "empty",
+			payload:      []interface{}{},
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:         "invalid",
+			payload:      "invalid",
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := serve(http.MethodPost, "/api/v1/private/product/prices", tc.payload)
+			assert.Equal(t, tc.expectedCode, rec.Code)
+		})
+	}
+
+	rec := serve(http.MethodGet, fmt.Sprintf("/api/v1/private/product/product/%v/prices", p.ProductID), nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	prices := make([]*model.Price, 0)
+	json.NewDecoder(rec.Body).Decode(&prices)
+	if assert.Len(t, prices, 2) {
+		assert.True(t, prices[0].Override)
+		assert.Equal(t, 800.0, prices[0].LandedCost)
+	}
+
+	rec = serve(http.MethodGet, fmt.Sprintf("/api/v1/private/product/product/%v/prices/history?marketplace_id=%d", p.ProductID, model.MarketPlaceOzon), nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	history := make([]*model.Price, 0)
+	json.NewDecoder(rec.Body).Decode(&history)
+	assert.Len(t, history, 2)
+
+	rec = serve(http.MethodGet, fmt.Sprintf("/api/v1/private/product/product/%v/prices", other.ProductID), nil)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestServer_HandleRepricing(t *testing.T) {
+	tariffs, _ := service.LoadTariffs("../../../configs/tariffs")
+	store := teststore.New()
+	srvc := service.NewService(store, service.WithTariffs(tariffs))
+	u := model.TestUser(t)
+	store.User().Create(u)
+
+	p := model.TestProduct(t)
+	p.UserID = u.ID
+	p.CategoryID = 2
+	mpiList := &model.MarketPlaceItemsList{}
+	mpiList.GetMPIList(p)
+	store.Product().Create(p, mpiList)
+
+	other := model.TestProduct(t)
+	other.UserID = u.ID + 1
+	store.Product().Create(other, mpiList)
+
+	for _, marketPlaceId := range []int{model.MarketPlaceOzon, model.MarketPlaceWildberries} {
+		price := model.TestPrice(t)
+		price.ProductID = p.ProductID
+		price.MarketPlaceID = marketPlaceId
+		if _, err := srvc.PriceService.SetPrices(u.ID, []*model.Price{price}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	sessManager := authservicefake.NewAuthServiceClientFake()
+	sessionS, _ := sessManager.Create(context.Background(), &authservice.Session{
+		UserID: int32(u.ID),
+	})
+
+	secretKey := []byte("secret_key")
+	handlers := handler.NewHandler(srvc, sessions.NewCookieStore(secretKey), sessManager)
+	handlers.InitHandler()
+	sc := securecookie.New(secretKey, nil)
+
+	serve := func(method string, url string, body io.Reader) *httptest.ResponseRecorder {
+		if body == nil {
+			body = &bytes.Buffer{}
+		}
+
+		rec := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, "/api/v1/private/repricing"+url, body)
+		coockieStr, _ := sc.Encode(handler.SessionName, map[interface{}]interface{}{"user_id": u.ID})
+		req.Header.Set("Cookie", fmt.Sprintf("%s=%s", handler.SessionName, coockieStr))
+		req.Header.Add("Cookie", fmt.Sprintf("%s=%s", handler.SessionIDKey, sessionS.ID))
+		ctx := context.WithValue(req.Context(), handler.CtxKeyUser, u)
+		handlers.Router.ServeHTTP(rec, req.WithContext(ctx))
+		return rec
+	}
+
+	rec := serve(http.MethodPost, "/rules", strings.NewReader(`{"name": "margin", "strategy": "target_margin"}`))
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+
+	rec = serve(http.MethodPost, "/rules", strings.NewReader(`{"name": "follow", "strategy": "follow_competitor", "competitor_offset": -10}`))
+	assert.Equal(t, http.StatusCreated, rec.Code)
+
+	rec = serve(http.MethodPost, "/competitor_prices", strings.NewReader(fmt.Sprintf("product_id,marketplace_id,price\n%d,1,950\n", other.ProductID)))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = serve(http.MethodPost, "/competitor_prices", strings.NewReader(fmt.Sprintf("product_id,marketplace_id,price\n%d,1,950\n", p.ProductID)))
+	assert.Equal(t, http.StatusCreated, rec.Code)
+
+	rec = serve(http.MethodPost, "/run", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	proposals := make([]*model.PriceProposal, 0)
+	json.NewDecoder(rec.Body).Decode(&proposals)
+	if !assert.Len(t, proposals, 1) {
+		return
+	}
+	assert.Equal(t, model.MarketPlaceOzon, proposals[0].MarketPlaceID)
+	assert.Equal(t, 940.0, proposals[0].ProposedPrice)
+
+	rec = serve(http.MethodPost, fmt.Sprintf("/proposals/%d/approve", proposals[0].ProposalID), nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = serve(http.MethodPost, fmt.Sprintf("/proposals/%d/reject", proposals[0].ProposalID), nil)
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+
+	rec = serve(http.MethodPost, "/proposals/apply", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	applied := make([]*model.PriceProposal, 0)
+	json.NewDecoder(rec.Body).Decode(&applied)
+	if assert.Len(t, applied, 1) {
+		assert.Equal(t, model.ProposalApplied, applied[0].Status)
+	}
+
+	prices, _ := srvc.PriceService.GetPrices(p.ProductID, u.ID)
+	assert.Equal(t, 940.0, prices[0].SellingPrice())
+
+	rec = serve(http.MethodGet, "/log", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	entries := make([]*model.RepricingLogEntry, 0)
+	json.NewDecoder(rec.Body).Decode(&entries)
+	if assert.Len(t, entries, 1) {
+		assert.Equal(t, 1000.0, entries[0].OldPrice)
+		assert.Equal(t, 940.0, entries[0].NewPrice)
+	}
+
+	rec = serve(http.MethodGet, "/proposals?status=pending", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	pending := make([]*model.PriceProposal, 0)
+	json.NewDecoder(rec.Body).Decode(&pending)
+	assert.Empty(t, pending)
+}
+
+func TestServer_HandleReplenishment(t *testing.T) {
+	store := teststore.New()
+	srvc := service.NewService(store)
+	u := model.TestUser(t)
+	store.User().Create(u)
+
+	p := model.TestProduct(t)
+	p.UserID = u.ID
+	mpiList := &model.MarketPlaceItemsList{}
+	mpiList.GetMPIList(p)
+	store.Product().Create(p, mpiList)
+
+	idle := model.TestProduct(t)
+	idle.UserID = u.ID
+	store.Product().Create(idle, mpiList)
+
+	other := model.TestProduct(t)
+	other.UserID = u.ID + 1
+	store.Product().Create(other, mpiList)
+
+	otherSupplier := model.TestSupplier(t)
+	otherSupplier.UserID = u.ID + 1
+	store.Supply().CreateSupplier(otherSupplier)
+
+	sessManager := authservicefake.NewAuthServiceClientFake()
+	sessionS, _ := sessManager.Create(context.Background(), &authservice.Session{
+		UserID: int32(u.ID),
+	})
+
+	secretKey := []byte("secret_key")
+	handlers := handler.NewHandler(srvc, sessions.NewCookieStore(secretKey), sessManager)
+	handlers.InitHandler()
+	sc := securecookie.New(secretKey, nil)
+
+	serve := func(method string, url string, body interface{}) *httptest.ResponseRecorder {
+		b := &bytes.Buffer{}
+		if body != nil {
+			json.NewEncoder(b).Encode(body)
+		}
+
+		rec := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, "/api/v1/private/supply"+url, b)
+		coockieStr, _ := sc.Encode(handler.SessionName, map[interface{}]interface{}{"user_id": u.ID})
+		req.Header.Set("Cookie", fmt.Sprintf("%s=%s", handler.SessionName, coockieStr))
+		req.Header.Add("Cookie", fmt.Sprintf("%s=%s", handler.SessionIDKey, sessionS.ID))
+		ctx := context.WithValue(req.Context(), handler.CtxKeyUser, u)
+		handlers.Router.ServeHTTP(rec, req.WithContext(ctx))
+		return rec
+	}
+
+	rec := serve(http.MethodPost, "/suppliers", map[string]interface{}{"supplier_name": "Factory", "lead_time_days": 21})
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	supplier := &model.Supplier{}
+	json.NewDecoder(rec.Body).Decode(supplier)
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	sales := make([]map[string]interface{}, 0)
+	for day := 1; day <= 30; day++ {
+		sales = append(sales, map[string]interface{}{
+			"product_id":     p.ProductID,
+			"marketplace_id": model.MarketPlaceOzon,
+			"sale_date":      today.AddDate(0, 0, -day),
+			"quantity":       2,
+		})
+	}
+	rec = serve(http.MethodPost, "/sales", sales)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+
+	rec = serve(http.MethodPost, "/sales", []map[string]interface{}{{
+		"product_id":     other.ProductID,
+		"marketplace_id": model.MarketPlaceOzon,
+		"sale_date":      today,
+		"quantity":       1,
+	}})
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = serve(http.MethodPut, "/stock", []map[string]interface{}{
+		{"product_id": p.ProductID, "quantity": 20},
+		{"product_id": idle.ProductID, "quantity": 5},
+	})
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = serve(http.MethodGet, fmt.Sprintf("/replenishment?supplier_id=%d", supplier.SupplierID), nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	lines := make([]*model.ReplenishmentLine, 0)
+	json.NewDecoder(rec.Body).Decode(&lines)
+	if assert.Len(t, lines, 2) {
+		assert.Equal(t, p.ProductID, lines[0].ProductID)
+		assert.True(t, lines[0].Reorder)
+		assert.Equal(t, 2.0, lines[0].Velocity)
+		assert.Equal(t, 10.0, lines[0].DaysOfCover)
+		assert.Equal(t, 96, lines[0].ReorderQuantity)
+		assert.False(t, lines[1].Reorder)
+	}
+
+	rec = serve(http.MethodGet, "/replenishment?history_days=abc", nil)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = serve(http.MethodGet, fmt.Sprintf("/replenishment?supplier_id=%d", otherSupplier.SupplierID), nil)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = serve(http.MethodPost, "/orders/draft", map[string]interface{}{"supplier_id": otherSupplier.SupplierID})
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = serve(http.MethodPost, "/orders/draft", map[string]interface{}{"supplier_id": supplier.SupplierID})
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	order := &model.SupplyOrder{}
+	json.NewDecoder(rec.Body).Decode(order)
+	assert.Equal(t, model.SupplyOrderStatusDraft, order.StatusID)
+	if assert.Len(t, order.Products, 1) {
+		assert.Equal(t, p.ProductID, order.Products[0].ProductID)
+		assert.Equal(t, float32(96), order.Products[0].Quantity)
+		assert.Equal(t, model.CurrencyRUB, order.Products[0].CurrencyID)
+	}
+
+	rec = serve(http.MethodGet, fmt.Sprintf("/orders/%d", order.SupplyOrderID), nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestServer_HandleWarehouseTransfers(t *testing.T) {
+	store := teststore.New()
+	srvc := service.NewService(store)
+	u := model.TestUser(t)
+	store.User().Create(u)
+
+	p := model.TestProduct(t)
+	p.UserID = u.ID
+	mpiList := &model.MarketPlaceItemsList{}
+	mpiList.GetMPIList(p)
+	store.Product().Create(p, mpiList)
+
+	otherWarehouse := model.TestWarehouse(t)
+	otherWarehouse.UserID = u.ID + 1
+	store.Warehouse().Create(otherWarehouse)
+
+	sessManager := authservicefake.NewAuthServiceClientFake()
+	sessionS, _ := sessManager.Create(context.Background(), &authservice.Session{
+		UserID: int32(u.ID),
+	})
+
+	secretKey := []byte("secret_key")
+	handlers := handler.NewHandler(srvc, sessions.NewCookieStore(secretKey), sessManager)
+	handlers.InitHandler()
+	sc := securecookie.New(secretKey, nil)
+
+	serve := func(method string, url string, body interface{}) *httptest.ResponseRecorder {
+		b := &bytes.Buffer{}
+		if body != nil {
+			json.NewEncoder(b).Encode(body)
+		}
+
+		rec := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, "/api/v1/private/supply"+url, b)
+		coockieStr, _ := sc.Encode(handler.SessionName, map[interface{}]interface{}{"user_id": u.ID})
+		req.Header.Set("Cookie", fmt.Sprintf("%s=%s", handler.SessionName, coockieStr))
+		req.Header.Add("Cookie", fmt.Sprintf("%s=%s", handler.SessionIDKey, sessionS.ID))
+		ctx := context.WithValue(req.Context(), handler.CtxKeyUser, u)
+		handlers.Router.ServeHTTP(rec, req.WithContext(ctx))
+		return rec
+	}
+
+	rec := serve(http.MethodPut, "/stock", []map[string]interface{}{{"product_id": p.ProductID, "quantity": 20}})
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = serve(http.MethodPost, "/warehouses", map[string]interface{}{"name": "Ozon Хоругвино", "type": model.WarehouseTypeMarketPlace})
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+
+	rec = serve(http.MethodPost, "/warehouses", map[string]interface{}{
+		"name":           "Ozon Хоругвино",
+		"type":           model.WarehouseTypeMarketPlace,
+		"marketplace_id": model.MarketPlaceOzon,
+	})
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	fbo := &model.Warehouse{}
+	json.NewDecoder(rec.Body).Decode(fbo)
+
+	rec = serve(http.MethodGet, "/warehouses", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	warehouses := make([]*model.Warehouse, 0)
+	json.NewDecoder(rec.Body).Decode(&warehouses)
+	if assert.Len(t, warehouses, 2) {
+		assert.Equal(t, model.DefaultWarehouseName, warehouses[0].Name)
+	}
+	own := warehouses[0]
+
+	rec = serve(http.MethodPost, "/transfers", map[string]interface{}{
+		"from_warehouse_id": own.WarehouseID,
+		"to_warehouse_id":   otherWarehouse.WarehouseID,
+		"items":             []map[string]interface{}{{"product_id": p.ProductID, "quantity": 5}},
+	})
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = serve(http.MethodPost, "/transfers", map[string]interface{}{
+		"from_warehouse_id": own.WarehouseID,
+		"to_warehouse_id":   fbo.WarehouseID,
+		"items":             []map[string]interface{}{{"product_id": p.ProductID, "quantity": 8}},
+	})
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	transfer := &model.Transfer{}
+	json.NewDecoder(rec.Body).Decode(transfer)
+	assert.Equal(t, model.TransferDraft, transfer.Status)
+
+	rec = serve(http.MethodPost, fmt.Sprintf("/transfers/%d/receive", transfer.TransferID), nil)
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+
+	rec = serve(http.MethodPost, fmt.Sprintf("/transfers/%d/ship", transfer.TransferID), nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = serve(http.MethodGet, "/stock/report", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	report := make([]*model.StockReportLine, 0)
+	json.NewDecoder(rec.Body).Decode(&report)
+	if assert.Len(t, report, 1) {
+		assert.Equal(t, 8, report[0].InTransit)
+		assert.Equal(t, 20, report[0].Total)
+		if assert.Len(t, report[0].Warehouses, 1) {
+			assert.Equal(t, 12, report[0].Warehouses[0].Quantity)
+		}
+	}
+
+	rec = serve(http.MethodDelete, fmt.Sprintf("/warehouses/%d", fbo.WarehouseID), nil)
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+
+	rec = serve(http.MethodPost, fmt.Sprintf("/transfers/%d/receive", transfer.TransferID), nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	json.NewDecoder(rec.Body).Decode(transfer)
+	assert.Equal(t, model.TransferReceived, transfer.Status)
+
+	rec = serve(http.MethodPost, fmt.Sprintf("/transfers/%d/cancel", transfer.TransferID), nil)
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+
+	rec = serve(http.MethodGet, "/stock/report", nil)
+	json.NewDecoder(rec.Body).Decode(&report)
+	if assert.Len(t, report, 1) {
+		assert.Equal(t, 0, report[0].InTransit)
+		assert.Equal(t, 20, report[0].Total)
+		assert.Len(t, report[0].Warehouses, 2)
+	}
+
+	rec = serve(http.MethodGet, fmt.Sprintf("/transfers/%d", otherWarehouse.WarehouseID+100), nil)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestServer_HandleShipments(t *testing.T) {
+	store := teststore.New()
+	srvc := service.NewService(store)
+	u := model.TestUser(t)
+	store.User().Create(u)
+
+	p := model.TestProduct(t)
+	p.UserID = u.ID
+	mpiList := &model.MarketPlaceItemsList{}
+	mpiList.GetMPIList(p)
+	store.Product().Create(p, mpiList)
+
+	own := model.TestWarehouse(t)
+	own.UserID = u.ID
+	store.Warehouse().Create(own)
+
+	fbo := model.TestWarehouse(t)
+	fbo.UserID = u.ID
+	fbo.Name = "Ozon Tver"
+	fbo.Type = model.WarehouseTypeMarketPlace
+	fbo.MarketPlaceID = model.MarketPlaceOzon
+	store.Warehouse().Create(fbo)
+
+	sessManager := authservicefake.NewAuthServiceClientFake()
+	sessionS, _ := sessManager.Create(context.Background(), &authservice.Session{
+		UserID: int32(u.ID),
+	})
+
+	secretKey := []byte("secret_key")
+	handlers := handler.NewHandler(srvc, sessions.NewCookieStore(secretKey), sessManager)
+	handlers.InitHandler()
+	sc := securecookie.New(secretKey, nil)
+
+	serve := func(method string, url string, body interface{}) *httptest.ResponseRecorder {
+		b := &bytes.Buffer{}
+		if body != nil {
+			json.NewEncoder(b).Encode(body)
+		}
+
+		rec := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, "/api/v1/private/supply"+url, b)
+		coockieStr, _ := sc.Encode(handler.SessionName, map[interface{}]interface{}{"user_id": u.ID})
+		req.Header.Set("Cookie", fmt.Sprintf("%s=%s", handler.SessionName, coockieStr))
+		req.Header.Add("Cookie", fmt.Sprintf("%s=%s", handler.SessionIDKey, sessionS.ID))
+		ctx := context.WithValue(req.Context(), handler.CtxKeyUser, u)
+		handlers.Router.ServeHTTP(rec, req.WithContext(ctx))
+		return rec
+	}
+
+	shipment := func(warehouseId int, quantity int) map[string]interface{} {
+		return map[string]interface{}{
+			"warehouse_id": warehouseId,
+			"planned_date": "2022-12-05T00:00:00Z",
+			"boxes": []map[string]interface{}{{
+				"box_number":    1,
+				"pallet_number": 1,
+				"length":        400,
+				"width":         300,
+				"height":        200,
+				"items":         []map[string]interface{}{{"product_id": p.ProductID, "quantity": quantity}},
+			}},
+		}
+	}
+
+	rec := serve(http.MethodPost, "/shipments", shipment(own.WarehouseID, 20))
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+
+	rec = serve(http.MethodPost, "/shipments", shipment(fbo.WarehouseID, 30))
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	sh := &model.Shipment{}
+	json.NewDecoder(rec.Body).Decode(sh)
+	assert.Equal(t, model.ShipmentDraft, sh.Status)
+	assert.Equal(t, model.MarketPlaceOzon, sh.MarketPlaceID)
+
+	rec = serve(http.MethodGet, fmt.Sprintf("/shipments/%d/packing", sh.ShipmentID), nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	issues := make([]*model.PackingIssue, 0)
+	json.NewDecoder(rec.Body).Decode(&issues)
+	assert.Len(t, issues, 1)
+
+	rec = serve(http.MethodPost, fmt.Sprintf("/shipments/%d/status", sh.ShipmentID), map[string]string{"status": model.ShipmentReady})
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+
+	rec = serve(http.MethodPut, fmt.Sprintf("/shipments/%d", sh.ShipmentID), shipment(fbo.WarehouseID, 20))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = serve(http.MethodPost, fmt.Sprintf("/shipments/%d/status", sh.ShipmentID), map[string]string{"status": model.ShipmentReady})
+	assert.Equal(t, http.StatusOK, rec.Code)
+	json.NewDecoder(rec.Body).Decode(sh)
+	assert.Equal(t, model.ShipmentReady, sh.Status)
+
+	rec = serve(http.MethodPut, fmt.Sprintf("/shipments/%d", sh.ShipmentID), shipment(fbo.WarehouseID, 10))
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+
+	rec = serve(http.MethodGet, fmt.Sprintf("/shipments/%d/packing_list?format=csv", sh.ShipmentID), nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "text/csv; charset=utf-8", rec.Header().Get("Content-Type"))
+	assert.Contains(t, rec.Body.String(), fmt.Sprintf("1,1,%d,%s,", p.ProductID, p.ProductName))
+
+	rec = serve(http.MethodGet, fmt.Sprintf("/shipments/%d/packing_list", sh.ShipmentID), nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/pdf", rec.Header().Get("Content-Type"))
+	assert.Regexp(t, `^%PDF-1\.4`, rec.Body.String())
+	assert.Contains(t, rec.Body.String(), "/Count 1")
+
+	rec = serve(http.MethodGet, fmt.Sprintf("/shipments/%d/packing_list?format=xls", sh.ShipmentID), nil)
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+
+	rec = serve(http.MethodPost, fmt.Sprintf("/shipments/%d/status", sh.ShipmentID), map[string]string{"status": model.ShipmentAccepted})
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+
+	rec = serve(http.MethodPost, fmt.Sprintf("/shipments/%d/status", sh.ShipmentID), map[string]string{"status": model.ShipmentShipped})
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = serve(http.MethodDelete, fmt.Sprintf("/warehouses/%d", fbo.WarehouseID), nil)
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+
+	rec = serve(http.MethodGet, "/shipments?status=shipped", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	shipments := make([]*model.Shipment, 0)
+	json.NewDecoder(rec.Body).Decode(&shipments)
+	assert.Len(t, shipments, 1)
+
+	rec = serve(http.MethodGet, fmt.Sprintf("/shipments/%d", sh.ShipmentID+1), nil)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestAuthServiceHealth(t *testing.T) {
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	healthServer := grpchealth.NewServer()
+	healthpb.RegisterHealthServer(server, healthServer)
+	go server.Serve(listener)
+	defer server.Stop()
+
+	conn, err := grpc.Dial(
+		"bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	client := healthpb.NewHealthClient(conn)
+	assert.NoError(t, checkAuthService(context.Background(), client, "", time.Second, true))
+
+	healthServer.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+	assert.EqualError(t, checkAuthService(context.Background(), client, "", time.Second, false), "auth service is NOT_SERVING")
+
+	readiness := health.NewChecker()
+	readiness.Set(authServiceComponent, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go watchAuthService(ctx, client, "", 10*time.Millisecond, time.Second, readiness, logrus.New())
+
+	assert.Eventually(t, func() bool { return !readiness.Ready() }, time.Second, 10*time.Millisecond)
+
+	healthServer.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+	assert.Eventually(t, readiness.Ready, time.Second, 10*time.Millisecond)
+}
+
+func TestAuthServiceConfig(t *testing.T) {
+	c := NewConfig().AuthService
+	timings, err := c.timings()
+	assert.NoError(t, err)
+	assert.Equal(t, 5*time.Second, timings.dial)
+	assert.Equal(t, 10*time.Second, timings.healthInterval)
+
+	c.KeepaliveTime = "often"
+	_, err = c.timings()
+	assert.Error(t, err)
+
+	c = NewConfig().AuthService
+	c.TLS = true
+	_, err = authServiceCredentials(&c)
+	assert.NoError(t, err)
+
+	c.CAFile = "testdata/missing.pem"
+	_, err = authServiceCredentials(&c)
+	assert.Error(t, err)
+}
+
+func TestServer_HandleReady(t *testing.T) {
+	readiness := health.NewChecker()
+	readiness.Register(authServiceComponent)
+	handlers := handler.NewHandler(
+		service.NewService(teststore.New()),
+		sessions.NewCookieStore([]byte("secret_key")),
+		authservicefake.NewAuthServiceClientFake(),
+		handler.WithReadiness(readiness),
+	)
+	handlers.InitHandler()
+
+	serve := func(url string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, url, nil)
+		handlers.Router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	assert.Equal(t, http.StatusOK, serve("/api/v1/health").Code)
+
+	rec := serve("/api/v1/ready")
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"name":"auth_service","ready":false`)
+
+	readiness.Set(authServiceComponent, nil)
+	assert.Equal(t, http.StatusOK, serve("/api/v1/ready").Code)
+}
+
+func TestSessionConfig(t *testing.T) {
+	config := NewConfig()
+	// sessions are kept by the API server unless the auth service is chosen, so the timeouts apply
+	assert.Equal(t, sessionBackendPostgres, config.SessionBackend)
+	timings, err := config.sessionTimings()
+	assert.NoError(t, err)
+	assert.Equal(t, 720*time.Hour, timings.ttl)
+	assert.Equal(t, 72*time.Hour, timings.idle)
+
+	config.SessionTTL = "0"
+	_, err = config.sessionTimings()
+	assert.Error(t, err)
+
+	cookie, err := config.SessionCookie.cookie(time.Hour)
+	assert.NoError(t, err)
+	assert.True(t, cookie.HttpOnly)
+	assert.Equal(t, http.SameSiteLaxMode, cookie.SameSite)
+
+	config.SessionCookie.SameSite = "none"
+	_, err = config.SessionCookie.cookie(time.Hour)
+	assert.Error(t, err)
+
+	config.SessionCookie.Secure = true
+	cookie, err = config.SessionCookie.cookie(time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, http.SameSiteNoneMode, cookie.SameSite)
+
+	_, err = newLocalSessions("redis", time.Hour, teststore.New())
+	assert.Error(t, err)
+}
+
+func TestServer_HandleSessions(t *testing.T) {
+	u := model.TestUser(t)
+	store := teststore.New()
+	store.User().Create(u)
+
+	sessManager, err := newLocalSessions(sessionBackendMemory, 24*time.Hour, store)
+	assert.NoError(t, err)
+
+	handlers := handler.NewHandler(
+		service.NewService(store),
+		sessions.NewCookieStore([]byte("secret_key")),
+		sessManager,
+		handler.WithSessionCookie(handler.SessionCookie{
+			Path:     "/",
+			Secure:   true,
+			HttpOnly: true,
+			SameSite: http.SameSiteStrictMode,
+			MaxAge:   24 * time.Hour,
+		}),
+		handler.WithSessionTimeouts(handler.SessionTimeouts{Idle: time.Hour, Absolute: 24 * time.Hour}),
+	)
+	handlers.InitHandler()
+
+	serve := func(method, url string, body interface{}, cookies []*http.Cookie) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		b := &bytes.Buffer{}
+		json.NewEncoder(b).Encode(body)
+		req, _ := http.NewRequest(method, url, b)
+		req.Header.Set("User-Agent", "test-agent")
+		for _, c := range cookies {
+			req.AddCookie(c)
+		}
+		handlers.Router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	signIn := func() []*http.Cookie {
+		rec := serve(http.MethodPost, "/api/v1/signin", map[string]string{"email": u.Email, "password": u.Password}, nil)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		return rec.Result().Cookies()
+	}
+
+	first := signIn()
+	if assert.Len(t, first, 1) {
+		assert.True(t, first[0].HttpOnly)
+		assert.True(t, first[0].Secure)
+		assert.Equal(t, http.SameSiteStrictMode, first[0].SameSite)
+		assert.Equal(t, 24*3600, first[0].MaxAge)
+	}
+	second := signIn()
+
+	rec := serve(http.MethodGet, "/api/v1/private/sessions", nil, second)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	list := make([]*model.Session, 0)
+	assert.NoError(t, json.NewDecoder(rec.Body).Decode(&list))
+	if assert.Len(t, list, 2) {
+		current := 0
+		for _, s := range list {
+			assert.Equal(t, "test-agent", s.UserAgent)
+			if s.Current {
+				current++
+			}
+		}
+		assert.Equal(t, 1, current)
+	}
+
+	// the first session was not used for the idle timeout
+	s, err := sessManager.Find(first[0].Value)
+	assert.NoError(t, err)
+	s.LastSeenAt = time.Now().Add(-2 * time.Hour)
+	assert.NoError(t, sessManager.Touch(s))
+	assert.Equal(t, http.StatusUnauthorized, serve(http.MethodGet, "/api/v1/private/whoami", nil, first).Code)
+	_, err = sessManager.Find(first[0].Value)
+	assert.Error(t, err)
+
+	third := signIn()
+	rec = serve(http.MethodPost, "/api/v1/private/signout/all", nil, third)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"signed_out": 2}`, rec.Body.String())
+	if cookies := rec.Result().Cookies(); assert.Len(t, cookies, 1) {
+		assert.Equal(t, -1, cookies[0].MaxAge)
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, serve(http.MethodGet, "/api/v1/private/whoami", nil, second).Code)
+	assert.Equal(t, http.StatusUnauthorized, serve(http.MethodGet, "/api/v1/private/whoami", nil, third).Code)
+
+	// the external auth service keeps sessions itself
+	remote := authservicefake.NewAuthServiceClientFake()
+	sessionS, _ := remote.Create(context.Background(), &authservice.Session{UserID: int32(u.ID)})
+	handlers = handler.NewHandler(service.NewService(store), sessions.NewCookieStore([]byte("secret_key")), remote)
+	handlers.InitHandler()
+	cookies := []*http.Cookie{{Name: handler.SessionIDKey, Value: sessionS.ID}}
+	assert.Equal(t, http.StatusOK, serve(http.MethodGet, "/api/v1/private/whoami", nil, cookies).Code)
+	assert.Equal(t, http.StatusNotImplemented, serve(http.MethodGet, "/api/v1/private/sessions", nil, cookies).Code)
+}
+
+func TestServer_HandleTokens(t *testing.T) {
+	keys, err := jwt.ParseKeySet([]byte(`{"keys": [{"kty": "oct", "kid": "test", "k": "c2VjcmV0LXNlY3JldC1zZWNyZXQtc2VjcmV0LXNlY3JldA"}]}`), "")
+	assert.NoError(t, err)
+
+	u := model.TestUser(t)
+	store := teststore.New()
+	store.User().Create(u)
+
+	handlers := handler.NewHandler(
+		service.NewService(store, service.WithTokens(keys, "", 0, 0)),
+		sessions.NewCookieStore([]byte("secret_key")),
+		authservicefake.NewAuthServiceClientFake(),
+	)
+	handlers.InitHandler()
+
+	serve := func(method, url string, body interface{}, header http.Header) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		b := &bytes.Buffer{}
+		json.NewEncoder(b).Encode(body)
+		req, _ := http.NewRequest(method, url, b)
+		for name, values := range header {
+			req.Header[name] = values
+		}
+		handlers.Router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	signIn := func() (*model.TokenPair, []*http.Cookie) {
+		rec := serve(http.MethodPost, "/api/v1/signin", map[string]string{"email": u.Email, "password": u.Password}, nil)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		pair := &model.TokenPair{}
+		assert.NoError(t, json.NewDecoder(rec.Body).Decode(pair))
+		return pair, rec.Result().Cookies()
+	}
+
+	bearer := func(token string) http.Header {
+		return http.Header{"Authorization": {"Bearer " + token}}
+	}
+
+	refresh := func(token string) *httptest.ResponseRecorder {
+		return serve(http.MethodPost, "/api/v1/token/refresh", map[string]string{"refresh_token": token}, nil)
+	}
+
+	pair, cookies := signIn()
+	assert.Equal(t, model.TokenTypeBearer, pair.TokenType)
+	assert.Equal(t, 900, pair.ExpiresIn)
+	assert.NotEmpty(t, pair.RefreshToken)
+	assert.Len(t, cookies, 1)
+
+	rec := serve(http.MethodGet, "/api/v1/private/whoami", nil, bearer(pair.AccessToken))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), u.Email)
+	assert.Equal(t, http.StatusUnauthorized, serve(http.MethodGet, "/api/v1/private/whoami", nil, bearer(pair.AccessToken+"x")).Code)
+	assert.Equal(t, http.StatusUnauthorized, serve(http.MethodGet, "/api/v1/private/whoami", nil, http.Header{"Authorization": {"Basic dXNlcjpwYXNz"}}).Code)
+
+	// refresh tokens are used once, reuse revokes all tokens of the user
+	rec = refresh(pair.RefreshToken)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	refreshed := &model.TokenPair{}
+	assert.NoError(t, json.NewDecoder(rec.Body).Decode(refreshed))
+	assert.NotEqual(t, pair.RefreshToken, refreshed.RefreshToken)
+	assert.Equal(t, http.StatusUnauthorized, refresh(pair.RefreshToken).Code)
+	assert.Equal(t, http.StatusUnauthorized, refresh(refreshed.RefreshToken).Code)
+
+	// signing out with the access token revokes its refresh token
+	pair, _ = signIn()
+	assert.Equal(t, http.StatusOK, serve(http.MethodGet, "/api/v1/private/signout", nil, bearer(pair.AccessToken)).Code)
+	assert.Equal(t, http.StatusUnauthorized, refresh(pair.RefreshToken).Code)
+
+	// signing out of the cookie session revokes tokens issued with it
+	pair, cookies = signIn()
+	other, _ := signIn()
+	header := http.Header{"Cookie": {cookies[0].Name + "=" + cookies[0].Value}}
+	assert.Equal(t, http.StatusOK, serve(http.MethodGet, "/api/v1/private/signout", nil, header).Code)
+	assert.Equal(t, http.StatusUnauthorized, refresh(pair.RefreshToken).Code)
+	assert.Equal(t, http.StatusOK, refresh(other.RefreshToken).Code)
+
+	// secret keys are not published
+	rec = serve(http.MethodGet, "/api/v1/.well-known/jwks.json", nil, nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"keys": []}`, rec.Body.String())
+
+	handlers = handler.NewHandler(service.NewService(store), sessions.NewCookieStore([]byte("secret_key")), authservicefake.NewAuthServiceClientFake())
+	handlers.InitHandler()
+	assert.Equal(t, http.StatusNotImplemented, refresh(other.RefreshToken).Code)
+	assert.Equal(t, http.StatusNotFound, serve(http.MethodGet, "/api/v1/.well-known/jwks.json", nil, nil).Code)
+	assert.Equal(t, http.StatusUnauthorized, serve(http.MethodGet, "/api/v1/private/whoami", nil, bearer(other.AccessToken)).Code)
+}
+
+func TestTokenConfig(t *testing.T) {
+	c := NewConfig().Tokens
+	_, err := c.option()
+	assert.NoError(t, err)
+
+	c.JWKSFile = "testdata/missing.json"
+	_, err = c.option()
+	assert.Error(t, err)
+}
+
+func TestServer_HandleAPIKeys(t *testing.T) {
+	store := teststore.New()
+	u := model.TestUser(t)
+	store.User().Create(u)
+
+	p := model.TestProduct(t)
+	p.UserID = u.ID
+	mpiList := &model.MarketPlaceItemsList{}
+	mpiList.GetMPIList(p)
+	store.Product().Create(p, mpiList)
+
+	sessManager := authservicefake.NewAuthServiceClientFake()
+	sessionS, _ := sessManager.Create(context.Background(), &authservice.Session{
+		UserID: int32(u.ID),
+	})
+
+	handlers := handler.NewHandler(service.NewService(store), sessions.NewCookieStore([]byte("secret_key")), sessManager)
+	handlers.InitHandler()
+
+	serve := func(method, url string, body interface{}, header http.Header) *httptest.ResponseRecorder {
+		b := &bytes.Buffer{}
+		if body != nil {
+			json.NewEncoder(b).Encode(body)
+		}
+
+		rec := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, url, b)
+		for name, values := range header {
+			req.Header[name] = values
+		}
+		handlers.Router.ServeHTTP(rec, req)
+		return rec
+	}
+	session := http.Header{"Cookie": {fmt.Sprintf("%s=%s", handler.SessionIDKey, sessionS.ID)}}
+
+	rec := serve(http.MethodPost, "/api/v1/private/api_keys", map[string]interface{}{
+		"name":   "ERP",
+		"scopes": []string{model.ScopeProductsRead, model.ScopeSupplyWrite},
+	}, session)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	created := &model.APIKey{}
+	assert.NoError(t, json.NewDecoder(rec.Body).Decode(created))
+	assert.True(t, strings.HasPrefix(created.Key, model.APIKeyPrefix))
+	assert.True(t, strings.HasPrefix(created.Key, created.Prefix))
+
+	expired := time.Now().Add(-time.Hour)
+	rec = serve(http.MethodPost, "/api/v1/private/api_keys", map[string]interface{}{
+		"name":       "old",
+		"scopes":     []string{model.ScopeProductsRead},
+		"expires_at": expired,
+	}, session)
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+
+	// the key is shown once
+	rec = serve(http.MethodGet, "/api/v1/private/api_keys", nil, session)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.NotContains(t, rec.Body.String(), created.Key)
+	assert.Contains(t, rec.Body.String(), created.Prefix)
+
+	apiKey := http.Header{"X-Api-Key": {created.Key}}
+	bearer := http.Header{"Authorization": {"Bearer " + created.Key}}
+	testCases := []struct {
+		name         string
+		method       string
+		url          string
+		header       http.Header
+		expectedCode int
+	}{
+		{"read products", http.MethodGet, "/api/v1/private/product/product", apiKey, http.StatusOK},
+		{"read product with bearer", http.MethodGet, fmt.Sprintf("/api/v1/private/product/product/%d", p.ProductID), bearer, http.StatusOK},
+		{"write products", http.MethodDelete, fmt.Sprintf("/api/v1/private/product/product/%d", p.ProductID), apiKey, http.StatusForbidden},
+		{"read supply", http.MethodGet, "/api/v1/private/supply/stock", apiKey, http.StatusForbidden},
+		{"write supply", http.MethodPost, "/api/v1/private/supply/warehouses", apiKey, http.StatusBadRequest},
+		{"route without scopes", http.MethodGet, "/api/v1/private/whoami", apiKey, http.StatusForbidden},
+		{"manage keys", http.MethodGet, "/api/v1/private/api_keys", apiKey, http.StatusForbidden},
+		{"unknown key", http.MethodGet, "/api/v1/private/product/product", http.Header{"X-Api-Key": {created.Key + "x"}}, http.StatusUnauthorized},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expectedCode, serve(tc.method, tc.url, nil, tc.header).Code)
+		})
+	}
+
+	keys, _ := store.APIKey().FindByUserId(u.ID)
+	if assert.Len(t, keys, 1) {
+		assert.NotNil(t, keys[0].LastUsedAt)
+	}
+
+	rec = serve(http.MethodDelete, fmt.Sprintf("/api/v1/private/api_keys/%d", created.APIKeyID+1), nil, session)
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	rec = serve(http.MethodDelete, fmt.Sprintf("/api/v1/private/api_keys/%d", created.APIKeyID), nil, session)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, http.StatusUnauthorized, serve(http.MethodGet, "/api/v1/private/product/product", nil, apiKey).Code)
+}
+
+func TestServer_HandleAccountEmails(t *testing.T) {
+	store := teststore.New()
+	mails := &bytes.Buffer{}
+	srvc := service.NewService(store, service.WithMailer(mailer.NewLocalMailer(mails, "noreply@example.org"), "http://localhost:3000/"))
+
+	sessManager := authservicefake.NewAuthServiceClientFake()
+	handlers := handler.NewHandler(srvc, sessions.NewCookieStore([]byte("secret_key")), sessManager)
+	handlers.InitHandler()
+
+	serve := func(url string, body interface{}) *httptest.ResponseRecorder {
+		b := &bytes.Buffer{}
+		json.NewEncoder(b).Encode(body)
+
+		rec := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, url, b)
+		handlers.Router.ServeHTTP(rec, req)
+		return rec
+	}
+	linkToken := regexp.MustCompile(`http://localhost:3000/(\w+)\?token=([\w-]+)`)
+	lastLink := func(page string) string {
+		links := linkToken.FindAllStringSubmatch(mails.String(), -1)
+		if assert.NotEmpty(t, links) {
+			link := links[len(links)-1]
+			assert.Equal(t, page, link[1])
+			return link[2]
+		}
+		return ""
+	}
+	credentials := map[string]string{"email": "user@example.org", "password": "password"}
+
+	assert.Equal(t, http.StatusCreated, serve("/api/v1/register", credentials).Code)
+	assert.Equal(t, http.StatusForbidden, serve("/api/v1/signin", credentials).Code)
+
+	firstToken := lastLink("verify_email")
+	assert.Equal(t, http.StatusAccepted, serve("/api/v1/email/verify/resend", map[string]string{"email": "user@example.org"}).Code)
+	verifyToken := lastLink("verify_email")
+	assert.NotEqual(t, firstToken, verifyToken)
+
+	// a new link replaces the earlier one
+	assert.Equal(t, http.StatusUnprocessableEntity, serve("/api/v1/email/verify", map[string]string{"token": firstToken}).Code)
+	assert.Equal(t, http.StatusOK, serve("/api/v1/email/verify", map[string]string{"token": verifyToken}).Code)
+	assert.Equal(t, http.StatusUnprocessableEntity, serve("/api/v1/email/verify", map[string]string{"token": verifyToken}).Code)
+	assert.Equal(t, http.StatusOK, serve("/api/v1/signin", credentials).Code)
+
+	sent := mails.Len()
+	assert.Equal(t, http.StatusAccepted, serve("/api/v1/password/forgot", map[string]string{"email": "unknown@example.org"}).Code)
+	assert.Equal(t, sent, mails.Len())
+
+	assert.Equal(t, http.StatusAccepted, serve("/api/v1/password/forgot", map[string]string{"email": "user@example.org"}).Code)
+	resetToken := lastLink("reset_password")
+
+	testCases := []struct {
+		name         string
+		payload      interface{}
+		expectedCode int
+	}{
+		{"invalid payload", "invalid", http.StatusBadRequest},
+		{"verification token", map[string]string{"token": verifyToken, "password": "new password"}, http.StatusUnprocessableEntity},
+		{"short password", map[string]string{"token": resetToken, "password": "new"}, http.StatusUnprocessableEntity},
+		{"valid", map[string]string{"token": resetToken, "password": "new password"}, http.StatusOK},
+		{"used token", map[string]string{"token": resetToken, "password": "another password"}, http.StatusUnprocessableEntity},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expectedCode, serve("/api/v1/password/reset", tc.payload).Code)
+		})
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, serve("/api/v1/signin", credentials).Code)
+	assert.Equal(t, http.StatusOK, serve("/api/v1/signin", map[string]string{"email": "user@example.org", "password": "new password"}).Code)
+
+	// without a mailer users are verified on registration
+	handlers = handler.NewHandler(service.NewService(teststore.New()), sessions.NewCookieStore([]byte("secret_key")), sessManager)
+	handlers.InitHandler()
+	assert.Equal(t, http.StatusCreated, serve("/api/v1/register", credentials).Code)
+	assert.Equal(t, http.StatusOK, serve("/api/v1/signin", credentials).Code)
+	assert.Equal(t, http.StatusNotImplemented, serve("/api/v1/password/forgot", map[string]string{"email": "user@example.org"}).Code)
+}
+
+func TestMailConfig(t *testing.T) {
+	// without a configured mailer users are verified on registration
+	c := NewConfig().Mail
+	assert.Empty(t, c.Backend)
+	_, err := c.option(logrus.New())
+	assert.NoError(t, err)
+
+	c.Backend = "log"
+	_, err = c.option(logrus.New())
+	assert.NoError(t, err)
+
+	c.Backend = "smtp"
+	c.SMTPAddr = "smtp.example.org"
+	_, err = c.option(logrus.New())
+	assert.Error(t, err)
+
+	c.SMTPAddr = "smtp.example.org:587"
+	c.AppURL = "localhost"
+	_, err = c.option(logrus.New())
+	assert.Error(t, err)
+
+	c.Backend = "pigeon"
+	c.AppURL = "http://localhost:3000"
+	_, err = c.option(logrus.New())
+	assert.Error(t, err)
+}
+
+func TestServer_HandleSignInLockout(t *testing.T) {
+	store := teststore.New()
+	u := model.TestUser(t)
+	password := u.Password
+	store.User().Create(u)
+	admin := model.TestAdminUser(t)
+	admin.Email = "admin@test.org"
+	store.User().Create(admin)
+
+	srvc := service.NewService(store, service.WithSignInProtection(
+		nil,
+		model.LockoutPolicy{FreeAttempts: 2, BaseDelay: time.Minute, MaxDelay: time.Hour, LockAfter: 4, LockoutDuration: time.Hour},
+		model.LockoutPolicy{FreeAttempts: 10, BaseDelay: time.Minute, MaxDelay: time.Hour},
+		0,
+		0,
+	))
+
+	sessManager := authservicefake.NewAuthServiceClientFake()
+	adminSession, _ := sessManager.Create(context.Background(), &authservice.Session{UserID: int32(admin.ID)})
+	userSession, _ := sessManager.Create(context.Background(), &authservice.Session{UserID: int32(u.ID)})
+
+	handlers := handler.NewHandler(srvc, sessions.NewCookieStore([]byte("secret_key")), sessManager)
+	handlers.InitHandler()
+
+	signIn := func(email, password, ip string) *httptest.ResponseRecorder {
+		b := &bytes.Buffer{}
+		json.NewEncoder(b).Encode(map[string]string{"email": email, "password": password})
+
+		rec := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/signin", b)
+		req.RemoteAddr = net.JoinHostPort(ip, "50000")
+		handlers.Router.ServeHTTP(rec, req)
+		return rec
+	}
+	serve := func(method, url, sessionId string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, url, nil)
+		req.Header.Set("Cookie", fmt.Sprintf("%s=%s", handler.SessionIDKey, sessionId))
+		handlers.Router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, http.StatusUnauthorized, signIn(u.Email, "invalid", "192.0.2.1").Code)
+	}
+
+	// the delay applies to the account from any address, even with the right password
+	rec := signIn(u.Email, password, "192.0.2.2")
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, "60", rec.Header().Get("Retry-After"))
+
+	rec = serve(http.MethodGet, "/api/v1/private/admin/signin_failures?email="+u.Email, adminSession.ID)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	failures := make([]*model.SignInFailure, 0)
+	assert.NoError(t, json.NewDecoder(rec.Body).Decode(&failures))
+	if assert.Len(t, failures, 4) {
+		assert.Equal(t, model.SignInFailureThrottled, failures[0].Reason)
+		assert.Equal(t, model.SignInFailurePassword, failures[1].Reason)
+		assert.Equal(t, u.ID, failures[1].UserID)
+		assert.Equal(t, "192.0.2.1", failures[1].IP)
+	}
+
+	assert.Equal(t, http.StatusForbidden, serve(http.MethodPost, fmt.Sprintf("/api/v1/private/admin/users/%d/unlock", u.ID), userSession.ID).Code)
+	assert.Equal(t, http.StatusForbidden, serve(http.MethodGet, "/api/v1/private/admin/signin_failures", userSession.ID).Code)
+	assert.Equal(t, http.StatusUnprocessableEntity, serve(http.MethodPost, "/api/v1/private/admin/users/100/unlock", adminSession.ID).Code)
+	assert.Equal(t, http.StatusOK, serve(http.MethodPost, fmt.Sprintf("/api/v1/private/admin/users/%d/unlock", u.ID), adminSession.ID).Code)
+	assert.Equal(t, http.StatusOK, signIn(u.Email, password, "192.0.2.2").Code)
+
+	// guessing many accounts from one address delays the address
+	for i := 0; i < 11; i++ {
+		assert.Equal(t, http.StatusUnauthorized, signIn(fmt.Sprintf("user%d@test.org", i), "invalid", "2001:db8::1").Code)
+	}
+	assert.Equal(t, http.StatusTooManyRequests, signIn(u.Email, password, "2001:db8::2").Code)
+	assert.Equal(t, http.StatusOK, signIn(u.Email, password, "192.0.2.2").Code)
+
+	rec = serve(http.MethodGet, "/api/v1/private/admin/signin_failures?ip=2001:db8::1&limit=5", adminSession.ID)
+	assert.NoError(t, json.NewDecoder(rec.Body).Decode(&failures))
+	if assert.Len(t, failures, 5) {
+		assert.Equal(t, model.SignInFailureUnknownEmail, failures[0].Reason)
+		assert.Zero(t, failures[0].UserID)
+	}
+}
+
+func TestSignInProtectionConfig(t *testing.T) {
+	c := NewConfig().SignInProtection
+	_, err := c.option()
+	assert.NoError(t, err)
+
+	c.CounterStore = "memory"
+	_, err = c.option()
+	assert.NoError(t, err)
+
+	c.CounterStore = "redis"
+	_, err = c.option()
+	assert.Error(t, err)
+
+	c = NewConfig().SignInProtection
+	c.Account.LockAfter = c.Account.FreeAttempts
+	_, err = c.option()
+	assert.Error(t, err)
+
+	c = NewConfig().SignInProtection
+	c.IP.MaxDelay = "1ms"
+	_, err = c.option()
+	assert.Error(t, err)
+
+	c = NewConfig().SignInProtection
+	c.ResetAfter = "10m"
+	_, err = c.option()
+	assert.Error(t, err)
+}
+
+func TestTrustedProxiesConfig(t *testing.T) {
+	config := NewConfig()
+	proxies, err := config.trustedProxies()
+	assert.NoError(t, err)
+	assert.Empty(t, proxies)
+
+	config.TrustedProxies = []string{"10.0.0.0/8", "192.0.2.10", "2001:db8::1"}
+	proxies, err = config.trustedProxies()
+	assert.NoError(t, err)
+	if assert.Len(t, proxies, 3) {
+		assert.True(t, proxies[0].Contains(net.ParseIP("10.1.2.3")))
+		assert.True(t, proxies[1].Contains(net.ParseIP("192.0.2.10")))
+		assert.False(t, proxies[1].Contains(net.ParseIP("192.0.2.11")))
+		assert.True(t, proxies[2].Contains(net.ParseIP("2001:db8::1")))
+	}
+
+	config.TrustedProxies = []string{"proxy.local"}
+	_, err = config.trustedProxies()
+	assert.Error(t, err)
+
+	config.TrustedProxies = []string{"10.0.0.0/33"}
+	_, err = config.trustedProxies()
+	assert.Error(t, err)
+}
+
+func TestServer_HandleSignInBehindProxy(t *testing.T) {
+	store := teststore.New()
+	u := model.TestUser(t)
+	password := u.Password
+	store.User().Create(u)
+
+	srvc := service.NewService(store, service.WithSignInProtection(
+		nil,
+		model.LockoutPolicy{},
+		model.LockoutPolicy{FreeAttempts: 2, BaseDelay: time.Minute, MaxDelay: time.Hour},
+		0,
+		0,
+	))
+
+	_, proxy, _ := net.ParseCIDR("10.0.0.0/8")
+	handlers := handler.NewHandler(srvc, sessions.NewCookieStore([]byte("secret_key")), authservicefake.NewAuthServiceClientFake(),
+		handler.WithTrustedProxies([]*net.IPNet{proxy}))
+	handlers.InitHandler()
+
+	signIn := func(email, password, remoteAddr, forwardedFor string) int {
+		b := &bytes.Buffer{}
+		json.NewEncoder(b).Encode(map[string]string{"email": email, "password": password})
+
+		rec := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/signin", b)
+		req.RemoteAddr = net.JoinHostPort(remoteAddr, "50000")
+		if forwardedFor != "" {
+			req.Header.Set("X-Forwarded-For", forwardedFor)
+		}
+		handlers.Router.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	// the forwarded address of a trusted proxy is the client, an address set by the client is ignored
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, http.StatusUnauthorized, signIn(u.Email, "invalid", "10.0.0.1", fmt.Sprintf("198.51.100.%d, 192.0.2.1, 10.0.0.2", i)))
+	}
+	assert.Equal(t, http.StatusTooManyRequests, signIn(u.Email, password, "10.0.0.1", "192.0.2.1"))
+	assert.Equal(t, http.StatusOK, signIn(u.Email, password, "10.0.0.1", "192.0.2.2"))
+
+	// other clients can't pick an address through the header
+	assert.Equal(t, http.StatusOK, signIn(u.Email, password, "192.0.2.3", "192.0.2.1"))
+}
+
+func TestServer_HandleTwoFactor(t *testing.T) {
+	store := teststore.New()
+	u := model.TestUser(t)
+	password := u.Password
+	store.User().Create(u)
+	admin := model.TestAdminUser(t)
+	admin.Email = "admin@test.org"
+	store.User().Create(admin)
+
+	srvc := service.NewService(store, service.WithTwoFactor("Marketplace", true))
+
+	sessManager := authservicefake.NewAuthServiceClientFake()
+	adminSession, _ := sessManager.Create(context.Background(), &authservice.Session{UserID: int32(admin.ID)})
+	userSession, _ := sessManager.Create(context.Background(), &authservice.Session{UserID: int32(u.ID)})
+
+	handlers := handler.NewHandler(srvc, sessions.NewCookieStore([]byte("secret_key")), sessManager)
+	handlers.InitHandler()
+
+	serve := func(method, url, sessionId string, payload interface{}) *httptest.ResponseRecorder {
+		b := &bytes.Buffer{}
+		if payload != nil {
+			json.NewEncoder(b).Encode(payload)
+		}
+
+		rec := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, url, b)
+		if sessionId != "" {
+			req.Header.Set("Cookie", fmt.Sprintf("%s=%s", handler.SessionIDKey, sessionId))
+		}
+		handlers.Router.ServeHTTP(rec, req)
+		return rec
+	}
+	enable := func(sessionId string) (string, []string) {
+		rec := serve(http.MethodPost, "/api/v1/private/2fa/enroll", sessionId, nil)
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.Equal(t, "no-store", rec.Header().Get("Cache-Control"))
+		enrollment := &model.TwoFactorEnrollment{}
+		assert.NoError(t, json.NewDecoder(rec.Body).Decode(enrollment))
+		assert.True(t, strings.HasPrefix(enrollment.URI, "otpauth://totp/Marketplace:"))
+
+		assert.Equal(t, http.StatusUnprocessableEntity, serve(http.MethodPost, "/api/v1/private/2fa/enable", sessionId, map[string]string{"code": "abcdef"}).Code)
+
+		code, err := totp.Code(enrollment.Secret, time.Now())
+		assert.NoError(t, err)
+		rec = serve(http.MethodPost, "/api/v1/private/2fa/enable", sessionId, map[string]string{"code": code})
+		assert.Equal(t, http.StatusOK, rec.Code)
+		codes := map[string][]string{}
+		assert.NoError(t, json.NewDecoder(rec.Body).Decode(&codes))
+		assert.Len(t, codes["recovery_codes"], 10)
+
+		return enrollment.Secret, codes["recovery_codes"]
+	}
+	signIn := func() string {
+		rec := serve(http.MethodPost, "/api/v1/signin", "", map[string]string{"email": u.Email, "password": password})
+		assert.Equal(t, http.StatusAccepted, rec.Code)
+		challenge := &model.TwoFactorChallenge{}
+		assert.NoError(t, json.NewDecoder(rec.Body).Decode(challenge))
+		assert.True(t, challenge.TwoFactorRequired)
+		assert.Equal(t, 300, challenge.ExpiresIn)
+
+		return challenge.ChallengeToken
+	}
+
+	// administrators have to enable 2FA before using admin endpoints and can't disable it
+	assert.Equal(t, http.StatusForbidden, serve(http.MethodGet, "/api/v1/private/admin/signin_failures", adminSession.ID, nil).Code)
+	enable(adminSession.ID)
+	assert.Equal(t, http.StatusOK, serve(http.MethodGet, "/api/v1/private/admin/signin_failures", adminSession.ID, nil).Code)
+	assert.Equal(t, http.StatusUnprocessableEntity, serve(http.MethodPost, "/api/v1/private/2fa/disable", adminSession.ID, map[string]string{"password": "password"}).Code)
+
+	assert.Equal(t, http.StatusOK, serve(http.MethodPost, "/api/v1/signin", "", map[string]string{"email": u.Email, "password": password}).Code)
+
+	secret, recoveryCodes := enable(userSession.ID)
+	assert.Equal(t, http.StatusUnprocessableEntity, serve(http.MethodPost, "/api/v1/private/2fa/enroll", userSession.ID, nil).Code)
+
+	rec := serve(http.MethodGet, "/api/v1/private/2fa", userSession.ID, nil)
+	status := &model.TwoFactorStatus{}
+	assert.NoError(t, json.NewDecoder(rec.Body).Decode(status))
+	assert.True(t, status.Enabled)
+	assert.False(t, status.Required)
+	assert.Equal(t, 10, status.RecoveryCodesLeft)
+
+	// codes of the step that enabled 2FA and older steps are rejected
+	challenge := signIn()
+	usedCode, _ := totp.Code(secret, time.Now().Add(-totp.Period))
+	for _, code := range []string{usedCode, "aaaa-bbbb-cccc-dddd"} {
+		rec = serve(http.MethodPost, "/api/v1/signin/2fa", "", map[string]string{"challenge_token": challenge, "code": code})
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	}
+	assert.Equal(t, http.StatusUnauthorized, serve(http.MethodPost, "/api/v1/signin/2fa", "", map[string]string{"challenge_token": "invalid", "code": recoveryCodes[0]}).Code)
+
+	rec = serve(http.MethodPost, "/api/v1/signin/2fa", "", map[string]string{"challenge_token": challenge, "code": strings.ToUpper(recoveryCodes[0])})
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Header().Get("Set-Cookie"), handler.SessionIDKey+"=")
+
+	// challenges and recovery codes work once
+	assert.Equal(t, http.StatusUnauthorized, serve(http.MethodPost, "/api/v1/signin/2fa", "", map[string]string{"challenge_token": challenge, "code": recoveryCodes[1]}).Code)
+	assert.Equal(t, http.StatusUnauthorized, serve(http.MethodPost, "/api/v1/signin/2fa", "", map[string]string{"challenge_token": signIn(), "code": recoveryCodes[0]}).Code)
+
+	rec = serve(http.MethodGet, "/api/v1/private/admin/signin_failures?email="+u.Email, adminSession.ID, nil)
+	failures := make([]*model.SignInFailure, 0)
+	assert.NoError(t, json.NewDecoder(rec.Body).Decode(&failures))
+	if assert.Len(t, failures, 3) {
+		assert.Equal(t, model.SignInFailureTwoFactor, failures[0].Reason)
+		assert.Equal(t, u.ID, failures[0].UserID)
+	}
+
+	nextCode, _ := totp.Code(secret, time.Now().Add(totp.Period))
+	assert.Equal(t, http.StatusUnprocessableEntity, serve(http.MethodPost, "/api/v1/private/2fa/disable", userSession.ID, map[string]string{"password": "invalid", "code": nextCode}).Code)
+	assert.Equal(t, http.StatusOK, serve(http.MethodPost, "/api/v1/private/2fa/disable", userSession.ID, map[string]string{"password": password, "code": nextCode}).Code)
+	assert.Equal(t, http.StatusUnprocessableEntity, serve(http.MethodPost, fmt.Sprintf("/api/v1/private/admin/users/%d/2fa/reset", u.ID), adminSession.ID, nil).Code)
+	assert.Equal(t, http.StatusOK, serve(http.MethodPost, fmt.Sprintf("/api/v1/private/admin/users/%d/2fa/reset", admin.ID), adminSession.ID, nil).Code)
+	assert.Equal(t, http.StatusOK, serve(http.MethodPost, "/api/v1/signin", "", map[string]string{"email": u.Email, "password": password}).Code)
+}
+
+func TestServer_HandlePasswordPolicy(t *testing.T) {
+	store := teststore.New()
+	u := model.TestUser(t)
+	password := u.Password
+	assert.NoError(t, u.EncryptPassword(model.PasswordHasher{Algorithm: model.PasswordHashBcrypt, BcryptCost: 4}))
+	u.Sanitize()
+	store.User().Create(u)
+
+	breached, err := service.LoadBreachedPasswords("../../../configs/breached_passwords.txt")
+	assert.NoError(t, err)
+
+	hasher := model.PasswordHasher{Algorithm: model.PasswordHashArgon2id, Argon2Time: 1, Argon2Memory: 1024, Argon2Threads: 1}
+	srvc := service.NewService(
+		store,
+		service.WithPasswordPolicy(model.PasswordPolicy{MinLength: 10, MaxLength: 64, CharacterClasses: 2, Breached: breached}),
+		service.WithPasswordHasher(hasher),
+	)
+	handlers := handler.NewHandler(srvc, sessions.NewCookieStore([]byte("secret_key")), authservicefake.NewAuthServiceClientFake())
+	handlers.InitHandler()
+
+	serve := func(url string, payload interface{}) *httptest.ResponseRecorder {
+		b := &bytes.Buffer{}
+		json.NewEncoder(b).Encode(payload)
+
+		rec := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, url, b)
+		handlers.Router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	testCases := []struct {
+		name         string
+		password     string
+		expectedCode int
+	}{
+		{"short", "Abc-12", http.StatusUnprocessableEntity},
+		{"one class", "correcthorsebattery", http.StatusUnprocessableEntity},
+		{"breached", "1q2w3e4r5t", http.StatusUnprocessableEntity},
+		{"email", "New@Test.org", http.StatusUnprocessableEntity},
+		{"valid", "correct horse battery", http.StatusCreated},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := serve("/api/v1/register", map[string]string{"email": "new@test.org", "password": tc.password})
+			assert.Equal(t, tc.expectedCode, rec.Code)
+		})
+	}
+
+	registered, err := store.User().FindByEmail("new@test.org")
+	assert.NoError(t, err)
+	assert.False(t, hasher.NeedsRehash(registered.EncryptedPassword))
+
+	// the old bcrypt hash is replaced with an argon2id one on sign-in
+	assert.True(t, hasher.NeedsRehash(u.EncryptedPassword))
+	assert.Equal(t, http.StatusOK, serve("/api/v1/signin", map[string]string{"email": u.Email, "password": password}).Code)
+
+	found, err := store.User().FindById(u.ID)
+	assert.NoError(t, err)
+	assert.False(t, hasher.NeedsRehash(found.EncryptedPassword))
+	assert.Equal(t, http.StatusOK, serve("/api/v1/signin", map[string]string{"email": u.Email, "password": password}).Code)
+}
+
+func TestPasswordConfig(t *testing.T) {
+	c := NewConfig()
+	c.PasswordPolicy.BreachedList = "../../../configs/breached_passwords.txt"
+	_, err := c.PasswordPolicy.option()
+	assert.NoError(t, err)
+	_, err = c.PasswordHashing.option()
+	assert.NoError(t, err)
+
+	c.PasswordHashing.Algorithm = model.PasswordHashArgon2id
+	_, err = c.PasswordHashing.option()
+	assert.NoError(t, err)
+
+	c.PasswordHashing.Argon2Threads = 0
+	_, err = c.PasswordHashing.option()
+	assert.Error(t, err)
+
+	c.PasswordPolicy.MaxLength = 4
+	_, err = c.PasswordPolicy.option()
+	assert.Error(t, err)
+
+	c = NewConfig()
+	c.PasswordPolicy.BreachedList = "missing.txt"
+	_, err = c.PasswordPolicy.option()
+	assert.Error(t, err)
+}
+
+func TestServer_HandleProfile(t *testing.T) {
+	store := teststore.New()
+	u := model.TestUser(t)
+	password := u.Password
+	store.User().Create(u)
+	other := model.TestUser(t)
+	other.Email = "other@test.org"
+	store.User().Create(other)
+	admin := model.TestAdminUser(t)
+	admin.Email = "admin@test.org"
+	store.User().Create(admin)
+
+	mails := &bytes.Buffer{}
+	srvc := service.NewService(store, service.WithMailer(mailer.NewLocalMailer(mails, "noreply@example.org"), "http://localhost:3000"))
+
+	sessManager := authservicefake.NewAuthServiceClientFake()
+	userSession, _ := sessManager.Create(context.Background(), &authservice.Session{UserID: int32(u.ID)})
+	adminSession, _ := sessManager.Create(context.Background(), &authservice.Session{UserID: int32(admin.ID)})
+
+	handlers := handler.NewHandler(srvc, sessions.NewCookieStore([]byte("secret_key")), sessManager)
+	handlers.InitHandler()
+
+	serve := func(method, url, sessionId string, payload interface{}) *httptest.ResponseRecorder {
+		b := &bytes.Buffer{}
+		if payload != nil {
+			json.NewEncoder(b).Encode(payload)
+		}
+
+		rec := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, url, b)
+		if sessionId != "" {
+			req.Header.Set("Cookie", fmt.Sprintf("%s=%s", handler.SessionIDKey, sessionId))
+		}
+		handlers.Router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := serve(http.MethodPatch, "/api/v1/private/profile", userSession.ID, map[string]string{
+		"display_name":  " Ivan ",
+		"company_name":  "Tableware LLC",
+		"base_currency": "usd",
+	})
+	assert.Equal(t, http.StatusOK, rec.Code)
+	profile := &model.User{}
+	assert.NoError(t, json.NewDecoder(rec.Body).Decode(profile))
+	assert.Equal(t, model.UserProfile{
+		DisplayName:  "Ivan",
+		CompanyName:  "Tableware LLC",
+		Timezone:     model.DefaultTimezone,
+		BaseCurrency: "USD",
+		Locale:       model.DefaultLocale,
+	}, profile.UserProfile)
+
+	testCases := []struct {
+		name         string
+		payload      interface{}
+		expectedCode int
+	}{
+		{"invalid payload", "invalid", http.StatusBadRequest},
+		{"unknown timezone", map[string]string{"timezone": "Europe/Atlantis"}, http.StatusUnprocessableEntity},
+		{"unknown currency", map[string]string{"base_currency": "XYZ"}, http.StatusUnprocessableEntity},
+		{"invalid locale", map[string]string{"locale": "russian"}, http.StatusUnprocessableEntity},
+		{"valid", map[string]string{"timezone": "Asia/Novosibirsk", "locale": "en-GB"}, http.StatusOK},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expectedCode, serve(http.MethodPatch, "/api/v1/private/profile", userSession.ID, tc.payload).Code)
+		})
+	}
+
+	rec = serve(http.MethodGet, "/api/v1/private/profile", userSession.ID, nil)
+	assert.NoError(t, json.NewDecoder(rec.Body).Decode(profile))
+	assert.Equal(t, "Asia/Novosibirsk", profile.Timezone)
+	assert.Equal(t, "USD", profile.BaseCurrency)
+
+	// the email changes when the link sent to the new email is followed
+	assert.Equal(t, http.StatusUnprocessableEntity, serve(http.MethodPost, "/api/v1/private/profile/email", userSession.ID, map[string]string{"email": "new@test.org", "password": "invalid"}).Code)
+	assert.Equal(t, http.StatusUnprocessableEntity, serve(http.MethodPost, "/api/v1/private/profile/email", userSession.ID, map[string]string{"email": other.Email, "password": password}).Code)
+	assert.Equal(t, http.StatusAccepted, serve(http.MethodPost, "/api/v1/private/profile/email", userSession.ID, map[string]string{"email": "new@test.org", "password": password}).Code)
+	assert.Contains(t, mails.String(), "To: new@test.org")
+
+	links := regexp.MustCompile(`http://localhost:3000/confirm_email\?token=([\w-]+)`).FindStringSubmatch(mails.String())
+	if assert.Len(t, links, 2) {
+		assert.Equal(t, http.StatusUnprocessableEntity, serve(http.MethodPost, "/api/v1/email/verify", "", map[string]string{"token": links[1]}).Code)
+		assert.Equal(t, http.StatusOK, serve(http.MethodPost, "/api/v1/email/change/confirm", "", map[string]string{"token": links[1]}).Code)
+		assert.Equal(t, http.StatusUnprocessableEntity, serve(http.MethodPost, "/api/v1/email/change/confirm", "", map[string]string{"token": links[1]}).Code)
+	}
+	assert.Contains(t, mails.String(), "To: ex@test.org")
+	assert.Equal(t, http.StatusOK, serve(http.MethodPost, "/api/v1/signin", "", map[string]string{"email": "new@test.org", "password": password}).Code)
+
+	rec = serve(http.MethodPost, "/api/v1/private/profile/password", userSession.ID, map[string]string{"current_password": "invalid", "password": "correct horse"})
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	rec = serve(http.MethodPost, "/api/v1/private/profile/password", userSession.ID, map[string]string{"current_password": password, "password": "short"})
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	rec = serve(http.MethodPost, "/api/v1/private/profile/password", userSession.ID, map[string]string{"current_password": password, "password": "correct horse"})
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Header().Get("Set-Cookie"), handler.SessionIDKey+"=")
+	assert.Equal(t, http.StatusUnauthorized, serve(http.MethodPost, "/api/v1/signin", "", map[string]string{"email": "new@test.org", "password": password}).Code)
+
+	// a deactivated user can't use sessions or sign in until an administrator activates the account
+	assert.Equal(t, http.StatusUnprocessableEntity, serve(http.MethodPost, "/api/v1/private/profile/deactivate", userSession.ID, map[string]string{"password": password}).Code)
+	assert.Equal(t, http.StatusOK, serve(http.MethodPost, "/api/v1/private/profile/deactivate", userSession.ID, map[string]string{"password": "correct horse"}).Code)
+	assert.Equal(t, http.StatusUnauthorized, serve(http.MethodGet, "/api/v1/private/whoami", userSession.ID, nil).Code)
+	assert.Equal(t, http.StatusForbidden, serve(http.MethodPost, "/api/v1/signin", "", map[string]string{"email": "new@test.org", "password": "correct horse"}).Code)
+
+	assert.Equal(t, http.StatusOK, serve(http.MethodPost, fmt.Sprintf("/api/v1/private/admin/users/%d/activate", u.ID), adminSession.ID, nil).Code)
+	assert.Equal(t, http.StatusOK, serve(http.MethodPost, "/api/v1/signin", "", map[string]string{"email": "new@test.org", "password": "correct horse"}).Code)
+}
@@ -0,0 +1,113 @@
+package apiserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/handler"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/pkg/authservice/local"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	sessionBackendGRPC     = "grpc"
+	sessionBackendPostgres = "postgres"
+	sessionBackendMemory   = "memory"
+)
+
+// sessionTimings are the parsed session durations of Config
+type sessionTimings struct {
+	ttl     time.Duration
+	idle    time.Duration
+	cleanup time.Duration
+}
+
+func (c *Config) sessionTimings() (*sessionTimings, error) {
+	t := &sessionTimings{}
+	for name, d := range map[string]struct {
+		value  string
+		target *time.Duration
+	}{
+		"session_ttl":              {c.SessionTTL, &t.ttl},
+		"session_idle_timeout":     {c.SessionIdleTimeout, &t.idle},
+		"session_cleanup_interval": {c.SessionCleanupInterval, &t.cleanup},
+	} {
+		v, err := time.ParseDuration(d.value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		*d.target = v
+	}
+
+	if t.ttl <= 0 {
+		return nil, errors.New("session_ttl must be positive")
+	}
+
+	return t, nil
+}
+
+// cookie returns the attributes of the session id cookie living for maxAge
+func (c *SessionCookieConfig) cookie(maxAge time.Duration) (handler.SessionCookie, error) {
+	cookie := handler.SessionCookie{
+		Path:     c.Path,
+		Domain:   c.Domain,
+		Secure:   c.Secure,
+		HttpOnly: c.HttpOnly,
+		MaxAge:   maxAge,
+	}
+
+	switch strings.ToLower(c.SameSite) {
+	case "", "lax":
+		cookie.SameSite = http.SameSiteLaxMode
+	case "strict":
+		cookie.SameSite = http.SameSiteStrictMode
+	case "none":
+		if !c.Secure {
+			return cookie, errors.New("session_cookie.same_site none requires secure")
+		}
+		cookie.SameSite = http.SameSiteNoneMode
+	default:
+		return cookie, fmt.Errorf("unknown session_cookie.same_site %q", c.SameSite)
+	}
+
+	return cookie, nil
+}
+
+// newLocalSessions creates the session manager of the postgres and memory backends
+func newLocalSessions(backend string, ttl time.Duration, st store.Store) (*local.Client, error) {
+	switch backend {
+	case sessionBackendPostgres:
+		return local.NewClient(st.Session(), ttl), nil
+	case sessionBackendMemory:
+		return local.NewClient(local.NewMemoryStore(), ttl), nil
+	}
+
+	return nil, fmt.Errorf("unknown session_backend %q", backend)
+}
+
+// runCleanup removes expired records with remove every interval until ctx is done
+func runCleanup(ctx context.Context, name string, remove func() (int, error), interval time.Duration, logger *logrus.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			count, err := remove()
+			if err != nil {
+				logger.Errorf("%s cleanup: %s", name, err.Error())
+				continue
+			}
+			if count > 0 {
+				logger.Infof("%s cleanup: %d expired records removed", name, count)
+			}
+		}
+	}
+}
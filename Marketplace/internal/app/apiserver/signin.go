@@ -0,0 +1,117 @@
+package apiserver
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/service"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store/memstore"
+)
+
+const (
+	counterStorePostgres = "postgres"
+	counterStoreMemory   = "memory"
+)
+
+// option returns the service option of the sign-in protection, the postgres counters are
+// the ones of the service store
+func (c *SignInProtectionConfig) option() (service.Option, error) {
+	var counters store.AttemptCounterRepo
+	switch c.CounterStore {
+	case counterStorePostgres:
+	case counterStoreMemory:
+		counters = memstore.NewAttemptCounterRepo()
+	default:
+		return nil, fmt.Errorf("unknown sign-in counter store %q", c.CounterStore)
+	}
+
+	resetAfter, err := time.ParseDuration(c.ResetAfter)
+	if err != nil {
+		return nil, fmt.Errorf("signin_protection.reset_after: %w", err)
+	}
+
+	auditRetention, err := time.ParseDuration(c.AuditRetention)
+	if err != nil {
+		return nil, fmt.Errorf("signin_protection.audit_retention: %w", err)
+	}
+
+	account, err := c.Account.policy("signin_protection.account")
+	if err != nil {
+		return nil, err
+	}
+
+	ip, err := c.IP.policy("signin_protection.ip")
+	if err != nil {
+		return nil, err
+	}
+
+	if resetAfter < account.LockoutDuration || resetAfter < ip.LockoutDuration {
+		return nil, fmt.Errorf("signin_protection.reset_after must not be shorter than the lockout durations")
+	}
+
+	return service.WithSignInProtection(counters, account, ip, resetAfter, auditRetention), nil
+}
+
+func (c *LockoutConfig) policy(name string) (model.LockoutPolicy, error) {
+	p := model.LockoutPolicy{
+		FreeAttempts: c.FreeAttempts,
+		LockAfter:    c.LockAfter,
+	}
+
+	for _, d := range []struct {
+		key   string
+		value string
+		dst   *time.Duration
+	}{
+		{"base_delay", c.BaseDelay, &p.BaseDelay},
+		{"max_delay", c.MaxDelay, &p.MaxDelay},
+		{"lockout_duration", c.LockoutDuration, &p.LockoutDuration},
+	} {
+		parsed, err := time.ParseDuration(d.value)
+		if err != nil {
+			return p, fmt.Errorf("%s.%s: %w", name, d.key, err)
+		}
+		*d.dst = parsed
+	}
+
+	if p.FreeAttempts < 0 || p.LockAfter < 0 || p.BaseDelay < 0 || p.MaxDelay < p.BaseDelay {
+		return p, fmt.Errorf("%s: attempts and delays must not be negative and max_delay not shorter than base_delay", name)
+	}
+
+	if p.LockAfter > 0 && p.LockAfter <= p.FreeAttempts {
+		return p, fmt.Errorf("%s.lock_after must be greater than free_attempts", name)
+	}
+
+	return p, nil
+}
+
+// trustedProxies parses the trusted proxies, a single address is a network of its own
+func (c *Config) trustedProxies() ([]*net.IPNet, error) {
+	proxies := make([]*net.IPNet, 0, len(c.TrustedProxies))
+	for _, proxy := range c.TrustedProxies {
+		if !strings.Contains(proxy, "/") {
+			ip := net.ParseIP(proxy)
+			if ip == nil {
+				return nil, fmt.Errorf("trusted_proxies: invalid address %q", proxy)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			proxies = append(proxies, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, network, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("trusted_proxies: %w", err)
+		}
+		proxies = append(proxies, network)
+	}
+
+	return proxies, nil
+}
@@ -0,0 +1,38 @@
+package apiserver
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/pkg/jwt"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/service"
+)
+
+// option returns the service option of the tokens, without the JWKS file tokens stay disabled
+func (c *TokenConfig) option() (service.Option, error) {
+	if c.JWKSFile == "" {
+		return service.WithTokens(nil, c.Issuer, 0, 0), nil
+	}
+
+	keys, err := jwt.LoadKeySet(c.JWKSFile, c.SigningKeyID)
+	if err != nil {
+		return nil, fmt.Errorf("tokens.jwks_file: %w", err)
+	}
+
+	accessTTL, err := time.ParseDuration(c.AccessTTL)
+	if err != nil {
+		return nil, fmt.Errorf("tokens.access_ttl: %w", err)
+	}
+
+	refreshTTL, err := time.ParseDuration(c.RefreshTTL)
+	if err != nil {
+		return nil, fmt.Errorf("tokens.refresh_ttl: %w", err)
+	}
+
+	if accessTTL <= 0 || refreshTTL <= accessTTL {
+		return nil, errors.New("tokens.access_ttl must be positive and shorter than tokens.refresh_ttl")
+	}
+
+	return service.WithTokens(keys, c.Issuer, accessTTL, refreshTTL), nil
+}
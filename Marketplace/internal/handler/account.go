@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+)
+
+var errMailNotSupported = errors.New("sending emails is not configured")
+
+type accountRequest struct {
+	Email           string `json:"email"`
+	Token           string `json:"token"`
+	Password        string `json:"password"`
+	CurrentPassword string `json:"current_password"`
+}
+
+func (h *Handler) handleEmailVerify() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req := &accountRequest{}
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			h.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		u, err := h.service.AuthService.VerifyEmail(req.Token, time.Now())
+		if err != nil {
+			h.error(w, r, http.StatusUnprocessableEntity, err)
+			return
+		}
+
+		h.respond(w, r, http.StatusOK, u)
+	}
+}
+
+// handleEmailVerifyResend and handleForgotPassword answer 202 whether the email is known or not
+func (h *Handler) handleEmailVerifyResend() http.HandlerFunc {
+	return h.handleAccountEmail(func(email string) error {
+		return h.service.AuthService.ResendVerification(email, time.Now())
+	})
+}
+
+func (h *Handler) handleForgotPassword() http.HandlerFunc {
+	return h.handleAccountEmail(func(email string) error {
+		return h.service.AuthService.ForgotPassword(email, time.Now())
+	})
+}
+
+func (h *Handler) handleAccountEmail(send func(email string) error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !h.service.AuthService.MailEnabled() {
+			h.error(w, r, http.StatusNotImplemented, errMailNotSupported)
+			return
+		}
+
+		req := &accountRequest{}
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			h.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := send(req.Email); err != nil {
+			h.error(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		h.respond(w, r, http.StatusAccepted, nil)
+	}
+}
+
+// handleResetPassword sets the new password and signs the user out everywhere
+func (h *Handler) handleResetPassword() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req := &accountRequest{}
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			h.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		u, err := h.service.AuthService.ResetPassword(req.Token, req.Password, time.Now())
+		if err != nil {
+			h.error(w, r, http.StatusUnprocessableEntity, err)
+			return
+		}
+
+		if err = h.service.LockoutService.Reset(u.Email); err != nil {
+			h.error(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		if err = h.endUserSessions(u.ID); err != nil {
+			h.error(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		h.respond(w, r, http.StatusOK, nil)
+	}
+}
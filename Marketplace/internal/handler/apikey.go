@@ -0,0 +1,143 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+	"github.com/gorilla/mux"
+)
+
+var (
+	errAPIKeyRoute = errors.New("API keys can't be used for this route")
+	errAPIKeyScope = errors.New("API key has no scope for this route")
+)
+
+// apiKeyRoutes are the only routes API keys can be used for, GET, HEAD and OPTIONS requests
+// need the read scope of the route and other requests the write one
+var apiKeyRoutes = []struct {
+	prefix string
+	read   string
+	write  string
+}{
+	{"/api/v1/private/product/", model.ScopeProductsRead, model.ScopeProductsWrite},
+	{"/api/v1/private/supply/", model.ScopeSupplyRead, model.ScopeSupplyWrite},
+}
+
+// apiKeyScope returns the scope an API key needs for the request, empty when keys can't be used
+func apiKeyScope(r *http.Request) string {
+	path := r.URL.Path
+	if route := mux.CurrentRoute(r); route != nil {
+		if tpl, err := route.GetPathTemplate(); err == nil {
+			path = tpl
+		}
+	}
+
+	for _, route := range apiKeyRoutes {
+		if !strings.HasPrefix(path, route.prefix) {
+			continue
+		}
+
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			return route.read
+		default:
+			return route.write
+		}
+	}
+
+	return ""
+}
+
+// apiKeyFromRequest returns the API key of the X-API-Key header or of the bearer authorization
+func apiKeyFromRequest(r *http.Request) string {
+	if key := r.Header.Get("X-API-Key"); key != "" {
+		return key
+	}
+
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if ok && strings.EqualFold(scheme, model.TokenTypeBearer) && strings.HasPrefix(token, model.APIKeyPrefix) {
+		return token
+	}
+
+	return ""
+}
+
+// authenticateAPIKey authenticates the request by the API key and checks the key has the scope
+// of the route, it returns the status code of the failure
+func (h *Handler) authenticateAPIKey(r *http.Request, key string) (*http.Request, int, error) {
+	u, k, err := h.service.APIKeyService.Authenticate(key, time.Now())
+	if err != nil {
+		return nil, http.StatusUnauthorized, err
+	}
+
+	scope := apiKeyScope(r)
+	if scope == "" {
+		return nil, http.StatusForbidden, errAPIKeyRoute
+	}
+	if !k.HasScope(scope) {
+		return nil, http.StatusForbidden, errAPIKeyScope
+	}
+
+	return r.WithContext(context.WithValue(r.Context(), CtxKeyUser, u)), http.StatusOK, nil
+}
+
+func (h *Handler) handleAPIKeyList() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(CtxKeyUser).(*model.User)
+
+		keys, err := h.service.APIKeyService.GetAPIKeys(u.ID)
+		if err != nil {
+			h.error(w, r, http.StatusUnprocessableEntity, err)
+			return
+		}
+
+		h.respond(w, r, http.StatusOK, keys)
+	}
+}
+
+// handleAPIKeyCreate responds with the key, it can't be seen again afterwards
+func (h *Handler) handleAPIKeyCreate() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		k := &model.APIKey{}
+		if err := json.NewDecoder(r.Body).Decode(k); err != nil {
+			h.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		u := r.Context().Value(CtxKeyUser).(*model.User)
+
+		if err := h.service.APIKeyService.CreateAPIKey(u.ID, k, time.Now()); err != nil {
+			h.error(w, r, http.StatusUnprocessableEntity, err)
+			return
+		}
+
+		w.Header().Set("Cache-Control", "no-store")
+		h.respond(w, r, http.StatusCreated, k)
+	}
+}
+
+func (h *Handler) handleAPIKeyDelete() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		reqVars := mux.Vars(r)
+		apiKeyId, err := strconv.Atoi(reqVars["id"])
+		if err != nil {
+			h.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		u := r.Context().Value(CtxKeyUser).(*model.User)
+
+		if err = h.service.APIKeyService.DeleteAPIKey(apiKeyId, u.ID); err != nil {
+			h.error(w, r, http.StatusUnprocessableEntity, err)
+			return
+		}
+
+		h.respond(w, r, http.StatusOK, nil)
+	}
+}
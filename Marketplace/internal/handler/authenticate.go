@@ -5,7 +5,9 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/VladimirBlinov/AuthService/pkg/authservice"
@@ -21,42 +23,123 @@ func (h *Handler) handleSignIn() http.HandlerFunc {
 			return
 		}
 
-		u, err := h.service.AuthService.SignIn(req)
+		now, ip := time.Now(), h.clientIP(r)
+		wait, err := h.service.LockoutService.Check(req.Email, ip, now)
 		if err != nil {
+			h.error(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		// the password isn't checked at all until the delay is over
+		if wait > 0 {
+			if err = h.service.LockoutService.Refuse(req.Email, ip, r.UserAgent(), now); err != nil {
+				h.error(w, r, http.StatusInternalServerError, err)
+				return
+			}
+
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			h.error(w, r, http.StatusTooManyRequests, errTooManySignInAttempts)
+			return
+		}
+
+		u, err := h.service.AuthService.SignIn(req)
+		if err == service.ErrEmailNotVerified || err == service.ErrAccountDeactivated {
+			// the password is right, the account can't be used until the email is verified
+			// or an administrator activates it
+			if resetErr := h.service.LockoutService.Reset(req.Email); resetErr != nil {
+				h.error(w, r, http.StatusInternalServerError, resetErr)
+				return
+			}
+
+			h.error(w, r, http.StatusForbidden, err)
+			return
+		} else if err != nil {
+			if err = h.service.LockoutService.Fail(req.Email, ip, r.UserAgent(), now); err != nil {
+				h.error(w, r, http.StatusInternalServerError, err)
+				return
+			}
+
 			h.error(w, r, http.StatusUnauthorized, errIncorrectEmailOrPassword)
 			return
 		}
 
-		sessionS, err := h.sessionManager.Create(context.Background(), &authservice.Session{
-			UserID: int32(u.ID),
-		})
+		if err = h.service.AuthService.RehashPassword(u, req.Password); err != nil {
+			h.logger.Errorf("rehash password of user %d: %s", u.ID, err.Error())
+		}
+
+		twoFactor, err := h.service.TwoFactorService.Enabled(u.ID)
 		if err != nil {
 			h.error(w, r, http.StatusInternalServerError, err)
 			return
 		}
 
-		expiration := time.Now().Add(365 * 24 * time.Hour)
-		cookie := http.Cookie{
-			Name:    SessionIDKey,
-			Value:   sessionS.ID,
-			Expires: expiration,
+		// failures of the account are forgotten only after the second factor too
+		if twoFactor {
+			challenge, err := h.service.TwoFactorService.Challenge(u, now)
+			if err != nil {
+				h.error(w, r, http.StatusInternalServerError, err)
+				return
+			}
+
+			w.Header().Set("Cache-Control", "no-store")
+			h.respond(w, r, http.StatusAccepted, challenge)
+			return
+		}
+
+		if err = h.service.LockoutService.Reset(req.Email); err != nil {
+			h.error(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		h.startSession(w, r, u)
+	}
+}
+
+// startSession creates the session of a signed in user and sets the cookie,
+// with JWT enabled the response holds the token pair of the session
+func (h *Handler) startSession(w http.ResponseWriter, r *http.Request, u *model.User) {
+	sessionS, err := h.sessionManager.Create(context.Background(), &authservice.Session{
+		UserID: int32(u.ID),
+	})
+	if err != nil {
+		h.error(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	if registry, ok := h.sessionManager.(SessionRegistry); ok {
+		if err := h.checkSession(registry, r, sessionS.ID); err != nil {
+			h.error(w, r, http.StatusInternalServerError, err)
+			return
 		}
-		http.SetCookie(w, &cookie)
+	}
 
-		// session, err := h.sessionStore.Get(r, SessionName)
-		// if err != nil {
-		// 	h.error(w, r, http.StatusInternalServerError, err)
-		// 	return
-		// }
+	h.setSessionCookie(w, sessionS.ID)
 
-		// session.Values["user_id"] = u.ID
-		// if err := h.sessionStore.Save(r, w, session); err != nil {
-		// 	h.error(w, r, http.StatusInternalServerError, err)
-		// 	return
-		// }
+	if h.service.TokenService.Enabled() {
+		pair, err := h.service.TokenService.Issue(u, sessionS.ID)
+		if err != nil {
+			h.error(w, r, http.StatusInternalServerError, err)
+			return
+		}
 
-		h.respond(w, r, http.StatusOK, nil)
+		w.Header().Set("Cache-Control", "no-store")
+		h.respond(w, r, http.StatusOK, pair)
+		return
 	}
+
+	// session, err := h.sessionStore.Get(r, SessionName)
+	// if err != nil {
+	// 	h.error(w, r, http.StatusInternalServerError, err)
+	// 	return
+	// }
+
+	// session.Values["user_id"] = u.ID
+	// if err := h.sessionStore.Save(r, w, session); err != nil {
+	// 	h.error(w, r, http.StatusInternalServerError, err)
+	// 	return
+	// }
+
+	h.respond(w, r, http.StatusOK, nil)
 }
 
 func (h *Handler) handleRegister() http.HandlerFunc {
@@ -79,6 +162,17 @@ func (h *Handler) handleRegister() http.HandlerFunc {
 
 func (h *Handler) handleSignOut() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		// a client authenticated with an access token signs out by revoking its refresh token
+		if tokenSession, ok := r.Context().Value(ctxKeyTokenSession).(string); ok {
+			if err := h.service.TokenService.Revoke(tokenSession); err != nil {
+				h.error(w, r, http.StatusInternalServerError, err)
+				return
+			}
+
+			h.respond(w, r, http.StatusOK, nil)
+			return
+		}
+
 		cookieSessionID, err := r.Cookie(SessionIDKey)
 		if err == http.ErrNoCookie {
 			h.error(w, r, http.StatusUnauthorized, errNotAuthenticated)
@@ -101,8 +195,12 @@ func (h *Handler) handleSignOut() http.HandlerFunc {
 			return
 		}
 
-		cookieSessionID.Expires = time.Now().AddDate(0, 0, -1)
-		http.SetCookie(w, cookieSessionID)
+		if err = h.service.TokenService.RevokeSession(cookieSessionID.Value); err != nil {
+			h.error(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		h.clearSessionCookie(w)
 
 		h.respond(w, r.WithContext(context.Background()), http.StatusOK, nil)
 	}
@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+	"github.com/gorilla/mux"
+)
+
+func (h *Handler) handleCategoryAttributeList() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		reqVars := mux.Vars(r)
+		categoryId, err := strconv.Atoi(reqVars["id"])
+		if err != nil {
+			h.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		schema, err := h.service.AttributeService.GetCategorySchema(categoryId)
+		if err != nil {
+			h.error(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		h.respond(w, r, http.StatusOK, schema)
+	}
+}
+
+func (h *Handler) handleCategoryAttributeCreate() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		reqVars := mux.Vars(r)
+		categoryId, err := strconv.Atoi(reqVars["id"])
+		if err != nil {
+			h.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		req := &model.CategoryAttribute{}
+		if err = json.NewDecoder(r.Body).Decode(req); err != nil {
+			h.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		if err = h.service.AttributeService.CreateCategoryAttribute(categoryId, req); err != nil {
+			h.error(w, r, http.StatusUnprocessableEntity, err)
+			return
+		}
+
+		h.respond(w, r, http.StatusCreated, req)
+	}
+}
+
+func (h *Handler) handleCategoryAttributeDelete() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		reqVars := mux.Vars(r)
+		categoryId, err := strconv.Atoi(reqVars["id"])
+		if err != nil {
+			h.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		attributeId, err := strconv.Atoi(reqVars["attribute_id"])
+		if err != nil {
+			h.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		if err = h.service.AttributeService.DeleteCategoryAttribute(categoryId, attributeId); err != nil {
+			h.serviceError(w, r, err)
+			return
+		}
+
+		h.respond(w, r, http.StatusOK, nil)
+	}
+}
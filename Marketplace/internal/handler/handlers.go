@@ -3,10 +3,13 @@ package handler
 import (
 	"encoding/json"
 	"errors"
+	"net"
 	"net/http"
 
 	"github.com/VladimirBlinov/AuthService/pkg/authservice"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/pkg/health"
 	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/service"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store"
 	"github.com/gorilla/handlers"
 	"github.com/gorilla/mux"
 	"github.com/gorilla/sessions"
@@ -18,51 +21,101 @@ const (
 	SessionIDKey           = "session_id"
 	CtxKeyUser      ctxKey = iota
 	ctxKeyRequestID ctxKey = iota
+	// ctxKeyTokenSession holds the refresh token id of a request authenticated with an access token
+	ctxKeyTokenSession ctxKey = iota
 )
 
 var (
 	errIncorrectEmailOrPassword = errors.New("incorrect email or password")
 	errNotAuthenticated         = errors.New("not authenticated")
+	errForbidden                = errors.New("forbidden")
+	errTooManySignInAttempts    = errors.New("too many failed sign-in attempts, try again later")
+	errTwoFactorRequired        = errors.New("enable two-factor authentication to use admin endpoints")
 )
 
 type ctxKey int8
 
 type Handler struct {
-	service        *service.Service
-	sessionStore   sessions.Store
-	Router         *mux.Router
-	logger         *logrus.Logger
-	sessionManager authservice.AuthServiceClient
+	service         *service.Service
+	sessionStore    sessions.Store
+	Router          *mux.Router
+	logger          *logrus.Logger
+	sessionManager  authservice.AuthServiceClient
+	readiness       *health.Checker
+	sessionCookie   SessionCookie
+	sessionTimeouts SessionTimeouts
+	trustedProxies  []*net.IPNet
 }
 
-func NewHandler(service *service.Service, sessionStore sessions.Store, sessionManager authservice.AuthServiceClient) *Handler {
-	return &Handler{
+func NewHandler(service *service.Service, sessionStore sessions.Store, sessionManager authservice.AuthServiceClient, opts ...Option) *Handler {
+	h := &Handler{
 		service:        service,
 		sessionStore:   sessionStore,
 		Router:         mux.NewRouter(),
 		logger:         logrus.New(),
 		sessionManager: sessionManager,
+		readiness:      health.NewChecker(),
+		sessionCookie:  defaultSessionCookie(),
 	}
+
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	return h
 }
 
 func (h *Handler) InitHandler() {
 	api := h.Router.PathPrefix("/api/v1").Subrouter()
 	api.Use(handlers.CORS(
-		handlers.ExposedHeaders([]string{"Set-Cookie"}),
+		handlers.ExposedHeaders([]string{"Set-Cookie", "ETag"}),
 		handlers.AllowCredentials(),
-		handlers.AllowedHeaders([]string{"Content-Type", "Authorization", "content-type", "Origin", "Accept", "X-Requested-With"}),
-		handlers.AllowedMethods([]string{"OPTIONS", "DELETE", "GET", "HEAD", "POST", "PUT"}),
+		handlers.AllowedHeaders([]string{"Content-Type", "Authorization", "content-type", "Origin", "Accept", "X-Requested-With", "If-Match", "X-API-Key"}),
+		handlers.AllowedMethods([]string{"OPTIONS", "DELETE", "GET", "HEAD", "POST", "PUT", "PATCH"}),
 		handlers.AllowedOrigins([]string{"http://localhost:3000", "http://localhost"}),
 	))
 	api.Use(h.setRequestID)
 	api.Use(h.logRequest)
 	api.HandleFunc("/register", h.handleRegister()).Methods("POST")
 	api.HandleFunc("/signin", h.handleSignIn()).Methods("POST")
+	api.HandleFunc("/signin/2fa", h.handleSignInTwoFactor()).Methods("POST")
+	api.HandleFunc("/email/verify", h.handleEmailVerify()).Methods("POST")
+	api.HandleFunc("/email/verify/resend", h.handleEmailVerifyResend()).Methods("POST")
+	api.HandleFunc("/email/change/confirm", h.handleEmailChangeConfirm()).Methods("POST")
+	api.HandleFunc("/password/forgot", h.handleForgotPassword()).Methods("POST")
+	api.HandleFunc("/password/reset", h.handleResetPassword()).Methods("POST")
+	api.HandleFunc("/token/refresh", h.handleTokenRefresh()).Methods("POST")
+	api.HandleFunc("/.well-known/jwks.json", h.handleJWKS()).Methods("GET")
+	api.HandleFunc("/media/{key:.+}", h.handleMedia()).Methods("GET")
+	api.HandleFunc("/health", h.handleHealth()).Methods("GET")
+	api.HandleFunc("/ready", h.handleReady()).Methods("GET")
 
 	private := api.PathPrefix("/private").Subrouter()
 	private.Use(h.AuthenticateUser)
 	private.HandleFunc("/whoami", h.handleWhoami()).Methods("GET")
+	private.HandleFunc("/profile", h.handleWhoami()).Methods("GET")
+	private.HandleFunc("/profile", h.handleProfileUpdate()).Methods("PATCH")
+	private.HandleFunc("/profile/email", h.handleEmailChange()).Methods("POST")
+	private.HandleFunc("/profile/password", h.handlePasswordChange()).Methods("POST")
+	private.HandleFunc("/profile/deactivate", h.handleDeactivate()).Methods("POST")
 	private.HandleFunc("/signout", h.handleSignOut()).Methods("GET")
+	private.HandleFunc("/signout/all", h.handleSignOutAll()).Methods("POST")
+	private.HandleFunc("/sessions", h.handleSessionList()).Methods("GET")
+	private.HandleFunc("/api_keys", h.handleAPIKeyList()).Methods("GET")
+	private.HandleFunc("/api_keys", h.handleAPIKeyCreate()).Methods("POST")
+	private.HandleFunc("/api_keys/{id}", h.handleAPIKeyDelete()).Methods("DELETE")
+	private.HandleFunc("/2fa", h.handleTwoFactorStatus()).Methods("GET")
+	private.HandleFunc("/2fa/enroll", h.handleTwoFactorEnroll()).Methods("POST")
+	private.HandleFunc("/2fa/enable", h.handleTwoFactorEnable()).Methods("POST")
+	private.HandleFunc("/2fa/disable", h.handleTwoFactorDisable()).Methods("POST")
+	private.HandleFunc("/2fa/recovery_codes", h.handleRecoveryCodesRegenerate()).Methods("POST")
+
+	admin := private.PathPrefix("/admin").Subrouter()
+	admin.Use(h.requireAdmin)
+	admin.HandleFunc("/signin_failures", h.handleSignInFailureList()).Methods("GET")
+	admin.HandleFunc("/users/{id}/unlock", h.handleUserUnlock()).Methods("POST")
+	admin.HandleFunc("/users/{id}/2fa/reset", h.handleTwoFactorReset()).Methods("POST")
+	admin.HandleFunc("/users/{id}/activate", h.handleUserActivate()).Methods("POST")
 
 	product := private.PathPrefix("/product").Subrouter()
 	product.HandleFunc("/product", h.handleProductCreate()).Methods("POST")
@@ -70,9 +123,81 @@ func (h *Handler) InitHandler() {
 	product.HandleFunc("/product/{id}", h.handleProductOptions()).Methods("OPTIONS")
 	product.HandleFunc("/product/{id}", h.handleProductGet()).Methods("GET")
 	product.HandleFunc("/product/{id}", h.handleProductUpdate()).Methods("PUT")
+	product.HandleFunc("/product/{id}", h.handleProductPatch()).Methods("PATCH")
 	product.HandleFunc("/product/{id}", h.handleProductDelete()).Methods("DELETE")
+	product.HandleFunc("/product/{id}/variants", h.handleProductVariantCreate()).Methods("POST")
+	product.HandleFunc("/product/{id}/variants", h.handleProductVariantList()).Methods("GET")
+	product.HandleFunc("/product/{id}/barcodes", h.handleProductBarcodeList()).Methods("GET")
+	product.HandleFunc("/product/{id}/barcodes", h.handleProductBarcodeCreate()).Methods("POST")
+	product.HandleFunc("/product/{id}/barcodes/generate", h.handleProductBarcodeGenerate()).Methods("POST")
+	product.HandleFunc("/product/{id}/barcodes/{barcode_id}", h.handleProductBarcodeDelete()).Methods("DELETE")
+	product.HandleFunc("/product/{id}/fees", h.handleProductFees()).Methods("GET")
+	product.HandleFunc("/product/{id}/prices", h.handleProductPriceList()).Methods("GET")
+	product.HandleFunc("/product/{id}/prices/history", h.handleProductPriceHistory()).Methods("GET")
+	product.HandleFunc("/product/{id}/images", h.handleProductImageUpload()).Methods("POST")
+	product.HandleFunc("/product/{id}/images", h.handleProductImageList()).Methods("GET")
+	product.HandleFunc("/product/{id}/images/order", h.handleProductImageOrder()).Methods("PUT")
+	product.HandleFunc("/product/{id}/images/{image_id}", h.handleProductImageDelete()).Methods("DELETE")
+	product.HandleFunc("/product/{id}/revisions", h.handleProductRevisionList()).Methods("GET")
+	product.HandleFunc("/product/{id}/revisions/diff", h.handleProductRevisionDiff()).Methods("GET")
+	product.HandleFunc("/product/{id}/revisions/{revision}/rollback", h.handleProductRollback()).Methods("POST")
+	product.HandleFunc("/labels", h.handleProductLabels()).Methods("POST")
+	product.HandleFunc("/prices", h.handleProductPriceSet()).Methods("POST")
+	product.HandleFunc("/barcode/{code}", h.handleProductByBarcode()).Methods("GET")
 	product.HandleFunc("/category/get_categories", h.handleProductCategoryGet()).Methods("GET")
+	product.HandleFunc("/category/{id}/attributes", h.handleCategoryAttributeList()).Methods("GET")
+	product.Handle("/category/{id}/attributes", h.requireAdmin(h.handleCategoryAttributeCreate())).Methods("POST")
+	product.Handle("/category/{id}/attributes/{attribute_id}", h.requireAdmin(h.handleCategoryAttributeDelete())).Methods("DELETE")
 	product.HandleFunc("/material/get_materials", h.handleProductMaterialGet()).Methods("GET")
+
+	repricing := private.PathPrefix("/repricing").Subrouter()
+	repricing.HandleFunc("/rules", h.handleRepricingRuleList()).Methods("GET")
+	repricing.HandleFunc("/rules", h.handleRepricingRuleCreate()).Methods("POST")
+	repricing.HandleFunc("/rules/{id}", h.handleRepricingRuleDelete()).Methods("DELETE")
+	repricing.HandleFunc("/competitor_prices", h.handleCompetitorPriceImport()).Methods("POST")
+	repricing.HandleFunc("/run", h.handleRepricingRun()).Methods("POST")
+	repricing.HandleFunc("/proposals", h.handleRepricingProposalList()).Methods("GET")
+	repricing.HandleFunc("/proposals/apply", h.handleRepricingProposalApply()).Methods("POST")
+	repricing.HandleFunc("/proposals/{id}/approve", h.handleRepricingProposalApprove()).Methods("POST")
+	repricing.HandleFunc("/proposals/{id}/reject", h.handleRepricingProposalReject()).Methods("POST")
+	repricing.HandleFunc("/log", h.handleRepricingLog()).Methods("GET")
+
+	supply := private.PathPrefix("/supply").Subrouter()
+	supply.HandleFunc("/sales", h.handleSalesImport()).Methods("POST")
+	supply.HandleFunc("/stock", h.handleStockList()).Methods("GET")
+	supply.HandleFunc("/stock", h.handleStockSet()).Methods("PUT")
+	supply.HandleFunc("/stock/report", h.handleStockReport()).Methods("GET")
+	supply.HandleFunc("/warehouses", h.handleWarehouseList()).Methods("GET")
+	supply.HandleFunc("/warehouses", h.handleWarehouseCreate()).Methods("POST")
+	supply.HandleFunc("/warehouses/{id}", h.handleWarehouseDelete()).Methods("DELETE")
+	supply.HandleFunc("/transfers", h.handleTransferList()).Methods("GET")
+	supply.HandleFunc("/transfers", h.handleTransferCreate()).Methods("POST")
+	supply.HandleFunc("/transfers/{id}", h.handleTransferGet()).Methods("GET")
+	supply.HandleFunc("/transfers/{id}/ship", h.handleTransferShip()).Methods("POST")
+	supply.HandleFunc("/transfers/{id}/receive", h.handleTransferReceive()).Methods("POST")
+	supply.HandleFunc("/transfers/{id}/cancel", h.handleTransferCancel()).Methods("POST")
+	supply.HandleFunc("/shipments", h.handleShipmentList()).Methods("GET")
+	supply.HandleFunc("/shipments", h.handleShipmentCreate()).Methods("POST")
+	supply.HandleFunc("/shipments/{id}", h.handleShipmentGet()).Methods("GET")
+	supply.HandleFunc("/shipments/{id}", h.handleShipmentUpdate()).Methods("PUT")
+	supply.HandleFunc("/shipments/{id}/packing", h.handleShipmentPackingCheck()).Methods("GET")
+	supply.HandleFunc("/shipments/{id}/status", h.handleShipmentStatus()).Methods("POST")
+	supply.HandleFunc("/shipments/{id}/packing_list", h.handleShipmentPackingList()).Methods("GET")
+	supply.HandleFunc("/suppliers", h.handleSupplierList()).Methods("GET")
+	supply.HandleFunc("/suppliers", h.handleSupplierCreate()).Methods("POST")
+	supply.HandleFunc("/replenishment", h.handleReplenishmentReport()).Methods("GET")
+	supply.HandleFunc("/orders/draft", h.handleSupplyOrderDraft()).Methods("POST")
+	supply.HandleFunc("/orders/{id}", h.handleSupplyOrderGet()).Methods("GET")
+}
+
+// serviceError responds 404 when a record the request refers to is not found, other service errors are 422
+func (h *Handler) serviceError(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, store.ErrRecordNotFound) {
+		h.error(w, r, http.StatusNotFound, err)
+		return
+	}
+
+	h.error(w, r, http.StatusUnprocessableEntity, err)
 }
 
 func (h *Handler) error(w http.ResponseWriter, r *http.Request, code int, err error) {
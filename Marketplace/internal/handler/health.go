@@ -0,0 +1,36 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/pkg/health"
+)
+
+type readinessResponse struct {
+	Ready      bool                `json:"ready"`
+	Components []*health.Component `json:"components"`
+}
+
+// handleHealth reports the process is alive, it doesn't depend on other services
+func (h *Handler) handleHealth() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		h.respond(w, r, http.StatusOK, map[string]string{"status": "ok"})
+	}
+}
+
+// handleReady answers 503 while any of the services the API depends on isn't ready
+func (h *Handler) handleReady() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		resp := &readinessResponse{
+			Ready:      h.readiness.Ready(),
+			Components: h.readiness.Components(),
+		}
+
+		code := http.StatusOK
+		if !resp.Ready {
+			code = http.StatusServiceUnavailable
+		}
+
+		h.respond(w, r, code, resp)
+	}
+}
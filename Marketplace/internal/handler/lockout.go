@@ -0,0 +1,49 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+func (h *Handler) handleSignInFailureList() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+
+		limit := 0
+		if l := query.Get("limit"); l != "" {
+			var err error
+			if limit, err = strconv.Atoi(l); err != nil {
+				h.error(w, r, http.StatusBadRequest, err)
+				return
+			}
+		}
+
+		failures, err := h.service.LockoutService.GetFailures(query.Get("email"), query.Get("ip"), limit)
+		if err != nil {
+			h.error(w, r, http.StatusUnprocessableEntity, err)
+			return
+		}
+
+		h.respond(w, r, http.StatusOK, failures)
+	}
+}
+
+func (h *Handler) handleUserUnlock() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		reqVars := mux.Vars(r)
+		userId, err := strconv.Atoi(reqVars["id"])
+		if err != nil {
+			h.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		if err = h.service.LockoutService.Unlock(userId); err != nil {
+			h.error(w, r, http.StatusUnprocessableEntity, err)
+			return
+		}
+
+		h.respond(w, r, http.StatusOK, nil)
+	}
+}
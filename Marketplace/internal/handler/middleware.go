@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/VladimirBlinov/AuthService/pkg/authservice"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 )
@@ -48,6 +49,28 @@ func (h *Handler) logRequest(next http.Handler) http.Handler {
 
 func (h *Handler) AuthenticateUser(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if key := apiKeyFromRequest(r); key != "" {
+			req, code, err := h.authenticateAPIKey(r, key)
+			if err != nil {
+				h.error(w, r, code, err)
+				return
+			}
+
+			next.ServeHTTP(w, req)
+			return
+		}
+
+		if authorization := r.Header.Get("Authorization"); authorization != "" {
+			req, err := h.authenticateToken(r, authorization)
+			if err != nil {
+				h.error(w, r, http.StatusUnauthorized, err)
+				return
+			}
+
+			next.ServeHTTP(w, req)
+			return
+		}
+
 		// session, err := h.sessionStore.Get(r, SessionName)
 		// if err != nil {
 		// 	h.error(w, r, http.StatusInternalServerError, err)
@@ -81,6 +104,13 @@ func (h *Handler) AuthenticateUser(next http.Handler) http.Handler {
 			return
 		}
 
+		if registry, ok := h.sessionManager.(SessionRegistry); ok {
+			if err := h.checkSession(registry, r, cookieSessionID.Value); err != nil {
+				h.error(w, r, http.StatusUnauthorized, err)
+				return
+			}
+		}
+
 		u, err := h.service.AuthService.Authenticate(int(sessionS.UserID))
 		if err != nil {
 			h.error(w, r, http.StatusUnauthorized, errNotAuthenticated)
@@ -90,3 +120,28 @@ func (h *Handler) AuthenticateUser(next http.Handler) http.Handler {
 		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), CtxKeyUser, u)))
 	})
 }
+
+func (h *Handler) requireAdmin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		u, ok := r.Context().Value(CtxKeyUser).(*model.User)
+		if !ok || u.UserRole != model.UserRoleAdmin {
+			h.error(w, r, http.StatusForbidden, errForbidden)
+			return
+		}
+
+		if h.service.TwoFactorService.Required(u) {
+			enabled, err := h.service.TwoFactorService.Enabled(u.ID)
+			if err != nil {
+				h.error(w, r, http.StatusInternalServerError, err)
+				return
+			}
+
+			if !enabled {
+				h.error(w, r, http.StatusForbidden, errTwoFactorRequired)
+				return
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,39 @@
+package handler
+
+import (
+	"net"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/pkg/health"
+)
+
+type Option func(*Handler)
+
+// WithReadiness sets the state of the services the readiness endpoint reports
+func WithReadiness(readiness *health.Checker) Option {
+	return func(h *Handler) {
+		h.readiness = readiness
+	}
+}
+
+// WithSessionCookie sets the attributes of the session id cookie
+func WithSessionCookie(cookie SessionCookie) Option {
+	return func(h *Handler) {
+		h.sessionCookie = cookie
+	}
+}
+
+// WithSessionTimeouts sets the idle and absolute timeouts AuthenticateUser enforces
+// for session managers implementing SessionRegistry
+func WithSessionTimeouts(timeouts SessionTimeouts) Option {
+	return func(h *Handler) {
+		h.sessionTimeouts = timeouts
+	}
+}
+
+// WithTrustedProxies sets the networks of reverse proxies whose X-Forwarded-For header
+// gives the client address
+func WithTrustedProxies(proxies []*net.IPNet) Option {
+	return func(h *Handler) {
+		h.trustedProxies = proxies
+	}
+}
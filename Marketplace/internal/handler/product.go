@@ -2,13 +2,27 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/pkg/mergepatch"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store"
 	"github.com/gorilla/mux"
 )
 
+const mergePatchContentType = "application/merge-patch+json"
+
+var (
+	errInvalidIfMatch       = errors.New("invalid If-Match header")
+	errVersionRequired      = errors.New("product version is required, send it in If-Match or the request body")
+	errUnsupportedPatchType = errors.New("unsupported patch content type, use " + mergePatchContentType)
+)
+
 func (h *Handler) handleProductOptions() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		h.respond(w, r, http.StatusOK, nil)
@@ -52,15 +66,87 @@ func (h *Handler) handleProductUpdate() func(http.ResponseWriter, *http.Request)
 		req.Active = true
 		req.UserID = r.Context().Value(CtxKeyUser).(*model.User).ID
 
+		version, conditional, err := ifMatchVersion(r)
+		if err != nil {
+			h.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+		if conditional {
+			req.Version = version
+		}
+		if req.Version < 1 {
+			h.error(w, r, http.StatusPreconditionRequired, errVersionRequired)
+			return
+		}
+
 		if err = h.service.ProductService.UpdateProduct(productId, req); err != nil {
+			if err == store.ErrVersionConflict {
+				h.productConflict(w, r, productId, conditional, err)
+				return
+			}
 			h.error(w, r, http.StatusUnprocessableEntity, err)
 			return
 		}
 
+		w.Header().Set("ETag", productETag(req.Version))
 		h.respond(w, r, http.StatusCreated, nil)
 	}
 }
 
+func (h *Handler) handleProductPatch() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		reqVars := mux.Vars(r)
+		productId, err := strconv.Atoi(reqVars["id"])
+		if err != nil {
+			h.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		contentType := strings.TrimSpace(strings.Split(r.Header.Get("Content-Type"), ";")[0])
+		if contentType != "" && contentType != mergePatchContentType && contentType != "application/json" {
+			h.error(w, r, http.StatusUnsupportedMediaType, errUnsupportedPatchType)
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			h.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		patch, err := mergepatch.Parse(body)
+		if err != nil {
+			h.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		version, conditional, err := ifMatchVersion(r)
+		if err != nil {
+			h.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+		if !conditional && !hasPatchVersion(patch) {
+			h.error(w, r, http.StatusPreconditionRequired, errVersionRequired)
+			return
+		}
+
+		u := r.Context().Value(CtxKeyUser).(*model.User)
+
+		product, err := h.service.ProductService.PatchProduct(productId, u.ID, version, patch)
+		if err != nil {
+			if err == store.ErrVersionConflict {
+				h.productConflict(w, r, productId, conditional, err)
+				return
+			}
+			h.serviceError(w, r, err)
+			return
+		}
+
+		w.Header().Set("ETag", productETag(product.Version))
+		h.respond(w, r, http.StatusOK, product)
+	}
+}
+
 func (h *Handler) handleProductGet() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		reqVars := mux.Vars(r)
@@ -76,6 +162,7 @@ func (h *Handler) handleProductGet() http.HandlerFunc {
 			return
 		}
 
+		w.Header().Set("ETag", productETag(product.Version))
 		h.respond(w, r, http.StatusOK, product)
 	}
 }
@@ -92,7 +179,7 @@ func (h *Handler) handleProductDelete() http.HandlerFunc {
 		u := r.Context().Value(CtxKeyUser).(*model.User)
 
 		if err = h.service.ProductService.DeleteProduct(productId, u.ID); err != nil {
-			h.error(w, r, http.StatusInternalServerError, nil)
+			h.error(w, r, http.StatusUnprocessableEntity, err)
 			return
 		}
 
@@ -104,7 +191,12 @@ func (h *Handler) handleProductList() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		u := r.Context().Value(CtxKeyUser).(*model.User)
 
-		products, err := h.service.ProductService.GetProductsByUserId(u.ID)
+		list := h.service.ProductService.GetProductsByUserId
+		if r.URL.Query().Get("group") == "variants" {
+			list = h.service.ProductService.GetGroupedProductsByUserId
+		}
+
+		products, err := list(u.ID)
 		if err != nil {
 			h.error(w, r, http.StatusInternalServerError, err)
 			return
@@ -137,3 +229,51 @@ func (h *Handler) handleProductMaterialGet() http.HandlerFunc {
 		h.respond(w, r, http.StatusOK, materials)
 	}
 }
+
+// productConflict responds with the current product state when an update was based on a stale version:
+// 412 if the version came from If-Match, 409 if it came from the request body
+func (h *Handler) productConflict(w http.ResponseWriter, r *http.Request, productId int, conditional bool, err error) {
+	code := http.StatusConflict
+	if conditional {
+		code = http.StatusPreconditionFailed
+	}
+
+	current, getErr := h.service.ProductService.GetProductById(productId)
+	if getErr != nil {
+		h.error(w, r, http.StatusUnprocessableEntity, getErr)
+		return
+	}
+
+	w.Header().Set("ETag", productETag(current.Version))
+	h.respond(w, r, code, map[string]interface{}{
+		"error":   err.Error(),
+		"product": current,
+	})
+}
+
+func productETag(version int) string {
+	return fmt.Sprintf(`"%d"`, version)
+}
+
+// ifMatchVersion extracts the product version from the If-Match header, "*" carries no version
+func ifMatchVersion(r *http.Request) (int, bool, error) {
+	ifMatch := strings.TrimSpace(r.Header.Get("If-Match"))
+	if ifMatch == "" || ifMatch == "*" {
+		return 0, false, nil
+	}
+
+	tag := strings.TrimSpace(strings.Split(ifMatch, ",")[0])
+	tag = strings.TrimPrefix(tag, "W/")
+	version, err := strconv.Atoi(strings.Trim(tag, `"`))
+	if err != nil || version < 1 {
+		return 0, false, errInvalidIfMatch
+	}
+
+	return version, true, nil
+}
+
+// hasPatchVersion reports whether the merge patch sets the version the update is based on
+func hasPatchVersion(patch mergepatch.Patch) bool {
+	version, ok := patch["version"].(float64)
+	return ok && version >= 1 && version == float64(int(version))
+}
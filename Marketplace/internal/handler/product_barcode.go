@@ -0,0 +1,125 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+	"github.com/gorilla/mux"
+)
+
+func (h *Handler) handleProductBarcodeList() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		reqVars := mux.Vars(r)
+		productId, err := strconv.Atoi(reqVars["id"])
+		if err != nil {
+			h.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		u := r.Context().Value(CtxKeyUser).(*model.User)
+
+		barcodes, err := h.service.BarcodeService.GetProductBarcodes(productId, u.ID)
+		if err != nil {
+			h.error(w, r, http.StatusUnprocessableEntity, err)
+			return
+		}
+
+		h.respond(w, r, http.StatusOK, barcodes)
+	}
+}
+
+func (h *Handler) handleProductBarcodeCreate() http.HandlerFunc {
+	type request struct {
+		Code string `json:"code"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		reqVars := mux.Vars(r)
+		productId, err := strconv.Atoi(reqVars["id"])
+		if err != nil {
+			h.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		req := &request{}
+		if err = json.NewDecoder(r.Body).Decode(req); err != nil {
+			h.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		u := r.Context().Value(CtxKeyUser).(*model.User)
+
+		barcode, err := h.service.BarcodeService.AddBarcode(productId, u.ID, req.Code)
+		if err != nil {
+			h.error(w, r, http.StatusUnprocessableEntity, err)
+			return
+		}
+
+		h.respond(w, r, http.StatusCreated, barcode)
+	}
+}
+
+func (h *Handler) handleProductBarcodeGenerate() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		reqVars := mux.Vars(r)
+		productId, err := strconv.Atoi(reqVars["id"])
+		if err != nil {
+			h.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		u := r.Context().Value(CtxKeyUser).(*model.User)
+
+		barcode, err := h.service.BarcodeService.GenerateBarcode(productId, u.ID)
+		if err != nil {
+			h.error(w, r, http.StatusUnprocessableEntity, err)
+			return
+		}
+
+		h.respond(w, r, http.StatusCreated, barcode)
+	}
+}
+
+func (h *Handler) handleProductBarcodeDelete() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		reqVars := mux.Vars(r)
+		productId, err := strconv.Atoi(reqVars["id"])
+		if err != nil {
+			h.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		barcodeId, err := strconv.Atoi(reqVars["barcode_id"])
+		if err != nil {
+			h.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		u := r.Context().Value(CtxKeyUser).(*model.User)
+
+		if err = h.service.BarcodeService.DeleteBarcode(productId, barcodeId, u.ID); err != nil {
+			h.error(w, r, http.StatusUnprocessableEntity, err)
+			return
+		}
+
+		h.respond(w, r, http.StatusOK, nil)
+	}
+}
+
+func (h *Handler) handleProductByBarcode() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		reqVars := mux.Vars(r)
+		u := r.Context().Value(CtxKeyUser).(*model.User)
+
+		product, err := h.service.ProductService.GetProductByBarcode(u.ID, reqVars["code"])
+		if err != nil {
+			h.error(w, r, http.StatusNotFound, err)
+			return
+		}
+
+		w.Header().Set("ETag", productETag(product.Version))
+		h.respond(w, r, http.StatusOK, product)
+	}
+}
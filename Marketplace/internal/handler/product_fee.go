@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+	"github.com/gorilla/mux"
+)
+
+var errPriceRequired = errors.New("price is required")
+
+func (h *Handler) handleProductFees() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		reqVars := mux.Vars(r)
+		productId, err := strconv.Atoi(reqVars["id"])
+		if err != nil {
+			h.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		query := r.URL.Query()
+		if query.Get("price") == "" {
+			h.error(w, r, http.StatusBadRequest, errPriceRequired)
+			return
+		}
+
+		price, err := strconv.ParseFloat(query.Get("price"), 64)
+		if err != nil {
+			h.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		at := time.Now()
+		if query.Get("date") != "" {
+			at, err = time.Parse("2006-01-02", query.Get("date"))
+			if err != nil {
+				h.error(w, r, http.StatusBadRequest, err)
+				return
+			}
+		}
+
+		u := r.Context().Value(CtxKeyUser).(*model.User)
+
+		fees, err := h.service.TariffService.EstimateFees(productId, u.ID, price, at)
+		if err != nil {
+			h.serviceError(w, r, err)
+			return
+		}
+
+		h.respond(w, r, http.StatusOK, fees)
+	}
+}
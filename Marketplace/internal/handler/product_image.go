@@ -0,0 +1,174 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path"
+	"strconv"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+	"github.com/gorilla/mux"
+)
+
+const imageFormField = "image"
+
+var errImageRequired = errors.New("image file is required")
+
+type imageOrderRequest struct {
+	ImageIDs []int `json:"image_ids"`
+}
+
+// countingBody counts the bytes read from the request body, a count above the limit of the
+// http.MaxBytesReader around it means the body was too large
+type countingBody struct {
+	io.ReadCloser
+	n int64
+}
+
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.n += int64(n)
+	return n, err
+}
+
+func (h *Handler) handleProductImageUpload() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		reqVars := mux.Vars(r)
+		productId, err := strconv.Atoi(reqVars["id"])
+		if err != nil {
+			h.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		maxSize := h.service.ImageService.MaxSize()
+		limit := maxSize + 1<<20
+		body := &countingBody{ReadCloser: r.Body}
+		r.Body = http.MaxBytesReader(w, body, limit)
+		if err = r.ParseMultipartForm(maxSize); err != nil {
+			if body.n > limit || errors.Is(err, multipart.ErrMessageTooLarge) {
+				h.error(w, r, http.StatusRequestEntityTooLarge, err)
+				return
+			}
+			h.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		file, _, err := r.FormFile(imageFormField)
+		if err != nil {
+			h.error(w, r, http.StatusBadRequest, errImageRequired)
+			return
+		}
+		defer file.Close()
+
+		u := r.Context().Value(CtxKeyUser).(*model.User)
+
+		image, err := h.service.ImageService.UploadImage(productId, u.ID, file)
+		if err != nil {
+			h.error(w, r, http.StatusUnprocessableEntity, err)
+			return
+		}
+
+		h.respond(w, r, http.StatusCreated, image)
+	}
+}
+
+func (h *Handler) handleProductImageList() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		reqVars := mux.Vars(r)
+		productId, err := strconv.Atoi(reqVars["id"])
+		if err != nil {
+			h.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		images, err := h.service.ImageService.GetProductImages(productId)
+		if err != nil {
+			h.error(w, r, http.StatusUnprocessableEntity, err)
+			return
+		}
+
+		h.respond(w, r, http.StatusOK, images)
+	}
+}
+
+func (h *Handler) handleProductImageOrder() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		reqVars := mux.Vars(r)
+		productId, err := strconv.Atoi(reqVars["id"])
+		if err != nil {
+			h.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		req := &imageOrderRequest{}
+		if err = json.NewDecoder(r.Body).Decode(req); err != nil {
+			h.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		u := r.Context().Value(CtxKeyUser).(*model.User)
+
+		images, err := h.service.ImageService.ReorderImages(productId, u.ID, req.ImageIDs)
+		if err != nil {
+			h.error(w, r, http.StatusUnprocessableEntity, err)
+			return
+		}
+
+		h.respond(w, r, http.StatusOK, images)
+	}
+}
+
+func (h *Handler) handleProductImageDelete() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		reqVars := mux.Vars(r)
+		productId, err := strconv.Atoi(reqVars["id"])
+		if err != nil {
+			h.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		imageId, err := strconv.Atoi(reqVars["image_id"])
+		if err != nil {
+			h.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		u := r.Context().Value(CtxKeyUser).(*model.User)
+
+		if err = h.service.ImageService.DeleteImage(productId, imageId, u.ID); err != nil {
+			h.error(w, r, http.StatusUnprocessableEntity, err)
+			return
+		}
+
+		h.respond(w, r, http.StatusOK, nil)
+	}
+}
+
+func (h *Handler) handleMedia() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := mux.Vars(r)["key"]
+
+		f, err := h.service.ImageService.OpenMedia(key)
+		if err != nil {
+			if os.IsNotExist(err) {
+				h.error(w, r, http.StatusNotFound, err)
+				return
+			}
+			h.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+		defer f.Close()
+
+		if contentType := mime.TypeByExtension(path.Ext(key)); contentType != "" {
+			w.Header().Set("Content-Type", contentType)
+		}
+		w.Header().Set("Cache-Control", "public, max-age=86400")
+		w.WriteHeader(http.StatusOK)
+		io.Copy(w, f)
+	}
+}
@@ -0,0 +1,34 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/service"
+)
+
+func (h *Handler) handleProductLabels() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req := &service.LabelRequest{}
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			h.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		u := r.Context().Value(CtxKeyUser).(*model.User)
+
+		doc, err := h.service.LabelService.RenderLabels(u.ID, req)
+		if err != nil {
+			h.serviceError(w, r, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/pdf")
+		w.Header().Set("Content-Disposition", `attachment; filename="labels.pdf"`)
+		w.Header().Set("Content-Length", strconv.Itoa(len(doc)))
+		w.WriteHeader(http.StatusOK)
+		w.Write(doc)
+	}
+}
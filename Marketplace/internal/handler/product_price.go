@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+	"github.com/gorilla/mux"
+)
+
+func (h *Handler) handleProductPriceList() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		reqVars := mux.Vars(r)
+		productId, err := strconv.Atoi(reqVars["id"])
+		if err != nil {
+			h.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		u := r.Context().Value(CtxKeyUser).(*model.User)
+
+		prices, err := h.service.PriceService.GetPrices(productId, u.ID)
+		if err != nil {
+			h.serviceError(w, r, err)
+			return
+		}
+
+		h.respond(w, r, http.StatusOK, prices)
+	}
+}
+
+func (h *Handler) handleProductPriceHistory() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		reqVars := mux.Vars(r)
+		productId, err := strconv.Atoi(reqVars["id"])
+		if err != nil {
+			h.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		marketPlaceId := 0
+		if mp := r.URL.Query().Get("marketplace_id"); mp != "" {
+			marketPlaceId, err = strconv.Atoi(mp)
+			if err != nil {
+				h.error(w, r, http.StatusBadRequest, err)
+				return
+			}
+		}
+
+		u := r.Context().Value(CtxKeyUser).(*model.User)
+
+		prices, err := h.service.PriceService.GetPriceHistory(productId, u.ID, marketPlaceId)
+		if err != nil {
+			h.serviceError(w, r, err)
+			return
+		}
+
+		h.respond(w, r, http.StatusOK, prices)
+	}
+}
+
+func (h *Handler) handleProductPriceSet() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		prices := make([]*model.Price, 0)
+		if err := json.NewDecoder(r.Body).Decode(&prices); err != nil {
+			h.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		u := r.Context().Value(CtxKeyUser).(*model.User)
+
+		prices, err := h.service.PriceService.SetPrices(u.ID, prices)
+		if err != nil {
+			h.serviceError(w, r, err)
+			return
+		}
+
+		h.respond(w, r, http.StatusCreated, prices)
+	}
+}
@@ -0,0 +1,146 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+	"github.com/gorilla/mux"
+)
+
+// handleProfileUpdate changes the profile fields present in the request, others keep their values
+func (h *Handler) handleProfileUpdate() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(CtxKeyUser).(*model.User)
+
+		profile := u.UserProfile
+		if err := json.NewDecoder(r.Body).Decode(&profile); err != nil {
+			h.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := h.service.ProfileService.UpdateProfile(u, &profile); err != nil {
+			h.error(w, r, http.StatusUnprocessableEntity, err)
+			return
+		}
+
+		h.respond(w, r, http.StatusOK, u)
+	}
+}
+
+// handleEmailChange answers 202 when a confirmation link was sent to the new email
+// and 200 when the email has changed at once
+func (h *Handler) handleEmailChange() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req := &accountRequest{}
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			h.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		u := r.Context().Value(CtxKeyUser).(*model.User)
+
+		pending, err := h.service.AuthService.ChangeEmail(u, req.Email, req.Password, time.Now())
+		if err != nil {
+			h.error(w, r, http.StatusUnprocessableEntity, err)
+			return
+		}
+
+		if pending {
+			h.respond(w, r, http.StatusAccepted, nil)
+			return
+		}
+
+		h.respond(w, r, http.StatusOK, u)
+	}
+}
+
+func (h *Handler) handleEmailChangeConfirm() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req := &accountRequest{}
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			h.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		u, err := h.service.AuthService.ConfirmEmailChange(req.Token, time.Now())
+		if err != nil {
+			h.error(w, r, http.StatusUnprocessableEntity, err)
+			return
+		}
+
+		h.respond(w, r, http.StatusOK, u)
+	}
+}
+
+// handlePasswordChange sets the new password and ends all sessions of the user, the client
+// gets a new session in the response
+func (h *Handler) handlePasswordChange() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req := &accountRequest{}
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			h.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		u := r.Context().Value(CtxKeyUser).(*model.User)
+
+		if err := h.service.AuthService.ChangePassword(u, req.CurrentPassword, req.Password); err != nil {
+			h.error(w, r, http.StatusUnprocessableEntity, err)
+			return
+		}
+
+		if err := h.endUserSessions(u.ID); err != nil {
+			h.error(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		h.startSession(w, r, u)
+	}
+}
+
+// handleDeactivate turns off the account and signs the user out everywhere
+func (h *Handler) handleDeactivate() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req := &accountRequest{}
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			h.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		u := r.Context().Value(CtxKeyUser).(*model.User)
+
+		if err := h.service.AuthService.Deactivate(u, req.Password); err != nil {
+			h.error(w, r, http.StatusUnprocessableEntity, err)
+			return
+		}
+
+		if err := h.endUserSessions(u.ID); err != nil {
+			h.error(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		h.clearSessionCookie(w)
+		h.respond(w, r, http.StatusOK, nil)
+	}
+}
+
+func (h *Handler) handleUserActivate() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		reqVars := mux.Vars(r)
+		userId, err := strconv.Atoi(reqVars["id"])
+		if err != nil {
+			h.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		if err = h.service.AuthService.Activate(userId); err != nil {
+			h.error(w, r, http.StatusUnprocessableEntity, err)
+			return
+		}
+
+		h.respond(w, r, http.StatusOK, nil)
+	}
+}
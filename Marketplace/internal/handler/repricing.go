@@ -0,0 +1,169 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+	"github.com/gorilla/mux"
+)
+
+func (h *Handler) handleRepricingRuleList() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(CtxKeyUser).(*model.User)
+
+		rules, err := h.service.RepricingService.GetRules(u.ID)
+		if err != nil {
+			h.serviceError(w, r, err)
+			return
+		}
+
+		h.respond(w, r, http.StatusOK, rules)
+	}
+}
+
+func (h *Handler) handleRepricingRuleCreate() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		rule := &model.RepricingRule{}
+		if err := json.NewDecoder(r.Body).Decode(rule); err != nil {
+			h.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		u := r.Context().Value(CtxKeyUser).(*model.User)
+
+		if err := h.service.RepricingService.CreateRule(u.ID, rule); err != nil {
+			h.serviceError(w, r, err)
+			return
+		}
+
+		h.respond(w, r, http.StatusCreated, rule)
+	}
+}
+
+func (h *Handler) handleRepricingRuleDelete() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		reqVars := mux.Vars(r)
+		ruleId, err := strconv.Atoi(reqVars["id"])
+		if err != nil {
+			h.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		u := r.Context().Value(CtxKeyUser).(*model.User)
+
+		if err = h.service.RepricingService.DeleteRule(ruleId, u.ID); err != nil {
+			h.serviceError(w, r, err)
+			return
+		}
+
+		h.respond(w, r, http.StatusOK, nil)
+	}
+}
+
+// handleCompetitorPriceImport accepts a CSV file with product_id, marketplace_id and price columns
+func (h *Handler) handleCompetitorPriceImport() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(CtxKeyUser).(*model.User)
+
+		prices, err := h.service.RepricingService.ImportCompetitorPrices(u.ID, r.Body)
+		if err != nil {
+			h.serviceError(w, r, err)
+			return
+		}
+
+		h.respond(w, r, http.StatusCreated, prices)
+	}
+}
+
+func (h *Handler) handleRepricingRun() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(CtxKeyUser).(*model.User)
+
+		proposals, err := h.service.RepricingService.RunForUser(u.ID, time.Now())
+		if err != nil {
+			h.serviceError(w, r, err)
+			return
+		}
+
+		h.respond(w, r, http.StatusOK, proposals)
+	}
+}
+
+func (h *Handler) handleRepricingProposalList() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(CtxKeyUser).(*model.User)
+
+		proposals, err := h.service.RepricingService.GetProposals(u.ID, r.URL.Query().Get("status"))
+		if err != nil {
+			h.serviceError(w, r, err)
+			return
+		}
+
+		h.respond(w, r, http.StatusOK, proposals)
+	}
+}
+
+func (h *Handler) handleRepricingProposalApprove() http.HandlerFunc {
+	return h.handleRepricingProposalDecision(true)
+}
+
+func (h *Handler) handleRepricingProposalReject() http.HandlerFunc {
+	return h.handleRepricingProposalDecision(false)
+}
+
+func (h *Handler) handleRepricingProposalDecision(approve bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		reqVars := mux.Vars(r)
+		proposalId, err := strconv.Atoi(reqVars["id"])
+		if err != nil {
+			h.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		u := r.Context().Value(CtxKeyUser).(*model.User)
+
+		var proposal *model.PriceProposal
+		if approve {
+			proposal, err = h.service.RepricingService.ApproveProposal(proposalId, u.ID)
+		} else {
+			proposal, err = h.service.RepricingService.RejectProposal(proposalId, u.ID)
+		}
+		if err != nil {
+			h.serviceError(w, r, err)
+			return
+		}
+
+		h.respond(w, r, http.StatusOK, proposal)
+	}
+}
+
+func (h *Handler) handleRepricingProposalApply() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(CtxKeyUser).(*model.User)
+
+		proposals, err := h.service.RepricingService.ApplyProposals(u.ID)
+		if err != nil {
+			h.serviceError(w, r, err)
+			return
+		}
+
+		h.respond(w, r, http.StatusOK, proposals)
+	}
+}
+
+func (h *Handler) handleRepricingLog() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(CtxKeyUser).(*model.User)
+
+		entries, err := h.service.RepricingService.GetLog(u.ID)
+		if err != nil {
+			h.serviceError(w, r, err)
+			return
+		}
+
+		h.respond(w, r, http.StatusOK, entries)
+	}
+}
@@ -0,0 +1,242 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/VladimirBlinov/AuthService/pkg/authservice"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+)
+
+// sessionTouchInterval limits how often the last request time of a session is saved
+const sessionTouchInterval = time.Minute
+
+var (
+	errSessionExpired       = errors.New("session expired")
+	errSessionsNotSupported = errors.New("session list is not supported by the session backend")
+)
+
+// SessionRegistry is implemented by session managers that keep sessions in the API server.
+// With it AuthenticateUser enforces the session timeouts and users can list and end their sessions,
+// the external auth service enforces its own expiry.
+type SessionRegistry interface {
+	Find(string) (*model.Session, error)
+	FindByUserId(int) ([]*model.Session, error)
+	Touch(*model.Session) error
+	DeleteByUserId(int) (int, error)
+}
+
+// SessionCookie holds the attributes of the session id cookie, zero MaxAge makes it
+// a browser session cookie
+type SessionCookie struct {
+	Path     string
+	Domain   string
+	Secure   bool
+	HttpOnly bool
+	SameSite http.SameSite
+	MaxAge   time.Duration
+}
+
+// SessionTimeouts end a session that was not used for Idle or is older than Absolute, zero disables the limit
+type SessionTimeouts struct {
+	Idle     time.Duration
+	Absolute time.Duration
+}
+
+func defaultSessionCookie() SessionCookie {
+	return SessionCookie{
+		Path:     "/",
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+		MaxAge:   30 * 24 * time.Hour,
+	}
+}
+
+func (h *Handler) setSessionCookie(w http.ResponseWriter, sessionId string) {
+	c := h.sessionCookie
+	cookie := &http.Cookie{
+		Name:     SessionIDKey,
+		Value:    sessionId,
+		Path:     c.Path,
+		Domain:   c.Domain,
+		Secure:   c.Secure,
+		HttpOnly: c.HttpOnly,
+		SameSite: c.SameSite,
+	}
+	if c.MaxAge > 0 {
+		cookie.MaxAge = int(c.MaxAge.Seconds())
+		cookie.Expires = time.Now().Add(c.MaxAge)
+	}
+
+	http.SetCookie(w, cookie)
+}
+
+func (h *Handler) clearSessionCookie(w http.ResponseWriter) {
+	c := h.sessionCookie
+	http.SetCookie(w, &http.Cookie{
+		Name:     SessionIDKey,
+		Path:     c.Path,
+		Domain:   c.Domain,
+		Secure:   c.Secure,
+		HttpOnly: c.HttpOnly,
+		SameSite: c.SameSite,
+		MaxAge:   -1,
+		Expires:  time.Unix(0, 0),
+	})
+}
+
+// checkSession ends the session when it is idle or too old, otherwise it saves the request
+// as the last use of the session
+func (h *Handler) checkSession(registry SessionRegistry, r *http.Request, sessionId string) error {
+	s, err := registry.Find(sessionId)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if s.Idle(now, h.sessionTimeouts.Idle) || s.Outlived(now, h.sessionTimeouts.Absolute) {
+		if _, err = h.sessionManager.Delete(context.Background(), &authservice.SessionID{ID: sessionId}); err != nil {
+			h.logger.Errorf("delete expired session: %s", err.Error())
+		}
+		return errSessionExpired
+	}
+
+	userAgent, ip := r.UserAgent(), h.clientIP(r)
+	if now.Sub(s.LastSeenAt) < sessionTouchInterval && s.UserAgent == userAgent && s.IP == ip {
+		return nil
+	}
+
+	s.LastSeenAt = now.UTC()
+	s.UserAgent = truncate(userAgent, 255)
+	s.IP = truncate(ip, 45)
+
+	return registry.Touch(s)
+}
+
+func (h *Handler) handleSessionList() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		registry, ok := h.sessionManager.(SessionRegistry)
+		if !ok {
+			h.error(w, r, http.StatusNotImplemented, errSessionsNotSupported)
+			return
+		}
+
+		u := r.Context().Value(CtxKeyUser).(*model.User)
+
+		sessions, err := registry.FindByUserId(u.ID)
+		if err != nil {
+			h.error(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		if cookie, err := r.Cookie(SessionIDKey); err == nil {
+			if current, err := registry.Find(cookie.Value); err == nil {
+				for _, s := range sessions {
+					s.Current = s.ID == current.ID
+				}
+			}
+		}
+
+		h.respond(w, r, http.StatusOK, sessions)
+	}
+}
+
+// handleSignOutAll ends all sessions of the user on every device, the current one too,
+// and revokes refresh tokens of the user
+func (h *Handler) handleSignOutAll() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		registry, ok := h.sessionManager.(SessionRegistry)
+		if !ok {
+			h.error(w, r, http.StatusNotImplemented, errSessionsNotSupported)
+			return
+		}
+
+		u := r.Context().Value(CtxKeyUser).(*model.User)
+
+		if err := h.service.TokenService.RevokeAll(u.ID); err != nil {
+			h.error(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		count, err := registry.DeleteByUserId(u.ID)
+		if err != nil {
+			h.error(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		h.clearSessionCookie(w)
+		h.respond(w, r, http.StatusOK, map[string]int{"signed_out": count})
+	}
+}
+
+// endUserSessions revokes the refresh tokens of the user and deletes the sessions the session
+// manager can list, sessions of the gRPC auth service end on their own timeout
+func (h *Handler) endUserSessions(userId int) error {
+	if err := h.service.TokenService.RevokeAll(userId); err != nil {
+		return err
+	}
+
+	if registry, ok := h.sessionManager.(SessionRegistry); ok {
+		if _, err := registry.DeleteByUserId(userId); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// clientIP is the address the request came from, without the port. For requests of a trusted
+// proxy it is the rightmost address of X-Forwarded-For that is not a trusted proxy itself,
+// the addresses left of it are set by the client and can't be relied on.
+func (h *Handler) clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	if !h.trustedProxy(host) {
+		return host
+	}
+
+	forwarded := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		addr := strings.TrimSpace(forwarded[i])
+		if net.ParseIP(addr) == nil {
+			break
+		}
+
+		host = addr
+		if !h.trustedProxy(addr) {
+			break
+		}
+	}
+
+	return host
+}
+
+func (h *Handler) trustedProxy(addr string) bool {
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return false
+	}
+
+	for _, proxy := range h.trustedProxies {
+		if proxy.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+
+	return s[:n]
+}
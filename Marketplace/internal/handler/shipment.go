@@ -0,0 +1,180 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/service"
+	"github.com/gorilla/mux"
+)
+
+type shipmentStatusRequest struct {
+	Status string `json:"status"`
+}
+
+func (h *Handler) handleShipmentList() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(CtxKeyUser).(*model.User)
+
+		shipments, err := h.service.ShipmentService.GetShipments(u.ID, r.URL.Query().Get("status"))
+		if err != nil {
+			h.serviceError(w, r, err)
+			return
+		}
+
+		h.respond(w, r, http.StatusOK, shipments)
+	}
+}
+
+func (h *Handler) handleShipmentCreate() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		shipment := &model.Shipment{}
+		if err := json.NewDecoder(r.Body).Decode(shipment); err != nil {
+			h.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		u := r.Context().Value(CtxKeyUser).(*model.User)
+
+		if err := h.service.ShipmentService.CreateShipment(u.ID, shipment); err != nil {
+			h.serviceError(w, r, err)
+			return
+		}
+
+		h.respond(w, r, http.StatusCreated, shipment)
+	}
+}
+
+func (h *Handler) handleShipmentGet() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		reqVars := mux.Vars(r)
+		shipmentId, err := strconv.Atoi(reqVars["id"])
+		if err != nil {
+			h.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		u := r.Context().Value(CtxKeyUser).(*model.User)
+
+		shipment, err := h.service.ShipmentService.GetShipment(shipmentId, u.ID)
+		if err != nil {
+			h.serviceError(w, r, err)
+			return
+		}
+
+		h.respond(w, r, http.StatusOK, shipment)
+	}
+}
+
+func (h *Handler) handleShipmentUpdate() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		reqVars := mux.Vars(r)
+		shipmentId, err := strconv.Atoi(reqVars["id"])
+		if err != nil {
+			h.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		shipment := &model.Shipment{}
+		if err = json.NewDecoder(r.Body).Decode(shipment); err != nil {
+			h.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		u := r.Context().Value(CtxKeyUser).(*model.User)
+
+		if err = h.service.ShipmentService.UpdateShipment(shipmentId, u.ID, shipment); err != nil {
+			h.serviceError(w, r, err)
+			return
+		}
+
+		h.respond(w, r, http.StatusOK, shipment)
+	}
+}
+
+func (h *Handler) handleShipmentPackingCheck() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		reqVars := mux.Vars(r)
+		shipmentId, err := strconv.Atoi(reqVars["id"])
+		if err != nil {
+			h.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		u := r.Context().Value(CtxKeyUser).(*model.User)
+
+		issues, err := h.service.ShipmentService.CheckPacking(shipmentId, u.ID)
+		if err != nil {
+			h.serviceError(w, r, err)
+			return
+		}
+
+		h.respond(w, r, http.StatusOK, issues)
+	}
+}
+
+func (h *Handler) handleShipmentStatus() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		reqVars := mux.Vars(r)
+		shipmentId, err := strconv.Atoi(reqVars["id"])
+		if err != nil {
+			h.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		req := &shipmentStatusRequest{}
+		if err = json.NewDecoder(r.Body).Decode(req); err != nil {
+			h.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		u := r.Context().Value(CtxKeyUser).(*model.User)
+
+		shipment, err := h.service.ShipmentService.SetStatus(shipmentId, u.ID, req.Status)
+		if err != nil {
+			h.serviceError(w, r, err)
+			return
+		}
+
+		h.respond(w, r, http.StatusOK, shipment)
+	}
+}
+
+// handleShipmentPackingList returns the packing list as a PDF or, with format=csv, as a CSV file
+func (h *Handler) handleShipmentPackingList() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		reqVars := mux.Vars(r)
+		shipmentId, err := strconv.Atoi(reqVars["id"])
+		if err != nil {
+			h.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		format := r.URL.Query().Get("format")
+		if format == "" {
+			format = service.PackingListPDF
+		}
+
+		u := r.Context().Value(CtxKeyUser).(*model.User)
+
+		doc, err := h.service.ShipmentService.PackingList(shipmentId, u.ID, format)
+		if err != nil {
+			h.serviceError(w, r, err)
+			return
+		}
+
+		contentType := "application/pdf"
+		if format == service.PackingListCSV {
+			contentType = "text/csv; charset=utf-8"
+		}
+
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="shipment-%d.%s"`, shipmentId, format))
+		w.Header().Set("Content-Length", strconv.Itoa(len(doc)))
+		w.WriteHeader(http.StatusOK)
+		w.Write(doc)
+	}
+}
@@ -0,0 +1,143 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/service"
+	"github.com/gorilla/mux"
+)
+
+func (h *Handler) handleSalesImport() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		sales := make([]*model.Sale, 0)
+		if err := json.NewDecoder(r.Body).Decode(&sales); err != nil {
+			h.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		u := r.Context().Value(CtxKeyUser).(*model.User)
+
+		if err := h.service.SupplyService.ImportSales(u.ID, sales); err != nil {
+			h.serviceError(w, r, err)
+			return
+		}
+
+		h.respond(w, r, http.StatusCreated, sales)
+	}
+}
+
+func (h *Handler) handleSupplierList() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(CtxKeyUser).(*model.User)
+
+		suppliers, err := h.service.SupplyService.GetSuppliers(u.ID)
+		if err != nil {
+			h.serviceError(w, r, err)
+			return
+		}
+
+		h.respond(w, r, http.StatusOK, suppliers)
+	}
+}
+
+func (h *Handler) handleSupplierCreate() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		supplier := &model.Supplier{}
+		if err := json.NewDecoder(r.Body).Decode(supplier); err != nil {
+			h.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		u := r.Context().Value(CtxKeyUser).(*model.User)
+
+		if err := h.service.SupplyService.CreateSupplier(u.ID, supplier); err != nil {
+			h.serviceError(w, r, err)
+			return
+		}
+
+		h.respond(w, r, http.StatusCreated, supplier)
+	}
+}
+
+// handleReplenishmentReport accepts supplier_id, history_days, lead_time_days, safety_days
+// and cover_days query parameters, missing ones keep the defaults
+func (h *Handler) handleReplenishmentReport() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		params := model.DefaultReplenishmentParams()
+		supplierId := 0
+		query := r.URL.Query()
+		for name, value := range map[string]*int{
+			"supplier_id":    &supplierId,
+			"history_days":   &params.HistoryDays,
+			"lead_time_days": &params.LeadTimeDays,
+			"safety_days":    &params.SafetyDays,
+			"cover_days":     &params.CoverDays,
+		} {
+			if query.Get(name) == "" {
+				continue
+			}
+
+			v, err := strconv.Atoi(query.Get(name))
+			if err != nil {
+				h.error(w, r, http.StatusBadRequest, err)
+				return
+			}
+			*value = v
+		}
+
+		u := r.Context().Value(CtxKeyUser).(*model.User)
+
+		lines, err := h.service.SupplyService.ReplenishmentReport(u.ID, supplierId, params, time.Now())
+		if err != nil {
+			h.serviceError(w, r, err)
+			return
+		}
+
+		h.respond(w, r, http.StatusOK, lines)
+	}
+}
+
+func (h *Handler) handleSupplyOrderDraft() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req := &service.DraftOrderRequest{}
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			h.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		u := r.Context().Value(CtxKeyUser).(*model.User)
+
+		order, err := h.service.SupplyService.CreateDraftOrder(u.ID, req, time.Now())
+		if err != nil {
+			h.serviceError(w, r, err)
+			return
+		}
+
+		h.respond(w, r, http.StatusCreated, order)
+	}
+}
+
+func (h *Handler) handleSupplyOrderGet() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		reqVars := mux.Vars(r)
+		orderId, err := strconv.Atoi(reqVars["id"])
+		if err != nil {
+			h.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		u := r.Context().Value(CtxKeyUser).(*model.User)
+
+		order, err := h.service.SupplyService.GetOrder(orderId, u.ID)
+		if err != nil {
+			h.serviceError(w, r, err)
+			return
+		}
+
+		h.respond(w, r, http.StatusOK, order)
+	}
+}
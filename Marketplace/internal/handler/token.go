@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+)
+
+var (
+	errTokensNotConfigured = errors.New("token authentication is not configured")
+	errBearerToken         = errors.New("authorization header must be a bearer token")
+)
+
+type tokenRefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+func (h *Handler) handleTokenRefresh() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !h.service.TokenService.Enabled() {
+			h.error(w, r, http.StatusNotImplemented, errTokensNotConfigured)
+			return
+		}
+
+		req := &tokenRefreshRequest{}
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			h.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		pair, err := h.service.TokenService.Refresh(req.RefreshToken)
+		if err != nil {
+			h.error(w, r, http.StatusUnauthorized, err)
+			return
+		}
+
+		w.Header().Set("Cache-Control", "no-store")
+		h.respond(w, r, http.StatusOK, pair)
+	}
+}
+
+func (h *Handler) handleJWKS() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !h.service.TokenService.Enabled() {
+			h.error(w, r, http.StatusNotFound, errTokensNotConfigured)
+			return
+		}
+
+		keys, err := h.service.TokenService.PublicKeys()
+		if err != nil {
+			h.error(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		h.respond(w, r, http.StatusOK, keys)
+	}
+}
+
+// authenticateToken authenticates the request by its bearer access token
+func (h *Handler) authenticateToken(r *http.Request, authorization string) (*http.Request, error) {
+	scheme, token, ok := strings.Cut(authorization, " ")
+	if !ok || !strings.EqualFold(scheme, model.TokenTypeBearer) || token == "" {
+		return nil, errBearerToken
+	}
+
+	u, tokenSession, err := h.service.TokenService.Authenticate(strings.TrimSpace(token))
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := context.WithValue(r.Context(), CtxKeyUser, u)
+	ctx = context.WithValue(ctx, ctxKeyTokenSession, tokenSession)
+
+	return r.WithContext(ctx), nil
+}
@@ -0,0 +1,177 @@
+package handler
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/service"
+	"github.com/gorilla/mux"
+)
+
+type twoFactorRequest struct {
+	ChallengeToken string `json:"challenge_token"`
+	Code           string `json:"code"`
+	Password       string `json:"password"`
+}
+
+// handleSignInTwoFactor is the second step of the sign-in, the code is a TOTP code or a recovery code.
+// Wrong codes count as failed sign-ins of the account.
+func (h *Handler) handleSignInTwoFactor() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req := &twoFactorRequest{}
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			h.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		now, ip := time.Now(), h.clientIP(r)
+		u, err := h.service.TwoFactorService.ChallengeUser(req.ChallengeToken, now)
+		if err != nil {
+			h.error(w, r, http.StatusUnauthorized, err)
+			return
+		}
+
+		wait, err := h.service.LockoutService.Check(u.Email, ip, now)
+		if err != nil {
+			h.error(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		if wait > 0 {
+			if err = h.service.LockoutService.Refuse(u.Email, ip, r.UserAgent(), now); err != nil {
+				h.error(w, r, http.StatusInternalServerError, err)
+				return
+			}
+
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			h.error(w, r, http.StatusTooManyRequests, errTooManySignInAttempts)
+			return
+		}
+
+		if _, err = h.service.TwoFactorService.CompleteChallenge(req.ChallengeToken, req.Code, now); err == service.ErrInvalidTwoFactorCode {
+			if err = h.service.LockoutService.FailTwoFactor(u, ip, r.UserAgent(), now); err != nil {
+				h.error(w, r, http.StatusInternalServerError, err)
+				return
+			}
+
+			h.error(w, r, http.StatusUnauthorized, service.ErrInvalidTwoFactorCode)
+			return
+		} else if err != nil {
+			h.error(w, r, http.StatusUnauthorized, err)
+			return
+		}
+
+		if err = h.service.LockoutService.Reset(u.Email); err != nil {
+			h.error(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		h.startSession(w, r, u)
+	}
+}
+
+func (h *Handler) handleTwoFactorStatus() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(CtxKeyUser).(*model.User)
+
+		status, err := h.service.TwoFactorService.Status(u)
+		if err != nil {
+			h.error(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		h.respond(w, r, http.StatusOK, status)
+	}
+}
+
+// handleTwoFactorEnroll returns the secret of a new authenticator, 2FA is enabled once a code from it is confirmed
+func (h *Handler) handleTwoFactorEnroll() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(CtxKeyUser).(*model.User)
+
+		enrollment, err := h.service.TwoFactorService.Enroll(u, time.Now())
+		if err != nil {
+			h.error(w, r, http.StatusUnprocessableEntity, err)
+			return
+		}
+
+		w.Header().Set("Cache-Control", "no-store")
+		h.respond(w, r, http.StatusCreated, enrollment)
+	}
+}
+
+func (h *Handler) handleTwoFactorEnable() http.HandlerFunc {
+	return h.handleRecoveryCodes(func(u *model.User, req *twoFactorRequest) ([]string, error) {
+		return h.service.TwoFactorService.Enable(u.ID, req.Code, time.Now())
+	})
+}
+
+func (h *Handler) handleRecoveryCodesRegenerate() http.HandlerFunc {
+	return h.handleRecoveryCodes(func(u *model.User, req *twoFactorRequest) ([]string, error) {
+		return h.service.TwoFactorService.RegenerateRecoveryCodes(u.ID, req.Code, time.Now())
+	})
+}
+
+// handleRecoveryCodes responds with new recovery codes, they are shown this time only
+func (h *Handler) handleRecoveryCodes(generate func(*model.User, *twoFactorRequest) ([]string, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req := &twoFactorRequest{}
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			h.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		u := r.Context().Value(CtxKeyUser).(*model.User)
+
+		codes, err := generate(u, req)
+		if err != nil {
+			h.error(w, r, http.StatusUnprocessableEntity, err)
+			return
+		}
+
+		w.Header().Set("Cache-Control", "no-store")
+		h.respond(w, r, http.StatusOK, map[string][]string{"recovery_codes": codes})
+	}
+}
+
+func (h *Handler) handleTwoFactorDisable() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req := &twoFactorRequest{}
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			h.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		u := r.Context().Value(CtxKeyUser).(*model.User)
+
+		if err := h.service.TwoFactorService.Disable(u, req.Password, req.Code, time.Now()); err != nil {
+			h.error(w, r, http.StatusUnprocessableEntity, err)
+			return
+		}
+
+		h.respond(w, r, http.StatusOK, nil)
+	}
+}
+
+// handleTwoFactorReset removes the authenticator of a user who lost it and the recovery codes
+func (h *Handler) handleTwoFactorReset() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		reqVars := mux.Vars(r)
+		userId, err := strconv.Atoi(reqVars["id"])
+		if err != nil {
+			h.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		if err = h.service.TwoFactorService.Reset(userId); err != nil {
+			h.error(w, r, http.StatusUnprocessableEntity, err)
+			return
+		}
+
+		h.respond(w, r, http.StatusOK, nil)
+	}
+}
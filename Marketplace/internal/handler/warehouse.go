@@ -0,0 +1,205 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+	"github.com/gorilla/mux"
+)
+
+func (h *Handler) handleWarehouseList() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(CtxKeyUser).(*model.User)
+
+		warehouses, err := h.service.WarehouseService.GetWarehouses(u.ID)
+		if err != nil {
+			h.serviceError(w, r, err)
+			return
+		}
+
+		h.respond(w, r, http.StatusOK, warehouses)
+	}
+}
+
+func (h *Handler) handleWarehouseCreate() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		warehouse := &model.Warehouse{}
+		if err := json.NewDecoder(r.Body).Decode(warehouse); err != nil {
+			h.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		u := r.Context().Value(CtxKeyUser).(*model.User)
+
+		if err := h.service.WarehouseService.CreateWarehouse(u.ID, warehouse); err != nil {
+			h.serviceError(w, r, err)
+			return
+		}
+
+		h.respond(w, r, http.StatusCreated, warehouse)
+	}
+}
+
+func (h *Handler) handleWarehouseDelete() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		reqVars := mux.Vars(r)
+		warehouseId, err := strconv.Atoi(reqVars["id"])
+		if err != nil {
+			h.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		u := r.Context().Value(CtxKeyUser).(*model.User)
+
+		if err = h.service.WarehouseService.DeleteWarehouse(warehouseId, u.ID); err != nil {
+			h.serviceError(w, r, err)
+			return
+		}
+
+		h.respond(w, r, http.StatusOK, nil)
+	}
+}
+
+func (h *Handler) handleStockList() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(CtxKeyUser).(*model.User)
+
+		levels, err := h.service.WarehouseService.GetStock(u.ID)
+		if err != nil {
+			h.serviceError(w, r, err)
+			return
+		}
+
+		h.respond(w, r, http.StatusOK, levels)
+	}
+}
+
+func (h *Handler) handleStockSet() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		levels := make([]*model.StockLevel, 0)
+		if err := json.NewDecoder(r.Body).Decode(&levels); err != nil {
+			h.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		u := r.Context().Value(CtxKeyUser).(*model.User)
+
+		if err := h.service.WarehouseService.SetStock(u.ID, levels); err != nil {
+			h.serviceError(w, r, err)
+			return
+		}
+
+		h.respond(w, r, http.StatusOK, levels)
+	}
+}
+
+func (h *Handler) handleStockReport() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(CtxKeyUser).(*model.User)
+
+		report, err := h.service.WarehouseService.StockReport(u.ID)
+		if err != nil {
+			h.serviceError(w, r, err)
+			return
+		}
+
+		h.respond(w, r, http.StatusOK, report)
+	}
+}
+
+func (h *Handler) handleTransferList() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(CtxKeyUser).(*model.User)
+
+		transfers, err := h.service.WarehouseService.GetTransfers(u.ID, r.URL.Query().Get("status"))
+		if err != nil {
+			h.serviceError(w, r, err)
+			return
+		}
+
+		h.respond(w, r, http.StatusOK, transfers)
+	}
+}
+
+func (h *Handler) handleTransferCreate() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		transfer := &model.Transfer{}
+		if err := json.NewDecoder(r.Body).Decode(transfer); err != nil {
+			h.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		u := r.Context().Value(CtxKeyUser).(*model.User)
+
+		if err := h.service.WarehouseService.CreateTransfer(u.ID, transfer); err != nil {
+			h.serviceError(w, r, err)
+			return
+		}
+
+		h.respond(w, r, http.StatusCreated, transfer)
+	}
+}
+
+func (h *Handler) handleTransferGet() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		reqVars := mux.Vars(r)
+		transferId, err := strconv.Atoi(reqVars["id"])
+		if err != nil {
+			h.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		u := r.Context().Value(CtxKeyUser).(*model.User)
+
+		transfer, err := h.service.WarehouseService.GetTransfer(transferId, u.ID)
+		if err != nil {
+			h.serviceError(w, r, err)
+			return
+		}
+
+		h.respond(w, r, http.StatusOK, transfer)
+	}
+}
+
+func (h *Handler) handleTransferShip() http.HandlerFunc {
+	return h.handleTransferChange(model.TransferInTransit)
+}
+
+func (h *Handler) handleTransferReceive() http.HandlerFunc {
+	return h.handleTransferChange(model.TransferReceived)
+}
+
+func (h *Handler) handleTransferCancel() http.HandlerFunc {
+	return h.handleTransferChange(model.TransferCancelled)
+}
+
+func (h *Handler) handleTransferChange(status string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		reqVars := mux.Vars(r)
+		transferId, err := strconv.Atoi(reqVars["id"])
+		if err != nil {
+			h.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		u := r.Context().Value(CtxKeyUser).(*model.User)
+
+		var transfer *model.Transfer
+		switch status {
+		case model.TransferInTransit:
+			transfer, err = h.service.WarehouseService.ShipTransfer(transferId, u.ID)
+		case model.TransferReceived:
+			transfer, err = h.service.WarehouseService.ReceiveTransfer(transferId, u.ID)
+		default:
+			transfer, err = h.service.WarehouseService.CancelTransfer(transferId, u.ID)
+		}
+		if err != nil {
+			h.serviceError(w, r, err)
+			return
+		}
+
+		h.respond(w, r, http.StatusOK, transfer)
+	}
+}
@@ -0,0 +1,81 @@
+package model
+
+import (
+	"errors"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+)
+
+// API key scopes, a write scope doesn't include the read one
+const (
+	ScopeProductsRead  = "products:read"
+	ScopeProductsWrite = "products:write"
+	ScopeSupplyRead    = "supply:read"
+	ScopeSupplyWrite   = "supply:write"
+)
+
+// APIKeyPrefix starts every API key, so keys are told from access tokens and found by secret scanners
+const APIKeyPrefix = "mpk_"
+
+var APIKeyScopes = []string{ScopeProductsRead, ScopeProductsWrite, ScopeSupplyRead, ScopeSupplyWrite}
+
+// APIKey is a personal key for unattended access to the API. Only the hash of the key is kept,
+// Key is filled once when the key is created. Prefix is the start of the key to recognize it by.
+type APIKey struct {
+	APIKeyID   int        `json:"api_key_id"`
+	UserID     int        `json:"-"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	KeyHash    string     `json:"-"`
+	Key        string     `json:"key,omitempty"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+func (k *APIKey) Validate() error {
+	return validation.ValidateStruct(
+		k,
+		validation.Field(&k.UserID, validation.Required),
+		validation.Field(&k.Name, validation.Required, validation.Length(1, 100)),
+		validation.Field(&k.Scopes, validation.Required, validation.By(checkScopes)),
+	)
+}
+
+// HasScope reports whether the key grants the scope
+func (k *APIKey) HasScope(scope string) bool {
+	for _, s := range k.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Expired reports whether the key can't be used at the time anymore
+func (k *APIKey) Expired(at time.Time) bool {
+	return k.ExpiresAt != nil && !at.Before(*k.ExpiresAt)
+}
+
+func checkScopes(value interface{}) error {
+	scopes, _ := value.([]string)
+	seen := make(map[string]bool)
+	for _, s := range scopes {
+		known := false
+		for _, scope := range APIKeyScopes {
+			known = known || s == scope
+		}
+		if !known {
+			return errors.New("unknown scope " + s)
+		}
+		if seen[s] {
+			return errors.New("scope " + s + " is listed more than once")
+		}
+		seen[s] = true
+	}
+
+	return nil
+}
@@ -0,0 +1,83 @@
+package model_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIKey_Validate(t *testing.T) {
+	testCases := []struct {
+		name    string
+		k       func() *model.APIKey
+		isValid bool
+	}{
+		{
+			name: "valid",
+			k: func() *model.APIKey {
+				return model.TestAPIKey(t)
+			},
+			isValid: true,
+		},
+		{
+			name: "no name",
+			k: func() *model.APIKey {
+				k := model.TestAPIKey(t)
+				k.Name = ""
+				return k
+			},
+			isValid: false,
+		},
+		{
+			name: "no scopes",
+			k: func() *model.APIKey {
+				k := model.TestAPIKey(t)
+				k.Scopes = nil
+				return k
+			},
+			isValid: false,
+		},
+		{
+			name: "unknown scope",
+			k: func() *model.APIKey {
+				k := model.TestAPIKey(t)
+				k.Scopes = []string{"users:write"}
+				return k
+			},
+			isValid: false,
+		},
+		{
+			name: "repeated scope",
+			k: func() *model.APIKey {
+				k := model.TestAPIKey(t)
+				k.Scopes = []string{model.ScopeSupplyWrite, model.ScopeSupplyWrite}
+				return k
+			},
+			isValid: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.isValid {
+				assert.NoError(t, tc.k().Validate())
+			} else {
+				assert.Error(t, tc.k().Validate())
+			}
+		})
+	}
+}
+
+func TestAPIKey_Expired(t *testing.T) {
+	k := model.TestAPIKey(t)
+	at := time.Date(2022, 12, 6, 10, 0, 0, 0, time.UTC)
+	assert.False(t, k.Expired(at))
+
+	k.ExpiresAt = &at
+	assert.True(t, k.Expired(at))
+	assert.False(t, k.Expired(at.Add(-time.Second)))
+	assert.True(t, k.HasScope(model.ScopeProductsRead))
+	assert.False(t, k.HasScope(model.ScopeProductsWrite))
+}
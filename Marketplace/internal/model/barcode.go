@@ -0,0 +1,103 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/go-ozzo/ozzo-validation/is"
+)
+
+const (
+	BarcodeTypeEAN8  = "EAN-8"
+	BarcodeTypeUPC   = "UPC-A"
+	BarcodeTypeEAN13 = "EAN-13"
+)
+
+var (
+	errBarcodeLength   = errors.New("must be 8 (EAN-8), 12 (UPC-A) or 13 (EAN-13) digits long")
+	errBarcodeChecksum = errors.New("invalid check digit")
+)
+
+type Barcode struct {
+	BarcodeID int       `json:"barcode_id"`
+	ProductID int       `json:"product_id"`
+	UserID    int       `json:"-"`
+	Code      string    `json:"code"`
+	Type      string    `json:"type"`
+	Internal  bool      `json:"internal"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// BeforeCreate normalizes the code and detects the barcode type by its length
+func (b *Barcode) BeforeCreate() {
+	b.Code = strings.TrimSpace(b.Code)
+	b.Type, _ = BarcodeType(b.Code)
+}
+
+func (b *Barcode) Validate() error {
+	return validation.ValidateStruct(
+		b,
+		validation.Field(&b.ProductID, validation.Required),
+		validation.Field(&b.UserID, validation.Required),
+		validation.Field(&b.Code, validation.Required, is.Digit, validation.By(checkBarcode)),
+	)
+}
+
+// BarcodeType returns the GS1 symbology for the code length
+func BarcodeType(code string) (string, error) {
+	switch len(code) {
+	case 8:
+		return BarcodeTypeEAN8, nil
+	case 12:
+		return BarcodeTypeUPC, nil
+	case 13:
+		return BarcodeTypeEAN13, nil
+	default:
+		return "", errBarcodeLength
+	}
+}
+
+// BarcodeCheckDigit calculates the GS1 mod 10 check digit for the code without it
+func BarcodeCheckDigit(payload string) int {
+	sum := 0
+	for i := 0; i < len(payload); i++ {
+		digit := int(payload[len(payload)-1-i] - '0')
+		if i%2 == 0 {
+			digit *= 3
+		}
+		sum += digit
+	}
+
+	return (10 - sum%10) % 10
+}
+
+// GenerateBarcode builds an EAN-13 code from the prefix and the serial number
+func GenerateBarcode(prefix string, serial int) (string, error) {
+	if err := validation.Validate(prefix, validation.Required, is.Digit, validation.Length(2, 11)); err != nil {
+		return "", fmt.Errorf("barcode prefix: %w", err)
+	}
+
+	width := 12 - len(prefix)
+	payload := fmt.Sprintf("%s%0*d", prefix, width, serial)
+	if serial < 1 || len(payload) != 12 {
+		return "", errors.New("barcode serial is out of range for the prefix")
+	}
+
+	return fmt.Sprintf("%s%d", payload, BarcodeCheckDigit(payload)), nil
+}
+
+func checkBarcode(value interface{}) error {
+	code, _ := value.(string)
+	if _, err := BarcodeType(code); err != nil {
+		return err
+	}
+
+	if BarcodeCheckDigit(code[:len(code)-1]) != int(code[len(code)-1]-'0') {
+		return errBarcodeChecksum
+	}
+
+	return nil
+}
@@ -0,0 +1,86 @@
+package model_test
+
+import (
+	"testing"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_BarcodeValidate(t *testing.T) {
+	testCases := []struct {
+		name         string
+		code         string
+		isValid      bool
+		expectedType string
+	}{
+		{
+			name:         "ean-13",
+			code:         "4006381333931",
+			isValid:      true,
+			expectedType: model.BarcodeTypeEAN13,
+		},
+		{
+			name:         "ean-8",
+			code:         "96385074",
+			isValid:      true,
+			expectedType: model.BarcodeTypeEAN8,
+		},
+		{
+			name:         "upc-a",
+			code:         " 036000291452 ",
+			isValid:      true,
+			expectedType: model.BarcodeTypeUPC,
+		},
+		{
+			name:    "wrong check digit",
+			code:    "4006381333932",
+			isValid: false,
+		},
+		{
+			name:    "wrong length",
+			code:    "400638133393",
+			isValid: false,
+		},
+		{
+			name:    "not digits",
+			code:    "40063813339a1",
+			isValid: false,
+		},
+		{
+			name:    "empty",
+			code:    "",
+			isValid: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			b := model.TestBarcode(t)
+			b.Code = tc.code
+			b.BeforeCreate()
+			if tc.isValid {
+				assert.NoError(t, b.Validate())
+				assert.Equal(t, tc.expectedType, b.Type)
+			} else {
+				assert.Error(t, b.Validate())
+			}
+		})
+	}
+}
+
+func Test_GenerateBarcode(t *testing.T) {
+	code, err := model.GenerateBarcode("200", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "2000000000015", code)
+
+	b := model.TestBarcode(t)
+	b.Code = code
+	assert.NoError(t, b.Validate())
+
+	_, err = model.GenerateBarcode("20a", 1)
+	assert.Error(t, err)
+
+	_, err = model.GenerateBarcode("2000000000", 1000)
+	assert.Error(t, err)
+}
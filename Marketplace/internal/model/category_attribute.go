@@ -0,0 +1,153 @@
+package model
+
+import (
+	"errors"
+	"regexp"
+	"sort"
+	"strconv"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+)
+
+const (
+	AttributeTypeString = "string"
+	AttributeTypeNumber = "number"
+	AttributeTypeBool   = "bool"
+	AttributeTypeEnum   = "enum"
+)
+
+var attributeCode = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// CategoryAttribute describes a characteristic products of the category must or may have
+type CategoryAttribute struct {
+	AttributeID   int      `json:"attribute_id"`
+	CategoryID    int      `json:"category_id"`
+	Code          string   `json:"code"`
+	Name          string   `json:"name"`
+	Type          string   `json:"type"`
+	Unit          string   `json:"unit,omitempty"`
+	Required      bool     `json:"required"`
+	AllowedValues []string `json:"allowed_values,omitempty"`
+	Active        bool     `json:"-"`
+}
+
+func (a *CategoryAttribute) Validate() error {
+	return validation.ValidateStruct(
+		a,
+		validation.Field(&a.CategoryID, validation.Required),
+		validation.Field(&a.Code, validation.Required, validation.Length(1, 100), validation.Match(attributeCode)),
+		validation.Field(&a.Name, validation.Required, validation.Length(1, 200)),
+		validation.Field(&a.Type, validation.Required, validation.In(AttributeTypeString, AttributeTypeNumber, AttributeTypeBool, AttributeTypeEnum)),
+		validation.Field(&a.AllowedValues, validation.By(requiredIf(a.Type == AttributeTypeEnum))),
+	)
+}
+
+// ValidateValue checks that the value matches the attribute type and allowed values
+func (a *CategoryAttribute) ValidateValue(value string) error {
+	if value == "" {
+		if a.Required {
+			return errors.New("cannot be blank")
+		}
+		return nil
+	}
+
+	switch a.Type {
+	case AttributeTypeNumber:
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return errors.New("must be a number")
+		}
+	case AttributeTypeBool:
+		if _, err := strconv.ParseBool(value); err != nil {
+			return errors.New("must be true or false")
+		}
+	}
+
+	if len(a.AllowedValues) == 0 {
+		return nil
+	}
+
+	for _, allowed := range a.AllowedValues {
+		if value == allowed {
+			return nil
+		}
+	}
+
+	return errors.New("must be one of the allowed values")
+}
+
+// CategorySchema collects attributes of the category and all its ancestors.
+// Definitions of a nested category override inherited ones with the same code.
+func CategorySchema(categoryID int, categories []*Category, attributes []*CategoryAttribute) []*CategoryAttribute {
+	depth := make(map[int]int)
+	for level, id := range CategoryPath(categoryID, categories) {
+		depth[id] = level
+	}
+
+	byCode := make(map[string]*CategoryAttribute)
+	for _, a := range attributes {
+		level, ok := depth[a.CategoryID]
+		if !ok {
+			continue
+		}
+
+		if current, ok := byCode[a.Code]; ok && depth[current.CategoryID] <= level {
+			continue
+		}
+		byCode[a.Code] = a
+	}
+
+	schema := make([]*CategoryAttribute, 0, len(byCode))
+	for _, a := range byCode {
+		schema = append(schema, a)
+	}
+
+	sort.Slice(schema, func(i, j int) bool {
+		if schema[i].Required != schema[j].Required {
+			return schema[i].Required
+		}
+		return schema[i].Code < schema[j].Code
+	})
+
+	return schema
+}
+
+// CategoryPath returns ids of the category and its ancestors starting from the category itself
+func CategoryPath(categoryID int, categories []*Category) []int {
+	parents := make(map[int]int, len(categories))
+	for _, c := range categories {
+		parents[c.CategoryID] = c.ParentCategoryID
+	}
+
+	path := make([]int, 0)
+	seen := make(map[int]bool)
+	for id := categoryID; id != 0 && !seen[id]; id = parents[id] {
+		seen[id] = true
+		path = append(path, id)
+	}
+
+	return path
+}
+
+// ValidateAttributes checks product attribute values against the category schema
+func ValidateAttributes(schema []*CategoryAttribute, values map[string]string) error {
+	errs := validation.Errors{}
+	known := make(map[string]bool, len(schema))
+	for _, a := range schema {
+		known[a.Code] = true
+		if err := a.ValidateValue(values[a.Code]); err != nil {
+			errs[a.Code] = err
+		}
+	}
+
+	for code := range values {
+		if !known[code] {
+			errs[code] = errors.New("unknown attribute for the category")
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+
+	return validation.Errors{"attributes": errs}
+}
@@ -0,0 +1,151 @@
+package model_test
+
+import (
+	"testing"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_CategoryAttributeValidate(t *testing.T) {
+	testCases := []struct {
+		name    string
+		a       func() *model.CategoryAttribute
+		isValid bool
+	}{
+		{
+			name: "valid",
+			a: func() *model.CategoryAttribute {
+				return model.TestCategoryAttribute(t)
+			},
+			isValid: true,
+		},
+		{
+			name: "invalid code",
+			a: func() *model.CategoryAttribute {
+				a := model.TestCategoryAttribute(t)
+				a.Code = "Screen size"
+				return a
+			},
+			isValid: false,
+		},
+		{
+			name: "unknown type",
+			a: func() *model.CategoryAttribute {
+				a := model.TestCategoryAttribute(t)
+				a.Type = "date"
+				return a
+			},
+			isValid: false,
+		},
+		{
+			name: "enum without values",
+			a: func() *model.CategoryAttribute {
+				a := model.TestCategoryAttribute(t)
+				a.Type = model.AttributeTypeEnum
+				return a
+			},
+			isValid: false,
+		},
+		{
+			name: "enum",
+			a: func() *model.CategoryAttribute {
+				a := model.TestCategoryAttribute(t)
+				a.Type = model.AttributeTypeEnum
+				a.AllowedValues = []string{"S", "M"}
+				return a
+			},
+			isValid: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.isValid {
+				assert.NoError(t, tc.a().Validate())
+			} else {
+				assert.Error(t, tc.a().Validate())
+			}
+		})
+	}
+}
+
+func Test_CategorySchema(t *testing.T) {
+	categories := []*model.Category{
+		{CategoryID: 1, CategoryName: "Электроника"},
+		{CategoryID: 100, CategoryName: "Телефоны", ParentCategoryID: 1},
+		{CategoryID: 2, CategoryName: "Одежда"},
+	}
+	attributes := []*model.CategoryAttribute{
+		{AttributeID: 1, CategoryID: 1, Code: "warranty", Type: model.AttributeTypeNumber},
+		{AttributeID: 2, CategoryID: 1, Code: "screen_size", Type: model.AttributeTypeNumber},
+		{AttributeID: 3, CategoryID: 100, Code: "screen_size", Type: model.AttributeTypeNumber, Required: true},
+		{AttributeID: 4, CategoryID: 2, Code: "fabric", Type: model.AttributeTypeString},
+	}
+
+	assert.Equal(t, []int{100, 1}, model.CategoryPath(100, categories))
+
+	schema := model.CategorySchema(100, categories, attributes)
+	assert.Len(t, schema, 2)
+	assert.Equal(t, 3, schema[0].AttributeID)
+	assert.Equal(t, 1, schema[1].AttributeID)
+
+	schema = model.CategorySchema(1, categories, attributes)
+	assert.Len(t, schema, 2)
+	assert.Equal(t, 2, schema[0].AttributeID)
+}
+
+func Test_ValidateAttributes(t *testing.T) {
+	schema := []*model.CategoryAttribute{
+		{Code: "screen_size", Type: model.AttributeTypeNumber, Required: true},
+		{Code: "size", Type: model.AttributeTypeEnum, AllowedValues: []string{"S", "M"}},
+		{Code: "waterproof", Type: model.AttributeTypeBool},
+	}
+
+	testCases := []struct {
+		name    string
+		values  map[string]string
+		isValid bool
+	}{
+		{
+			name:    "valid",
+			values:  map[string]string{"screen_size": "6.1", "size": "M", "waterproof": "true"},
+			isValid: true,
+		},
+		{
+			name:    "required missing",
+			values:  map[string]string{"size": "M"},
+			isValid: false,
+		},
+		{
+			name:    "not a number",
+			values:  map[string]string{"screen_size": "big"},
+			isValid: false,
+		},
+		{
+			name:    "not allowed value",
+			values:  map[string]string{"screen_size": "6.1", "size": "XXL"},
+			isValid: false,
+		},
+		{
+			name:    "not a bool",
+			values:  map[string]string{"screen_size": "6.1", "waterproof": "maybe"},
+			isValid: false,
+		},
+		{
+			name:    "unknown attribute",
+			values:  map[string]string{"screen_size": "6.1", "fabric": "cotton"},
+			isValid: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.isValid {
+				assert.NoError(t, model.ValidateAttributes(schema, tc.values))
+			} else {
+				assert.Error(t, model.ValidateAttributes(schema, tc.values))
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package model
+
+import (
+	"time"
+)
+
+const (
+	SignInFailureUnknownEmail = "unknown_email"
+	SignInFailurePassword     = "invalid_password"
+	SignInFailureThrottled    = "throttled"
+	SignInFailureTwoFactor    = "invalid_2fa_code"
+)
+
+// AttemptCounter counts failed sign-ins of an account or a client address since the counter
+// was reset, Key is like "account:user@example.org" or "ip:192.0.2.1"
+type AttemptCounter struct {
+	Key           string    `json:"key"`
+	Failures      int       `json:"failures"`
+	LastFailureAt time.Time `json:"last_failure_at"`
+}
+
+// LockoutPolicy allows FreeAttempts failures without a delay, then every failure doubles the
+// delay before the next attempt starting with BaseDelay up to MaxDelay. After LockAfter
+// failures attempts are refused for LockoutDuration, zero LockAfter disables the lockout.
+type LockoutPolicy struct {
+	FreeAttempts    int
+	BaseDelay       time.Duration
+	MaxDelay        time.Duration
+	LockAfter       int
+	LockoutDuration time.Duration
+}
+
+// SignInFailure is an entry of the audit log of failed sign-ins, UserID is zero for unknown emails
+type SignInFailure struct {
+	ID        int       `json:"id"`
+	UserID    int       `json:"user_id"`
+	Email     string    `json:"email"`
+	IP        string    `json:"ip"`
+	UserAgent string    `json:"user_agent"`
+	Reason    string    `json:"reason"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// RetryAt is the time the next attempt is allowed after the failures counted by c
+func (p LockoutPolicy) RetryAt(c *AttemptCounter) time.Time {
+	if c == nil || c.Failures <= p.FreeAttempts {
+		return time.Time{}
+	}
+
+	if p.LockAfter > 0 && c.Failures >= p.LockAfter {
+		return c.LastFailureAt.Add(p.LockoutDuration)
+	}
+
+	delay := p.BaseDelay
+	for i := p.FreeAttempts + 1; i < c.Failures && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+
+	return c.LastFailureAt.Add(delay)
+}
+
+// Locked reports whether the failures reached the lockout rather than a backoff delay
+func (p LockoutPolicy) Locked(c *AttemptCounter) bool {
+	return c != nil && p.LockAfter > 0 && c.Failures >= p.LockAfter
+}
@@ -0,0 +1,49 @@
+package model_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLockoutPolicy_RetryAt(t *testing.T) {
+	p := model.LockoutPolicy{
+		FreeAttempts:    3,
+		BaseDelay:       time.Second,
+		MaxDelay:        10 * time.Second,
+		LockAfter:       10,
+		LockoutDuration: 30 * time.Minute,
+	}
+	at := time.Date(2022, 12, 8, 10, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		failures int
+		delay    time.Duration
+	}{
+		{0, 0},
+		{3, 0},
+		{4, time.Second},
+		{5, 2 * time.Second},
+		{7, 8 * time.Second},
+		{8, 10 * time.Second},
+		{10, 30 * time.Minute},
+		{25, 30 * time.Minute},
+	}
+
+	for _, tc := range testCases {
+		c := &model.AttemptCounter{Key: "account:ex@test.org", Failures: tc.failures, LastFailureAt: at}
+		if tc.delay == 0 {
+			assert.True(t, p.RetryAt(c).IsZero(), tc.failures)
+		} else {
+			assert.Equal(t, at.Add(tc.delay), p.RetryAt(c), tc.failures)
+		}
+		assert.Equal(t, tc.failures >= 10, p.Locked(c))
+	}
+
+	assert.True(t, p.RetryAt(nil).IsZero())
+
+	p.LockAfter = 0
+	assert.Equal(t, at.Add(10*time.Second), p.RetryAt(&model.AttemptCounter{Failures: 25, LastFailureAt: at}))
+}
@@ -0,0 +1,113 @@
+package model
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// LogisticsRule holds marketplace rules for volumetric weight and size limits of standard shipments
+type LogisticsRule struct {
+	MarketPlaceID int     `json:"marketplace_id" toml:"marketplace_id"`
+	Name          string  `json:"name" toml:"name"`
+	Divisor       float64 `json:"volumetric_divisor" toml:"volumetric_divisor"` // cm³ per kg
+	MaxWeightKg   float64 `json:"max_weight_kg" toml:"max_weight_kg"`
+	MaxSideCm     float64 `json:"max_side_cm" toml:"max_side_cm"`
+	MaxSidesSumCm float64 `json:"max_sides_sum_cm" toml:"max_sides_sum_cm"`
+}
+
+// DefaultLogisticsRules are the limits for goods that are not treated as bulky cargo
+var DefaultLogisticsRules = []*LogisticsRule{
+	{
+		MarketPlaceID: MarketPlaceOzon,
+		Name:          "ozon",
+		Divisor:       5000,
+		MaxWeightKg:   25,
+		MaxSideCm:     120,
+		MaxSidesSumCm: 200,
+	},
+	{
+		MarketPlaceID: MarketPlaceWildberries,
+		Name:          "wildberries",
+		Divisor:       5000,
+		MaxWeightKg:   25,
+		MaxSideCm:     120,
+		MaxSidesSumCm: 200,
+	},
+}
+
+// Logistics describes the package of a product. Weight and dimensions of the product are
+// the ones of the package as shipped, the package contains PiecesInPack pieces.
+type Logistics struct {
+	VolumeLiters  float64                 `json:"volume_liters"`
+	PackWeightKg  float64                 `json:"pack_weight_kg"`
+	PieceWeightKg float64                 `json:"piece_weight_kg"`
+	Warnings      []string                `json:"warnings,omitempty"`
+	MarketPlaces  []*MarketPlaceLogistics `json:"marketplaces"`
+}
+
+type MarketPlaceLogistics struct {
+	MarketPlaceID      int      `json:"marketplace_id"`
+	MarketPlace        string   `json:"marketplace"`
+	VolumetricWeightKg float64  `json:"volumetric_weight_kg"`
+	ChargeableWeightKg float64  `json:"chargeable_weight_kg"`
+	Oversized          bool     `json:"oversized"`
+	Violations         []string `json:"violations,omitempty"`
+}
+
+// CalculateLogistics computes package volume, weights and checks marketplace size limits
+func CalculateLogistics(p *Product, rules []*LogisticsRule) *Logistics {
+	sides := []float64{float64(p.Lenght) / 10, float64(p.Width) / 10, float64(p.Height) / 10}
+	sort.Sort(sort.Reverse(sort.Float64Slice(sides)))
+	volumeCm := sides[0] * sides[1] * sides[2]
+
+	l := &Logistics{
+		VolumeLiters: round3(volumeCm / 1000),
+		PackWeightKg: round3(float64(p.Weight) / 1000),
+		MarketPlaces: make([]*MarketPlaceLogistics, 0, len(rules)),
+	}
+
+	pieces := p.PiecesInPack
+	if pieces < 1 {
+		pieces = 1
+	}
+	l.PieceWeightKg = round3(float64(p.Weight) / 1000 / float64(pieces))
+
+	if sides[2] <= 0 {
+		l.Warnings = append(l.Warnings, "package dimensions are not set")
+	}
+	if p.Weight <= 0 {
+		l.Warnings = append(l.Warnings, "package weight is not set")
+	}
+
+	for _, rule := range rules {
+		mp := &MarketPlaceLogistics{
+			MarketPlaceID: rule.MarketPlaceID,
+			MarketPlace:   rule.Name,
+		}
+
+		if rule.Divisor > 0 {
+			mp.VolumetricWeightKg = round3(volumeCm / rule.Divisor)
+		}
+		mp.ChargeableWeightKg = math.Max(mp.VolumetricWeightKg, l.PackWeightKg)
+
+		if rule.MaxWeightKg > 0 && l.PackWeightKg > rule.MaxWeightKg {
+			mp.Violations = append(mp.Violations, fmt.Sprintf("weight exceeds %g kg", rule.MaxWeightKg))
+		}
+		if rule.MaxSideCm > 0 && sides[0] > rule.MaxSideCm {
+			mp.Violations = append(mp.Violations, fmt.Sprintf("longest side exceeds %g cm", rule.MaxSideCm))
+		}
+		if rule.MaxSidesSumCm > 0 && sides[0]+sides[1]+sides[2] > rule.MaxSidesSumCm {
+			mp.Violations = append(mp.Violations, fmt.Sprintf("sum of sides exceeds %g cm", rule.MaxSidesSumCm))
+		}
+		mp.Oversized = len(mp.Violations) > 0
+
+		l.MarketPlaces = append(l.MarketPlaces, mp)
+	}
+
+	return l
+}
+
+func round3(v float64) float64 {
+	return math.Round(v*1000) / 1000
+}
@@ -0,0 +1,94 @@
+package model_test
+
+import (
+	"testing"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_CalculateLogistics(t *testing.T) {
+	p := model.TestProduct(t)
+	p.PiecesInPack = 4
+
+	l := model.CalculateLogistics(p, model.DefaultLogisticsRules)
+	assert.Equal(t, 0.9, l.VolumeLiters)
+	assert.Equal(t, 0.5, l.PackWeightKg)
+	assert.Equal(t, 0.125, l.PieceWeightKg)
+	assert.Empty(t, l.Warnings)
+	assert.Len(t, l.MarketPlaces, 2)
+	assert.Equal(t, 0.18, l.MarketPlaces[0].VolumetricWeightKg)
+	assert.Equal(t, 0.5, l.MarketPlaces[0].ChargeableWeightKg)
+	assert.False(t, l.MarketPlaces[0].Oversized)
+}
+
+func Test_CalculateLogisticsLimits(t *testing.T) {
+	testCases := []struct {
+		name       string
+		p          func() *model.Product
+		violations int
+	}{
+		{
+			name: "heavy",
+			p: func() *model.Product {
+				p := model.TestProduct(t)
+				p.Weight = 30000
+				return p
+			},
+			violations: 1,
+		},
+		{
+			name: "long",
+			p: func() *model.Product {
+				p := model.TestProduct(t)
+				p.Lenght = 1300
+				return p
+			},
+			violations: 1,
+		},
+		{
+			name: "bulky",
+			p: func() *model.Product {
+				p := model.TestProduct(t)
+				p.Lenght = 1000
+				p.Width = 800
+				p.Height = 500
+				return p
+			},
+			violations: 1,
+		},
+		{
+			name: "all",
+			p: func() *model.Product {
+				p := model.TestProduct(t)
+				p.Weight = 30000
+				p.Lenght = 1300
+				p.Width = 800
+				return p
+			},
+			violations: 3,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			l := model.CalculateLogistics(tc.p(), model.DefaultLogisticsRules)
+			for _, mp := range l.MarketPlaces {
+				assert.True(t, mp.Oversized)
+				assert.Len(t, mp.Violations, tc.violations)
+			}
+		})
+	}
+}
+
+func Test_CalculateLogisticsWarnings(t *testing.T) {
+	p := model.TestProduct(t)
+	p.Weight = 0
+	p.Height = 0
+	p.PiecesInPack = 0
+
+	l := model.CalculateLogistics(p, model.DefaultLogisticsRules)
+	assert.Equal(t, 0.0, l.VolumeLiters)
+	assert.Equal(t, 0.0, l.PieceWeightKg)
+	assert.Len(t, l.Warnings, 2)
+}
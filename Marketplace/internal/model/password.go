@@ -0,0 +1,250 @@
+package model
+
+import (
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	PasswordHashBcrypt   = "bcrypt"
+	PasswordHashArgon2id = "argon2id"
+
+	// bcryptMaxLength is the number of password bytes bcrypt uses, the rest is ignored
+	bcryptMaxLength = 72
+	argon2SaltBytes = 16
+	argon2KeyBytes  = 32
+)
+
+var (
+	ErrPasswordTooLong = errors.New("password is too long")
+
+	errPasswordClasses  = errors.New("password must mix more kinds of characters: lowercase and uppercase letters, digits and symbols")
+	errPasswordBreached = errors.New("password is commonly used or was leaked, choose another one")
+	errPasswordIsEmail  = errors.New("password must not be the email")
+	errInvalidHash      = errors.New("invalid password hash")
+
+	// DefaultPasswordHasher encrypts passwords the stores save without a configured hasher
+	DefaultPasswordHasher = PasswordHasher{
+		Algorithm:  PasswordHashBcrypt,
+		BcryptCost: bcrypt.DefaultCost,
+	}
+)
+
+// PasswordPolicy is checked for every new password. Lengths are in characters, CharacterClasses
+// is how many of lowercase letters, uppercase letters, digits and other characters a password
+// must contain. Breached holds passwords that are too common or leaked.
+type PasswordPolicy struct {
+	MinLength        int
+	MaxLength        int
+	CharacterClasses int
+	Breached         BreachedPasswords
+}
+
+// BreachedPasswords is a set of uppercase hex SHA-1 hashes of passwords, the format of leaked
+// password lists
+type BreachedPasswords map[string]struct{}
+
+// PasswordHasher encrypts passwords with bcrypt or argon2id. Hashes of either algorithm are
+// verified, NeedsRehash reports hashes made with another algorithm or other parameters.
+// Argon2Memory is in KiB.
+type PasswordHasher struct {
+	Algorithm     string
+	BcryptCost    int
+	Argon2Time    uint32
+	Argon2Memory  uint32
+	Argon2Threads uint8
+}
+
+// Check validates a new password of the user with the email
+func (p *PasswordPolicy) Check(password string, email string) error {
+	return validation.Validate(
+		password,
+		validation.Required,
+		validation.Length(p.MinLength, p.MaxLength),
+		validation.By(p.checkClasses),
+		validation.By(p.checkBreached),
+		validation.By(checkNotEmail(email)),
+	)
+}
+
+func (p *PasswordPolicy) checkClasses(value interface{}) error {
+	var lower, upper, digit, other int
+	for _, r := range value.(string) {
+		switch {
+		case unicode.IsLower(r):
+			lower = 1
+		case unicode.IsUpper(r):
+			upper = 1
+		case unicode.IsDigit(r):
+			digit = 1
+		default:
+			other = 1
+		}
+	}
+
+	if lower+upper+digit+other < p.CharacterClasses {
+		return errPasswordClasses
+	}
+
+	return nil
+}
+
+func (p *PasswordPolicy) checkBreached(value interface{}) error {
+	if p.Breached.Contains(value.(string)) {
+		return errPasswordBreached
+	}
+
+	return nil
+}
+
+func checkNotEmail(email string) validation.RuleFunc {
+	email = strings.ToLower(email)
+
+	return func(value interface{}) error {
+		password := strings.ToLower(value.(string))
+		if email == "" {
+			return nil
+		}
+
+		if password == email || password == strings.SplitN(email, "@", 2)[0] {
+			return errPasswordIsEmail
+		}
+
+		return nil
+	}
+}
+
+// Add puts the password or its SHA-1 hash written in hex into the set
+func (b BreachedPasswords) Add(passwordOrHash string) {
+	if len(passwordOrHash) == sha1.Size*2 {
+		if _, err := hex.DecodeString(passwordOrHash); err == nil {
+			b[strings.ToUpper(passwordOrHash)] = struct{}{}
+			return
+		}
+	}
+
+	b[breachedKey(passwordOrHash)] = struct{}{}
+}
+
+func (b BreachedPasswords) Contains(password string) bool {
+	_, ok := b[breachedKey(password)]
+	return ok
+}
+
+func breachedKey(password string) string {
+	sum := sha1.Sum([]byte(password))
+	return strings.ToUpper(hex.EncodeToString(sum[:]))
+}
+
+func (h PasswordHasher) Validate() error {
+	return validation.ValidateStruct(
+		&h,
+		validation.Field(&h.Algorithm, validation.Required, validation.In(PasswordHashBcrypt, PasswordHashArgon2id)),
+		validation.Field(&h.BcryptCost, validation.By(requiredIf(h.Algorithm == PasswordHashBcrypt)), validation.Min(bcrypt.MinCost), validation.Max(bcrypt.MaxCost)),
+		validation.Field(&h.Argon2Time, validation.By(requiredIf(h.Algorithm == PasswordHashArgon2id))),
+		validation.Field(&h.Argon2Memory, validation.By(requiredIf(h.Algorithm == PasswordHashArgon2id)), validation.Min(8*uint32(h.Argon2Threads))),
+		validation.Field(&h.Argon2Threads, validation.By(requiredIf(h.Algorithm == PasswordHashArgon2id))),
+	)
+}
+
+func (h PasswordHasher) Hash(password string) (string, error) {
+	if h.Algorithm != PasswordHashArgon2id {
+		if len(password) > bcryptMaxLength {
+			return "", ErrPasswordTooLong
+		}
+
+		b, err := bcrypt.GenerateFromPassword([]byte(password), h.BcryptCost)
+		if err != nil {
+			return "", err
+		}
+		return string(b), nil
+	}
+
+	salt := make([]byte, argon2SaltBytes)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	key := argon2.IDKey([]byte(password), salt, h.Argon2Time, h.Argon2Memory, h.Argon2Threads, argon2KeyBytes)
+
+	return fmt.Sprintf(
+		"$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version,
+		h.Argon2Memory,
+		h.Argon2Time,
+		h.Argon2Threads,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+// Verify compares the password with a hash of any supported algorithm
+func (h PasswordHasher) Verify(hash string, password string) bool {
+	if !strings.HasPrefix(hash, "$"+PasswordHashArgon2id+"$") {
+		return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+	}
+
+	params, salt, key, err := parseArgon2Hash(hash)
+	if err != nil {
+		return false
+	}
+
+	other := argon2.IDKey([]byte(password), salt, params.Argon2Time, params.Argon2Memory, params.Argon2Threads, uint32(len(key)))
+	return subtle.ConstantTimeCompare(key, other) == 1
+}
+
+// NeedsRehash reports whether the hash was made with another algorithm or other parameters,
+// the password is hashed again when the user signs in
+func (h PasswordHasher) NeedsRehash(hash string) bool {
+	if h.Algorithm != PasswordHashArgon2id {
+		cost, err := bcrypt.Cost([]byte(hash))
+		return err != nil || cost != h.BcryptCost
+	}
+
+	params, _, _, err := parseArgon2Hash(hash)
+	return err != nil ||
+		params.Argon2Time != h.Argon2Time ||
+		params.Argon2Memory != h.Argon2Memory ||
+		params.Argon2Threads != h.Argon2Threads
+}
+
+// parseArgon2Hash reads a hash like "$argon2id$v=19$m=65536,t=3,p=2$salt$key"
+func parseArgon2Hash(hash string) (*PasswordHasher, []byte, []byte, error) {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[1] != PasswordHashArgon2id {
+		return nil, nil, nil, errInvalidHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return nil, nil, nil, errInvalidHash
+	}
+
+	params := &PasswordHasher{Algorithm: PasswordHashArgon2id}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Argon2Memory, &params.Argon2Time, &params.Argon2Threads); err != nil {
+		return nil, nil, nil, errInvalidHash
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return nil, nil, nil, errInvalidHash
+	}
+
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return nil, nil, nil, errInvalidHash
+	}
+
+	return params, salt, key, nil
+}
@@ -0,0 +1,111 @@
+package model_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPasswordPolicy_Check(t *testing.T) {
+	breached := model.BreachedPasswords{}
+	breached.Add("Summer2022!")
+	// SHA-1 of "password1"
+	breached.Add("e38ad214943daad1d64c102faec29de4afe9da3d")
+
+	p := &model.PasswordPolicy{
+		MinLength:        8,
+		MaxLength:        20,
+		CharacterClasses: 3,
+		Breached:         breached,
+	}
+
+	testCases := []struct {
+		name     string
+		password string
+		email    string
+		isValid  bool
+	}{
+		{"valid", "Correct-horse", "ex@test.org", true},
+		{"unicode", "Пароль-2022", "ex@test.org", true},
+		{"empty", "", "ex@test.org", false},
+		{"short", "Ab1!", "ex@test.org", false},
+		{"long", "Correct-horse-battery-staple", "ex@test.org", false},
+		{"two classes", "correct-horse", "ex@test.org", false},
+		{"breached", "Summer2022!", "ex@test.org", false},
+		{"breached in another case", "Password1", "ex@test.org", true},
+		{"email", "Seller-1@Test.org", "seller-1@test.org", false},
+		{"email name", "Seller-1", "seller-1@test.org", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.isValid {
+				assert.NoError(t, p.Check(tc.password, tc.email))
+			} else {
+				assert.Error(t, p.Check(tc.password, tc.email))
+			}
+		})
+	}
+
+	p.CharacterClasses = 1
+	assert.Error(t, p.Check("password1", "ex@test.org"))
+}
+
+func TestPasswordHasher(t *testing.T) {
+	bcryptHasher := model.PasswordHasher{Algorithm: model.PasswordHashBcrypt, BcryptCost: 5}
+	argon2Hasher := model.PasswordHasher{Algorithm: model.PasswordHashArgon2id, Argon2Time: 1, Argon2Memory: 1024, Argon2Threads: 1}
+
+	bcryptHash, err := bcryptHasher.Hash("password")
+	assert.NoError(t, err)
+	argon2Hash, err := argon2Hasher.Hash("password")
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(argon2Hash, "$argon2id$v=19$m=1024,t=1,p=1$"))
+
+	for _, h := range []model.PasswordHasher{bcryptHasher, argon2Hasher} {
+		assert.True(t, h.Verify(bcryptHash, "password"))
+		assert.True(t, h.Verify(argon2Hash, "password"))
+		assert.False(t, h.Verify(bcryptHash, "invalid"))
+		assert.False(t, h.Verify(argon2Hash, "invalid"))
+		assert.False(t, h.Verify("$argon2id$v=19$m=1024$invalid", "password"))
+	}
+
+	assert.False(t, bcryptHasher.NeedsRehash(bcryptHash))
+	assert.True(t, bcryptHasher.NeedsRehash(argon2Hash))
+	assert.False(t, argon2Hasher.NeedsRehash(argon2Hash))
+	assert.True(t, argon2Hasher.NeedsRehash(bcryptHash))
+
+	bcryptHasher.BcryptCost = 6
+	assert.True(t, bcryptHasher.NeedsRehash(bcryptHash))
+	argon2Hasher.Argon2Memory = 2048
+	assert.True(t, argon2Hasher.NeedsRehash(argon2Hash))
+
+	_, err = bcryptHasher.Hash(strings.Repeat("a", 73))
+	assert.Equal(t, model.ErrPasswordTooLong, err)
+}
+
+func TestPasswordHasher_Validate(t *testing.T) {
+	testCases := []struct {
+		name    string
+		h       model.PasswordHasher
+		isValid bool
+	}{
+		{"bcrypt", model.DefaultPasswordHasher, true},
+		{"argon2id", model.PasswordHasher{Algorithm: model.PasswordHashArgon2id, Argon2Time: 3, Argon2Memory: 64 * 1024, Argon2Threads: 2}, true},
+		{"unknown algorithm", model.PasswordHasher{Algorithm: "scrypt"}, false},
+		{"low bcrypt cost", model.PasswordHasher{Algorithm: model.PasswordHashBcrypt, BcryptCost: 2}, false},
+		{"no argon2 time", model.PasswordHasher{Algorithm: model.PasswordHashArgon2id, Argon2Memory: 64 * 1024, Argon2Threads: 2}, false},
+		{"little argon2 memory", model.PasswordHasher{Algorithm: model.PasswordHashArgon2id, Argon2Time: 1, Argon2Memory: 8, Argon2Threads: 2}, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.isValid {
+				assert.NoError(t, tc.h.Validate())
+			} else {
+				assert.Error(t, tc.h.Validate())
+			}
+		})
+	}
+}
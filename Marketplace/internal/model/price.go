@@ -0,0 +1,95 @@
+package model
+
+import (
+	"fmt"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+)
+
+// Price is a version of the product price on a marketplace. Prices are never updated,
+// every change adds a new record so that the latest one is the current price.
+type Price struct {
+	PriceID       int     `json:"price_id"`
+	ProductID     int     `json:"product_id"`
+	MarketPlaceID int     `json:"marketplace_id"`
+	UserID        int     `json:"-"`
+	ListPrice     float64 `json:"list_price"`
+	DiscountPrice float64 `json:"discount_price"`
+	// MinPrice is the lowest price the product may be sold at, zero means the selling price
+	MinPrice float64 `json:"min_price"`
+	// LandedCost is the unit cost of the product delivered to the warehouse
+	LandedCost float64 `json:"landed_cost"`
+	// Override marks prices saved below landed cost plus fees on purpose
+	Override  bool      `json:"override"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (p *Price) Validate() error {
+	return validation.ValidateStruct(
+		p,
+		validation.Field(&p.ProductID, validation.Required),
+		validation.Field(&p.MarketPlaceID, validation.Required, validation.In(MarketPlaceOzon, MarketPlaceWildberries)),
+		validation.Field(&p.UserID, validation.Required),
+		validation.Field(&p.ListPrice, validation.Required, validation.Min(0.01)),
+		validation.Field(&p.DiscountPrice, validation.Min(0.0), validation.Max(p.ListPrice)),
+		validation.Field(&p.MinPrice, validation.Min(0.0), validation.Max(p.SellingPrice())),
+		validation.Field(&p.LandedCost, validation.Min(0.0)),
+	)
+}
+
+// SellingPrice is the price buyers pay, the discount price when it is set
+func (p *Price) SellingPrice() float64 {
+	if p.DiscountPrice > 0 {
+		return p.DiscountPrice
+	}
+
+	return p.ListPrice
+}
+
+// FloorPrice is the lowest price the product is allowed to be sold at
+func (p *Price) FloorPrice() float64 {
+	if p.MinPrice > 0 {
+		return p.MinPrice
+	}
+
+	return p.SellingPrice()
+}
+
+// Reprice returns a new version of the price with the selling price changed
+func (p *Price) Reprice(sellingPrice float64) *Price {
+	next := &Price{
+		ProductID:     p.ProductID,
+		MarketPlaceID: p.MarketPlaceID,
+		UserID:        p.UserID,
+		ListPrice:     p.ListPrice,
+		DiscountPrice: p.DiscountPrice,
+		MinPrice:      p.MinPrice,
+		LandedCost:    p.LandedCost,
+	}
+
+	if next.DiscountPrice > 0 && sellingPrice <= next.ListPrice {
+		next.DiscountPrice = sellingPrice
+	} else {
+		next.ListPrice = sellingPrice
+		next.DiscountPrice = 0
+	}
+
+	return next
+}
+
+// CheckMargin reports an error when the payout at the floor price doesn't cover the landed cost
+func (p *Price) CheckMargin(fees *FeeEstimate) error {
+	if p.Override {
+		return nil
+	}
+
+	if fees.Payout < p.LandedCost {
+		return fmt.Errorf(
+			"price %.2f is below landed cost %.2f plus fees %.2f, set override to save it",
+			p.FloorPrice(), p.LandedCost, roundMoney(fees.Commission+fees.Logistics),
+		)
+	}
+
+	return nil
+}
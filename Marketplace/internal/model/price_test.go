@@ -0,0 +1,113 @@
+package model_test
+
+import (
+	"testing"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrice_Validate(t *testing.T) {
+	testCases := []struct {
+		name    string
+		p       func() *model.Price
+		isValid bool
+	}{
+		{
+			name: "valid",
+			p: func() *model.Price {
+				return model.TestPrice(t)
+			},
+			isValid: true,
+		},
+		{
+			name: "list price only",
+			p: func() *model.Price {
+				p := model.TestPrice(t)
+				p.DiscountPrice = 0
+				p.MinPrice = 0
+				return p
+			},
+			isValid: true,
+		},
+		{
+			name: "no list price",
+			p: func() *model.Price {
+				p := model.TestPrice(t)
+				p.ListPrice = 0
+				return p
+			},
+			isValid: false,
+		},
+		{
+			name: "discount above list price",
+			p: func() *model.Price {
+				p := model.TestPrice(t)
+				p.DiscountPrice = 1300
+				return p
+			},
+			isValid: false,
+		},
+		{
+			name: "min above selling price",
+			p: func() *model.Price {
+				p := model.TestPrice(t)
+				p.MinPrice = 1100
+				return p
+			},
+			isValid: false,
+		},
+		{
+			name: "unknown marketplace",
+			p: func() *model.Price {
+				p := model.TestPrice(t)
+				p.MarketPlaceID = 5
+				return p
+			},
+			isValid: false,
+		},
+		{
+			name: "negative landed cost",
+			p: func() *model.Price {
+				p := model.TestPrice(t)
+				p.LandedCost = -1
+				return p
+			},
+			isValid: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.isValid {
+				assert.NoError(t, tc.p().Validate())
+			} else {
+				assert.Error(t, tc.p().Validate())
+			}
+		})
+	}
+}
+
+func TestPrice_FloorPrice(t *testing.T) {
+	p := model.TestPrice(t)
+	assert.Equal(t, 1000.0, p.SellingPrice())
+	assert.Equal(t, 900.0, p.FloorPrice())
+
+	p.MinPrice = 0
+	assert.Equal(t, 1000.0, p.FloorPrice())
+
+	p.DiscountPrice = 0
+	assert.Equal(t, 1200.0, p.FloorPrice())
+}
+
+func TestPrice_CheckMargin(t *testing.T) {
+	p := model.TestPrice(t)
+	fees := model.TestTariff(t).Estimate(p.FloorPrice(), 0.9, nil)
+	assert.NoError(t, p.CheckMargin(fees))
+
+	p.LandedCost = 800
+	assert.Error(t, p.CheckMargin(fees))
+
+	p.Override = true
+	assert.NoError(t, p.CheckMargin(fees))
+}
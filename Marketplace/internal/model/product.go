@@ -1,24 +1,40 @@
 package model
 
 import (
+	"errors"
+
 	validation "github.com/go-ozzo/ozzo-validation"
 )
 
+const (
+	MarketPlaceOzon        = 1
+	MarketPlaceWildberries = 2
+)
+
 type Product struct {
-	ProductID      int     `json:"product_id"`
-	ProductName    string  `json:"product_name"`
-	CategoryID     int     `json:"category_id"`
-	PiecesInPack   int     `json:"pieces_in_pack"`
-	MaterialID     int     `json:"material_id"`
-	Weight         float32 `json:"weight"`
-	Lenght         float32 `json:"lenght"`
-	Width          float32 `json:"width"`
-	Height         float32 `json:"height"`
-	Description    string  `json:"description"`
-	UserID         int     `json:"user_id"`
-	Active         bool    `json:"-"`
-	WildberriesSKU int     `json:"wildberries_sku"`
-	OzonSKU        int     `json:"ozon_sku"`
+	ProductID      int             `json:"product_id"`
+	ProductName    string          `json:"product_name"`
+	CategoryID     int             `json:"category_id"`
+	PiecesInPack   int             `json:"pieces_in_pack"`
+	MaterialID     int             `json:"material_id"`
+	Weight         float32         `json:"weight"`
+	Lenght         float32         `json:"lenght"`
+	Width          float32         `json:"width"`
+	Height         float32         `json:"height"`
+	Description    string          `json:"description"`
+	UserID         int             `json:"user_id"`
+	Active         bool            `json:"-"`
+	WildberriesSKU int             `json:"wildberries_sku"`
+	OzonSKU        int             `json:"ozon_sku"`
+	Version        int             `json:"version"`
+	Images         []*ProductImage `json:"images,omitempty"`
+
+	Attributes map[string]string `json:"attributes,omitempty"`
+	Logistics  *Logistics        `json:"logistics,omitempty"`
+
+	ParentProductID   int               `json:"parent_product_id"`
+	VariantAttributes map[string]string `json:"variant_attributes,omitempty"`
+	Variants          []*Product        `json:"variants,omitempty"`
 }
 
 func (p *Product) Validate() error {
@@ -31,6 +47,49 @@ func (p *Product) Validate() error {
 	)
 }
 
+// ValidateVariant checks that the product can be a variant of the parent product
+func (p *Product) ValidateVariant(parent *Product) error {
+	if parent.ParentProductID != 0 {
+		return errors.New("variant can't have own variants")
+	}
+	if parent.ProductID == p.ProductID {
+		return errors.New("product can't be a variant of itself")
+	}
+	if parent.UserID != p.UserID {
+		return errors.New("parent product belongs to another user")
+	}
+	if parent.CategoryID != p.CategoryID {
+		return errors.New("variant must share the parent product category")
+	}
+	if len(p.VariantAttributes) == 0 {
+		return errors.New("variant attributes are required")
+	}
+
+	return nil
+}
+
+// GroupVariants nests variants under their parents, variants without a listed parent stay on top
+func GroupVariants(products []*Product) []*Product {
+	parents := make(map[int]*Product)
+	for _, p := range products {
+		if p.ParentProductID == 0 {
+			p.Variants = nil
+			parents[p.ProductID] = p
+		}
+	}
+
+	grouped := make([]*Product, 0, len(parents))
+	for _, p := range products {
+		if parent, ok := parents[p.ParentProductID]; ok {
+			parent.Variants = append(parent.Variants, p)
+			continue
+		}
+		grouped = append(grouped, p)
+	}
+
+	return grouped
+}
+
 type MarketPlaceItem struct {
 	MarketPlaceItemID int
 	ProductID         int
@@ -98,6 +157,21 @@ func (mpil *MarketPlaceItemsList) UpdateMPIList(p *Product) {
 	mpil.MPIList = append(mpil.MPIList, mpiWb)
 }
 
+// FilterMPIList keeps only the items of the given marketplaces
+func (mpil *MarketPlaceItemsList) FilterMPIList(marketPlaceIDs ...int) {
+	filtered := make([]*MarketPlaceItem, 0, len(mpil.MPIList))
+	for _, mpi := range mpil.MPIList {
+		for _, id := range marketPlaceIDs {
+			if mpi.MarketPlaceID == id {
+				filtered = append(filtered, mpi)
+				break
+			}
+		}
+	}
+
+	mpil.MPIList = filtered
+}
+
 func (mpi *MarketPlaceItemsList) ValidateMarketPlaceItems() error {
 	for _, mpi := range mpi.MPIList {
 		err := mpi.ValidateMarketPlaceItem()
@@ -0,0 +1,46 @@
+package model
+
+import (
+	"errors"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+)
+
+var ImageContentTypes = []interface{}{"image/jpeg", "image/png", "image/gif"}
+
+type ProductImage struct {
+	ImageID      int       `json:"image_id"`
+	ProductID    int       `json:"product_id"`
+	UserID       int       `json:"-"`
+	FileKey      string    `json:"-"`
+	ThumbnailKey string    `json:"-"`
+	ContentType  string    `json:"content_type"`
+	Size         int64     `json:"size"`
+	Width        int       `json:"width"`
+	Height       int       `json:"height"`
+	Position     int       `json:"position"`
+	URL          string    `json:"url"`
+	ThumbnailURL string    `json:"thumbnail_url"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+func (i *ProductImage) Validate(maxSize int64) error {
+	return validation.ValidateStruct(
+		i,
+		validation.Field(&i.ProductID, validation.Required),
+		validation.Field(&i.UserID, validation.Required),
+		validation.Field(&i.ContentType, validation.Required, validation.In(ImageContentTypes...)),
+		validation.Field(&i.Size, validation.Required, validation.By(checkMaxSize(maxSize))),
+	)
+}
+
+func checkMaxSize(maxSize int64) validation.RuleFunc {
+	return func(value interface{}) error {
+		s, _ := value.(int64)
+		if maxSize > 0 && s > maxSize {
+			return errors.New("file is too large")
+		}
+		return nil
+	}
+}
@@ -0,0 +1,61 @@
+package model_test
+
+import (
+	"testing"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ProductImageValidate(t *testing.T) {
+	testCases := []struct {
+		name    string
+		i       func() *model.ProductImage
+		isValid bool
+	}{
+		{
+			name: "valid",
+			i: func() *model.ProductImage {
+				return model.TestProductImage(t)
+			},
+			isValid: true,
+		},
+		{
+			name: "wrong content type",
+			i: func() *model.ProductImage {
+				i := model.TestProductImage(t)
+				i.ContentType = "application/pdf"
+				return i
+			},
+			isValid: false,
+		},
+		{
+			name: "too large",
+			i: func() *model.ProductImage {
+				i := model.TestProductImage(t)
+				i.Size = 4096
+				return i
+			},
+			isValid: false,
+		},
+		{
+			name: "empty file",
+			i: func() *model.ProductImage {
+				i := model.TestProductImage(t)
+				i.Size = 0
+				return i
+			},
+			isValid: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.isValid {
+				assert.NoError(t, tc.i().Validate(2048))
+			} else {
+				assert.Error(t, tc.i().Validate(2048))
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package model
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"time"
+)
+
+const (
+	RevisionActionCreate = "create"
+	RevisionActionUpdate = "update"
+	RevisionActionDelete = "delete"
+)
+
+// ProductRevision is a full snapshot of a product taken on every change
+type ProductRevision struct {
+	RevisionID int       `json:"revision_id"`
+	ProductID  int       `json:"product_id"`
+	Revision   int       `json:"revision"`
+	Action     string    `json:"action"`
+	Product    *Product  `json:"product"`
+	UserID     int       `json:"user_id"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type FieldChange struct {
+	Field string      `json:"field"`
+	From  interface{} `json:"from"`
+	To    interface{} `json:"to"`
+}
+
+// DiffProducts returns changed fields between two products keyed by their json names
+func DiffProducts(from, to *Product) ([]*FieldChange, error) {
+	fromFields, err := productFields(from)
+	if err != nil {
+		return nil, err
+	}
+
+	toFields, err := productFields(to)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]struct{})
+	for k := range fromFields {
+		keys[k] = struct{}{}
+	}
+	for k := range toFields {
+		keys[k] = struct{}{}
+	}
+
+	names := make([]string, 0, len(keys))
+	for k := range keys {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	changes := make([]*FieldChange, 0)
+	for _, name := range names {
+		if !reflect.DeepEqual(fromFields[name], toFields[name]) {
+			changes = append(changes, &FieldChange{
+				Field: name,
+				From:  fromFields[name],
+				To:    toFields[name],
+			})
+		}
+	}
+
+	return changes, nil
+}
+
+func productFields(p *Product) (map[string]interface{}, error) {
+	fields := make(map[string]interface{})
+	if p == nil {
+		return fields, nil
+	}
+
+	b, err := json.Marshal(p)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = json.Unmarshal(b, &fields); err != nil {
+		return nil, err
+	}
+
+	return fields, nil
+}
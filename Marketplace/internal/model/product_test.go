@@ -159,3 +159,139 @@ func Test_MarketPlaceItemValidate(t *testing.T) {
 		})
 	}
 }
+
+func Test_DiffProducts(t *testing.T) {
+	from := model.TestProduct(t)
+	to := model.TestProduct(t)
+	to.Description = "new description"
+	to.OzonSKU = 1111111
+
+	changes, err := model.DiffProducts(from, to)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(changes))
+	assert.Equal(t, "description", changes[0].Field)
+	assert.Equal(t, from.Description, changes[0].From)
+	assert.Equal(t, to.Description, changes[0].To)
+	assert.Equal(t, "ozon_sku", changes[1].Field)
+
+	changes, err = model.DiffProducts(from, from)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(changes))
+}
+
+func Test_FilterMPIList(t *testing.T) {
+	p := model.TestProduct(t)
+	mpiList := &model.MarketPlaceItemsList{}
+	mpiList.UpdateMPIList(p)
+
+	mpiList.FilterMPIList(model.MarketPlaceWildberries)
+
+	assert.Equal(t, 1, len(mpiList.MPIList))
+	assert.Equal(t, p.WildberriesSKU, mpiList.MPIList[0].SKU)
+
+	mpiList.FilterMPIList()
+
+	assert.Equal(t, 0, len(mpiList.MPIList))
+}
+
+func Test_ProductValidateVariant(t *testing.T) {
+	parent := model.TestProduct(t)
+	parent.ProductID = 1
+
+	testCases := []struct {
+		name    string
+		p       func() *model.Product
+		parent  func() *model.Product
+		isValid bool
+	}{
+		{
+			name: "valid",
+			p: func() *model.Product {
+				return model.TestProductVariant(t, parent)
+			},
+			parent: func() *model.Product {
+				return parent
+			},
+			isValid: true,
+		},
+		{
+			name: "other category",
+			p: func() *model.Product {
+				v := model.TestProductVariant(t, parent)
+				v.CategoryID = 2
+				return v
+			},
+			parent: func() *model.Product {
+				return parent
+			},
+			isValid: false,
+		},
+		{
+			name: "other user",
+			p: func() *model.Product {
+				v := model.TestProductVariant(t, parent)
+				v.UserID = 2
+				return v
+			},
+			parent: func() *model.Product {
+				return parent
+			},
+			isValid: false,
+		},
+		{
+			name: "parent is variant",
+			p: func() *model.Product {
+				return model.TestProductVariant(t, parent)
+			},
+			parent: func() *model.Product {
+				v := model.TestProductVariant(t, parent)
+				v.ProductID = 2
+				return v
+			},
+			isValid: false,
+		},
+		{
+			name: "without attributes",
+			p: func() *model.Product {
+				v := model.TestProductVariant(t, parent)
+				v.VariantAttributes = nil
+				return v
+			},
+			parent: func() *model.Product {
+				return parent
+			},
+			isValid: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.isValid {
+				assert.NoError(t, tc.p().ValidateVariant(tc.parent()))
+			} else {
+				assert.Error(t, tc.p().ValidateVariant(tc.parent()))
+			}
+		})
+	}
+}
+
+func Test_GroupVariants(t *testing.T) {
+	parent := model.TestProduct(t)
+	parent.ProductID = 1
+	v1 := model.TestProductVariant(t, parent)
+	v1.ProductID = 2
+	v2 := model.TestProductVariant(t, parent)
+	v2.ProductID = 3
+	orphan := model.TestProductVariant(t, parent)
+	orphan.ProductID = 4
+	orphan.ParentProductID = 10
+
+	grouped := model.GroupVariants([]*model.Product{v1, parent, v2, orphan})
+
+	assert.Equal(t, 2, len(grouped))
+	assert.Equal(t, parent.ProductID, grouped[0].ProductID)
+	assert.Equal(t, 2, len(grouped[0].Variants))
+	assert.Equal(t, orphan.ProductID, grouped[1].ProductID)
+}
@@ -0,0 +1,63 @@
+package model
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+	// the API server may run in an image without the system time zone database
+	_ "time/tzdata"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/go-ozzo/ozzo-validation/is"
+)
+
+var (
+	// localeRegexp matches BCP 47 tags of a language with an optional script and region like "ru-RU" or "sr-Latn-RS"
+	localeRegexp = regexp.MustCompile(`^[a-z]{2,3}(-[A-Z][a-z]{3})?(-([A-Z]{2}|[0-9]{3}))?$`)
+
+	errUnknownTimezone = errors.New("must be a time zone of the IANA database like Europe/Moscow")
+)
+
+// UserProfile holds the settings the user changes on their own. Timezone is an IANA time zone
+// name, BaseCurrency an ISO 4217 code and Locale a BCP 47 language tag.
+type UserProfile struct {
+	DisplayName  string `json:"display_name"`
+	CompanyName  string `json:"company_name"`
+	Timezone     string `json:"timezone"`
+	BaseCurrency string `json:"base_currency"`
+	Locale       string `json:"locale"`
+}
+
+func (p *UserProfile) Validate() error {
+	return validation.ValidateStruct(
+		p,
+		validation.Field(&p.DisplayName, validation.Length(0, 100)),
+		validation.Field(&p.CompanyName, validation.Length(0, 200)),
+		validation.Field(&p.Timezone, validation.Required, validation.By(checkTimezone)),
+		validation.Field(&p.BaseCurrency, validation.Required, is.CurrencyCode),
+		validation.Field(&p.Locale, validation.Required, validation.Match(localeRegexp)),
+	)
+}
+
+// Normalize trims the names and writes the currency in upper case
+func (p *UserProfile) Normalize() {
+	p.DisplayName = strings.TrimSpace(p.DisplayName)
+	p.CompanyName = strings.TrimSpace(p.CompanyName)
+	p.Timezone = strings.TrimSpace(p.Timezone)
+	p.BaseCurrency = strings.ToUpper(strings.TrimSpace(p.BaseCurrency))
+	p.Locale = strings.TrimSpace(p.Locale)
+}
+
+func checkTimezone(value interface{}) error {
+	name, _ := value.(string)
+	if name == "Local" {
+		return errUnknownTimezone
+	}
+
+	if _, err := time.LoadLocation(name); err != nil {
+		return errUnknownTimezone
+	}
+
+	return nil
+}
@@ -0,0 +1,83 @@
+package model
+
+import (
+	"math"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+)
+
+// DefaultLeadTimeDays is used when the report isn't made for a particular supplier
+const DefaultLeadTimeDays = 14
+
+// ReplenishmentParams control the forecast: sales of the last HistoryDays give the velocity,
+// SafetyDays of sales are kept as safety stock and an order covers CoverDays of sales after it arrives.
+type ReplenishmentParams struct {
+	HistoryDays  int `json:"history_days"`
+	LeadTimeDays int `json:"lead_time_days"`
+	SafetyDays   int `json:"safety_days"`
+	CoverDays    int `json:"cover_days"`
+}
+
+type ReplenishmentLine struct {
+	ProductID    int     `json:"product_id"`
+	ProductName  string  `json:"product_name"`
+	Sold         int     `json:"sold"`
+	Velocity     float64 `json:"velocity"`
+	Stock        int     `json:"stock"`
+	DaysOfCover  float64 `json:"days_of_cover"`
+	SafetyStock  int     `json:"safety_stock"`
+	ReorderPoint int     `json:"reorder_point"`
+	// ReorderQuantity brings the stock up to the lead time, cover and safety stock demand
+	ReorderQuantity int `json:"reorder_quantity"`
+	// Reorder is set when the stock has fallen to the reorder point
+	Reorder bool `json:"reorder"`
+}
+
+func DefaultReplenishmentParams() ReplenishmentParams {
+	return ReplenishmentParams{
+		HistoryDays:  30,
+		LeadTimeDays: DefaultLeadTimeDays,
+		SafetyDays:   7,
+		CoverDays:    30,
+	}
+}
+
+func (p ReplenishmentParams) Validate() error {
+	return validation.ValidateStruct(
+		&p,
+		validation.Field(&p.HistoryDays, validation.Required, validation.Min(1), validation.Max(365)),
+		validation.Field(&p.LeadTimeDays, validation.Min(0), validation.Max(365)),
+		validation.Field(&p.SafetyDays, validation.Min(0), validation.Max(365)),
+		validation.Field(&p.CoverDays, validation.Min(0), validation.Max(365)),
+	)
+}
+
+// Forecast calculates replenishment of the product from units sold during the history period.
+// Days of cover is -1 when the product doesn't sell.
+func Forecast(p *Product, sold int, stock int, params ReplenishmentParams) *ReplenishmentLine {
+	l := &ReplenishmentLine{
+		ProductID:   p.ProductID,
+		ProductName: p.ProductName,
+		Sold:        sold,
+		Stock:       stock,
+		Velocity:    round3(float64(sold) / float64(params.HistoryDays)),
+		DaysOfCover: -1,
+	}
+
+	velocity := float64(sold) / float64(params.HistoryDays)
+	if velocity == 0 {
+		return l
+	}
+
+	l.DaysOfCover = math.Round(float64(stock)/velocity*10) / 10
+	l.SafetyStock = int(math.Ceil(velocity * float64(params.SafetyDays)))
+	l.ReorderPoint = int(math.Ceil(velocity*float64(params.LeadTimeDays))) + l.SafetyStock
+
+	target := int(math.Ceil(velocity*float64(params.LeadTimeDays+params.CoverDays))) + l.SafetyStock
+	if stock < target {
+		l.ReorderQuantity = target - stock
+	}
+	l.Reorder = stock <= l.ReorderPoint
+
+	return l
+}
@@ -0,0 +1,61 @@
+package model_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Forecast(t *testing.T) {
+	p := model.TestProduct(t)
+	params := model.DefaultReplenishmentParams()
+
+	l := model.Forecast(p, 60, 20, params)
+	assert.Equal(t, 2.0, l.Velocity)
+	assert.Equal(t, 10.0, l.DaysOfCover)
+	assert.Equal(t, 14, l.SafetyStock)
+	assert.Equal(t, 42, l.ReorderPoint)
+	assert.Equal(t, 82, l.ReorderQuantity)
+	assert.True(t, l.Reorder)
+
+	l = model.Forecast(p, 60, 120, params)
+	assert.Equal(t, 60.0, l.DaysOfCover)
+	assert.Zero(t, l.ReorderQuantity)
+	assert.False(t, l.Reorder)
+
+	l = model.Forecast(p, 0, 5, params)
+	assert.Equal(t, -1.0, l.DaysOfCover)
+	assert.Zero(t, l.ReorderQuantity)
+	assert.False(t, l.Reorder)
+}
+
+func TestReplenishmentParams_Validate(t *testing.T) {
+	params := model.DefaultReplenishmentParams()
+	assert.NoError(t, params.Validate())
+
+	params.HistoryDays = 0
+	assert.Error(t, params.Validate())
+
+	params = model.DefaultReplenishmentParams()
+	params.SafetyDays = -1
+	assert.Error(t, params.Validate())
+}
+
+func TestSale_BeforeCreate(t *testing.T) {
+	s := &model.Sale{
+		ProductID:     1,
+		MarketPlaceID: model.MarketPlaceOzon,
+		UserID:        1,
+		SaleDate:      time.Date(2022, 11, 20, 15, 30, 0, 0, time.UTC),
+		Quantity:      3,
+	}
+
+	s.BeforeCreate()
+	assert.Equal(t, time.Date(2022, 11, 20, 0, 0, 0, 0, time.UTC), s.SaleDate)
+	assert.NoError(t, s.Validate())
+
+	s.Quantity = -1
+	assert.Error(t, s.Validate())
+}
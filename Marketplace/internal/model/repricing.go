@@ -0,0 +1,161 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+)
+
+const (
+	// RepricingTargetMargin sets the price that earns the target margin over landed cost after fees
+	RepricingTargetMargin = "target_margin"
+	// RepricingFollowCompetitor sets the price to the imported competitor price plus the offset
+	RepricingFollowCompetitor = "follow_competitor"
+)
+
+const (
+	ProposalPending  = "pending"
+	ProposalApproved = "approved"
+	ProposalRejected = "rejected"
+	ProposalApplied  = "applied"
+	ProposalFailed   = "failed"
+	// ProposalExpired marks proposals replaced by the next repricing run before they were approved
+	ProposalExpired = "expired"
+)
+
+// RepricingRule applies to one product or, when ProductID is zero, to all products of the seller.
+// A zero MarketPlaceID applies the rule on every marketplace.
+type RepricingRule struct {
+	RuleID              int       `json:"rule_id"`
+	UserID              int       `json:"-"`
+	Name                string    `json:"name"`
+	ProductID           int       `json:"product_id"`
+	MarketPlaceID       int       `json:"marketplace_id"`
+	Strategy            string    `json:"strategy"`
+	TargetMarginPercent float64   `json:"target_margin_percent"`
+	CompetitorOffset    float64   `json:"competitor_offset"`
+	FloorPrice          float64   `json:"floor_price"`
+	CeilingPrice        float64   `json:"ceiling_price"`
+	Active              bool      `json:"active"`
+	CreatedAt           time.Time `json:"created_at"`
+}
+
+// CompetitorPrice is the latest known price of the same product sold by a competitor
+type CompetitorPrice struct {
+	ProductID     int       `json:"product_id"`
+	MarketPlaceID int       `json:"marketplace_id"`
+	UserID        int       `json:"-"`
+	Price         float64   `json:"price"`
+	ImportedAt    time.Time `json:"imported_at"`
+}
+
+// PriceProposal is a price suggested by a repricing rule, it changes the price only once approved and applied
+type PriceProposal struct {
+	ProposalID    int        `json:"proposal_id"`
+	UserID        int        `json:"-"`
+	RuleID        int        `json:"rule_id"`
+	ProductID     int        `json:"product_id"`
+	MarketPlaceID int        `json:"marketplace_id"`
+	CurrentPrice  float64    `json:"current_price"`
+	ProposedPrice float64    `json:"proposed_price"`
+	Reason        string     `json:"reason"`
+	Status        string     `json:"status"`
+	Error         string     `json:"error,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	DecidedAt     *time.Time `json:"decided_at,omitempty"`
+}
+
+// RepricingLogEntry records a price changed by an applied proposal
+type RepricingLogEntry struct {
+	LogID         int       `json:"log_id"`
+	UserID        int       `json:"-"`
+	ProposalID    int       `json:"proposal_id"`
+	RuleID        int       `json:"rule_id"`
+	ProductID     int       `json:"product_id"`
+	MarketPlaceID int       `json:"marketplace_id"`
+	PriceID       int       `json:"price_id"`
+	OldPrice      float64   `json:"old_price"`
+	NewPrice      float64   `json:"new_price"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+func (r *RepricingRule) Validate() error {
+	return validation.ValidateStruct(
+		r,
+		validation.Field(&r.UserID, validation.Required),
+		validation.Field(&r.Name, validation.Required, validation.Length(1, 200)),
+		validation.Field(&r.MarketPlaceID, validation.In(0, MarketPlaceOzon, MarketPlaceWildberries)),
+		validation.Field(&r.Strategy, validation.Required, validation.In(RepricingTargetMargin, RepricingFollowCompetitor)),
+		validation.Field(
+			&r.TargetMarginPercent,
+			validation.By(requiredIf(r.Strategy == RepricingTargetMargin)),
+			validation.Min(0.0),
+			validation.Max(90.0),
+		),
+		validation.Field(&r.FloorPrice, validation.Min(0.0)),
+		validation.Field(&r.CeilingPrice, validation.Min(0.0), validation.By(checkCeiling(r.FloorPrice))),
+	)
+}
+
+// Matches reports whether the rule applies to the product on the marketplace
+func (r *RepricingRule) Matches(productId int, marketPlaceId int) bool {
+	return r.Active &&
+		(r.ProductID == 0 || r.ProductID == productId) &&
+		(r.MarketPlaceID == 0 || r.MarketPlaceID == marketPlaceId)
+}
+
+// Propose calculates the new selling price for the current price. fees are the marketplace fees
+// at the current price, competitor is zero when no competitor price is known.
+// It returns zero when the rule has nothing to change.
+func (r *RepricingRule) Propose(current *Price, fees *FeeEstimate, competitor float64) (float64, string, error) {
+	var price float64
+	var reason string
+	switch r.Strategy {
+	case RepricingTargetMargin:
+		share := 1 - fees.CommissionPercent/100 - r.TargetMarginPercent/100
+		if share <= 0 {
+			return 0, "", errors.New("target margin can't be reached with the marketplace commission")
+		}
+		price = (current.LandedCost + fees.Logistics) / share
+		reason = fmt.Sprintf("target margin %g%%", r.TargetMarginPercent)
+	case RepricingFollowCompetitor:
+		if competitor <= 0 {
+			return 0, "", nil
+		}
+		price = competitor + r.CompetitorOffset
+		reason = fmt.Sprintf("competitor price %.2f", competitor)
+	default:
+		return 0, "", fmt.Errorf("unknown repricing strategy %s", r.Strategy)
+	}
+
+	floor := math.Max(r.FloorPrice, current.MinPrice)
+	if price < floor {
+		price = floor
+		reason += fmt.Sprintf(", limited by floor %.2f", floor)
+	}
+	if r.CeilingPrice > 0 && price > r.CeilingPrice {
+		price = r.CeilingPrice
+		reason += fmt.Sprintf(", limited by ceiling %.2f", r.CeilingPrice)
+	}
+
+	price = roundMoney(price)
+	if price <= 0 || price == current.SellingPrice() {
+		return 0, "", nil
+	}
+
+	return price, reason, nil
+}
+
+func checkCeiling(floor float64) validation.RuleFunc {
+	return func(value interface{}) error {
+		ceiling, _ := value.(float64)
+		if ceiling > 0 && ceiling < floor {
+			return errors.New("ceiling price must be above floor price")
+		}
+
+		return nil
+	}
+}
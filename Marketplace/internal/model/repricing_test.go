@@ -0,0 +1,146 @@
+package model_test
+
+import (
+	"testing"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepricingRule_Validate(t *testing.T) {
+	testCases := []struct {
+		name    string
+		r       func() *model.RepricingRule
+		isValid bool
+	}{
+		{
+			name: "valid",
+			r: func() *model.RepricingRule {
+				return model.TestRepricingRule(t)
+			},
+			isValid: true,
+		},
+		{
+			name: "target margin",
+			r: func() *model.RepricingRule {
+				r := model.TestRepricingRule(t)
+				r.Strategy = model.RepricingTargetMargin
+				r.TargetMarginPercent = 20
+				return r
+			},
+			isValid: true,
+		},
+		{
+			name: "target margin not set",
+			r: func() *model.RepricingRule {
+				r := model.TestRepricingRule(t)
+				r.Strategy = model.RepricingTargetMargin
+				return r
+			},
+			isValid: false,
+		},
+		{
+			name: "unknown strategy",
+			r: func() *model.RepricingRule {
+				r := model.TestRepricingRule(t)
+				r.Strategy = "random"
+				return r
+			},
+			isValid: false,
+		},
+		{
+			name: "ceiling below floor",
+			r: func() *model.RepricingRule {
+				r := model.TestRepricingRule(t)
+				r.FloorPrice = 500
+				r.CeilingPrice = 400
+				return r
+			},
+			isValid: false,
+		},
+		{
+			name: "no name",
+			r: func() *model.RepricingRule {
+				r := model.TestRepricingRule(t)
+				r.Name = ""
+				return r
+			},
+			isValid: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.isValid {
+				assert.NoError(t, tc.r().Validate())
+			} else {
+				assert.Error(t, tc.r().Validate())
+			}
+		})
+	}
+}
+
+func TestRepricingRule_Matches(t *testing.T) {
+	r := model.TestRepricingRule(t)
+	assert.True(t, r.Matches(1, model.MarketPlaceOzon))
+
+	r.ProductID = 2
+	r.MarketPlaceID = model.MarketPlaceWildberries
+	assert.False(t, r.Matches(1, model.MarketPlaceWildberries))
+	assert.False(t, r.Matches(2, model.MarketPlaceOzon))
+	assert.True(t, r.Matches(2, model.MarketPlaceWildberries))
+
+	r.Active = false
+	assert.False(t, r.Matches(2, model.MarketPlaceWildberries))
+}
+
+func TestRepricingRule_Propose(t *testing.T) {
+	price := model.TestPrice(t)
+	fees := model.TestTariff(t).Estimate(price.SellingPrice(), 0.9, nil)
+
+	r := model.TestRepricingRule(t)
+	proposed, _, err := r.Propose(price, fees, 950)
+	assert.NoError(t, err)
+	assert.Equal(t, 940.0, proposed)
+
+	proposed, _, _ = r.Propose(price, fees, 0)
+	assert.Zero(t, proposed)
+
+	proposed, _, _ = r.Propose(price, fees, 1010)
+	assert.Zero(t, proposed, "competitor price gives the current price")
+
+	proposed, reason, _ := r.Propose(price, fees, 800)
+	assert.Equal(t, 900.0, proposed)
+	assert.Contains(t, reason, "floor")
+
+	r.CeilingPrice = 920
+	proposed, reason, _ = r.Propose(price, fees, 950)
+	assert.Equal(t, 920.0, proposed)
+	assert.Contains(t, reason, "ceiling")
+
+	margin := model.TestRepricingRule(t)
+	margin.Strategy = model.RepricingTargetMargin
+	margin.TargetMarginPercent = 20
+	price.MinPrice = 0
+	proposed, _, err = margin.Propose(price, fees, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 538.46, proposed)
+
+	margin.TargetMarginPercent = 90
+	_, _, err = margin.Propose(price, fees, 0)
+	assert.Error(t, err)
+}
+
+func TestPrice_Reprice(t *testing.T) {
+	price := model.TestPrice(t)
+
+	next := price.Reprice(940)
+	assert.Equal(t, 1200.0, next.ListPrice)
+	assert.Equal(t, 940.0, next.DiscountPrice)
+	assert.Equal(t, price.LandedCost, next.LandedCost)
+	assert.Zero(t, next.PriceID)
+
+	next = price.Reprice(1300)
+	assert.Equal(t, 1300.0, next.ListPrice)
+	assert.Zero(t, next.DiscountPrice)
+}
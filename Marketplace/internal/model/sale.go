@@ -0,0 +1,50 @@
+package model
+
+import (
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+)
+
+// Sale is the number of units of the product sold on a marketplace during one day
+type Sale struct {
+	ProductID     int       `json:"product_id"`
+	MarketPlaceID int       `json:"marketplace_id"`
+	UserID        int       `json:"-"`
+	SaleDate      time.Time `json:"sale_date"`
+	Quantity      int       `json:"quantity"`
+}
+
+// StockLevel is the number of units of the product available for sale in the warehouse
+type StockLevel struct {
+	WarehouseID int       `json:"warehouse_id"`
+	ProductID   int       `json:"product_id"`
+	UserID      int       `json:"-"`
+	Quantity    int       `json:"quantity"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func (s *Sale) BeforeCreate() {
+	s.SaleDate = s.SaleDate.UTC().Truncate(24 * time.Hour)
+}
+
+func (s *Sale) Validate() error {
+	return validation.ValidateStruct(
+		s,
+		validation.Field(&s.ProductID, validation.Required),
+		validation.Field(&s.MarketPlaceID, validation.Required, validation.In(MarketPlaceOzon, MarketPlaceWildberries)),
+		validation.Field(&s.UserID, validation.Required),
+		validation.Field(&s.SaleDate, validation.Required),
+		validation.Field(&s.Quantity, validation.Min(0)),
+	)
+}
+
+func (s *StockLevel) Validate() error {
+	return validation.ValidateStruct(
+		s,
+		validation.Field(&s.WarehouseID, validation.Required),
+		validation.Field(&s.ProductID, validation.Required),
+		validation.Field(&s.UserID, validation.Required),
+		validation.Field(&s.Quantity, validation.Min(0)),
+	)
+}
@@ -0,0 +1,47 @@
+package model
+
+import (
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+)
+
+// Session is a sign-in of the user kept by the API server itself when it runs
+// without the external auth service. The id is the hash of the session cookie and is never shown,
+// Current marks the session of the request in the session list.
+type Session struct {
+	ID         string    `json:"-"`
+	UserID     int       `json:"user_id"`
+	CreatedAt  time.Time `json:"created_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	UserAgent  string    `json:"user_agent"`
+	IP         string    `json:"ip"`
+	Current    bool      `json:"current"`
+}
+
+func (s *Session) Validate() error {
+	return validation.ValidateStruct(
+		s,
+		validation.Field(&s.ID, validation.Required, validation.Length(1, 64)),
+		validation.Field(&s.UserID, validation.Required),
+		validation.Field(&s.ExpiresAt, validation.Required),
+		validation.Field(&s.UserAgent, validation.Length(0, 255)),
+		validation.Field(&s.IP, validation.Length(0, 45)),
+	)
+}
+
+// Expired reports whether the session is no longer valid at the time
+func (s *Session) Expired(at time.Time) bool {
+	return !at.Before(s.ExpiresAt)
+}
+
+// Idle reports whether the session was not used for the timeout by the time
+func (s *Session) Idle(at time.Time, timeout time.Duration) bool {
+	return timeout > 0 && at.Sub(s.LastSeenAt) >= timeout
+}
+
+// Outlived reports whether the session is older than the timeout by the time
+func (s *Session) Outlived(at time.Time, timeout time.Duration) bool {
+	return timeout > 0 && at.Sub(s.CreatedAt) >= timeout
+}
@@ -0,0 +1,258 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+)
+
+const (
+	ShipmentDraft = "draft"
+	// ShipmentReady is a shipment that passed the packing check and waits for the pickup
+	ShipmentReady     = "ready"
+	ShipmentShipped   = "shipped"
+	ShipmentAccepted  = "accepted"
+	ShipmentCancelled = "cancelled"
+)
+
+// Euro pallet limits of marketplace warehouses, sizes are in millimetres
+const (
+	PalletLength      = 1200
+	PalletWidth       = 800
+	PalletMaxHeight   = 1800
+	PalletMaxWeightKg = 500
+)
+
+// Shipment is a delivery of boxes to a marketplace (FBO) warehouse. Boxes with the same
+// pallet number are stacked on one pallet, boxes without it are delivered loose.
+type Shipment struct {
+	ShipmentID    int            `json:"shipment_id"`
+	UserID        int            `json:"-"`
+	WarehouseID   int            `json:"warehouse_id"`
+	MarketPlaceID int            `json:"marketplace_id"`
+	PlannedDate   time.Time      `json:"planned_date"`
+	Status        string         `json:"status"`
+	Boxes         []*ShipmentBox `json:"boxes"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+}
+
+// ShipmentBox sizes are outer dimensions in millimetres
+type ShipmentBox struct {
+	BoxNumber    int             `json:"box_number"`
+	PalletNumber int             `json:"pallet_number"`
+	Length       int             `json:"length"`
+	Width        int             `json:"width"`
+	Height       int             `json:"height"`
+	Items        []*ShipmentItem `json:"items"`
+}
+
+type ShipmentItem struct {
+	ProductID int `json:"product_id"`
+	Quantity  int `json:"quantity"`
+}
+
+// PackingIssue is a problem of the box or pallet found by the packing check
+type PackingIssue struct {
+	BoxNumber    int    `json:"box_number,omitempty"`
+	PalletNumber int    `json:"pallet_number,omitempty"`
+	ProductID    int    `json:"product_id,omitempty"`
+	Message      string `json:"message"`
+}
+
+func (s *Shipment) Validate() error {
+	return validation.ValidateStruct(
+		s,
+		validation.Field(&s.UserID, validation.Required),
+		validation.Field(&s.WarehouseID, validation.Required),
+		validation.Field(&s.MarketPlaceID, validation.Required, validation.In(MarketPlaceOzon, MarketPlaceWildberries)),
+		validation.Field(&s.PlannedDate, validation.Required),
+		validation.Field(&s.Boxes, validation.Required, validation.By(checkBoxNumbers)),
+	)
+}
+
+func (b *ShipmentBox) Validate() error {
+	return validation.ValidateStruct(
+		b,
+		validation.Field(&b.BoxNumber, validation.Required, validation.Min(1)),
+		validation.Field(&b.PalletNumber, validation.Min(0)),
+		validation.Field(&b.Length, validation.Required, validation.Min(1)),
+		validation.Field(&b.Width, validation.Required, validation.Min(1)),
+		validation.Field(&b.Height, validation.Required, validation.Min(1)),
+		validation.Field(&b.Items, validation.Required, validation.By(checkShipmentItems)),
+	)
+}
+
+// CanChange reports whether the shipment in its current status can get the status
+func (s *Shipment) CanChange(status string) bool {
+	switch status {
+	case ShipmentDraft, ShipmentShipped:
+		return s.Status == ShipmentReady
+	case ShipmentReady:
+		return s.Status == ShipmentDraft
+	case ShipmentAccepted:
+		return s.Status == ShipmentShipped
+	case ShipmentCancelled:
+		return s.Status == ShipmentDraft || s.Status == ShipmentReady
+	}
+
+	return false
+}
+
+// Quantities returns the number of units of every product in all boxes
+func (s *Shipment) Quantities() map[int]int {
+	q := make(map[int]int)
+	for _, b := range s.Boxes {
+		for _, item := range b.Items {
+			q[item.ProductID] += item.Quantity
+		}
+	}
+
+	return q
+}
+
+// CheckPacking checks that every product fits into its box, the box contents fit by volume and
+// weight and pallets stay within the pallet limits. Products are packed in their own packages.
+func (s *Shipment) CheckPacking(products map[int]*Product, rule *LogisticsRule) []*PackingIssue {
+	issues := make([]*PackingIssue, 0)
+	palletWeight := make(map[int]float64)
+	palletVolume := make(map[int]float64)
+
+	for _, b := range s.Boxes {
+		box := sortedSides(float64(b.Length), float64(b.Width), float64(b.Height))
+		boxVolume := box[0] * box[1] * box[2]
+		if rule != nil && rule.MaxSideCm > 0 && box[0] > rule.MaxSideCm*10 {
+			issues = append(issues, &PackingIssue{
+				BoxNumber: b.BoxNumber,
+				Message:   fmt.Sprintf("longest side of the box exceeds %g cm", rule.MaxSideCm),
+			})
+		}
+
+		volume, weight := 0.0, 0.0
+		for _, item := range b.Items {
+			p, ok := products[item.ProductID]
+			if !ok {
+				issues = append(issues, &PackingIssue{BoxNumber: b.BoxNumber, ProductID: item.ProductID, Message: "unknown product"})
+				continue
+			}
+
+			side := sortedSides(float64(p.Lenght), float64(p.Width), float64(p.Height))
+			if side[2] <= 0 {
+				issues = append(issues, &PackingIssue{
+					BoxNumber: b.BoxNumber,
+					ProductID: p.ProductID,
+					Message:   "product dimensions are not set",
+				})
+				continue
+			}
+
+			if side[0] > box[0] || side[1] > box[1] || side[2] > box[2] {
+				issues = append(issues, &PackingIssue{
+					BoxNumber: b.BoxNumber,
+					ProductID: p.ProductID,
+					Message:   "product doesn't fit into the box",
+				})
+			}
+
+			volume += side[0] * side[1] * side[2] * float64(item.Quantity)
+			weight += float64(p.Weight) / 1000 * float64(item.Quantity)
+		}
+
+		if volume > boxVolume {
+			issues = append(issues, &PackingIssue{
+				BoxNumber: b.BoxNumber,
+				Message:   fmt.Sprintf("products take %.1f L, the box holds %.1f L", volume/1e6, boxVolume/1e6),
+			})
+		}
+		if rule != nil && rule.MaxWeightKg > 0 && weight > rule.MaxWeightKg {
+			issues = append(issues, &PackingIssue{
+				BoxNumber: b.BoxNumber,
+				Message:   fmt.Sprintf("box weighs %.1f kg, no more than %g kg is allowed", weight, rule.MaxWeightKg),
+			})
+		}
+
+		if b.PalletNumber != 0 {
+			palletWeight[b.PalletNumber] += weight
+			palletVolume[b.PalletNumber] += boxVolume
+		}
+	}
+
+	pallets := make([]int, 0, len(palletWeight))
+	for n := range palletWeight {
+		pallets = append(pallets, n)
+	}
+	sort.Ints(pallets)
+
+	for _, n := range pallets {
+		if palletWeight[n] > PalletMaxWeightKg {
+			issues = append(issues, &PackingIssue{
+				PalletNumber: n,
+				Message:      fmt.Sprintf("pallet weighs %.1f kg, no more than %d kg is allowed", palletWeight[n], PalletMaxWeightKg),
+			})
+		}
+		if palletVolume[n] > PalletLength*PalletWidth*PalletMaxHeight {
+			issues = append(issues, &PackingIssue{
+				PalletNumber: n,
+				Message:      fmt.Sprintf("boxes don't fit on a pallet under %d mm high", PalletMaxHeight),
+			})
+		}
+	}
+
+	return issues
+}
+
+// Pallets returns the number of pallets of the shipment
+func (s *Shipment) Pallets() int {
+	pallets := make(map[int]bool)
+	for _, b := range s.Boxes {
+		if b.PalletNumber != 0 {
+			pallets[b.PalletNumber] = true
+		}
+	}
+
+	return len(pallets)
+}
+
+func sortedSides(a, b, c float64) [3]float64 {
+	sides := []float64{a, b, c}
+	sort.Sort(sort.Reverse(sort.Float64Slice(sides)))
+	return [3]float64{sides[0], sides[1], sides[2]}
+}
+
+func checkBoxNumbers(value interface{}) error {
+	boxes, _ := value.([]*ShipmentBox)
+	seen := make(map[int]bool)
+	for _, b := range boxes {
+		if err := b.Validate(); err != nil {
+			return fmt.Errorf("box %d: %w", b.BoxNumber, err)
+		}
+		if seen[b.BoxNumber] {
+			return fmt.Errorf("box %d is listed more than once", b.BoxNumber)
+		}
+		seen[b.BoxNumber] = true
+	}
+
+	return nil
+}
+
+func checkShipmentItems(value interface{}) error {
+	items, _ := value.([]*ShipmentItem)
+	seen := make(map[int]bool)
+	for _, item := range items {
+		if item.ProductID == 0 {
+			return errors.New("product is required")
+		}
+		if item.Quantity <= 0 {
+			return errors.New("quantity must be positive")
+		}
+		if seen[item.ProductID] {
+			return errors.New("product is listed more than once")
+		}
+		seen[item.ProductID] = true
+	}
+
+	return nil
+}
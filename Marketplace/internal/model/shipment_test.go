@@ -0,0 +1,192 @@
+package model_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShipment_Validate(t *testing.T) {
+	testCases := []struct {
+		name    string
+		s       func() *model.Shipment
+		isValid bool
+	}{
+		{
+			name: "valid",
+			s: func() *model.Shipment {
+				return model.TestShipment(t)
+			},
+			isValid: true,
+		},
+		{
+			name: "no boxes",
+			s: func() *model.Shipment {
+				s := model.TestShipment(t)
+				s.Boxes = nil
+				return s
+			},
+			isValid: false,
+		},
+		{
+			name: "duplicate box number",
+			s: func() *model.Shipment {
+				s := model.TestShipment(t)
+				box := *s.Boxes[0]
+				s.Boxes = append(s.Boxes, &box)
+				return s
+			},
+			isValid: false,
+		},
+		{
+			name: "box without size",
+			s: func() *model.Shipment {
+				s := model.TestShipment(t)
+				s.Boxes[0].Height = 0
+				return s
+			},
+			isValid: false,
+		},
+		{
+			name: "empty box",
+			s: func() *model.Shipment {
+				s := model.TestShipment(t)
+				s.Boxes[0].Items = nil
+				return s
+			},
+			isValid: false,
+		},
+		{
+			name: "no planned date",
+			s: func() *model.Shipment {
+				s := model.TestShipment(t)
+				s.PlannedDate = time.Time{}
+				return s
+			},
+			isValid: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.isValid {
+				assert.NoError(t, tc.s().Validate())
+			} else {
+				assert.Error(t, tc.s().Validate())
+			}
+		})
+	}
+}
+
+func TestShipment_CheckPacking(t *testing.T) {
+	p := model.TestProduct(t)
+	p.ProductID = 1
+	products := map[int]*model.Product{1: p}
+	rule := model.DefaultLogisticsRules[0]
+
+	testCases := []struct {
+		name     string
+		s        func() *model.Shipment
+		messages []string
+	}{
+		{
+			name: "fits",
+			s: func() *model.Shipment {
+				return model.TestShipment(t)
+			},
+		},
+		{
+			name: "too many by volume",
+			s: func() *model.Shipment {
+				s := model.TestShipment(t)
+				s.Boxes[0].Items[0].Quantity = 30
+				return s
+			},
+			messages: []string{"products take 27.0 L, the box holds 24.0 L"},
+		},
+		{
+			name: "too heavy",
+			s: func() *model.Shipment {
+				s := model.TestShipment(t)
+				s.Boxes[0].Length = 1000
+				s.Boxes[0].Items[0].Quantity = 60
+				return s
+			},
+			messages: []string{"box weighs 30.0 kg, no more than 25 kg is allowed"},
+		},
+		{
+			name: "product doesn't fit",
+			s: func() *model.Shipment {
+				s := model.TestShipment(t)
+				s.Boxes[0].Length, s.Boxes[0].Width, s.Boxes[0].Height = 250, 250, 250
+				s.Boxes[0].Items[0].Quantity = 1
+				return s
+			},
+			messages: []string{"product doesn't fit into the box"},
+		},
+		{
+			name: "unknown product",
+			s: func() *model.Shipment {
+				s := model.TestShipment(t)
+				s.Boxes[0].Items[0].ProductID = 2
+				return s
+			},
+			messages: []string{"unknown product"},
+		},
+		{
+			name: "pallet too heavy",
+			s: func() *model.Shipment {
+				s := model.TestShipment(t)
+				s.Boxes = nil
+				for i := 1; i <= 45; i++ {
+					s.Boxes = append(s.Boxes, &model.ShipmentBox{
+						BoxNumber:    i,
+						PalletNumber: 2,
+						Length:       600,
+						Width:        400,
+						Height:       300,
+						Items:        []*model.ShipmentItem{{ProductID: 1, Quantity: 24}},
+					})
+				}
+				return s
+			},
+			messages: []string{
+				"pallet weighs 540.0 kg, no more than 500 kg is allowed",
+				"boxes don't fit on a pallet under 1800 mm high",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			issues := tc.s().CheckPacking(products, rule)
+			messages := make([]string, 0)
+			for _, i := range issues {
+				messages = append(messages, i.Message)
+			}
+			if tc.messages == nil {
+				assert.Empty(t, messages)
+			} else {
+				assert.Equal(t, tc.messages, messages)
+			}
+		})
+	}
+}
+
+func TestShipment_CanChange(t *testing.T) {
+	s := model.TestShipment(t)
+	s.Status = model.ShipmentDraft
+	assert.True(t, s.CanChange(model.ShipmentReady))
+	assert.False(t, s.CanChange(model.ShipmentShipped))
+
+	s.Status = model.ShipmentReady
+	assert.True(t, s.CanChange(model.ShipmentDraft))
+	assert.True(t, s.CanChange(model.ShipmentShipped))
+	assert.True(t, s.CanChange(model.ShipmentCancelled))
+
+	s.Status = model.ShipmentShipped
+	assert.True(t, s.CanChange(model.ShipmentAccepted))
+	assert.False(t, s.CanChange(model.ShipmentCancelled))
+}
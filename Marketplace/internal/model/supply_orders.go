@@ -2,34 +2,83 @@ package model
 
 import (
 	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+)
+
+const (
+	SupplyOrderStatusDraft     = 1
+	SupplyOrderStatusOrdered   = 2
+	SupplyOrderStatusShipped   = 3
+	SupplyOrderStatusReceived  = 4
+	SupplyOrderStatusCancelled = 5
 )
 
+// CurrencyRUB is the default currency of supply orders
+const CurrencyRUB = 1
+
 type SupplyOrder struct {
-	SupplyOrderID          int
-	OrderDate              time.Time
-	SupplierID             int
-	ShippingCostToLogistic float32
-	ShippingCostByLogistic float32
-	Active                 bool
+	SupplyOrderID          int                   `json:"supply_order_id"`
+	OrderDate              time.Time             `json:"order_date"`
+	SupplierID             int                   `json:"supplier_id"`
+	UserID                 int                   `json:"-"`
+	StatusID               int                   `json:"status_id"`
+	ShippingCostToLogistic float32               `json:"shipping_cost_to_logistic"`
+	ShippingCostByLogistic float32               `json:"shipping_cost_by_logistic"`
+	Active                 bool                  `json:"-"`
+	Products               []*SupplyOrderProduct `json:"products"`
 }
 
 type SupplyOrderProduct struct {
-	SupplyOrderProductID int
-	SupplyOrderID        int
-	ProductID            int
-	Quantity             float32
-	UnitPrice            float32
-	CurrencyID           int
+	SupplyOrderProductID int     `json:"supply_order_product_id"`
+	SupplyOrderID        int     `json:"supply_order_id"`
+	ProductID            int     `json:"product_id"`
+	Quantity             float32 `json:"quantity"`
+	UnitPrice            float32 `json:"unit_price"`
+	CurrencyID           int     `json:"currency_id"`
 }
 
 type Supplier struct {
-	SupplierID            int
-	SupplierName          string
-	SupplierAddress       string
-	SupplierCountryID     int
-	SupplierSWIFT         string
-	SupplierAccountNumber int
-	Active                bool
+	SupplierID            int    `json:"supplier_id"`
+	SupplierName          string `json:"supplier_name"`
+	SupplierAddress       string `json:"supplier_address"`
+	SupplierCountryID     int    `json:"supplier_country_id"`
+	SupplierSWIFT         string `json:"supplier_swift"`
+	SupplierAccountNumber int    `json:"supplier_account_number"`
+	UserID                int    `json:"-"`
+	// LeadTimeDays is the time from placing an order to the goods arriving at the warehouse
+	LeadTimeDays int  `json:"lead_time_days"`
+	Active       bool `json:"-"`
+}
+
+func (s *Supplier) Validate() error {
+	return validation.ValidateStruct(
+		s,
+		validation.Field(&s.SupplierName, validation.Required, validation.Length(1, 200)),
+		validation.Field(&s.SupplierAddress, validation.Length(0, 500)),
+		validation.Field(&s.UserID, validation.Required),
+		validation.Field(&s.LeadTimeDays, validation.Min(0), validation.Max(365)),
+	)
+}
+
+func (o *SupplyOrder) Validate() error {
+	return validation.ValidateStruct(
+		o,
+		validation.Field(&o.SupplierID, validation.Required),
+		validation.Field(&o.UserID, validation.Required),
+		validation.Field(&o.StatusID, validation.Required),
+		validation.Field(&o.Products, validation.Required),
+	)
+}
+
+func (p SupplyOrderProduct) Validate() error {
+	return validation.ValidateStruct(
+		&p,
+		validation.Field(&p.ProductID, validation.Required),
+		validation.Field(&p.Quantity, validation.Required, validation.Min(float32(0))),
+		validation.Field(&p.UnitPrice, validation.Min(float32(0))),
+		validation.Field(&p.CurrencyID, validation.Required),
+	)
 }
 
 type SupplyOrderStatus struct {
@@ -0,0 +1,166 @@
+package model
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+)
+
+// Tariff is a versioned set of marketplace fees loaded from a config file
+type Tariff struct {
+	MarketPlaceID int                `json:"marketplace_id" toml:"marketplace_id"`
+	Name          string             `json:"name" toml:"name"`
+	Version       string             `json:"version" toml:"version"`
+	EffectiveFrom time.Time          `json:"effective_from" toml:"effective_from"`
+	Commission    CommissionTariff   `json:"commission" toml:"commission"`
+	Logistics     []*LogisticsTariff `json:"logistics" toml:"logistics"`
+	// ExtraLiterPrice is charged for every started liter above the largest logistics bracket
+	ExtraLiterPrice float64 `json:"extra_liter_price" toml:"extra_liter_price"`
+}
+
+type CommissionTariff struct {
+	DefaultPercent float64            `json:"default_percent" toml:"default_percent"`
+	Categories     []*CategoryPercent `json:"categories" toml:"categories"`
+}
+
+type CategoryPercent struct {
+	CategoryID int     `json:"category_id" toml:"category_id"`
+	Percent    float64 `json:"percent" toml:"percent"`
+}
+
+// LogisticsTariff is the delivery price for packages up to MaxVolumeLiters
+type LogisticsTariff struct {
+	MaxVolumeLiters float64 `json:"max_volume_liters" toml:"max_volume_liters"`
+	Price           float64 `json:"price" toml:"price"`
+}
+
+// FeeEstimate is the expected marketplace fees and the seller payout for the sale price
+type FeeEstimate struct {
+	MarketPlaceID     int     `json:"marketplace_id"`
+	MarketPlace       string  `json:"marketplace"`
+	TariffVersion     string  `json:"tariff_version"`
+	Price             float64 `json:"price"`
+	CommissionPercent float64 `json:"commission_percent"`
+	Commission        float64 `json:"commission"`
+	VolumeLiters      float64 `json:"volume_liters"`
+	Logistics         float64 `json:"logistics"`
+	Payout            float64 `json:"payout"`
+}
+
+func (t *Tariff) Validate() error {
+	return validation.ValidateStruct(
+		t,
+		validation.Field(&t.MarketPlaceID, validation.Required),
+		validation.Field(&t.Version, validation.Required),
+		validation.Field(&t.EffectiveFrom, validation.Required),
+		validation.Field(&t.Commission, validation.By(checkCommission)),
+		validation.Field(&t.Logistics, validation.Required, validation.By(checkLogisticsBrackets)),
+		validation.Field(&t.ExtraLiterPrice, validation.Min(0.0)),
+	)
+}
+
+// CommissionPercent returns the commission of the nearest category in the path that has its own rate
+func (t *Tariff) CommissionPercent(categoryPath []int) float64 {
+	for _, categoryID := range categoryPath {
+		for _, c := range t.Commission.Categories {
+			if c.CategoryID == categoryID {
+				return c.Percent
+			}
+		}
+	}
+
+	return t.Commission.DefaultPercent
+}
+
+// LogisticsPrice returns the delivery price for the package volume
+func (t *Tariff) LogisticsPrice(volumeLiters float64) float64 {
+	for _, l := range t.Logistics {
+		if volumeLiters <= l.MaxVolumeLiters {
+			return l.Price
+		}
+	}
+
+	last := t.Logistics[len(t.Logistics)-1]
+	extra := math.Ceil(volumeLiters - last.MaxVolumeLiters)
+
+	return last.Price + extra*t.ExtraLiterPrice
+}
+
+// Estimate calculates fees for the product package of the volume sold at the price
+func (t *Tariff) Estimate(price float64, volumeLiters float64, categoryPath []int) *FeeEstimate {
+	e := &FeeEstimate{
+		MarketPlaceID:     t.MarketPlaceID,
+		MarketPlace:       t.Name,
+		TariffVersion:     t.Version,
+		Price:             price,
+		CommissionPercent: t.CommissionPercent(categoryPath),
+		VolumeLiters:      volumeLiters,
+		Logistics:         roundMoney(t.LogisticsPrice(volumeLiters)),
+	}
+
+	e.Commission = roundMoney(price * e.CommissionPercent / 100)
+	e.Payout = roundMoney(price - e.Commission - e.Logistics)
+
+	return e
+}
+
+// ActiveTariffs selects for every marketplace the latest tariff effective at the moment
+func ActiveTariffs(tariffs []*Tariff, at time.Time) []*Tariff {
+	active := make(map[int]*Tariff)
+	order := make([]int, 0)
+	for _, t := range tariffs {
+		if t.EffectiveFrom.After(at) {
+			continue
+		}
+
+		current, ok := active[t.MarketPlaceID]
+		if !ok {
+			order = append(order, t.MarketPlaceID)
+		}
+		if !ok || t.EffectiveFrom.After(current.EffectiveFrom) {
+			active[t.MarketPlaceID] = t
+		}
+	}
+
+	result := make([]*Tariff, 0, len(order))
+	for _, id := range order {
+		result = append(result, active[id])
+	}
+
+	return result
+}
+
+func checkCommission(value interface{}) error {
+	c, _ := value.(CommissionTariff)
+	if c.DefaultPercent < 0 || c.DefaultPercent > 100 {
+		return errors.New("commission percent must be between 0 and 100")
+	}
+
+	for _, category := range c.Categories {
+		if category.Percent < 0 || category.Percent > 100 {
+			return errors.New("commission percent must be between 0 and 100")
+		}
+	}
+
+	return nil
+}
+
+func checkLogisticsBrackets(value interface{}) error {
+	brackets, _ := value.([]*LogisticsTariff)
+	for i, b := range brackets {
+		if b.Price < 0 {
+			return errors.New("logistics price can't be negative")
+		}
+		if i > 0 && b.MaxVolumeLiters <= brackets[i-1].MaxVolumeLiters {
+			return errors.New("logistics brackets must be sorted by volume")
+		}
+	}
+
+	return nil
+}
+
+func roundMoney(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,130 @@
+package model_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTariff_Validate(t *testing.T) {
+	testCases := []struct {
+		name    string
+		t       func() *model.Tariff
+		isValid bool
+	}{
+		{
+			name: "valid",
+			t: func() *model.Tariff {
+				return model.TestTariff(t)
+			},
+			isValid: true,
+		},
+		{
+			name: "no version",
+			t: func() *model.Tariff {
+				tariff := model.TestTariff(t)
+				tariff.Version = ""
+				return tariff
+			},
+			isValid: false,
+		},
+		{
+			name: "commission over 100",
+			t: func() *model.Tariff {
+				tariff := model.TestTariff(t)
+				tariff.Commission.Categories[0].Percent = 120
+				return tariff
+			},
+			isValid: false,
+		},
+		{
+			name: "unsorted logistics",
+			t: func() *model.Tariff {
+				tariff := model.TestTariff(t)
+				tariff.Logistics[1].MaxVolumeLiters = 0.5
+				return tariff
+			},
+			isValid: false,
+		},
+		{
+			name: "no logistics",
+			t: func() *model.Tariff {
+				tariff := model.TestTariff(t)
+				tariff.Logistics = nil
+				return tariff
+			},
+			isValid: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.isValid {
+				assert.NoError(t, tc.t().Validate())
+			} else {
+				assert.Error(t, tc.t().Validate())
+			}
+		})
+	}
+}
+
+func TestTariff_CommissionPercent(t *testing.T) {
+	tariff := model.TestTariff(t)
+
+	assert.Equal(t, 12.0, tariff.CommissionPercent([]int{2}))
+	assert.Equal(t, 10.0, tariff.CommissionPercent([]int{105, 104, 100}))
+	assert.Equal(t, 15.0, tariff.CommissionPercent([]int{7}))
+	assert.Equal(t, 15.0, tariff.CommissionPercent(nil))
+}
+
+func TestTariff_LogisticsPrice(t *testing.T) {
+	tariff := model.TestTariff(t)
+
+	assert.Equal(t, 50.0, tariff.LogisticsPrice(0.9))
+	assert.Equal(t, 50.0, tariff.LogisticsPrice(1))
+	assert.Equal(t, 70.0, tariff.LogisticsPrice(1.2))
+	assert.Equal(t, 90.0, tariff.LogisticsPrice(6.5))
+}
+
+func TestTariff_Estimate(t *testing.T) {
+	e := model.TestTariff(t).Estimate(999, 0.9, []int{105, 104})
+
+	assert.Equal(t, "2022-11", e.TariffVersion)
+	assert.Equal(t, 10.0, e.CommissionPercent)
+	assert.Equal(t, 99.9, e.Commission)
+	assert.Equal(t, 50.0, e.Logistics)
+	assert.Equal(t, 849.1, e.Payout)
+}
+
+func Test_ActiveTariffs(t *testing.T) {
+	old := model.TestTariff(t)
+	old.Version = "2022-06"
+	old.EffectiveFrom = time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	current := model.TestTariff(t)
+
+	next := model.TestTariff(t)
+	next.Version = "2023-01"
+	next.EffectiveFrom = time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	wb := model.TestTariff(t)
+	wb.MarketPlaceID = model.MarketPlaceWildberries
+	wb.Name = "wildberries"
+
+	tariffs := []*model.Tariff{old, next, current, wb}
+
+	active := model.ActiveTariffs(tariffs, time.Date(2022, 12, 15, 0, 0, 0, 0, time.UTC))
+	if assert.Len(t, active, 2) {
+		assert.Equal(t, "2022-11", active[0].Version)
+		assert.Equal(t, "wildberries", active[1].Name)
+	}
+
+	active = model.ActiveTariffs(tariffs, time.Date(2022, 7, 1, 0, 0, 0, 0, time.UTC))
+	if assert.Len(t, active, 1) {
+		assert.Equal(t, "2022-06", active[0].Version)
+	}
+
+	assert.Empty(t, model.ActiveTariffs(tariffs, time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)))
+}
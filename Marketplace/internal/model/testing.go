@@ -1,22 +1,28 @@
 package model
 
-import "testing"
+import (
+	"strings"
+	"testing"
+	"time"
+)
 
 func TestUser(t *testing.T) *User {
 	return &User{
-		Email:    "ex@test.org",
-		Password: "password",
-		UserRole: 2,
-		Active:   true,
+		Email:         "ex@test.org",
+		Password:      "password",
+		UserRole:      UserRoleSeller,
+		Active:        true,
+		EmailVerified: true,
 	}
 }
 
 func TestAdminUser(t *testing.T) *User {
 	return &User{
-		Email:    "ex@test.org",
-		Password: "password",
-		UserRole: 1,
-		Active:   true,
+		Email:         "ex@test.org",
+		Password:      "password",
+		UserRole:      UserRoleAdmin,
+		Active:        true,
+		EmailVerified: true,
 	}
 }
 
@@ -54,6 +60,23 @@ func TestProductWOSKU(t *testing.T) *Product {
 	}
 }
 
+func TestProductVariant(t *testing.T, parent *Product) *Product {
+	return &Product{
+		ProductName:       parent.ProductName + " XL",
+		CategoryID:        parent.CategoryID,
+		PiecesInPack:      1,
+		MaterialID:        parent.MaterialID,
+		Weight:            550,
+		Lenght:            210,
+		Width:             310,
+		Height:            15,
+		UserID:            parent.UserID,
+		Active:            true,
+		ParentProductID:   parent.ProductID,
+		VariantAttributes: map[string]string{"size": "XL", "color": "black"},
+	}
+}
+
 func TestCategory(t *testing.T) *Category {
 	return &Category{
 		CategoryName:     "Менажница Деревянная",
@@ -79,3 +102,164 @@ func TestMarketPlaceItem(t *testing.T) *MarketPlaceItem {
 		Active:        true,
 	}
 }
+
+func TestProductImage(t *testing.T) *ProductImage {
+	return &ProductImage{
+		ProductID:    1,
+		UserID:       1,
+		FileKey:      "products/1/image.png",
+		ThumbnailKey: "products/1/image_thumb.png",
+		ContentType:  "image/png",
+		Size:         1024,
+		Width:        800,
+		Height:       600,
+	}
+}
+
+func TestCategoryAttribute(t *testing.T) *CategoryAttribute {
+	return &CategoryAttribute{
+		CategoryID: 2,
+		Code:       "fabric",
+		Name:       "Ткань",
+		Type:       AttributeTypeString,
+		Required:   true,
+		Active:     true,
+	}
+}
+
+func TestBarcode(t *testing.T) *Barcode {
+	return &Barcode{
+		ProductID: 1,
+		UserID:    1,
+		Code:      "4006381333931",
+	}
+}
+
+func TestTariff(t *testing.T) *Tariff {
+	return &Tariff{
+		MarketPlaceID: MarketPlaceOzon,
+		Name:          "ozon",
+		Version:       "2022-11",
+		EffectiveFrom: time.Date(2022, 11, 1, 0, 0, 0, 0, time.UTC),
+		Commission: CommissionTariff{
+			DefaultPercent: 15,
+			Categories: []*CategoryPercent{
+				{CategoryID: 2, Percent: 12},
+				{CategoryID: 104, Percent: 10},
+			},
+		},
+		Logistics: []*LogisticsTariff{
+			{MaxVolumeLiters: 1, Price: 50},
+			{MaxVolumeLiters: 5, Price: 70},
+		},
+		ExtraLiterPrice: 10,
+	}
+}
+
+func TestPrice(t *testing.T) *Price {
+	return &Price{
+		ProductID:     1,
+		MarketPlaceID: MarketPlaceOzon,
+		UserID:        1,
+		ListPrice:     1200,
+		DiscountPrice: 1000,
+		MinPrice:      900,
+		LandedCost:    300,
+	}
+}
+
+func TestRepricingRule(t *testing.T) *RepricingRule {
+	return &RepricingRule{
+		UserID:           1,
+		Name:             "follow competitors",
+		Strategy:         RepricingFollowCompetitor,
+		CompetitorOffset: -10,
+		Active:           true,
+	}
+}
+
+func TestSupplier(t *testing.T) *Supplier {
+	return &Supplier{
+		SupplierName:    "Поставщик",
+		SupplierAddress: "Guangzhou",
+		UserID:          1,
+		LeadTimeDays:    21,
+	}
+}
+
+func TestWarehouse(t *testing.T) *Warehouse {
+	return &Warehouse{
+		UserID:  1,
+		Name:    "Склад Подольск",
+		Type:    WarehouseTypeOwn,
+		Address: "Подольск",
+	}
+}
+
+func TestShipment(t *testing.T) *Shipment {
+	return &Shipment{
+		UserID:        1,
+		WarehouseID:   1,
+		MarketPlaceID: MarketPlaceOzon,
+		PlannedDate:   time.Date(2022, 12, 5, 0, 0, 0, 0, time.UTC),
+		Boxes: []*ShipmentBox{
+			{
+				BoxNumber:    1,
+				PalletNumber: 1,
+				Length:       400,
+				Width:        300,
+				Height:       200,
+				Items:        []*ShipmentItem{{ProductID: 1, Quantity: 20}},
+			},
+		},
+	}
+}
+
+func TestSession(t *testing.T) *Session {
+	return &Session{
+		ID:        "5nKQ0oXb3yTz8cVvPp1dHs6fWmEa2rLgJuY7iN4kO9q",
+		UserID:    1,
+		CreatedAt: time.Date(2022, 12, 1, 10, 0, 0, 0, time.UTC),
+		ExpiresAt: time.Date(2022, 12, 31, 10, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestRefreshToken(t *testing.T) *RefreshToken {
+	return &RefreshToken{
+		ID:        "7d444840-9dc0-11d1-b245-5ffdce74fad2",
+		UserID:    1,
+		TokenHash: strings.Repeat("a", 64),
+		CreatedAt: time.Date(2022, 12, 5, 10, 0, 0, 0, time.UTC),
+		ExpiresAt: time.Date(2023, 1, 4, 10, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestAPIKey(t *testing.T) *APIKey {
+	return &APIKey{
+		UserID:    1,
+		Name:      "ERP",
+		Prefix:    "mpk_3f9a",
+		KeyHash:   strings.Repeat("c", 64),
+		Scopes:    []string{ScopeProductsRead},
+		CreatedAt: time.Date(2022, 12, 6, 10, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestUserToken(t *testing.T) *UserToken {
+	return &UserToken{
+		TokenHash: strings.Repeat("d", 64),
+		UserID:    1,
+		Purpose:   UserTokenVerifyEmail,
+		Email:     "ex@test.org",
+		CreatedAt: time.Date(2022, 12, 7, 10, 0, 0, 0, time.UTC),
+		ExpiresAt: time.Date(2022, 12, 9, 10, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestTwoFactor(t *testing.T) *TwoFactor {
+	return &TwoFactor{
+		UserID:    1,
+		Secret:    "JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP",
+		CreatedAt: time.Date(2022, 12, 9, 10, 0, 0, 0, time.UTC),
+	}
+}
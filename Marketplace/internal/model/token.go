@@ -0,0 +1,53 @@
+package model
+
+import (
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/go-ozzo/ozzo-validation/is"
+)
+
+const TokenTypeBearer = "Bearer"
+
+// RefreshToken is kept as a hash, the token itself is given to the client once. ID is the sid
+// claim of access tokens issued with it, SessionHash ties it to the cookie session of the sign-in.
+// ReplacedBy is the id of the token issued when this one was exchanged.
+type RefreshToken struct {
+	ID          string     `json:"id"`
+	UserID      int        `json:"user_id"`
+	TokenHash   string     `json:"-"`
+	SessionHash string     `json:"-"`
+	CreatedAt   time.Time  `json:"created_at"`
+	ExpiresAt   time.Time  `json:"expires_at"`
+	RevokedAt   *time.Time `json:"revoked_at"`
+	ReplacedBy  string     `json:"-"`
+}
+
+// TokenPair is the OAuth 2.0 style token response, ExpiresIn is in seconds
+type TokenPair struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+func (t *RefreshToken) Validate() error {
+	return validation.ValidateStruct(
+		t,
+		validation.Field(&t.ID, validation.Required, is.UUID),
+		validation.Field(&t.UserID, validation.Required),
+		validation.Field(&t.TokenHash, validation.Required, validation.Length(64, 64)),
+		validation.Field(&t.SessionHash, validation.Length(64, 64)),
+		validation.Field(&t.ExpiresAt, validation.Required),
+	)
+}
+
+// Rotated reports whether the token was exchanged for a new one
+func (t *RefreshToken) Rotated() bool {
+	return t.RevokedAt != nil && t.ReplacedBy != ""
+}
+
+// Usable reports whether the token can be exchanged at the time
+func (t *RefreshToken) Usable(at time.Time) bool {
+	return t.RevokedAt == nil && at.Before(t.ExpiresAt)
+}
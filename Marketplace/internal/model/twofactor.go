@@ -0,0 +1,60 @@
+package model
+
+import (
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+)
+
+// TwoFactor is the TOTP authenticator of a user, it is pending until the user confirms
+// a code and EnabledAt is set. LastStep is the time step of the last accepted code,
+// codes of that step and older are rejected.
+type TwoFactor struct {
+	UserID    int        `json:"-"`
+	Secret    string     `json:"-"`
+	LastStep  int64      `json:"-"`
+	CreatedAt time.Time  `json:"created_at"`
+	EnabledAt *time.Time `json:"enabled_at"`
+}
+
+// TwoFactorStatus is the 2FA state shown to the user
+type TwoFactorStatus struct {
+	Enabled           bool       `json:"enabled"`
+	Required          bool       `json:"required"`
+	EnabledAt         *time.Time `json:"enabled_at"`
+	RecoveryCodesLeft int        `json:"recovery_codes_left"`
+}
+
+// TwoFactorEnrollment is shown once when the user adds an authenticator, the client shows URI as a QR code
+type TwoFactorEnrollment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+}
+
+// TwoFactorChallenge answers a sign-in with the right password of a user with 2FA,
+// the client sends the token back with a code to get the session
+type TwoFactorChallenge struct {
+	TwoFactorRequired bool   `json:"two_factor_required"`
+	ChallengeToken    string `json:"challenge_token"`
+	ExpiresIn         int    `json:"expires_in"`
+}
+
+// RecoveryCode replaces a TOTP code once when the authenticator is lost, it is kept as a hash
+type RecoveryCode struct {
+	ID       int        `json:"id"`
+	UserID   int        `json:"user_id"`
+	CodeHash string     `json:"-"`
+	UsedAt   *time.Time `json:"used_at"`
+}
+
+func (t *TwoFactor) Validate() error {
+	return validation.ValidateStruct(
+		t,
+		validation.Field(&t.UserID, validation.Required),
+		validation.Field(&t.Secret, validation.Required, validation.Length(16, 64)),
+	)
+}
+
+func (t *TwoFactor) Enabled() bool {
+	return t != nil && t.EnabledAt != nil
+}
@@ -0,0 +1,65 @@
+package model_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTwoFactor_Validate(t *testing.T) {
+	testCases := []struct {
+		name    string
+		tf      func() *model.TwoFactor
+		isValid bool
+	}{
+		{
+			name: "valid",
+			tf: func() *model.TwoFactor {
+				return model.TestTwoFactor(t)
+			},
+			isValid: true,
+		},
+		{
+			name: "no user",
+			tf: func() *model.TwoFactor {
+				tf := model.TestTwoFactor(t)
+				tf.UserID = 0
+				return tf
+			},
+			isValid: false,
+		},
+		{
+			name: "short secret",
+			tf: func() *model.TwoFactor {
+				tf := model.TestTwoFactor(t)
+				tf.Secret = "JBSWY3DP"
+				return tf
+			},
+			isValid: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.isValid {
+				assert.NoError(t, tc.tf().Validate())
+			} else {
+				assert.Error(t, tc.tf().Validate())
+			}
+		})
+	}
+}
+
+func TestTwoFactor_Enabled(t *testing.T) {
+	tf := model.TestTwoFactor(t)
+	assert.False(t, tf.Enabled())
+
+	enabledAt := time.Now()
+	tf.EnabledAt = &enabledAt
+	assert.True(t, tf.Enabled())
+
+	var missing *model.TwoFactor
+	assert.False(t, missing.Enabled())
+}
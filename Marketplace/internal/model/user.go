@@ -3,7 +3,15 @@ package model
 import (
 	validation "github.com/go-ozzo/ozzo-validation"
 	"github.com/go-ozzo/ozzo-validation/is"
-	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	UserRoleAdmin  = 1
+	UserRoleSeller = 2
+
+	DefaultTimezone     = "Europe/Moscow"
+	DefaultBaseCurrency = "RUB"
+	DefaultLocale       = "ru-RU"
 )
 
 // User
@@ -14,19 +22,50 @@ type User struct {
 	EncryptedPassword string `json:"-"`
 	UserRole          int    `json:"userrole"`
 	Active            bool   `json:"active"`
+	EmailVerified     bool   `json:"email_verified"`
+	UserProfile
 }
 
 func (u *User) Validate() error {
 	return validation.ValidateStruct(
 		u,
 		validation.Field(&u.Email, validation.Required, is.Email),
-		validation.Field(&u.Password, validation.By(requiredIf(u.EncryptedPassword == "")), validation.Length(4, 50)),
+		validation.Field(&u.Password, validation.By(requiredIf(u.EncryptedPassword == "")), validation.Length(4, 128)),
 	)
 }
 
+// ValidatePassword checks a new password of an existing user, a password already encrypted
+// by the service is saved as it is. The password policy of the service is stricter.
+func (u *User) ValidatePassword() error {
+	return validation.Validate(u.Password, validation.By(requiredIf(u.EncryptedPassword == "")), validation.Length(4, 128))
+}
+
+// ValidateEmail checks a new email of an existing user
+func (u *User) ValidateEmail() error {
+	return validation.Validate(u.Email, validation.Required, is.Email)
+}
+
+// BeforeCreate fills the profile settings the user didn't choose
+func (u *User) BeforeCreate() {
+	if u.Timezone == "" {
+		u.Timezone = DefaultTimezone
+	}
+	if u.BaseCurrency == "" {
+		u.BaseCurrency = DefaultBaseCurrency
+	}
+	if u.Locale == "" {
+		u.Locale = DefaultLocale
+	}
+}
+
 func (u *User) EncryptPasswordBeforeCreate() error {
+	return u.EncryptPassword(DefaultPasswordHasher)
+}
+
+// EncryptPassword replaces the plain password with its hash
+func (u *User) EncryptPassword(h PasswordHasher) error {
 	if len(u.Password) > 0 {
-		encryptedString, err := encryptString(u.Password)
+		encryptedString, err := h.Hash(u.Password)
 		if err != nil {
 			return err
 		}
@@ -42,13 +81,5 @@ func (u *User) Sanitize() {
 }
 
 func (u *User) ComparePassword(password string) bool {
-	return bcrypt.CompareHashAndPassword([]byte(u.EncryptedPassword), []byte(password)) == nil
-}
-
-func encryptString(s string) (string, error) {
-	b, err := bcrypt.GenerateFromPassword([]byte(s), bcrypt.MinCost)
-	if err != nil {
-		return "", err
-	}
-	return string(b), nil
+	return DefaultPasswordHasher.Verify(u.EncryptedPassword, password)
 }
@@ -0,0 +1,47 @@
+package model
+
+import (
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/go-ozzo/ozzo-validation/is"
+)
+
+const (
+	UserTokenVerifyEmail   = "verify_email"
+	UserTokenResetPassword = "reset_password"
+	// UserTokenChangeEmail is sent to the new email of the user, Email holds the new address
+	UserTokenChangeEmail = "change_email"
+	// UserTokenTwoFactor is the sign-in challenge of a user who entered the password and
+	// still has to enter a TOTP or recovery code, it isn't sent by email
+	UserTokenTwoFactor = "two_factor"
+)
+
+// UserToken is a one-time token sent by email or given to the client, it is kept as a hash.
+// Email is the address of the user when the token was issued, a verification token confirms
+// that address only.
+type UserToken struct {
+	TokenHash string     `json:"-"`
+	UserID    int        `json:"user_id"`
+	Purpose   string     `json:"purpose"`
+	Email     string     `json:"email"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at"`
+}
+
+func (t *UserToken) Validate() error {
+	return validation.ValidateStruct(
+		t,
+		validation.Field(&t.TokenHash, validation.Required, validation.Length(64, 64)),
+		validation.Field(&t.UserID, validation.Required),
+		validation.Field(&t.Purpose, validation.Required, validation.In(UserTokenVerifyEmail, UserTokenResetPassword, UserTokenChangeEmail, UserTokenTwoFactor)),
+		validation.Field(&t.Email, validation.Required, is.Email),
+		validation.Field(&t.ExpiresAt, validation.Required),
+	)
+}
+
+// Usable reports whether the token can be used at the time
+func (t *UserToken) Usable(at time.Time) bool {
+	return t.UsedAt == nil && at.Before(t.ExpiresAt)
+}
@@ -0,0 +1,72 @@
+package model_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserToken_Validate(t *testing.T) {
+	testCases := []struct {
+		name    string
+		ut      func() *model.UserToken
+		isValid bool
+	}{
+		{
+			name: "valid",
+			ut: func() *model.UserToken {
+				return model.TestUserToken(t)
+			},
+			isValid: true,
+		},
+		{
+			name: "unknown purpose",
+			ut: func() *model.UserToken {
+				ut := model.TestUserToken(t)
+				ut.Purpose = "sign_in"
+				return ut
+			},
+			isValid: false,
+		},
+		{
+			name: "invalid email",
+			ut: func() *model.UserToken {
+				ut := model.TestUserToken(t)
+				ut.Email = "invalid"
+				return ut
+			},
+			isValid: false,
+		},
+		{
+			name: "short hash",
+			ut: func() *model.UserToken {
+				ut := model.TestUserToken(t)
+				ut.TokenHash = "d"
+				return ut
+			},
+			isValid: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.isValid {
+				assert.NoError(t, tc.ut().Validate())
+			} else {
+				assert.Error(t, tc.ut().Validate())
+			}
+		})
+	}
+}
+
+func TestUserToken_Usable(t *testing.T) {
+	ut := model.TestUserToken(t)
+	assert.True(t, ut.Usable(ut.CreatedAt))
+	assert.False(t, ut.Usable(ut.ExpiresAt))
+
+	used := ut.CreatedAt.Add(time.Minute)
+	ut.UsedAt = &used
+	assert.False(t, ut.Usable(ut.CreatedAt))
+}
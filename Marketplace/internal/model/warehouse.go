@@ -0,0 +1,137 @@
+package model
+
+import (
+	"errors"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+)
+
+const (
+	WarehouseTypeOwn = "own"
+	// WarehouseTypeMarketPlace is a fulfilment (FBO) warehouse operated by the marketplace
+	WarehouseTypeMarketPlace = "marketplace"
+)
+
+// DefaultWarehouseName is given to the own warehouse created for stock saved without a warehouse
+const DefaultWarehouseName = "Основной склад"
+
+const (
+	TransferDraft     = "draft"
+	TransferInTransit = "in_transit"
+	TransferReceived  = "received"
+	TransferCancelled = "cancelled"
+)
+
+type Warehouse struct {
+	WarehouseID   int    `json:"warehouse_id"`
+	UserID        int    `json:"-"`
+	Name          string `json:"name"`
+	Type          string `json:"type"`
+	MarketPlaceID int    `json:"marketplace_id"`
+	Address       string `json:"address"`
+	Active        bool   `json:"-"`
+}
+
+// Transfer moves stock between warehouses of the seller. Shipped items leave the source warehouse
+// and are in transit until the destination warehouse receives them.
+type Transfer struct {
+	TransferID      int             `json:"transfer_id"`
+	UserID          int             `json:"-"`
+	FromWarehouseID int             `json:"from_warehouse_id"`
+	ToWarehouseID   int             `json:"to_warehouse_id"`
+	Status          string          `json:"status"`
+	Items           []*TransferItem `json:"items"`
+	CreatedAt       time.Time       `json:"created_at"`
+	ShippedAt       *time.Time      `json:"shipped_at,omitempty"`
+	ReceivedAt      *time.Time      `json:"received_at,omitempty"`
+}
+
+type TransferItem struct {
+	ProductID int `json:"product_id"`
+	Quantity  int `json:"quantity"`
+}
+
+// StockReportLine is the stock of the product in every warehouse, in transit and in total
+type StockReportLine struct {
+	ProductID   int               `json:"product_id"`
+	ProductName string            `json:"product_name"`
+	Warehouses  []*WarehouseStock `json:"warehouses"`
+	InTransit   int               `json:"in_transit"`
+	Total       int               `json:"total"`
+}
+
+type WarehouseStock struct {
+	WarehouseID int    `json:"warehouse_id"`
+	Name        string `json:"name"`
+	Quantity    int    `json:"quantity"`
+}
+
+func (w *Warehouse) Validate() error {
+	return validation.ValidateStruct(
+		w,
+		validation.Field(&w.UserID, validation.Required),
+		validation.Field(&w.Name, validation.Required, validation.Length(1, 200)),
+		validation.Field(&w.Type, validation.Required, validation.In(WarehouseTypeOwn, WarehouseTypeMarketPlace)),
+		validation.Field(
+			&w.MarketPlaceID,
+			validation.By(requiredIf(w.Type == WarehouseTypeMarketPlace)),
+			validation.In(MarketPlaceOzon, MarketPlaceWildberries),
+		),
+		validation.Field(&w.Address, validation.Length(0, 500)),
+	)
+}
+
+func (t *Transfer) Validate() error {
+	return validation.ValidateStruct(
+		t,
+		validation.Field(&t.UserID, validation.Required),
+		validation.Field(&t.FromWarehouseID, validation.Required),
+		validation.Field(&t.ToWarehouseID, validation.Required, validation.By(checkDestination(t.FromWarehouseID))),
+		validation.Field(&t.Items, validation.Required, validation.By(checkTransferItems)),
+	)
+}
+
+// CanChange reports whether the transfer in its current status can get the status
+func (t *Transfer) CanChange(status string) bool {
+	switch status {
+	case TransferInTransit:
+		return t.Status == TransferDraft
+	case TransferReceived:
+		return t.Status == TransferInTransit
+	case TransferCancelled:
+		return t.Status == TransferDraft || t.Status == TransferInTransit
+	}
+
+	return false
+}
+
+func checkDestination(from int) validation.RuleFunc {
+	return func(value interface{}) error {
+		to, _ := value.(int)
+		if to == from {
+			return errors.New("destination must differ from the source warehouse")
+		}
+
+		return nil
+	}
+}
+
+func checkTransferItems(value interface{}) error {
+	items, _ := value.([]*TransferItem)
+	seen := make(map[int]bool)
+	for _, item := range items {
+		if item.ProductID == 0 {
+			return errors.New("product is required")
+		}
+		if item.Quantity <= 0 {
+			return errors.New("quantity must be positive")
+		}
+		if seen[item.ProductID] {
+			return errors.New("product is listed more than once")
+		}
+		seen[item.ProductID] = true
+	}
+
+	return nil
+}
@@ -0,0 +1,115 @@
+package model_test
+
+import (
+	"testing"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWarehouse_Validate(t *testing.T) {
+	testCases := []struct {
+		name    string
+		w       func() *model.Warehouse
+		isValid bool
+	}{
+		{
+			name: "valid own",
+			w: func() *model.Warehouse {
+				return model.TestWarehouse(t)
+			},
+			isValid: true,
+		},
+		{
+			name: "valid marketplace",
+			w: func() *model.Warehouse {
+				w := model.TestWarehouse(t)
+				w.Type = model.WarehouseTypeMarketPlace
+				w.MarketPlaceID = model.MarketPlaceOzon
+				return w
+			},
+			isValid: true,
+		},
+		{
+			name: "marketplace without marketplace id",
+			w: func() *model.Warehouse {
+				w := model.TestWarehouse(t)
+				w.Type = model.WarehouseTypeMarketPlace
+				return w
+			},
+			isValid: false,
+		},
+		{
+			name: "unknown type",
+			w: func() *model.Warehouse {
+				w := model.TestWarehouse(t)
+				w.Type = "rented"
+				return w
+			},
+			isValid: false,
+		},
+		{
+			name: "empty name",
+			w: func() *model.Warehouse {
+				w := model.TestWarehouse(t)
+				w.Name = ""
+				return w
+			},
+			isValid: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.isValid {
+				assert.NoError(t, tc.w().Validate())
+			} else {
+				assert.Error(t, tc.w().Validate())
+			}
+		})
+	}
+}
+
+func TestTransfer_Validate(t *testing.T) {
+	transfer := func() *model.Transfer {
+		return &model.Transfer{
+			UserID:          1,
+			FromWarehouseID: 1,
+			ToWarehouseID:   2,
+			Items:           []*model.TransferItem{{ProductID: 1, Quantity: 5}},
+		}
+	}
+
+	assert.NoError(t, transfer().Validate())
+
+	same := transfer()
+	same.ToWarehouseID = 1
+	assert.Error(t, same.Validate())
+
+	empty := transfer()
+	empty.Items = nil
+	assert.Error(t, empty.Validate())
+
+	zero := transfer()
+	zero.Items[0].Quantity = 0
+	assert.Error(t, zero.Validate())
+
+	duplicate := transfer()
+	duplicate.Items = append(duplicate.Items, &model.TransferItem{ProductID: 1, Quantity: 2})
+	assert.Error(t, duplicate.Validate())
+}
+
+func TestTransfer_CanChange(t *testing.T) {
+	tr := &model.Transfer{Status: model.TransferDraft}
+	assert.True(t, tr.CanChange(model.TransferInTransit))
+	assert.True(t, tr.CanChange(model.TransferCancelled))
+	assert.False(t, tr.CanChange(model.TransferReceived))
+
+	tr.Status = model.TransferInTransit
+	assert.True(t, tr.CanChange(model.TransferReceived))
+	assert.True(t, tr.CanChange(model.TransferCancelled))
+	assert.False(t, tr.CanChange(model.TransferInTransit))
+
+	tr.Status = model.TransferReceived
+	assert.False(t, tr.CanChange(model.TransferCancelled))
+}
@@ -0,0 +1,156 @@
+// Package local implements the session manager of the auth service inside the API server,
+// so it can run without the external service. Sessions are kept in a store.SessionRepo:
+// the PostgreSQL one of sqlstore or the in-memory MemoryStore. The repo holds only hashes
+// of session ids, so its content can't be used as session cookies.
+package local
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"time"
+
+	"github.com/VladimirBlinov/AuthService/pkg/authservice"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// sessionIDBytes is the number of random bytes of a session id, it is sent base64 encoded
+const sessionIDBytes = 32
+
+// Client is an authservice.AuthServiceClient answering from the session repo. Like the remote
+// service it returns codes.NotFound for unknown and expired sessions.
+type Client struct {
+	sessions store.SessionRepo
+	ttl      time.Duration
+	now      func() time.Time
+}
+
+func NewClient(sessions store.SessionRepo, ttl time.Duration) *Client {
+	return &Client{
+		sessions: sessions,
+		ttl:      ttl,
+		now:      time.Now,
+	}
+}
+
+func (c *Client) Create(ctx context.Context, in *authservice.Session, opts ...grpc.CallOption) (*authservice.SessionID, error) {
+	id, err := newSessionID()
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	now := c.now().UTC()
+	s := &model.Session{
+		ID:        hashID(id),
+		UserID:    int(in.UserID),
+		CreatedAt: now,
+		ExpiresAt: now.Add(c.ttl),
+	}
+	if err = c.sessions.Create(s); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &authservice.SessionID{ID: id}, nil
+}
+
+func (c *Client) Check(ctx context.Context, in *authservice.SessionID, opts ...grpc.CallOption) (*authservice.Session, error) {
+	s, err := c.find(in.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &authservice.Session{UserID: int32(s.UserID)}, nil
+}
+
+func (c *Client) Delete(ctx context.Context, in *authservice.SessionID, opts ...grpc.CallOption) (*authservice.Nothing, error) {
+	if err := c.sessions.Delete(hashID(in.ID)); err != nil {
+		return &authservice.Nothing{Dummy: false}, sessionError(err)
+	}
+
+	return &authservice.Nothing{Dummy: true}, nil
+}
+
+// Find returns the session unless it is missing or expired, the ID of the session is the hash of sessionId
+func (c *Client) Find(sessionId string) (*model.Session, error) {
+	return c.find(sessionId)
+}
+
+// FindByUserId returns the sessions of the user that have not expired, recently used first
+func (c *Client) FindByUserId(userId int) ([]*model.Session, error) {
+	sessions, err := c.sessions.FindByUserId(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	now := c.now()
+	active := make([]*model.Session, 0, len(sessions))
+	for _, s := range sessions {
+		if !s.Expired(now) {
+			active = append(active, s)
+		}
+	}
+
+	return active, nil
+}
+
+// Touch saves the time and the client of the last request of the session
+func (c *Client) Touch(s *model.Session) error {
+	return c.sessions.Touch(s)
+}
+
+// DeleteByUserId ends all sessions of the user and returns their number
+func (c *Client) DeleteByUserId(userId int) (int, error) {
+	return c.sessions.DeleteByUserId(userId)
+}
+
+// DeleteExpired removes expired sessions from the repo and returns their number
+func (c *Client) DeleteExpired() (int, error) {
+	return c.sessions.DeleteExpired(c.now())
+}
+
+// find returns the session unless it is missing or expired, an expired session is removed
+func (c *Client) find(id string) (*model.Session, error) {
+	id = hashID(id)
+	s, err := c.sessions.Find(id)
+	if err != nil {
+		return nil, sessionError(err)
+	}
+
+	if s.Expired(c.now()) {
+		if err = c.sessions.Delete(id); err != nil && err != store.ErrRecordNotFound {
+			return nil, sessionError(err)
+		}
+		return nil, status.Error(codes.NotFound, "session expired")
+	}
+
+	return s, nil
+}
+
+func sessionError(err error) error {
+	if err == store.ErrRecordNotFound {
+		return status.Error(codes.NotFound, "session not found")
+	}
+
+	return status.Error(codes.Internal, err.Error())
+}
+
+func newSessionID() (string, error) {
+	b := make([]byte, sessionIDBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashID returns the form of the session id kept in the repo
+func hashID(id string) string {
+	sum := sha256.Sum256([]byte(id))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,76 @@
+package local
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/VladimirBlinov/AuthService/pkg/authservice"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestClient(t *testing.T) {
+	now := time.Date(2022, 12, 2, 10, 0, 0, 0, time.UTC)
+	c := NewClient(NewMemoryStore(), time.Hour)
+	c.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	id, err := c.Create(ctx, &authservice.Session{UserID: 7})
+	assert.NoError(t, err)
+	assert.Len(t, id.ID, 43)
+
+	other, err := c.Create(ctx, &authservice.Session{UserID: 7})
+	assert.NoError(t, err)
+	assert.NotEqual(t, id.ID, other.ID)
+
+	_, err = c.sessions.Find(id.ID)
+	assert.Error(t, err)
+	stored, err := c.sessions.Find(hashID(id.ID))
+	assert.NoError(t, err)
+	assert.Equal(t, 7, stored.UserID)
+
+	s, err := c.Check(ctx, id)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(7), s.UserID)
+
+	deleted, err := c.Delete(ctx, id)
+	assert.NoError(t, err)
+	assert.True(t, deleted.Dummy)
+
+	_, err = c.Check(ctx, id)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	deleted, err = c.Delete(ctx, id)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	assert.False(t, deleted.Dummy)
+
+	now = now.Add(time.Hour)
+	_, err = c.Check(ctx, other)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestClient_DeleteExpired(t *testing.T) {
+	now := time.Date(2022, 12, 2, 10, 0, 0, 0, time.UTC)
+	c := NewClient(NewMemoryStore(), time.Hour)
+	c.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	old, err := c.Create(ctx, &authservice.Session{UserID: 1})
+	assert.NoError(t, err)
+
+	now = now.Add(30 * time.Minute)
+	fresh, err := c.Create(ctx, &authservice.Session{UserID: 1})
+	assert.NoError(t, err)
+
+	now = now.Add(30 * time.Minute)
+	n, err := c.DeleteExpired()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	_, err = c.sessions.Find(hashID(old.ID))
+	assert.Error(t, err)
+	_, err = c.Check(ctx, fresh)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,138 @@
+package local
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store"
+)
+
+// MemoryStore is a store.SessionRepo in the process memory, sessions are lost on restart
+type MemoryStore struct {
+	mu       sync.RWMutex
+	sessions map[string]*model.Session
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		sessions: make(map[string]*model.Session),
+	}
+}
+
+func (m *MemoryStore) Create(s *model.Session) error {
+	if err := s.Validate(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.sessions[s.ID]; ok {
+		return store.ErrRecordExists
+	}
+
+	if s.LastSeenAt.IsZero() {
+		s.LastSeenAt = s.CreatedAt
+	}
+
+	stored := *s
+	m.sessions[s.ID] = &stored
+
+	return nil
+}
+
+func (m *MemoryStore) Find(sessionId string) (*model.Session, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	s, ok := m.sessions[sessionId]
+	if !ok {
+		return nil, store.ErrRecordNotFound
+	}
+
+	found := *s
+	return &found, nil
+}
+
+func (m *MemoryStore) FindByUserId(userId int) ([]*model.Session, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	sessions := make([]*model.Session, 0)
+	for _, s := range m.sessions {
+		if s.UserID == userId {
+			found := *s
+			sessions = append(sessions, &found)
+		}
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].LastSeenAt.After(sessions[j].LastSeenAt)
+	})
+
+	return sessions, nil
+}
+
+func (m *MemoryStore) Touch(s *model.Session) error {
+	if err := s.Validate(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stored, ok := m.sessions[s.ID]
+	if !ok {
+		return store.ErrRecordNotFound
+	}
+
+	stored.LastSeenAt = s.LastSeenAt
+	stored.UserAgent = s.UserAgent
+	stored.IP = s.IP
+
+	return nil
+}
+
+func (m *MemoryStore) Delete(sessionId string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.sessions[sessionId]; !ok {
+		return store.ErrRecordNotFound
+	}
+
+	delete(m.sessions, sessionId)
+	return nil
+}
+
+func (m *MemoryStore) DeleteByUserId(userId int) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	n := 0
+	for id, s := range m.sessions {
+		if s.UserID == userId {
+			delete(m.sessions, id)
+			n++
+		}
+	}
+
+	return n, nil
+}
+
+func (m *MemoryStore) DeleteExpired(at time.Time) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	n := 0
+	for id, s := range m.sessions {
+		if s.Expired(at) {
+			delete(m.sessions, id)
+			n++
+		}
+	}
+
+	return n, nil
+}
@@ -0,0 +1,205 @@
+// Package barcode encodes EAN-8, EAN-13 and Code 128 symbols into bar modules
+package barcode
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+)
+
+var (
+	ErrInvalidEAN     = errors.New("EAN code must contain 7/8 or 12/13 digits with a valid check digit")
+	ErrInvalidCode128 = errors.New("Code 128 supports printable ASCII characters only")
+)
+
+// Symbol is a sequence of modules of equal width, true modules are printed as bars
+type Symbol struct {
+	Modules []bool
+	// Guards marks modules of EAN guard bars which are drawn longer than data bars
+	Guards []bool
+	Text   string
+}
+
+var eanL = [10]string{
+	"0001101", "0011001", "0010011", "0111101", "0100011",
+	"0110001", "0101111", "0111011", "0110111", "0001011",
+}
+
+var eanG = [10]string{
+	"0100111", "0110011", "0011011", "0100001", "0011101",
+	"0111001", "0000101", "0010001", "0001001", "0010111",
+}
+
+var eanR = [10]string{
+	"1110010", "1100110", "1101100", "1000010", "1011100",
+	"1001110", "1010000", "1000100", "1001000", "1110100",
+}
+
+// ean13Parity selects L or G codes of the left half by the first digit
+var ean13Parity = [10]string{
+	"LLLLLL", "LLGLGG", "LLGGLG", "LLGGGL", "LGLLGG",
+	"LGGLLG", "LGGGLL", "LGLGLG", "LGLGGL", "LGGLGL",
+}
+
+// EAN13 encodes 13 digits with a check digit, 12 digit UPC-A codes are encoded with a leading zero
+func EAN13(code string) (*Symbol, error) {
+	if len(code) == 12 {
+		code = "0" + code
+	}
+
+	if len(code) != 13 || !isDigits(code) || checkDigit(code[:12]) != code[12] {
+		return nil, ErrInvalidEAN
+	}
+
+	s := &Symbol{Text: code}
+	s.add("101", true)
+	parity := ean13Parity[code[0]-'0']
+	for i := 1; i < 7; i++ {
+		if parity[i-1] == 'L' {
+			s.add(eanL[code[i]-'0'], false)
+		} else {
+			s.add(eanG[code[i]-'0'], false)
+		}
+	}
+	s.add("01010", true)
+	for i := 7; i < 13; i++ {
+		s.add(eanR[code[i]-'0'], false)
+	}
+	s.add("101", true)
+
+	return s, nil
+}
+
+// EAN8 encodes 7 digits or 8 digits with a check digit
+func EAN8(code string) (*Symbol, error) {
+	if len(code) == 7 && isDigits(code) {
+		code += string(checkDigit(code))
+	}
+
+	if len(code) != 8 || !isDigits(code) || checkDigit(code[:7]) != code[7] {
+		return nil, ErrInvalidEAN
+	}
+
+	s := &Symbol{Text: code}
+	s.add("101", true)
+	for i := 0; i < 4; i++ {
+		s.add(eanL[code[i]-'0'], false)
+	}
+	s.add("01010", true)
+	for i := 4; i < 8; i++ {
+		s.add(eanR[code[i]-'0'], false)
+	}
+	s.add("101", true)
+
+	return s, nil
+}
+
+// code128Patterns holds bar and space widths of Code 128 symbols 0-106
+var code128Patterns = [107]string{
+	"212222", "222122", "222221", "121223", "121322", "131222", "122213", "122312", "132212", "221213",
+	"221312", "231212", "112232", "122132", "122231", "113222", "123122", "123221", "223211", "221132",
+	"221231", "213212", "223112", "312131", "311222", "321122", "321221", "312212", "322112", "322211",
+	"212123", "212321", "232121", "111323", "131123", "131321", "112313", "132113", "132311", "211313",
+	"231113", "231311", "112133", "112331", "132131", "113123", "113321", "133121", "313121", "211331",
+	"231131", "213113", "213311", "213131", "311123", "311321", "331121", "312113", "312311", "332111",
+	"314111", "221411", "431111", "111224", "111422", "121124", "121421", "141122", "141221", "112214",
+	"112412", "122114", "122411", "142112", "142211", "241211", "221114", "413111", "241112", "134111",
+	"111242", "121142", "121241", "114212", "124112", "124211", "411212", "421112", "421211", "212141",
+	"214121", "412121", "111143", "111341", "131141", "114113", "114311", "411113", "411311", "113141",
+	"114131", "311141", "411131", "211412", "211214", "211232", "2331112",
+}
+
+const (
+	code128StartB = 104
+	code128StartC = 105
+	code128Stop   = 106
+)
+
+// Code128 encodes printable ASCII text, even runs of digits are packed with code set C
+func Code128(text string) (*Symbol, error) {
+	if text == "" {
+		return nil, ErrInvalidCode128
+	}
+	for i := 0; i < len(text); i++ {
+		if text[i] < 32 || text[i] > 126 {
+			return nil, ErrInvalidCode128
+		}
+	}
+
+	values := make([]int, 0, len(text)+3)
+	if len(text) >= 4 && len(text)%2 == 0 && isDigits(text) {
+		values = append(values, code128StartC)
+		for i := 0; i < len(text); i += 2 {
+			values = append(values, int(text[i]-'0')*10+int(text[i+1]-'0'))
+		}
+	} else {
+		values = append(values, code128StartB)
+		for i := 0; i < len(text); i++ {
+			values = append(values, int(text[i])-32)
+		}
+	}
+
+	checksum := values[0]
+	for i := 1; i < len(values); i++ {
+		checksum += i * values[i]
+	}
+	values = append(values, checksum%103, code128Stop)
+
+	s := &Symbol{Text: text}
+	for _, v := range values {
+		s.addWidths(code128Patterns[v])
+	}
+
+	return s, nil
+}
+
+// Encode picks EAN-8 or EAN-13 for numeric codes of the matching length and Code 128 otherwise
+func Encode(code string) (*Symbol, error) {
+	code = strings.TrimSpace(code)
+	switch {
+	case len(code) == 8 && isDigits(code):
+		if s, err := EAN8(code); err == nil {
+			return s, nil
+		}
+	case (len(code) == 12 || len(code) == 13) && isDigits(code):
+		if s, err := EAN13(code); err == nil {
+			return s, nil
+		}
+	}
+
+	return Code128(code)
+}
+
+func (s *Symbol) add(pattern string, guard bool) {
+	for i := 0; i < len(pattern); i++ {
+		s.Modules = append(s.Modules, pattern[i] == '1')
+		s.Guards = append(s.Guards, guard)
+	}
+}
+
+func (s *Symbol) addWidths(widths string) {
+	bar := true
+	for i := 0; i < len(widths); i++ {
+		for j := 0; j < int(widths[i]-'0'); j++ {
+			s.Modules = append(s.Modules, bar)
+			s.Guards = append(s.Guards, false)
+		}
+		bar = !bar
+	}
+}
+
+// checkDigit returns the GS1 mod 10 check digit for the payload as a character
+func checkDigit(payload string) byte {
+	return byte('0' + model.BarcodeCheckDigit(payload))
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+
+	return s != ""
+}
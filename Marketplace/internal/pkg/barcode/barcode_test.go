@@ -0,0 +1,76 @@
+package barcode_test
+
+import (
+	"testing"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/pkg/barcode"
+	"github.com/stretchr/testify/assert"
+)
+
+func modules(s *barcode.Symbol) string {
+	b := make([]byte, len(s.Modules))
+	for i, m := range s.Modules {
+		b[i] = '0'
+		if m {
+			b[i] = '1'
+		}
+	}
+	return string(b)
+}
+
+func TestEAN13(t *testing.T) {
+	s, err := barcode.EAN13("4006381333931")
+	assert.NoError(t, err)
+	assert.Len(t, s.Modules, 95)
+	assert.Len(t, s.Guards, 95)
+	// start guard, digit 0 with L parity, then digit 0 with G parity as the first digit is 4
+	assert.Equal(t, "101"+"0001101"+"0100111", modules(s)[:17])
+	assert.Equal(t, "101", modules(s)[92:])
+
+	s, err = barcode.EAN13("036000291452")
+	assert.NoError(t, err)
+	assert.Equal(t, "0036000291452", s.Text)
+
+	_, err = barcode.EAN13("4006381333932")
+	assert.Error(t, err)
+}
+
+func TestEAN8(t *testing.T) {
+	s, err := barcode.EAN8("96385074")
+	assert.NoError(t, err)
+	assert.Len(t, s.Modules, 67)
+
+	s, err = barcode.EAN8("9638507")
+	assert.NoError(t, err)
+	assert.Equal(t, "96385074", s.Text)
+
+	_, err = barcode.EAN8("96385075")
+	assert.Error(t, err)
+}
+
+func TestCode128(t *testing.T) {
+	s, err := barcode.Code128("OZ-1242124")
+	assert.NoError(t, err)
+	// start, 10 characters, checksum and 13 modules of the stop symbol
+	assert.Len(t, s.Modules, 11*12+13)
+	assert.Equal(t, "11010010000", modules(s)[:11])
+	assert.Equal(t, "1100011101011", modules(s)[len(s.Modules)-13:])
+
+	s, err = barcode.Code128("123456")
+	assert.NoError(t, err)
+	assert.Len(t, s.Modules, 11*5+13)
+	assert.Equal(t, "11010011100", modules(s)[:11])
+
+	_, err = barcode.Code128("Менажница")
+	assert.Error(t, err)
+}
+
+func TestEncode(t *testing.T) {
+	s, err := barcode.Encode("4006381333931")
+	assert.NoError(t, err)
+	assert.Len(t, s.Modules, 95)
+
+	s, err = barcode.Encode("4006381333932")
+	assert.NoError(t, err)
+	assert.Len(t, s.Modules, 11*15+13)
+}
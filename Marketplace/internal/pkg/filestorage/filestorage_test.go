@@ -0,0 +1,43 @@
+package filestorage_test
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/pkg/filestorage"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocalStorage(t *testing.T) {
+	s, err := filestorage.NewLocalStorage(t.TempDir(), "/media/")
+	assert.NoError(t, err)
+
+	key := "products/1/image.png"
+	assert.NoError(t, s.Save(key, bytes.NewBufferString("content")))
+	assert.Equal(t, "/media/products/1/image.png", s.URL(key))
+
+	f, err := s.Open(key)
+	assert.NoError(t, err)
+	b, _ := io.ReadAll(f)
+	f.Close()
+	assert.Equal(t, "content", string(b))
+
+	assert.NoError(t, s.Delete(key))
+	_, err = s.Open(key)
+	assert.True(t, os.IsNotExist(err))
+	assert.NoError(t, s.Delete(key))
+}
+
+func TestLocalStorage_InvalidKey(t *testing.T) {
+	s, err := filestorage.NewLocalStorage(t.TempDir(), "/media")
+	assert.NoError(t, err)
+
+	keys := []string{"", "/etc/passwd", "../secret", "products/../../secret", "products//image.png"}
+	for _, key := range keys {
+		assert.EqualError(t, s.Save(key, bytes.NewBufferString("content")), filestorage.ErrInvalidKey.Error(), key)
+		_, err = s.Open(key)
+		assert.EqualError(t, err, filestorage.ErrInvalidKey.Error(), key)
+	}
+}
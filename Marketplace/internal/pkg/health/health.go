@@ -0,0 +1,84 @@
+// Package health keeps the state of the services the API depends on for the readiness endpoint
+package health
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+type Component struct {
+	Name      string    `json:"name"`
+	Ready     bool      `json:"ready"`
+	Error     string    `json:"error,omitempty"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// Checker is safe for concurrent use. A registered component isn't ready until its first check passes.
+type Checker struct {
+	mu         sync.RWMutex
+	components map[string]*Component
+}
+
+func NewChecker() *Checker {
+	return &Checker{
+		components: make(map[string]*Component),
+	}
+}
+
+func (c *Checker) Register(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.components[name]; !ok {
+		c.components[name] = &Component{Name: name, Error: "not checked yet"}
+	}
+}
+
+// Set records the result of a check of the component, a nil error means the component is ready
+func (c *Checker) Set(name string, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	component := &Component{
+		Name:      name,
+		Ready:     err == nil,
+		CheckedAt: time.Now(),
+	}
+	if err != nil {
+		component.Error = err.Error()
+	}
+	c.components[name] = component
+}
+
+// Ready reports whether all components are ready
+func (c *Checker) Ready() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for _, component := range c.components {
+		if !component.Ready {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Components returns copies of the component states sorted by name
+func (c *Checker) Components() []*Component {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	components := make([]*Component, 0, len(c.components))
+	for _, component := range c.components {
+		copied := *component
+		components = append(components, &copied)
+	}
+
+	sort.Slice(components, func(i, j int) bool {
+		return components[i].Name < components[j].Name
+	})
+
+	return components
+}
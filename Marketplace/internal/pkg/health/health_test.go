@@ -0,0 +1,33 @@
+package health_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/pkg/health"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChecker(t *testing.T) {
+	c := health.NewChecker()
+	assert.True(t, c.Ready())
+
+	c.Register("auth_service")
+	c.Register("database")
+	assert.False(t, c.Ready())
+
+	c.Set("database", nil)
+	c.Set("auth_service", errors.New("connection refused"))
+	assert.False(t, c.Ready())
+
+	components := c.Components()
+	if assert.Len(t, components, 2) {
+		assert.Equal(t, "auth_service", components[0].Name)
+		assert.Equal(t, "connection refused", components[0].Error)
+		assert.True(t, components[1].Ready)
+	}
+
+	c.Register("auth_service")
+	c.Set("auth_service", nil)
+	assert.True(t, c.Ready())
+}
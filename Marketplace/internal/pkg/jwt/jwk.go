@@ -0,0 +1,238 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+)
+
+const (
+	ES256 = "ES256"
+	RS256 = "RS256"
+	HS256 = "HS256"
+)
+
+// JWK is a JSON Web Key (RFC 7517) of the key types used here: EC P-256, RSA and oct
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg,omitempty"`
+	Use string `json:"use,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	D   string `json:"d,omitempty"`
+	P   string `json:"p,omitempty"`
+	Q   string `json:"q,omitempty"`
+	K   string `json:"k,omitempty"`
+}
+
+// JWKS is a JSON Web Key Set
+type JWKS struct {
+	Keys []*JWK `json:"keys"`
+}
+
+// Key is a parsed key of the set. Keys without the private part only verify tokens.
+type Key struct {
+	ID        string
+	Algorithm string
+	signer    crypto.Signer
+	public    crypto.PublicKey
+	secret    []byte
+}
+
+// CanSign reports whether the key has the private part
+func (k *Key) CanSign() bool {
+	return k.signer != nil || k.secret != nil
+}
+
+// LoadKeySet reads the key set from a JWKS file
+func LoadKeySet(path string, signingKeyID string) (*KeySet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseKeySet(data, signingKeyID)
+}
+
+// ParseKeySet parses the JWKS. Tokens are signed with the key signingKeyID or, when it is empty,
+// with the first key having the private part. Every key of the set verifies tokens, so a new key
+// is rolled out by adding it to the set and moving the signing key id to it.
+func ParseKeySet(data []byte, signingKeyID string) (*KeySet, error) {
+	set := &JWKS{}
+	if err := json.Unmarshal(data, set); err != nil {
+		return nil, err
+	}
+
+	ks := &KeySet{keys: make(map[string]*Key)}
+	for _, jwk := range set.Keys {
+		k, err := parseKey(jwk)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", jwk.Kid, err)
+		}
+
+		if _, ok := ks.keys[k.ID]; ok {
+			return nil, fmt.Errorf("key %q is listed more than once", k.ID)
+		}
+		ks.keys[k.ID] = k
+		ks.order = append(ks.order, k.ID)
+
+		if ks.signing == nil && k.CanSign() && (signingKeyID == "" || signingKeyID == k.ID) {
+			ks.signing = k
+		}
+	}
+
+	if ks.signing == nil {
+		if signingKeyID != "" {
+			return nil, fmt.Errorf("signing key %q is missing or has no private part", signingKeyID)
+		}
+		return nil, errors.New("key set has no signing key")
+	}
+
+	return ks, nil
+}
+
+func parseKey(jwk *JWK) (*Key, error) {
+	if jwk.Kid == "" {
+		return nil, errors.New("kid is required")
+	}
+	if jwk.Use != "" && jwk.Use != "sig" {
+		return nil, fmt.Errorf("unsupported use %q", jwk.Use)
+	}
+
+	k := &Key{ID: jwk.Kid}
+	switch jwk.Kty {
+	case "EC":
+		if jwk.Crv != "P-256" {
+			return nil, fmt.Errorf("unsupported curve %q", jwk.Crv)
+		}
+		x, err := decodeInt(jwk.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeInt(jwk.Y)
+		if err != nil {
+			return nil, err
+		}
+
+		pub := &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}
+		if !pub.Curve.IsOnCurve(x, y) {
+			return nil, errors.New("point is not on the curve")
+		}
+		k.Algorithm, k.public = ES256, pub
+
+		if jwk.D != "" {
+			d, err := decodeInt(jwk.D)
+			if err != nil {
+				return nil, err
+			}
+			if dx, dy := pub.Curve.ScalarBaseMult(d.Bytes()); dx.Cmp(x) != 0 || dy.Cmp(y) != 0 {
+				return nil, errors.New("private key doesn't match the public one")
+			}
+			k.signer = &ecdsa.PrivateKey{PublicKey: *pub, D: d}
+		}
+	case "RSA":
+		n, err := decodeInt(jwk.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeInt(jwk.E)
+		if err != nil {
+			return nil, err
+		}
+		if n.BitLen() < 2048 {
+			return nil, errors.New("RSA keys must be at least 2048 bits")
+		}
+
+		pub := &rsa.PublicKey{N: n, E: int(e.Int64())}
+		k.Algorithm, k.public = RS256, pub
+
+		if jwk.D != "" {
+			priv := &rsa.PrivateKey{PublicKey: *pub}
+			if priv.D, err = decodeInt(jwk.D); err != nil {
+				return nil, err
+			}
+			for _, v := range []string{jwk.P, jwk.Q} {
+				prime, err := decodeInt(v)
+				if err != nil {
+					return nil, err
+				}
+				priv.Primes = append(priv.Primes, prime)
+			}
+			if err = priv.Validate(); err != nil {
+				return nil, err
+			}
+			priv.Precompute()
+			k.signer = priv
+		}
+	case "oct":
+		secret, err := base64.RawURLEncoding.DecodeString(jwk.K)
+		if err != nil {
+			return nil, err
+		}
+		if len(secret) < 32 {
+			return nil, errors.New("HMAC secrets must be at least 32 bytes")
+		}
+		k.Algorithm, k.secret = HS256, secret
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", jwk.Kty)
+	}
+
+	if jwk.Alg != "" && jwk.Alg != k.Algorithm {
+		return nil, fmt.Errorf("unsupported algorithm %q for the key type %s", jwk.Alg, jwk.Kty)
+	}
+
+	return k, nil
+}
+
+// publicJWK returns the public part of the key, secret keys have none
+func (k *Key) publicJWK() *JWK {
+	jwk := &JWK{Kid: k.ID, Alg: k.Algorithm, Use: "sig"}
+	switch pub := k.public.(type) {
+	case *ecdsa.PublicKey:
+		jwk.Kty, jwk.Crv = "EC", "P-256"
+		jwk.X = encodeInt(pub.X, 32)
+		jwk.Y = encodeInt(pub.Y, 32)
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = encodeInt(pub.N, 0)
+		jwk.E = encodeInt(big.NewInt(int64(pub.E)), 0)
+	default:
+		return nil
+	}
+
+	return jwk
+}
+
+func decodeInt(s string) (*big.Int, error) {
+	if s == "" {
+		return nil, errors.New("key parameter is missing")
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+
+	return new(big.Int).SetBytes(b), nil
+}
+
+// encodeInt encodes the number padded to size bytes, zero size keeps the minimal length
+func encodeInt(i *big.Int, size int) string {
+	b := i.Bytes()
+	if len(b) < size {
+		b = append(make([]byte, size-len(b)), b...)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b)
+}
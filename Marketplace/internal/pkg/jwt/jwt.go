@@ -0,0 +1,142 @@
+// Package jwt signs and verifies JSON Web Tokens (RFC 7519) with the ES256, RS256 and HS256
+// algorithms using keys of a JWKS. Tokens are handled by github.com/golang-jwt/jwt, this package
+// loads the keys and picks the one of the kid header.
+package jwt
+
+import (
+	"errors"
+	"time"
+
+	gojwt "github.com/golang-jwt/jwt/v4"
+)
+
+var (
+	ErrMalformed  = errors.New("token is malformed")
+	ErrUnknownKey = errors.New("token is signed with an unknown key")
+	ErrSignature  = errors.New("token signature is invalid")
+	ErrExpired    = errors.New("token is expired")
+	ErrClaims     = errors.New("token claims are invalid")
+)
+
+// leeway allows for clock differences when checking times of the claims
+const leeway = 30 * time.Second
+
+// Claims are the registered claims of the tokens, SessionID ties access tokens to the refresh token
+type Claims struct {
+	Issuer    string `json:"iss,omitempty"`
+	Subject   string `json:"sub"`
+	Audience  string `json:"aud,omitempty"`
+	IssuedAt  int64  `json:"iat"`
+	NotBefore int64  `json:"nbf,omitempty"`
+	ExpiresAt int64  `json:"exp"`
+	ID        string `json:"jti,omitempty"`
+	SessionID string `json:"sid,omitempty"`
+}
+
+// Valid checks the claims at the current time, Parse checks them at the time it is given
+func (c *Claims) Valid() error {
+	return c.valid(time.Now())
+}
+
+func (c *Claims) valid(at time.Time) error {
+	now := at.Unix()
+	if c.ExpiresAt == 0 || c.Subject == "" {
+		return ErrClaims
+	}
+	if now >= c.ExpiresAt+int64(leeway.Seconds()) {
+		return ErrExpired
+	}
+	if c.NotBefore != 0 && now < c.NotBefore-int64(leeway.Seconds()) {
+		return ErrClaims
+	}
+
+	return nil
+}
+
+// KeySet holds the keys tokens are verified with and the key new tokens are signed with
+type KeySet struct {
+	keys    map[string]*Key
+	order   []string
+	signing *Key
+}
+
+// SigningKey returns the key new tokens are signed with
+func (ks *KeySet) SigningKey() *Key {
+	return ks.signing
+}
+
+// Public returns the public keys of the set for the JWKS endpoint, secret keys are left out
+func (ks *KeySet) Public() *JWKS {
+	set := &JWKS{Keys: make([]*JWK, 0, len(ks.order))}
+	for _, id := range ks.order {
+		if jwk := ks.keys[id].publicJWK(); jwk != nil {
+			set.Keys = append(set.Keys, jwk)
+		}
+	}
+
+	return set
+}
+
+// Sign returns the token of the claims signed with the signing key
+func (ks *KeySet) Sign(claims *Claims) (string, error) {
+	k := ks.signing
+	token := gojwt.NewWithClaims(gojwt.GetSigningMethod(k.Algorithm), claims)
+	token.Header["kid"] = k.ID
+
+	return token.SignedString(k.signingKey())
+}
+
+// Parse verifies the token signature and the time claims at the time and returns the claims
+func (ks *KeySet) Parse(token string, at time.Time) (*Claims, error) {
+	claims := &Claims{}
+	parser := gojwt.NewParser(gojwt.WithoutClaimsValidation())
+	_, err := parser.ParseWithClaims(token, claims, func(t *gojwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		k, ok := ks.keys[kid]
+		if !ok {
+			return nil, ErrUnknownKey
+		}
+		// the algorithm is fixed by the key, so a token can't switch it to another one
+		if t.Method.Alg() != k.Algorithm {
+			return nil, ErrSignature
+		}
+
+		return k.verifyingKey(), nil
+	})
+	if err != nil {
+		ve := &gojwt.ValidationError{}
+		switch {
+		case errors.Is(err, ErrUnknownKey):
+			return nil, ErrUnknownKey
+		case errors.Is(err, ErrSignature):
+			return nil, ErrSignature
+		case errors.As(err, &ve) && ve.Errors&gojwt.ValidationErrorSignatureInvalid != 0:
+			return nil, ErrSignature
+		}
+		return nil, ErrMalformed
+	}
+
+	if err = claims.valid(at); err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+// signingKey returns the key in the form golang-jwt signs with
+func (k *Key) signingKey() interface{} {
+	if k.secret != nil {
+		return k.secret
+	}
+
+	return k.signer
+}
+
+// verifyingKey returns the key in the form golang-jwt verifies with
+func (k *Key) verifyingKey() interface{} {
+	if k.secret != nil {
+		return k.secret
+	}
+
+	return k.public
+}
@@ -0,0 +1,158 @@
+package jwt_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/pkg/jwt"
+	"github.com/stretchr/testify/assert"
+)
+
+func b64(i *big.Int) string {
+	return base64.RawURLEncoding.EncodeToString(i.Bytes())
+}
+
+func ecJWK(t *testing.T, kid string) *jwt.JWK {
+	t.Helper()
+
+	k, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &jwt.JWK{Kty: "EC", Kid: kid, Crv: "P-256", X: b64(k.X), Y: b64(k.Y), D: b64(k.D)}
+}
+
+func rsaJWK(t *testing.T, kid string) *jwt.JWK {
+	t.Helper()
+
+	k, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &jwt.JWK{
+		Kty: "RSA",
+		Kid: kid,
+		N:   b64(k.N),
+		E:   b64(big.NewInt(int64(k.E))),
+		D:   b64(k.D),
+		P:   b64(k.Primes[0]),
+		Q:   b64(k.Primes[1]),
+	}
+}
+
+func octJWK(kid string) *jwt.JWK {
+	return &jwt.JWK{Kty: "oct", Kid: kid, K: base64.RawURLEncoding.EncodeToString([]byte(strings.Repeat("s", 32)))}
+}
+
+func keySet(t *testing.T, signingKeyID string, keys ...*jwt.JWK) *jwt.KeySet {
+	t.Helper()
+
+	data, _ := json.Marshal(&jwt.JWKS{Keys: keys})
+	ks, err := jwt.ParseKeySet(data, signingKeyID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return ks
+}
+
+func TestKeySet_SignAndParse(t *testing.T) {
+	now := time.Date(2022, 12, 5, 10, 0, 0, 0, time.UTC)
+	claims := &jwt.Claims{
+		Issuer:    "marketplace",
+		Subject:   "1",
+		IssuedAt:  now.Unix(),
+		ExpiresAt: now.Add(15 * time.Minute).Unix(),
+		SessionID: "sid",
+	}
+
+	for _, jwk := range []*jwt.JWK{ecJWK(t, "ec"), rsaJWK(t, "rsa"), octJWK("oct")} {
+		t.Run(jwk.Kty, func(t *testing.T) {
+			ks := keySet(t, "", jwk)
+			token, err := ks.Sign(claims)
+			assert.NoError(t, err)
+
+			parsed, err := ks.Parse(token, now)
+			assert.NoError(t, err)
+			assert.Equal(t, claims, parsed)
+
+			_, err = ks.Parse(token, now.Add(time.Hour))
+			assert.Equal(t, jwt.ErrExpired, err)
+
+			parts := strings.Split(token, ".")
+			forged, _ := json.Marshal(&jwt.Claims{Subject: "2", ExpiresAt: claims.ExpiresAt})
+			_, err = ks.Parse(parts[0]+"."+base64.RawURLEncoding.EncodeToString(forged)+"."+parts[2], now)
+			assert.Equal(t, jwt.ErrSignature, err)
+		})
+	}
+}
+
+func TestKeySet_Rotation(t *testing.T) {
+	now := time.Now()
+	old, next := ecJWK(t, "2022-11"), ecJWK(t, "2022-12")
+	claims := &jwt.Claims{Subject: "1", ExpiresAt: now.Add(time.Minute).Unix()}
+
+	token, err := keySet(t, "2022-11", old, next).Sign(claims)
+	assert.NoError(t, err)
+
+	rotated := keySet(t, "2022-12", old, next)
+	assert.Equal(t, "2022-12", rotated.SigningKey().ID)
+	_, err = rotated.Parse(token, now)
+	assert.NoError(t, err)
+
+	_, err = keySet(t, "", next).Parse(token, now)
+	assert.Equal(t, jwt.ErrUnknownKey, err)
+
+	// a token can't choose another algorithm for the key
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","kid":"2022-11"}`))
+	_, err = rotated.Parse(header+"."+strings.SplitN(token, ".", 2)[1], now)
+	assert.Equal(t, jwt.ErrSignature, err)
+
+	unsigned := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none","kid":"2022-11"}`))
+	_, err = rotated.Parse(unsigned+"."+strings.Split(token, ".")[1]+".", now)
+	assert.Equal(t, jwt.ErrSignature, err)
+
+	_, err = rotated.Parse("not a token", now)
+	assert.Equal(t, jwt.ErrMalformed, err)
+}
+
+func TestParseKeySet(t *testing.T) {
+	ec := ecJWK(t, "ec")
+	public := *ec
+	public.Kid, public.D = "public", ""
+
+	ks := keySet(t, "", &public, ec, octJWK("oct"))
+	assert.Equal(t, "ec", ks.SigningKey().ID)
+
+	keys := ks.Public().Keys
+	if assert.Len(t, keys, 2) {
+		assert.Equal(t, "public", keys[0].Kid)
+		assert.Empty(t, keys[1].D)
+		assert.Equal(t, jwt.ES256, keys[1].Alg)
+	}
+
+	for name, keys := range map[string][]*jwt.JWK{
+		"no signing key": {&public},
+		"duplicate kid":  {ec, ec},
+		"short secret":   {{Kty: "oct", Kid: "oct", K: "c2VjcmV0"}},
+		"unknown type":   {{Kty: "OKP", Kid: "ed"}},
+	} {
+		data, _ := json.Marshal(&jwt.JWKS{Keys: keys})
+		_, err := jwt.ParseKeySet(data, "")
+		assert.Error(t, err, name)
+	}
+
+	data, _ := json.Marshal(&jwt.JWKS{Keys: []*jwt.JWK{ec}})
+	_, err := jwt.ParseKeySet(data, "missing")
+	assert.Error(t, err)
+}
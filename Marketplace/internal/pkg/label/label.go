@@ -0,0 +1,150 @@
+// Package label renders barcode labels into a PDF document
+package label
+
+import (
+	"errors"
+	"io"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/pkg/barcode"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/pkg/pdf"
+)
+
+const (
+	// A4 sheet size in millimetres
+	A4Width  = 210
+	A4Height = 297
+
+	sheetMargin = 5
+	labelMargin = 2
+	quietZone   = 10 // modules of white space around the symbol
+)
+
+var ErrLabelTooSmall = errors.New("label size must be at least 20x15 mm")
+
+type Label struct {
+	Title   string
+	Caption string
+	Code    string
+}
+
+// Layout describes the label size in millimetres. On a sheet labels are placed in a grid on A4 pages,
+// otherwise every label gets its own page of the label size as thermal printers expect.
+type Layout struct {
+	Width  float64
+	Height float64
+	Sheet  bool
+}
+
+// Render writes labels to w as a PDF document
+func Render(w io.Writer, labels []*Label, layout Layout) error {
+	if layout.Width < 20 || layout.Height < 15 {
+		return ErrLabelTooSmall
+	}
+
+	symbols := make([]*barcode.Symbol, len(labels))
+	for i, l := range labels {
+		s, err := barcode.Encode(l.Code)
+		if err != nil {
+			return err
+		}
+		symbols[i] = s
+	}
+
+	if !layout.Sheet {
+		doc := pdf.New(layout.Width, layout.Height)
+		for i, l := range labels {
+			drawLabel(doc.AddPage(), 0, 0, layout, l, symbols[i])
+		}
+
+		_, err := doc.WriteTo(w)
+		return err
+	}
+
+	columns := int((A4Width - 2*sheetMargin) / layout.Width)
+	rows := int((A4Height - 2*sheetMargin) / layout.Height)
+	if columns < 1 || rows < 1 {
+		return errors.New("label doesn't fit on an A4 sheet")
+	}
+
+	doc := pdf.New(A4Width, A4Height)
+	var page *pdf.Page
+	for i, l := range labels {
+		cell := i % (columns * rows)
+		if cell == 0 {
+			page = doc.AddPage()
+		}
+
+		x := sheetMargin + float64(cell%columns)*layout.Width
+		y := sheetMargin + float64(cell/columns)*layout.Height
+		page.Line(x, y, x+layout.Width, y)
+		page.Line(x, y, x, y+layout.Height)
+		page.Line(x+layout.Width, y, x+layout.Width, y+layout.Height)
+		page.Line(x, y+layout.Height, x+layout.Width, y+layout.Height)
+		drawLabel(page, x, y, layout, l, symbols[i])
+	}
+
+	_, err := doc.WriteTo(w)
+	return err
+}
+
+// drawLabel places the title on top, the symbol in the middle and the code with the caption below it
+func drawLabel(page *pdf.Page, x, y float64, layout Layout, l *Label, s *barcode.Symbol) {
+	width := layout.Width - 2*labelMargin
+	textSize := clamp(layout.Height/4, 5, 10)
+	lineHeight := textSize * 0.3528 * 1.2 // points to millimetres with line spacing
+
+	top := y + labelMargin
+	if l.Title != "" {
+		top += lineHeight
+		page.Text(x+labelMargin, top, textSize, pdf.FitText(l.Title, textSize, width))
+	}
+
+	bottom := y + layout.Height - labelMargin
+	if l.Caption != "" {
+		page.Text(x+labelMargin, bottom, textSize, pdf.FitText(l.Caption, textSize, width))
+		bottom -= lineHeight
+	}
+
+	codeSize := clamp(textSize-1, 5, 9)
+	codeText := pdf.FitText(s.Text, codeSize, width)
+	page.Text(x+(layout.Width-pdf.TextWidth(codeText, codeSize))/2, bottom, codeSize, codeText)
+	bottom -= lineHeight
+
+	barsHeight := bottom - top - labelMargin
+	if barsHeight <= 0 {
+		return
+	}
+
+	module := width / float64(len(s.Modules)+2*quietZone)
+	left := x + labelMargin + float64(quietZone)*module
+	barsTop := top + labelMargin/2
+	for i := 0; i < len(s.Modules); {
+		if !s.Modules[i] {
+			i++
+			continue
+		}
+
+		// merge adjacent modules of the same bar into one rectangle
+		j := i
+		for j < len(s.Modules) && s.Modules[j] && s.Guards[j] == s.Guards[i] {
+			j++
+		}
+
+		height := barsHeight
+		if s.Guards[i] {
+			height += lineHeight / 3
+		}
+		page.Rect(left+float64(i)*module, barsTop, float64(j-i)*module, height)
+		i = j
+	}
+}
+
+func clamp(v, lo, hi float64) float64 {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
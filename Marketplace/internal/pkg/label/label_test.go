@@ -0,0 +1,81 @@
+package label_test
+
+import (
+	"bytes"
+	"regexp"
+	"testing"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/pkg/label"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/pkg/pdf"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRender(t *testing.T) {
+	labels := []*label.Label{
+		{Title: "Менажница", Caption: "OZ 1242124 / WB 24345325", Code: "4006381333931"},
+		{Title: "Tray", Code: "TRAY-1"},
+		{Title: "Tray", Code: "TRAY-1"},
+	}
+
+	testCases := []struct {
+		name          string
+		layout        label.Layout
+		expectedPages string
+		isValid       bool
+	}{
+		{
+			name:          "label per page",
+			layout:        label.Layout{Width: 58, Height: 40},
+			expectedPages: "/Count 3",
+			isValid:       true,
+		},
+		{
+			name:          "a4 sheet",
+			layout:        label.Layout{Width: 58, Height: 40, Sheet: true},
+			expectedPages: "/Count 1",
+			isValid:       true,
+		},
+		{
+			name:    "too small",
+			layout:  label.Layout{Width: 10, Height: 10},
+			isValid: false,
+		},
+		{
+			name:    "too large for a sheet",
+			layout:  label.Layout{Width: 250, Height: 40, Sheet: true},
+			isValid: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			err := label.Render(buf, labels, tc.layout)
+			if !tc.isValid {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Contains(t, buf.String(), tc.expectedPages)
+			assert.Contains(t, buf.String(), shown(t, "Менажница"))
+			assert.Contains(t, buf.String(), shown(t, "4006381333931"))
+		})
+	}
+}
+
+func TestRender_InvalidCode(t *testing.T) {
+	err := label.Render(&bytes.Buffer{}, []*label.Label{{Code: "Менажница"}}, label.Layout{Width: 58, Height: 40})
+	assert.Error(t, err)
+}
+
+// shown returns the text operator the document uses to show the text in the embedded font
+func shown(t *testing.T, text string) string {
+	doc := pdf.New(10, 10)
+	doc.AddPage().Text(0, 0, 8, text)
+	buf := &bytes.Buffer{}
+	_, err := doc.WriteTo(buf)
+	assert.NoError(t, err)
+
+	return regexp.MustCompile(`<[0-9A-F]*> Tj`).FindString(buf.String())
+}
@@ -0,0 +1,151 @@
+// Package mailer sends account emails such as verification and password reset links
+package mailer
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+var ErrInvalidMessage = errors.New("invalid message")
+
+type Mailer interface {
+	Send(*Message) error
+}
+
+// Message is a plain text email
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// SMTPMailer sends messages through an SMTP server, the connection is upgraded with STARTTLS
+// when the server supports it
+type SMTPMailer struct {
+	addr string
+	from string
+	auth smtp.Auth
+	send func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+}
+
+// NewSMTPMailer creates the mailer for the server at addr like "smtp.example.org:587",
+// empty username sends without authentication
+func NewSMTPMailer(addr string, username string, password string, from string) (*SMTPMailer, error) {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = mail.ParseAddress(from); err != nil {
+		return nil, fmt.Errorf("sender address: %w", err)
+	}
+
+	m := &SMTPMailer{
+		addr: addr,
+		from: from,
+		send: smtp.SendMail,
+	}
+	if username != "" {
+		m.auth = smtp.PlainAuth("", username, password, host)
+	}
+
+	return m, nil
+}
+
+func (m *SMTPMailer) Send(msg *Message) error {
+	data, err := msg.format(m.from, time.Now())
+	if err != nil {
+		return err
+	}
+
+	from, _ := mail.ParseAddress(m.from)
+	to, _ := mail.ParseAddress(msg.To)
+
+	return m.send(m.addr, m.auth, from.Address, []string{to.Address}, data)
+}
+
+// LocalMailer writes messages to a file or a log instead of sending them, it is meant
+// for development and tests
+type LocalMailer struct {
+	mu   sync.Mutex
+	w    io.Writer
+	from string
+}
+
+func NewLocalMailer(w io.Writer, from string) *LocalMailer {
+	return &LocalMailer{
+		w:    w,
+		from: from,
+	}
+}
+
+// NewFileMailer appends messages to the file at path
+func NewFileMailer(path string, from string) (*LocalMailer, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewLocalMailer(f, from), nil
+}
+
+func (m *LocalMailer) Send(msg *Message) error {
+	if err := msg.validate(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	_, err := fmt.Fprintf(m.w, "From: %s\nTo: %s\nSubject: %s\n\n%s\n\n", m.from, msg.To, msg.Subject, msg.Body)
+	return err
+}
+
+func (msg *Message) validate() error {
+	if _, err := mail.ParseAddress(msg.To); err != nil {
+		return fmt.Errorf("%w: recipient: %v", ErrInvalidMessage, err)
+	}
+
+	if strings.ContainsAny(msg.Subject, "\r\n") {
+		return fmt.Errorf("%w: line break in the subject", ErrInvalidMessage)
+	}
+
+	return nil
+}
+
+// format builds the RFC 5322 message with a quoted-printable UTF-8 body
+func (msg *Message) format(from string, at time.Time) ([]byte, error) {
+	if err := msg.validate(); err != nil {
+		return nil, err
+	}
+
+	b := &bytes.Buffer{}
+	fmt.Fprintf(b, "From: %s\r\n", from)
+	fmt.Fprintf(b, "To: %s\r\n", msg.To)
+	fmt.Fprintf(b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(b, "Date: %s\r\n", at.Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+
+	qp := quotedprintable.NewWriter(b)
+	if _, err := qp.Write([]byte(strings.ReplaceAll(msg.Body, "\n", "\r\n"))); err != nil {
+		return nil, err
+	}
+	if err := qp.Close(); err != nil {
+		return nil, err
+	}
+
+	return b.Bytes(), nil
+}
@@ -0,0 +1,47 @@
+package mailer
+
+import (
+	"bytes"
+	"net/smtp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocalMailer_Send(t *testing.T) {
+	b := &bytes.Buffer{}
+	m := NewLocalMailer(b, "noreply@example.org")
+
+	assert.NoError(t, m.Send(&Message{To: "user@example.org", Subject: "Verify", Body: "token"}))
+	assert.Contains(t, b.String(), "To: user@example.org")
+	assert.Contains(t, b.String(), "token")
+
+	assert.ErrorIs(t, m.Send(&Message{To: "invalid", Subject: "Verify"}), ErrInvalidMessage)
+	assert.ErrorIs(t, m.Send(&Message{To: "user@example.org", Subject: "Verify\r\nBcc: x@example.org"}), ErrInvalidMessage)
+}
+
+func TestSMTPMailer_Send(t *testing.T) {
+	_, err := NewSMTPMailer("smtp.example.org", "", "", "noreply@example.org")
+	assert.Error(t, err)
+	_, err = NewSMTPMailer("smtp.example.org:587", "", "", "invalid")
+	assert.Error(t, err)
+
+	m, err := NewSMTPMailer("smtp.example.org:587", "user", "secret", "Marketplace <noreply@example.org>")
+	assert.NoError(t, err)
+
+	var sent []byte
+	var from string
+	var to []string
+	m.send = func(addr string, a smtp.Auth, f string, t []string, msg []byte) error {
+		from, to, sent = f, t, msg
+		return nil
+	}
+
+	assert.NoError(t, m.Send(&Message{To: "User <user@example.org>", Subject: "Сброс пароля", Body: "Ссылка:\nhttps://example.org/reset"}))
+	assert.Equal(t, "noreply@example.org", from)
+	assert.Equal(t, []string{"user@example.org"}, to)
+	assert.True(t, strings.HasPrefix(string(sent), "From: Marketplace <noreply@example.org>\r\nTo: User <user@example.org>\r\nSubject: =?utf-8?q?"))
+	assert.Contains(t, string(sent), "Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+	assert.NotContains(t, string(sent), "Ссылка")
+}
@@ -0,0 +1,59 @@
+// Package mergepatch implements JSON Merge Patch (RFC 7396)
+package mergepatch
+
+import (
+	"encoding/json"
+)
+
+// Patch is a merge patch object decoded once, members removed by the patch hold nil
+type Patch map[string]interface{}
+
+// Parse decodes an object patch
+func Parse(data []byte) (Patch, error) {
+	p := make(Patch)
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// Has reports whether the patch sets or removes the top-level member
+func (p Patch) Has(key string) bool {
+	_, ok := p[key]
+	return ok
+}
+
+// Apply merges the patch into the original document and returns the result
+func (p Patch) Apply(original []byte) ([]byte, error) {
+	var target interface{}
+	if len(original) > 0 {
+		if err := json.Unmarshal(original, &target); err != nil {
+			return nil, err
+		}
+	}
+
+	return json.Marshal(merge(target, map[string]interface{}(p)))
+}
+
+func merge(target, patch interface{}) interface{} {
+	patchObj, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	targetObj, ok := target.(map[string]interface{})
+	if !ok {
+		targetObj = make(map[string]interface{})
+	}
+
+	for k, v := range patchObj {
+		if v == nil {
+			delete(targetObj, k)
+			continue
+		}
+		targetObj[k] = merge(targetObj[k], v)
+	}
+
+	return targetObj
+}
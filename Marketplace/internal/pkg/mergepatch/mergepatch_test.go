@@ -0,0 +1,87 @@
+package mergepatch_test
+
+import (
+	"testing"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/pkg/mergepatch"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPatch_Apply(t *testing.T) {
+	testCases := []struct {
+		name     string
+		original string
+		patch    string
+		expected string
+	}{
+		{
+			name:     "replace member",
+			original: `{"a":"b"}`,
+			patch:    `{"a":"c"}`,
+			expected: `{"a":"c"}`,
+		},
+		{
+			name:     "add member",
+			original: `{"a":"b"}`,
+			patch:    `{"b":"c"}`,
+			expected: `{"a":"b","b":"c"}`,
+		},
+		{
+			name:     "remove member",
+			original: `{"a":"b","b":"c"}`,
+			patch:    `{"a":null}`,
+			expected: `{"b":"c"}`,
+		},
+		{
+			name:     "nested object",
+			original: `{"a":{"b":"c","d":"e"}}`,
+			patch:    `{"a":{"d":null,"f":"g"}}`,
+			expected: `{"a":{"b":"c","f":"g"}}`,
+		},
+		{
+			name:     "replace array",
+			original: `{"a":["b"]}`,
+			patch:    `{"a":["c","d"]}`,
+			expected: `{"a":["c","d"]}`,
+		},
+		{
+			name:     "non object original",
+			original: `["a"]`,
+			patch:    `{"a":"b"}`,
+			expected: `{"a":"b"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			patch, err := mergepatch.Parse([]byte(tc.patch))
+			assert.NoError(t, err)
+			result, err := patch.Apply([]byte(tc.original))
+			assert.NoError(t, err)
+			assert.JSONEq(t, tc.expected, string(result))
+		})
+	}
+}
+
+func TestPatch_ApplyInvalidOriginal(t *testing.T) {
+	_, err := mergepatch.Patch{"a": "b"}.Apply([]byte(`{"a":`))
+	assert.Error(t, err)
+}
+
+func TestParse(t *testing.T) {
+	patch, err := mergepatch.Parse([]byte(`{"a":1,"b":null}`))
+	assert.NoError(t, err)
+	assert.True(t, patch.Has("a"))
+	assert.True(t, patch.Has("b"))
+	assert.False(t, patch.Has("c"))
+
+	result, err := patch.Apply([]byte(`{"b":"c","d":"e"}`))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"a":1,"d":"e"}`, string(result))
+
+	_, err = mergepatch.Parse([]byte(`["a"]`))
+	assert.Error(t, err)
+
+	_, err = mergepatch.Parse([]byte(`{"a":`))
+	assert.Error(t, err)
+}
@@ -0,0 +1,137 @@
+// Package packinglist writes packing lists of shipments to marketplace warehouses as CSV or PDF
+package packinglist
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/pkg/label"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/pkg/pdf"
+)
+
+const (
+	margin     = 15
+	rowHeight  = 6
+	textSize   = 9
+	titleSize  = 14
+	tableStart = 40
+)
+
+// List is a packing list, lines are expected in the box order
+type List struct {
+	Title    string
+	Subtitle string
+	Boxes    int
+	Pallets  int
+	Lines    []*Line
+}
+
+type Line struct {
+	BoxNumber    int
+	PalletNumber int
+	ProductID    int
+	ProductName  string
+	SKU          int
+	Barcode      string
+	Quantity     int
+}
+
+type column struct {
+	title string
+	x     float64
+	width float64
+	value func(*Line) string
+}
+
+var columns = []*column{
+	{"Box", margin, 12, func(l *Line) string { return strconv.Itoa(l.BoxNumber) }},
+	{"Pallet", 27, 14, func(l *Line) string { return number(l.PalletNumber) }},
+	{"Product", 41, 18, func(l *Line) string { return strconv.Itoa(l.ProductID) }},
+	{"Name", 59, 72, func(l *Line) string { return l.ProductName }},
+	{"SKU", 131, 22, func(l *Line) string { return number(l.SKU) }},
+	{"Barcode", 153, 29, func(l *Line) string { return l.Barcode }},
+	{"Qty", 182, 13, func(l *Line) string { return strconv.Itoa(l.Quantity) }},
+}
+
+// WriteCSV writes the lines with a header row, the pallet and SKU are empty when not set
+func WriteCSV(w io.Writer, list *List) error {
+	cw := csv.NewWriter(w)
+	header := []string{"box_number", "pallet_number", "product_id", "product_name", "sku", "barcode", "quantity"}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	for _, l := range list.Lines {
+		record := make([]string, 0, len(columns))
+		for _, c := range columns {
+			record = append(record, c.value(l))
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// WritePDF writes the list as a table on A4 pages with the totals after the last line
+func WritePDF(w io.Writer, list *List) error {
+	doc := pdf.New(label.A4Width, label.A4Height)
+	rowsPerPage := int((label.A4Height - tableStart - 2*margin) / rowHeight)
+
+	var page *pdf.Page
+	y := 0.0
+	units := 0
+	for i, l := range list.Lines {
+		if i%rowsPerPage == 0 {
+			page = newPage(doc, list)
+			y = tableStart + rowHeight
+		}
+
+		if i > 0 && list.Lines[i-1].BoxNumber != l.BoxNumber && i%rowsPerPage != 0 {
+			page.Line(margin, y-rowHeight+1.5, label.A4Width-margin, y-rowHeight+1.5)
+		}
+
+		for _, c := range columns {
+			page.Text(c.x, y, textSize, pdf.FitText(c.value(l), textSize, c.width-1))
+		}
+
+		units += l.Quantity
+		y += rowHeight
+	}
+
+	if page == nil {
+		page = newPage(doc, list)
+		y = tableStart + rowHeight
+	}
+
+	page.Line(margin, y-rowHeight+1.5, label.A4Width-margin, y-rowHeight+1.5)
+	page.Text(margin, y+2, textSize, fmt.Sprintf("Boxes: %d   Pallets: %d   Units: %d", list.Boxes, list.Pallets, units))
+
+	_, err := doc.WriteTo(w)
+	return err
+}
+
+func newPage(doc *pdf.Document, list *List) *pdf.Page {
+	page := doc.AddPage()
+	page.Text(margin, 22, titleSize, list.Title)
+	page.Text(margin, 30, textSize, list.Subtitle)
+
+	for _, c := range columns {
+		page.Text(c.x, tableStart, textSize, c.title)
+	}
+	page.Line(margin, tableStart+1.5, label.A4Width-margin, tableStart+1.5)
+
+	return page
+}
+
+func number(n int) string {
+	if n == 0 {
+		return ""
+	}
+
+	return strconv.Itoa(n)
+}
@@ -0,0 +1,78 @@
+package packinglist_test
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/pkg/packinglist"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/pkg/pdf"
+	"github.com/stretchr/testify/assert"
+)
+
+func testList(lines int) *packinglist.List {
+	list := &packinglist.List{
+		Title:    "Shipment 1",
+		Subtitle: "Ozon Хоругвино, 2022-12-05",
+		Boxes:    2,
+		Pallets:  1,
+	}
+	for i := 0; i < lines; i++ {
+		list.Lines = append(list.Lines, &packinglist.Line{
+			BoxNumber:    i/2 + 1,
+			PalletNumber: 1,
+			ProductID:    100 + i,
+			ProductName:  "Менажница деревянная",
+			SKU:          123456,
+			Barcode:      "2000000000015",
+			Quantity:     10,
+		})
+	}
+
+	return list
+}
+
+func TestWriteCSV(t *testing.T) {
+	buf := &bytes.Buffer{}
+	assert.NoError(t, packinglist.WriteCSV(buf, testList(2)))
+	assert.Equal(
+		t,
+		"box_number,pallet_number,product_id,product_name,sku,barcode,quantity\n"+
+			"1,1,100,Менажница деревянная,123456,2000000000015,10\n"+
+			"1,1,101,Менажница деревянная,123456,2000000000015,10\n",
+		buf.String(),
+	)
+}
+
+func TestWritePDF(t *testing.T) {
+	testCases := []struct {
+		lines int
+		pages int
+	}{
+		{lines: 0, pages: 1},
+		{lines: 3, pages: 1},
+		{lines: 60, pages: 2},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("%d lines", tc.lines), func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			assert.NoError(t, packinglist.WritePDF(buf, testList(tc.lines)))
+			assert.Contains(t, buf.String(), fmt.Sprintf("/Count %d", tc.pages))
+			assert.Contains(t, buf.String(), shown(t, "Ozon Хоругвино, 2022-12-05"))
+			assert.Contains(t, buf.String(), shown(t, fmt.Sprintf("Boxes: 2   Pallets: 1   Units: %d", tc.lines*10)))
+		})
+	}
+}
+
+// shown returns the text operator the document uses to show the text in the embedded font
+func shown(t *testing.T, text string) string {
+	doc := pdf.New(10, 10)
+	doc.AddPage().Text(0, 0, 8, text)
+	buf := &bytes.Buffer{}
+	_, err := doc.WriteTo(buf)
+	assert.NoError(t, err)
+
+	return regexp.MustCompile(`<[0-9A-F]*> Tj`).FindString(buf.String())
+}
@@ -0,0 +1,160 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf16"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+)
+
+// glyphSpace is the size of the PDF glyph space, widths and metrics are given in thousandths of an em
+var glyphSpace = fixed.I(1000)
+
+// embeddedFont is the Go Regular TrueType font, unlike the standard PDF fonts it has Latin,
+// Greek and Cyrillic glyphs. It is embedded whole into every document.
+type embeddedFont struct {
+	sfnt       *sfnt.Font
+	name       string
+	missing    sfnt.GlyphIndex
+	advances   map[sfnt.GlyphIndex]float64
+	descriptor string
+	file       []byte
+}
+
+var goRegular = loadFont(goregular.TTF)
+
+func loadFont(ttf []byte) *embeddedFont {
+	f, err := sfnt.Parse(ttf)
+	if err != nil {
+		panic(err)
+	}
+
+	b := &sfnt.Buffer{}
+	name, err := f.Name(b, sfnt.NameIDPostScript)
+	if err != nil {
+		panic(err)
+	}
+
+	bounds, err := f.Bounds(b, glyphSpace, font.HintingNone)
+	if err != nil {
+		panic(err)
+	}
+
+	metrics, err := f.Metrics(b, glyphSpace, font.HintingNone)
+	if err != nil {
+		panic(err)
+	}
+
+	e := &embeddedFont{
+		sfnt:     f,
+		name:     name,
+		advances: make(map[sfnt.GlyphIndex]float64),
+	}
+
+	// the font's Y axis increases down while the PDF one increases up
+	e.descriptor = fmt.Sprintf(
+		"/FontBBox [%d %d %d %d] /Ascent %d /Descent %d /CapHeight %d /ItalicAngle 0 /StemV 80 /Flags 32",
+		bounds.Min.X.Round(), -bounds.Max.Y.Round(), bounds.Max.X.Round(), -bounds.Min.Y.Round(),
+		metrics.Ascent.Round(), -metrics.Descent.Round(), metrics.CapHeight.Round(),
+	)
+
+	for x := 0; x < f.NumGlyphs(); x++ {
+		advance, err := f.GlyphAdvance(b, sfnt.GlyphIndex(x), glyphSpace, font.HintingNone)
+		if err != nil {
+			panic(err)
+		}
+		e.advances[sfnt.GlyphIndex(x)] = float64(advance) / 64
+	}
+
+	if e.missing, err = f.GlyphIndex(b, '?'); err != nil {
+		panic(err)
+	}
+
+	buf := &bytes.Buffer{}
+	zw := zlib.NewWriter(buf)
+	zw.Write(ttf)
+	zw.Close()
+	e.file = buf.Bytes()
+
+	return e
+}
+
+// glyph returns the glyph of the rune and the rune it stands for, runes the font has
+// no glyph for are replaced with "?"
+func (e *embeddedFont) glyph(r rune) (sfnt.GlyphIndex, rune) {
+	x, err := e.sfnt.GlyphIndex(nil, r)
+	if err != nil || x == 0 {
+		return e.missing, '?'
+	}
+
+	return x, r
+}
+
+// objects returns the Type0 font with the given object number and the objects following it:
+// the CID font, its descriptor, the font file and the map of the used glyphs back to text
+func (e *embeddedFont) objects(number int, used map[sfnt.GlyphIndex]rune) []string {
+	glyphs := make([]sfnt.GlyphIndex, 0, len(used))
+	for x := range used {
+		glyphs = append(glyphs, x)
+	}
+	sort.Slice(glyphs, func(i, j int) bool { return glyphs[i] < glyphs[j] })
+
+	widths := make([]string, 0, len(glyphs))
+	for _, x := range glyphs {
+		widths = append(widths, fmt.Sprintf("%d [%s]", x, num(e.advances[x])))
+	}
+
+	toUnicode := toUnicodeCMap(glyphs, used)
+
+	return []string{
+		fmt.Sprintf(
+			"<< /Type /Font /Subtype /Type0 /BaseFont /%s /Encoding /Identity-H /DescendantFonts [%d 0 R] /ToUnicode %d 0 R >>",
+			e.name, number+1, number+4,
+		),
+		fmt.Sprintf(
+			"<< /Type /Font /Subtype /CIDFontType2 /BaseFont /%s /CIDSystemInfo << /Registry (Adobe) /Ordering (Identity) /Supplement 0 >> /FontDescriptor %d 0 R /CIDToGIDMap /Identity /W [%s] >>",
+			e.name, number+2, strings.Join(widths, " "),
+		),
+		fmt.Sprintf("<< /Type /FontDescriptor /FontName /%s %s /FontFile2 %d 0 R >>", e.name, e.descriptor, number+3),
+		fmt.Sprintf("<< /Length %d /Length1 %d /Filter /FlateDecode >>\nstream\n%s\nendstream", len(e.file), len(goregular.TTF), e.file),
+		fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", len(toUnicode), toUnicode),
+	}
+}
+
+// toUnicodeCMap lets viewers copy and search the text written with glyph indexes
+func toUnicodeCMap(glyphs []sfnt.GlyphIndex, used map[sfnt.GlyphIndex]rune) string {
+	b := strings.Builder{}
+	b.WriteString("/CIDInit /ProcSet findresource begin\n12 dict begin\nbegincmap\n")
+	b.WriteString("/CIDSystemInfo << /Registry (Adobe) /Ordering (UCS) /Supplement 0 >> def\n")
+	b.WriteString("/CMapName /Adobe-Identity-UCS def\n/CMapType 2 def\n")
+	b.WriteString("1 begincodespacerange\n<0000> <FFFF>\nendcodespacerange\n")
+
+	// a bfchar block holds at most 100 entries
+	for start := 0; start < len(glyphs); start += 100 {
+		end := start + 100
+		if end > len(glyphs) {
+			end = len(glyphs)
+		}
+
+		fmt.Fprintf(&b, "%d beginbfchar\n", end-start)
+		for _, x := range glyphs[start:end] {
+			fmt.Fprintf(&b, "<%04X> <", x)
+			for _, unit := range utf16.Encode([]rune{used[x]}) {
+				fmt.Fprintf(&b, "%04X", unit)
+			}
+			b.WriteString(">\n")
+		}
+		b.WriteString("endbfchar\n")
+	}
+
+	b.WriteString("endcmap\nCMapName currentdict /CMap defineresource pop\nend\nend\n")
+
+	return b.String()
+}
@@ -0,0 +1,159 @@
+// Package pdf writes simple vector PDF documents with filled rectangles, lines and text set in the
+// embedded Go Regular font. Coordinates are in millimetres with the origin in the top left corner of the page.
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/image/font/sfnt"
+)
+
+const pointsPerMM = 72 / 25.4
+
+// fontObject is the object number of the font, the font objects are followed by the pages
+const fontObject = 3
+
+type Document struct {
+	width  float64
+	height float64
+	pages  []*Page
+	// glyphs are the glyphs used in the text with the runes they stand for
+	glyphs map[sfnt.GlyphIndex]rune
+}
+
+type Page struct {
+	doc     *Document
+	content bytes.Buffer
+}
+
+// New creates a document with pages of the given size in millimetres
+func New(width, height float64) *Document {
+	return &Document{
+		width:  width,
+		height: height,
+		glyphs: make(map[sfnt.GlyphIndex]rune),
+	}
+}
+
+func (d *Document) AddPage() *Page {
+	p := &Page{doc: d}
+	d.pages = append(d.pages, p)
+	return p
+}
+
+func (d *Document) PageCount() int {
+	return len(d.pages)
+}
+
+// Rect draws a filled black rectangle
+func (p *Page) Rect(x, y, w, h float64) {
+	fmt.Fprintf(&p.content, "%s %s %s %s re f\n", pt(x), p.y(y+h), pt(w), pt(h))
+}
+
+// Line draws a thin grey line, used for cut marks between labels
+func (p *Page) Line(x1, y1, x2, y2 float64) {
+	fmt.Fprintf(&p.content, "q 0.6 G 0.2 w %s %s m %s %s l S Q\n", pt(x1), p.y(y1), pt(x2), p.y(y2))
+}
+
+// Text draws a single line of text with the baseline at y, size is in points.
+// Characters the font has no glyph for are replaced with "?".
+func (p *Page) Text(x, y, size float64, text string) {
+	glyphs := strings.Builder{}
+	for _, r := range text {
+		g, r := goRegular.glyph(r)
+		p.doc.glyphs[g] = r
+		fmt.Fprintf(&glyphs, "%04X", g)
+	}
+
+	fmt.Fprintf(&p.content, "BT /F1 %s Tf %s %s Td <%s> Tj ET\n", num(size), pt(x), p.y(y), glyphs.String())
+}
+
+// TextWidth returns the width of the text in millimetres
+func TextWidth(text string, size float64) float64 {
+	width := 0.0
+	for _, r := range text {
+		g, _ := goRegular.glyph(r)
+		width += goRegular.advances[g]
+	}
+
+	return width / 1000 * size / pointsPerMM
+}
+
+// FitText shortens the text with an ellipsis so that it fits into the width
+func FitText(text string, size float64, width float64) string {
+	if TextWidth(text, size) <= width {
+		return text
+	}
+
+	runes := []rune(text)
+	for len(runes) > 0 && TextWidth(string(runes)+"...", size) > width {
+		runes = runes[:len(runes)-1]
+	}
+
+	return strings.TrimSpace(string(runes)) + "..."
+}
+
+// WriteTo writes the document in PDF 1.4 format
+func (d *Document) WriteTo(w io.Writer) (int64, error) {
+	buf := &bytes.Buffer{}
+	offsets := make([]int, 0)
+	object := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	fonts := goRegular.objects(fontObject, d.glyphs)
+
+	// pages follow the font objects, each page is followed by its content stream
+	firstPage := fontObject + len(fonts)
+	kids := make([]string, 0, len(d.pages))
+	for i := range d.pages {
+		kids = append(kids, fmt.Sprintf("%d 0 R", firstPage+i*2))
+	}
+
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	for _, f := range fonts {
+		object(f)
+	}
+
+	for i, p := range d.pages {
+		object(fmt.Sprintf(
+			"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] /Resources << /Font << /F1 %d 0 R >> >> /Contents %d 0 R >>",
+			pt(d.width), pt(d.height), fontObject, firstPage+i*2+1,
+		))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", p.content.Len(), p.content.String()))
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	n, err := w.Write(buf.Bytes())
+	return int64(n), err
+}
+
+func (p *Page) y(y float64) string {
+	return pt(p.doc.height - y)
+}
+
+func pt(mm float64) string {
+	return num(mm * pointsPerMM)
+}
+
+func num(v float64) string {
+	s := fmt.Sprintf("%.2f", v)
+	s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	if s == "" || s == "-0" {
+		return "0"
+	}
+	return s
+}
@@ -0,0 +1,61 @@
+package pdf_test
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"testing"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/pkg/pdf"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDocument_WriteTo(t *testing.T) {
+	doc := pdf.New(58, 40)
+	p := doc.AddPage()
+	p.Rect(2, 2, 10, 20)
+	p.Text(2, 30, 8, "Tray (wooden) Größe Менажница 中")
+	doc.AddPage().Line(0, 0, 58, 40)
+
+	buf := &bytes.Buffer{}
+	_, err := doc.WriteTo(buf)
+	assert.NoError(t, err)
+
+	out := buf.String()
+	assert.Regexp(t, `^%PDF-1\.4`, out)
+	assert.Contains(t, out, "/Count 2")
+	assert.Contains(t, out, "/MediaBox [0 0 164.41 113.39]")
+	assert.Contains(t, out, "/Subtype /Type0 /BaseFont /GoRegular /Encoding /Identity-H")
+	assert.Contains(t, out, "/FontFile2 6 0 R")
+	assert.Regexp(t, `BT /F1 8 Tf 5\.67 28\.35 Td <(?:[0-9A-F]{4}){31}> Tj ET`, out)
+
+	// the text is written with glyph indexes, the ToUnicode map turns them back into the characters
+	for _, r := range []string{"<0054>", "<00DF>", "<041C>", "<0446>", "<003F>"} {
+		assert.Contains(t, out, r)
+	}
+	assert.NotContains(t, out, "<4E2D>")
+	assert.Contains(t, out, "5.67 51.02 28.35 56.69 re f")
+
+	// every xref entry must point to the beginning of its object
+	xref := regexp.MustCompile(`startxref\n(\d+)`).FindStringSubmatch(out)
+	assert.Len(t, xref, 2)
+	start, _ := strconv.Atoi(xref[1])
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllStringSubmatch(out[start:], -1)
+	assert.Len(t, entries, 11)
+	for i, e := range entries {
+		offset, _ := strconv.Atoi(e[1])
+		assert.Equal(t, fmt.Sprintf("%d 0 obj", i+1), out[offset:offset+len(fmt.Sprintf("%d 0 obj", i+1))])
+	}
+}
+
+func TestFitText(t *testing.T) {
+	assert.Equal(t, "short", pdf.FitText("short", 8, 50))
+
+	fitted := pdf.FitText("a very long product name that doesn't fit", 8, 30)
+	assert.LessOrEqual(t, pdf.TextWidth(fitted, 8), 30.0)
+	assert.Regexp(t, `\.\.\.$`, fitted)
+
+	assert.Less(t, pdf.TextWidth("iiii", 8), pdf.TextWidth("WWWW", 8))
+	assert.Greater(t, pdf.TextWidth("Менажница", 8), pdf.TextWidth("Менаж", 8))
+}
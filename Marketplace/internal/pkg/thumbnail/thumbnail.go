@@ -0,0 +1,111 @@
+// Package thumbnail downscales images with a box filter using only the standard library
+package thumbnail
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+)
+
+// MaxPixels limits the width x height of decoded images, a small file may declare a huge
+// image and decoding it would allocate gigabytes
+const MaxPixels = 40_000_000
+
+var (
+	ErrInvalidSize   = errors.New("thumbnail size must be positive")
+	ErrImageTooLarge = errors.New("image has too many pixels")
+)
+
+// Make decodes an image and returns a thumbnail fitting into maxSide x maxSide pixels.
+// JPEG sources produce JPEG thumbnails, everything else is encoded as PNG.
+func Make(src []byte, maxSide int) ([]byte, string, error) {
+	if maxSide < 1 {
+		return nil, "", ErrInvalidSize
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(src))
+	if err != nil {
+		return nil, "", err
+	}
+	if cfg.Width*cfg.Height > MaxPixels {
+		return nil, "", ErrImageTooLarge
+	}
+
+	img, format, err := image.Decode(bytes.NewReader(src))
+	if err != nil {
+		return nil, "", err
+	}
+
+	thumb := Resize(img, maxSide)
+
+	buf := &bytes.Buffer{}
+	if format == "jpeg" {
+		if err = jpeg.Encode(buf, thumb, &jpeg.Options{Quality: 85}); err != nil {
+			return nil, "", err
+		}
+		return buf.Bytes(), "image/jpeg", nil
+	}
+
+	if err = png.Encode(buf, thumb); err != nil {
+		return nil, "", err
+	}
+	return buf.Bytes(), "image/png", nil
+}
+
+// Resize scales the image down preserving aspect ratio, smaller images are copied as is
+func Resize(img image.Image, maxSide int) image.Image {
+	b := img.Bounds()
+	srcW, srcH := b.Dx(), b.Dy()
+	dstW, dstH := srcW, srcH
+	if srcW > maxSide || srcH > maxSide {
+		if srcW >= srcH {
+			dstW = maxSide
+			dstH = max(1, srcH*maxSide/srcW)
+		} else {
+			dstH = maxSide
+			dstW = max(1, srcW*maxSide/srcH)
+		}
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, dstW, dstH))
+	for y := 0; y < dstH; y++ {
+		y0 := b.Min.Y + y*srcH/dstH
+		y1 := max(y0+1, b.Min.Y+(y+1)*srcH/dstH)
+		for x := 0; x < dstW; x++ {
+			x0 := b.Min.X + x*srcW/dstW
+			x1 := max(x0+1, b.Min.X+(x+1)*srcW/dstW)
+
+			var r, g, bl, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					c := color.NRGBA64Model.Convert(img.At(sx, sy)).(color.NRGBA64)
+					r += uint64(c.R)
+					g += uint64(c.G)
+					bl += uint64(c.B)
+					a += uint64(c.A)
+					n++
+				}
+			}
+
+			dst.Set(x, y, color.NRGBA64{
+				R: uint16(r / n),
+				G: uint16(g / n),
+				B: uint16(bl / n),
+				A: uint16(a / n),
+			})
+		}
+	}
+
+	return dst
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
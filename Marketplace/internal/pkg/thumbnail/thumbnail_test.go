@@ -0,0 +1,85 @@
+package thumbnail_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/pkg/thumbnail"
+	"github.com/stretchr/testify/assert"
+)
+
+func testImage(w, h int) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 100, A: 255})
+		}
+	}
+	return img
+}
+
+func TestMake(t *testing.T) {
+	buf := &bytes.Buffer{}
+	png.Encode(buf, testImage(400, 200))
+
+	thumb, contentType, err := thumbnail.Make(buf.Bytes(), 100)
+	assert.NoError(t, err)
+	assert.Equal(t, "image/png", contentType)
+
+	img, _, err := image.Decode(bytes.NewReader(thumb))
+	assert.NoError(t, err)
+	assert.Equal(t, 100, img.Bounds().Dx())
+	assert.Equal(t, 50, img.Bounds().Dy())
+}
+
+func TestMake_Jpeg(t *testing.T) {
+	buf := &bytes.Buffer{}
+	jpeg.Encode(buf, testImage(100, 300), nil)
+
+	thumb, contentType, err := thumbnail.Make(buf.Bytes(), 60)
+	assert.NoError(t, err)
+	assert.Equal(t, "image/jpeg", contentType)
+
+	img, _, err := image.Decode(bytes.NewReader(thumb))
+	assert.NoError(t, err)
+	assert.Equal(t, 20, img.Bounds().Dx())
+	assert.Equal(t, 60, img.Bounds().Dy())
+}
+
+func TestMake_Invalid(t *testing.T) {
+	_, _, err := thumbnail.Make([]byte("not an image"), 100)
+	assert.Error(t, err)
+
+	_, _, err = thumbnail.Make([]byte("not an image"), 0)
+	assert.EqualError(t, err, thumbnail.ErrInvalidSize.Error())
+}
+
+func TestMake_TooLarge(t *testing.T) {
+	// only the PNG signature and a header declaring 50000x50000 pixels
+	header := make([]byte, 13)
+	binary.BigEndian.PutUint32(header[0:], 50000)
+	binary.BigEndian.PutUint32(header[4:], 50000)
+	header[8], header[9] = 8, 2
+
+	buf := &bytes.Buffer{}
+	buf.WriteString("\x89PNG\r\n\x1a\n")
+	binary.Write(buf, binary.BigEndian, uint32(len(header)))
+	chunk := append([]byte("IHDR"), header...)
+	buf.Write(chunk)
+	binary.Write(buf, binary.BigEndian, crc32.ChecksumIEEE(chunk))
+
+	_, _, err := thumbnail.Make(buf.Bytes(), 100)
+	assert.EqualError(t, err, thumbnail.ErrImageTooLarge.Error())
+}
+
+func TestResize_Small(t *testing.T) {
+	img := thumbnail.Resize(testImage(10, 20), 100)
+	assert.Equal(t, 10, img.Bounds().Dx())
+	assert.Equal(t, 20, img.Bounds().Dy())
+}
@@ -0,0 +1,63 @@
+package totp
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCode_RFC6238(t *testing.T) {
+	key := []byte("12345678901234567890")
+	testCases := map[int64]string{
+		59:          "94287082",
+		1111111109:  "07081804",
+		1111111111:  "14050471",
+		1234567890:  "89005924",
+		2000000000:  "69279037",
+		20000000000: "65353130",
+	}
+
+	for unix, expected := range testCases {
+		assert.Equal(t, expected, code(key, uint64(counter(time.Unix(unix, 0))), 8))
+	}
+
+	secret := base32.StdEncoding.EncodeToString(key)
+	c, err := Code(secret, time.Unix(59, 0))
+	assert.NoError(t, err)
+	assert.Equal(t, "287082", c)
+}
+
+func TestValidate(t *testing.T) {
+	secret, err := GenerateSecret()
+	assert.NoError(t, err)
+	assert.Len(t, secret, 32)
+
+	at := time.Date(2022, 12, 9, 10, 0, 0, 0, time.UTC)
+	c, _ := Code(secret, at.Add(-Period))
+
+	step, ok := Validate(secret, c, at, 1)
+	assert.True(t, ok)
+	assert.Equal(t, at.Add(-Period).Unix()/30, step)
+
+	_, ok = Validate(secret, c, at.Add(Period), 1)
+	assert.False(t, ok)
+	_, ok = Validate(secret, "12345", at, 1)
+	assert.False(t, ok)
+	_, ok = Validate("not base32!", c, at, 1)
+	assert.False(t, ok)
+
+	// secrets are accepted in lower case
+	_, ok = Validate(strings.ToLower(secret), c, at, 1)
+	assert.True(t, ok)
+}
+
+func TestURI(t *testing.T) {
+	assert.Equal(t,
+		"otpauth://totp/Marketplace:ex@test.org?algorithm=SHA1&digits=6&issuer=Marketplace&period=30&secret=JBSWY3DPEHPK3PXP",
+		URI("Marketplace", "ex@test.org", "JBSWY3DPEHPK3PXP"),
+	)
+	assert.Contains(t, URI("Market Place", "ex@test.org", "JBSWY3DPEHPK3PXP"), "totp/Market%20Place:")
+}
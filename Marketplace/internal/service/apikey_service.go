@@ -0,0 +1,116 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store"
+)
+
+const (
+	maxAPIKeys     = 20
+	apiKeyBytes    = 32
+	apiKeyShownLen = len(model.APIKeyPrefix) + 6
+	// apiKeyTouchInterval limits how often the last use of a key is saved
+	apiKeyTouchInterval = time.Minute
+)
+
+var (
+	errTooManyAPIKeys   = errors.New("no more than 20 API keys are allowed")
+	errAPIKeyExpiration = errors.New("expiry date of the API key must be in the future")
+	errInvalidAPIKey    = errors.New("API key is invalid or expired")
+)
+
+type APIKeyService struct {
+	store store.Store
+}
+
+func NewAPIKeyService(store store.Store) *APIKeyService {
+	return &APIKeyService{
+		store: store,
+	}
+}
+
+func (s *APIKeyService) GetAPIKeys(userId int) ([]*model.APIKey, error) {
+	return s.store.APIKey().FindByUserId(userId)
+}
+
+// CreateAPIKey generates the key, only its hash is saved and the key is returned this time only
+func (s *APIKeyService) CreateAPIKey(userId int, k *model.APIKey, at time.Time) error {
+	if k.ExpiresAt != nil && !k.ExpiresAt.After(at) {
+		return errAPIKeyExpiration
+	}
+
+	keys, err := s.store.APIKey().FindByUserId(userId)
+	if err != nil {
+		return err
+	}
+	if len(keys) >= maxAPIKeys {
+		return errTooManyAPIKeys
+	}
+
+	secret := make([]byte, apiKeyBytes)
+	if _, err = rand.Read(secret); err != nil {
+		return err
+	}
+
+	key := model.APIKeyPrefix + base64.RawURLEncoding.EncodeToString(secret)
+	k.UserID = userId
+	k.Key = key
+	k.Prefix = key[:apiKeyShownLen]
+	k.KeyHash = hashToken(key)
+	k.LastUsedAt = nil
+	k.CreatedAt = at.UTC()
+
+	return s.store.APIKey().Create(k)
+}
+
+func (s *APIKeyService) DeleteAPIKey(apiKeyId int, userId int) error {
+	keys, err := s.store.APIKey().FindByUserId(userId)
+	if err != nil {
+		return err
+	}
+
+	for _, k := range keys {
+		if k.APIKeyID == apiKeyId {
+			return s.store.APIKey().Delete(apiKeyId)
+		}
+	}
+
+	return store.ErrRecordNotFound
+}
+
+// Authenticate returns the user of the key and the key, the use of the key is saved
+func (s *APIKeyService) Authenticate(key string, at time.Time) (*model.User, *model.APIKey, error) {
+	if !strings.HasPrefix(key, model.APIKeyPrefix) {
+		return nil, nil, errInvalidAPIKey
+	}
+
+	k, err := s.store.APIKey().FindByHash(hashToken(key))
+	if err == store.ErrRecordNotFound {
+		return nil, nil, errInvalidAPIKey
+	} else if err != nil {
+		return nil, nil, err
+	}
+
+	if k.Expired(at) {
+		return nil, nil, errInvalidAPIKey
+	}
+
+	u, err := s.store.User().FindById(k.UserID)
+	if err != nil || !u.Active {
+		return nil, nil, errInvalidAPIKey
+	}
+
+	if k.LastUsedAt == nil || at.Sub(*k.LastUsedAt) >= apiKeyTouchInterval {
+		if err = s.store.APIKey().Touch(k.APIKeyID, at.UTC()); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return u, k, nil
+}
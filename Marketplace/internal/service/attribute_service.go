@@ -0,0 +1,65 @@
+package service
+
+import (
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store"
+)
+
+type AttributeService struct {
+	store store.Store
+}
+
+func NewAttributeService(store store.Store) *AttributeService {
+	return &AttributeService{
+		store: store,
+	}
+}
+
+// GetCategorySchema returns attributes of the category including the ones inherited from parent categories
+func (s *AttributeService) GetCategorySchema(categoryId int) ([]*model.CategoryAttribute, error) {
+	categories, err := s.store.Product().GetCategories()
+	if err != nil && err != store.ErrRecordNotFound {
+		return nil, err
+	}
+
+	attributes, err := s.store.Attribute().FindByCategoryIds(model.CategoryPath(categoryId, categories))
+	if err != nil {
+		return nil, err
+	}
+
+	return model.CategorySchema(categoryId, categories, attributes), nil
+}
+
+func (s *AttributeService) CreateCategoryAttribute(categoryId int, a *model.CategoryAttribute) error {
+	a.CategoryID = categoryId
+
+	return s.store.Attribute().Create(a)
+}
+
+func (s *AttributeService) DeleteCategoryAttribute(categoryId int, attributeId int) error {
+	return s.store.Attribute().Delete(categoryId, attributeId)
+}
+
+// validateProduct checks product attribute values against the schema of the product category
+func (s *AttributeService) validateProduct(p *model.Product) error {
+	schema, err := s.GetCategorySchema(p.CategoryID)
+	if err != nil {
+		return err
+	}
+
+	return model.ValidateAttributes(schema, p.Attributes)
+}
+
+func (s *AttributeService) attachAttributes(p *model.Product) error {
+	values, err := s.store.Attribute().FindProductValues(p.ProductID)
+	if err != nil {
+		return err
+	}
+
+	p.Attributes = nil
+	if len(values) > 0 {
+		p.Attributes = values
+	}
+
+	return nil
+}
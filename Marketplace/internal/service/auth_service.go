@@ -1,10 +1,37 @@
 package service
 
 import (
+	"crypto/rand"
+	"encoding/base64"
 	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
 
 	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/pkg/mailer"
 	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store"
+	validation "github.com/go-ozzo/ozzo-validation"
+)
+
+const (
+	userTokenBytes   = 32
+	verifyEmailTTL   = 48 * time.Hour
+	resetPasswordTTL = time.Hour
+)
+
+var (
+	// ErrEmailNotVerified is returned by SignIn for a correct password of an unverified user
+	ErrEmailNotVerified = errors.New("email is not verified")
+	// ErrAccountDeactivated is returned by SignIn for a correct password of a deactivated user
+	ErrAccountDeactivated = errors.New("account is deactivated")
+
+	errMailDisabled      = errors.New("sending emails is not configured")
+	errInvalidUserToken  = errors.New("token is invalid or expired")
+	errIncorrectPassword = errors.New("incorrect password")
+	errSameEmail         = errors.New("it is the current email")
+	errEmailTaken        = errors.New("email is used by another account")
 )
 
 type InputUser struct {
@@ -12,16 +39,28 @@ type InputUser struct {
 	Password string `json:"password"`
 }
 
+// AuthService registers and signs in users. With a mailer new users verify their email
+// before signing in and passwords are reset with links sent by email, the links point to
+// pages of the web app at appURL. New passwords are checked against the policy and hashed with
+// the hasher, hashes made with other settings are replaced when the user signs in.
 type AuthService struct {
-	store store.Store
+	store  store.Store
+	mailer mailer.Mailer
+	appURL string
+	policy model.PasswordPolicy
+	hasher model.PasswordHasher
 }
 
 func (s *AuthService) Register(req *InputUser) (*model.User, error) {
 	u := &model.User{
-		Email:    req.Email,
-		Password: req.Password,
-		UserRole: 2,
-		Active:   true,
+		Email:         req.Email,
+		UserRole:      model.UserRoleSeller,
+		Active:        true,
+		EmailVerified: s.mailer == nil,
+	}
+
+	if err := s.encryptPassword(u, req.Password); err != nil {
+		return nil, err
 	}
 
 	if err := s.store.User().Create(u); err != nil {
@@ -30,6 +69,12 @@ func (s *AuthService) Register(req *InputUser) (*model.User, error) {
 
 	u.Sanitize()
 
+	if !u.EmailVerified {
+		if err := s.sendToken(u, u.Email, model.UserTokenVerifyEmail, time.Now()); err != nil {
+			return nil, err
+		}
+	}
+
 	return u, nil
 }
 
@@ -39,24 +84,389 @@ func (s *AuthService) SignIn(req *InputUser) (*model.User, error) {
 		return nil, err
 	}
 
-	if !u.ComparePassword(req.Password) {
+	if !s.hasher.Verify(u.EncryptedPassword, req.Password) {
 		return nil, errors.New("invalid Password")
 	}
 
+	if !u.Active {
+		return nil, ErrAccountDeactivated
+	}
+
+	if !u.EmailVerified {
+		return nil, ErrEmailNotVerified
+	}
+
 	return u, nil
 }
 
+// RehashPassword hashes the password again with the current settings when the hash of the
+// user was made with other ones, password is the one the user has just signed in with
+func (s *AuthService) RehashPassword(u *model.User, password string) error {
+	if !s.hasher.NeedsRehash(u.EncryptedPassword) {
+		return nil
+	}
+
+	u.Password = password
+	if err := u.EncryptPassword(s.hasher); err != nil {
+		return err
+	}
+	u.Sanitize()
+
+	return s.store.User().UpdatePassword(u)
+}
+
 func (s *AuthService) Authenticate(id int) (*model.User, error) {
 	u, err := s.store.User().FindById(id)
 	if err != nil {
 		return nil, err
 	}
 
+	if !u.Active {
+		return nil, ErrAccountDeactivated
+	}
+
 	return u, nil
 }
 
-func NewAuthService(store store.Store) *AuthService {
+// MailEnabled reports whether verification and password reset emails can be sent
+func (s *AuthService) MailEnabled() bool {
+	return s.mailer != nil
+}
+
+// ResendVerification sends a new verification link, earlier links stop working. Unknown and
+// verified emails are ignored so that the response doesn't tell whether an account exists.
+func (s *AuthService) ResendVerification(email string, at time.Time) error {
+	if s.mailer == nil {
+		return errMailDisabled
+	}
+
+	u, err := s.store.User().FindByEmail(email)
+	if err == store.ErrRecordNotFound {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	if u.EmailVerified || !u.Active {
+		return nil
+	}
+
+	return s.sendToken(u, u.Email, model.UserTokenVerifyEmail, at)
+}
+
+func (s *AuthService) VerifyEmail(token string, at time.Time) (*model.User, error) {
+	t, u, err := s.useToken(token, model.UserTokenVerifyEmail, at)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.store.User().VerifyEmail(u.ID); err != nil {
+		return nil, err
+	}
+	u.EmailVerified = true
+
+	return u, s.store.UserToken().DeleteByUserId(t.UserID, t.Purpose)
+}
+
+// ForgotPassword sends a password reset link, unknown emails are ignored like in ResendVerification
+func (s *AuthService) ForgotPassword(email string, at time.Time) error {
+	if s.mailer == nil {
+		return errMailDisabled
+	}
+
+	u, err := s.store.User().FindByEmail(email)
+	if err == store.ErrRecordNotFound {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	if !u.Active {
+		return nil
+	}
+
+	return s.sendToken(u, u.Email, model.UserTokenResetPassword, at)
+}
+
+// ResetPassword sets the password of the user the token was sent to. Following the link proves
+// the user owns the email, so an unverified email becomes verified.
+func (s *AuthService) ResetPassword(token string, password string, at time.Time) (*model.User, error) {
+	// the password is checked before the token is used, so that the user can choose another one
+	email := ""
+	if t, err := s.store.UserToken().FindByHash(hashToken(token)); err == nil {
+		email = t.Email
+	}
+	if err := s.checkPassword(password, email); err != nil {
+		return nil, err
+	}
+
+	t, u, err := s.useToken(token, model.UserTokenResetPassword, at)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.encryptPassword(u, password); err != nil {
+		return nil, err
+	}
+
+	if err = s.store.User().UpdatePassword(u); err != nil {
+		return nil, err
+	}
+
+	if !u.EmailVerified {
+		if err = s.store.User().VerifyEmail(u.ID); err != nil {
+			return nil, err
+		}
+		u.EmailVerified = true
+	}
+
+	return u, s.store.UserToken().DeleteByUserId(t.UserID, t.Purpose)
+}
+
+// ChangePassword sets the new password of a signed in user who knows the current one
+func (s *AuthService) ChangePassword(u *model.User, currentPassword string, password string) error {
+	if !s.hasher.Verify(u.EncryptedPassword, currentPassword) {
+		return errIncorrectPassword
+	}
+
+	if err := s.encryptPassword(u, password); err != nil {
+		return err
+	}
+
+	return s.store.User().UpdatePassword(u)
+}
+
+// ChangeEmail asks a signed in user for the password and sends a confirmation link to the new
+// email, the email changes when the link is followed. Without a mailer the email changes at once,
+// it reports whether the change waits for the confirmation.
+func (s *AuthService) ChangeEmail(u *model.User, email string, password string, at time.Time) (bool, error) {
+	if !s.hasher.Verify(u.EncryptedPassword, password) {
+		return false, errIncorrectPassword
+	}
+
+	email = strings.TrimSpace(email)
+	if err := (&model.User{Email: email}).ValidateEmail(); err != nil {
+		return false, validation.Errors{"email": err}
+	}
+
+	if strings.EqualFold(email, u.Email) {
+		return false, errSameEmail
+	}
+
+	if _, err := s.store.User().FindByEmail(email); err == nil {
+		return false, errEmailTaken
+	} else if err != store.ErrRecordNotFound {
+		return false, err
+	}
+
+	if s.mailer != nil {
+		return true, s.sendToken(u, email, model.UserTokenChangeEmail, at)
+	}
+
+	return false, s.updateEmail(u, email)
+}
+
+// ConfirmEmailChange sets the email the token was sent to and tells the previous email about the change
+func (s *AuthService) ConfirmEmailChange(token string, at time.Time) (*model.User, error) {
+	t, u, err := s.useToken(token, model.UserTokenChangeEmail, at)
+	if err != nil {
+		return nil, err
+	}
+
+	previous := u.Email
+	if err = s.updateEmail(u, t.Email); err != nil {
+		return nil, err
+	}
+
+	if err = s.store.UserToken().DeleteByUserId(t.UserID, t.Purpose); err != nil {
+		return nil, err
+	}
+
+	if s.mailer == nil {
+		return u, nil
+	}
+
+	return u, s.mailer.Send(&mailer.Message{
+		To:      previous,
+		Subject: "Your email was changed",
+		Body: fmt.Sprintf(
+			"The email of your account was changed to %s. If you didn't change it, reset your password "+
+				"with the new email or contact support.",
+			t.Email,
+		),
+	})
+}
+
+// Deactivate turns off the account of a signed in user who knows the password, the user can't sign in anymore
+func (s *AuthService) Deactivate(u *model.User, password string) error {
+	if !s.hasher.Verify(u.EncryptedPassword, password) {
+		return errIncorrectPassword
+	}
+
+	if err := s.store.User().UpdateActive(u.ID, false); err != nil {
+		return err
+	}
+	u.Active = false
+
+	return nil
+}
+
+// Activate lets a deactivated user sign in again
+func (s *AuthService) Activate(userId int) error {
+	return s.store.User().UpdateActive(userId, true)
+}
+
+// updateEmail saves the email, it is verified since the user has either followed the link
+// sent to it or emails aren't verified at all
+func (s *AuthService) updateEmail(u *model.User, email string) error {
+	changed := *u
+	changed.Email = email
+	changed.EmailVerified = true
+	if err := s.store.User().UpdateEmail(&changed); err == store.ErrRecordExists {
+		return errEmailTaken
+	} else if err != nil {
+		return err
+	}
+
+	u.Email = changed.Email
+	u.EmailVerified = true
+	return nil
+}
+
+// checkPassword applies the password policy, the error is keyed by the field like validation
+// errors of the model
+func (s *AuthService) checkPassword(password string, email string) error {
+	if err := s.policy.Check(password, email); err != nil {
+		return validation.Errors{"password": err}
+	}
+
+	return nil
+}
+
+// encryptPassword checks the new password of the user and replaces it with its hash
+func (s *AuthService) encryptPassword(u *model.User, password string) error {
+	if err := s.checkPassword(password, u.Email); err != nil {
+		return err
+	}
+
+	u.Password = password
+	if err := u.EncryptPassword(s.hasher); err == model.ErrPasswordTooLong {
+		return validation.Errors{"password": err}
+	} else if err != nil {
+		return err
+	}
+	u.Sanitize()
+
+	return nil
+}
+
+func (s *AuthService) DeleteExpiredTokens() (int, error) {
+	return s.store.UserToken().DeleteExpired(time.Now().UTC())
+}
+
+// useToken marks the token used, a token works once even if two requests race for it
+func (s *AuthService) useToken(token string, purpose string, at time.Time) (*model.UserToken, *model.User, error) {
+	t, err := s.store.UserToken().FindByHash(hashToken(token))
+	if err == store.ErrRecordNotFound {
+		return nil, nil, errInvalidUserToken
+	} else if err != nil {
+		return nil, nil, err
+	}
+
+	if t.Purpose != purpose || !t.Usable(at) {
+		return nil, nil, errInvalidUserToken
+	}
+
+	u, err := s.store.User().FindById(t.UserID)
+	if err != nil {
+		return nil, nil, errInvalidUserToken
+	}
+
+	// the token was sent to an address the user no longer has, a change email token is sent
+	// to the new address and replaced when the user asks for another change
+	if purpose != model.UserTokenChangeEmail && !strings.EqualFold(u.Email, t.Email) {
+		return nil, nil, errInvalidUserToken
+	}
+
+	if err = s.store.UserToken().Use(t.TokenHash, at.UTC()); err == store.ErrTokenUsed {
+		return nil, nil, errInvalidUserToken
+	} else if err != nil {
+		return nil, nil, err
+	}
+
+	return t, u, nil
+}
+
+// sendToken replaces earlier tokens of the purpose with a new one and emails the link to the email
+func (s *AuthService) sendToken(u *model.User, email string, purpose string, at time.Time) error {
+	secret := make([]byte, userTokenBytes)
+	if _, err := rand.Read(secret); err != nil {
+		return err
+	}
+	token := base64.RawURLEncoding.EncodeToString(secret)
+
+	if err := s.store.UserToken().DeleteByUserId(u.ID, purpose); err != nil {
+		return err
+	}
+
+	msg := &mailer.Message{To: email}
+	ttl := verifyEmailTTL
+	switch purpose {
+	case model.UserTokenVerifyEmail:
+		msg.Subject = "Confirm your email"
+		msg.Body = fmt.Sprintf(
+			"Follow the link to confirm your email:\n\n%s\n\nThe link is valid for %s.",
+			s.link("/verify_email", token), formatHours(verifyEmailTTL),
+		)
+	case model.UserTokenResetPassword:
+		ttl = resetPasswordTTL
+		msg.Subject = "Reset your password"
+		msg.Body = fmt.Sprintf(
+			"Follow the link to set a new password:\n\n%s\n\nThe link is valid for %s. "+
+				"If you didn't ask to reset the password, ignore this email.",
+			s.link("/reset_password", token), formatHours(resetPasswordTTL),
+		)
+	case model.UserTokenChangeEmail:
+		msg.Subject = "Confirm your new email"
+		msg.Body = fmt.Sprintf(
+			"Follow the link to use this email for your account:\n\n%s\n\nThe link is valid for %s. "+
+				"If you didn't ask to change the email, ignore this email.",
+			s.link("/confirm_email", token), formatHours(verifyEmailTTL),
+		)
+	}
+
+	if err := s.store.UserToken().Create(&model.UserToken{
+		TokenHash: hashToken(token),
+		UserID:    u.ID,
+		Purpose:   purpose,
+		Email:     email,
+		CreatedAt: at.UTC(),
+		ExpiresAt: at.Add(ttl).UTC(),
+	}); err != nil {
+		return err
+	}
+
+	return s.mailer.Send(msg)
+}
+
+func (s *AuthService) link(path string, token string) string {
+	return s.appURL + path + "?token=" + url.QueryEscape(token)
+}
+
+func formatHours(d time.Duration) string {
+	if h := int(d.Hours()); h != 1 {
+		return fmt.Sprintf("%d hours", h)
+	}
+
+	return "1 hour"
+}
+
+func NewAuthService(store store.Store, mailer mailer.Mailer, appURL string, policy model.PasswordPolicy, hasher model.PasswordHasher) *AuthService {
 	return &AuthService{
-		store: store,
+		store:  store,
+		mailer: mailer,
+		appURL: strings.TrimSuffix(appURL, "/"),
+		policy: policy,
+		hasher: hasher,
 	}
 }
@@ -0,0 +1,113 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store"
+)
+
+// maxBarcodeAttempts limits the search for a free serial when internal barcodes were added manually
+const maxBarcodeAttempts = 1000
+
+type BarcodeService struct {
+	store  store.Store
+	prefix string
+}
+
+func NewBarcodeService(store store.Store, prefix string) *BarcodeService {
+	return &BarcodeService{
+		store:  store,
+		prefix: prefix,
+	}
+}
+
+func (s *BarcodeService) GetProductBarcodes(productId int, userId int) ([]*model.Barcode, error) {
+	if _, err := ownProduct(s.store, productId, userId); err != nil {
+		return nil, err
+	}
+
+	return s.store.Barcode().FindByProductId(productId)
+}
+
+// AddBarcode assigns an existing barcode to the product, a barcode can belong to one product of the seller
+func (s *BarcodeService) AddBarcode(productId int, userId int, code string) (*model.Barcode, error) {
+	if _, err := ownProduct(s.store, productId, userId); err != nil {
+		return nil, err
+	}
+
+	b := &model.Barcode{
+		ProductID: productId,
+		UserID:    userId,
+		Code:      code,
+	}
+
+	if err := s.create(b); err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+// GenerateBarcode assigns a new internal EAN-13 barcode built from the configured prefix
+func (s *BarcodeService) GenerateBarcode(productId int, userId int) (*model.Barcode, error) {
+	if _, err := ownProduct(s.store, productId, userId); err != nil {
+		return nil, err
+	}
+
+	count, err := s.store.Barcode().CountInternal(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	for serial := count + 1; serial <= count+maxBarcodeAttempts; serial++ {
+		code, err := model.GenerateBarcode(s.prefix, serial)
+		if err != nil {
+			return nil, err
+		}
+
+		if _, err = s.store.Barcode().FindByCode(userId, code); err == nil {
+			continue
+		} else if err != store.ErrRecordNotFound {
+			return nil, err
+		}
+
+		b := &model.Barcode{
+			ProductID: productId,
+			UserID:    userId,
+			Code:      code,
+			Internal:  true,
+		}
+		if err = s.store.Barcode().Create(b); err != nil {
+			return nil, err
+		}
+
+		return b, nil
+	}
+
+	return nil, fmt.Errorf("no free barcode found for prefix %s", s.prefix)
+}
+
+func (s *BarcodeService) DeleteBarcode(productId int, barcodeId int, userId int) error {
+	b, err := s.store.Barcode().Find(barcodeId)
+	if err != nil {
+		return err
+	}
+
+	if b.ProductID != productId || b.UserID != userId {
+		return store.ErrRecordNotFound
+	}
+
+	return s.store.Barcode().Delete(barcodeId)
+}
+
+func (s *BarcodeService) create(b *model.Barcode) error {
+	b.BeforeCreate()
+	if existing, err := s.store.Barcode().FindByCode(b.UserID, b.Code); err == nil {
+		return fmt.Errorf("barcode %s is already assigned to product %d", existing.Code, existing.ProductID)
+	} else if err != store.ErrRecordNotFound {
+		return err
+	}
+
+	return s.store.Barcode().Create(b)
+}
@@ -0,0 +1,201 @@
+package service
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"net/http"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/pkg/filestorage"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/pkg/thumbnail"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store"
+	"github.com/google/uuid"
+)
+
+var errImageStorageNotConfigured = errors.New("image storage is not configured")
+
+var imageExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+}
+
+type ImageService struct {
+	store         store.Store
+	storage       filestorage.Storage
+	maxSize       int64
+	thumbnailSize int
+}
+
+func NewImageService(store store.Store, storage filestorage.Storage, maxSize int64, thumbnailSize int) *ImageService {
+	return &ImageService{
+		store:         store,
+		storage:       storage,
+		maxSize:       maxSize,
+		thumbnailSize: thumbnailSize,
+	}
+}
+
+func (s *ImageService) MaxSize() int64 {
+	return s.maxSize
+}
+
+// UploadImage validates the image, stores it with a thumbnail and appends it to the product images
+func (s *ImageService) UploadImage(productId int, userId int, r io.Reader) (*model.ProductImage, error) {
+	if s.storage == nil {
+		return nil, errImageStorageNotConfigured
+	}
+
+	if _, err := ownProduct(s.store, productId, userId); err != nil {
+		return nil, err
+	}
+
+	data, err := io.ReadAll(io.LimitReader(r, s.maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+
+	i := &model.ProductImage{
+		ProductID:   productId,
+		UserID:      userId,
+		ContentType: http.DetectContentType(data),
+		Size:        int64(len(data)),
+	}
+	if err = i.Validate(s.maxSize); err != nil {
+		return nil, err
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	i.Width = cfg.Width
+	i.Height = cfg.Height
+
+	thumb, thumbType, err := thumbnail.Make(data, s.thumbnailSize)
+	if err != nil {
+		return nil, err
+	}
+
+	name := uuid.New().String()
+	i.FileKey = fmt.Sprintf("products/%d/%s%s", productId, name, imageExtensions[i.ContentType])
+	i.ThumbnailKey = fmt.Sprintf("products/%d/%s_thumb%s", productId, name, imageExtensions[thumbType])
+
+	if err = s.storage.Save(i.FileKey, bytes.NewReader(data)); err != nil {
+		return nil, err
+	}
+	if err = s.storage.Save(i.ThumbnailKey, bytes.NewReader(thumb)); err != nil {
+		s.storage.Delete(i.FileKey)
+		return nil, err
+	}
+
+	if err = s.store.Image().Create(i); err != nil {
+		s.storage.Delete(i.FileKey)
+		s.storage.Delete(i.ThumbnailKey)
+		return nil, err
+	}
+
+	s.setURLs(i)
+
+	return i, nil
+}
+
+func (s *ImageService) GetProductImages(productId int) ([]*model.ProductImage, error) {
+	images, err := s.store.Image().FindByProductId(productId)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, i := range images {
+		s.setURLs(i)
+	}
+
+	return images, nil
+}
+
+// ReorderImages sets image positions in the order of ids, all product images must be listed
+func (s *ImageService) ReorderImages(productId int, userId int, imageIds []int) ([]*model.ProductImage, error) {
+	if _, err := ownProduct(s.store, productId, userId); err != nil {
+		return nil, err
+	}
+
+	images, err := s.store.Image().FindByProductId(productId)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(images) != len(imageIds) {
+		return nil, errors.New("all product images must be listed exactly once")
+	}
+
+	seen := make(map[int]bool, len(imageIds))
+	for _, id := range imageIds {
+		if seen[id] {
+			return nil, errors.New("all product images must be listed exactly once")
+		}
+		seen[id] = true
+	}
+
+	if err = s.store.Image().UpdatePositions(productId, imageIds); err != nil {
+		return nil, err
+	}
+
+	return s.GetProductImages(productId)
+}
+
+func (s *ImageService) DeleteImage(productId int, imageId int, userId int) error {
+	if _, err := ownProduct(s.store, productId, userId); err != nil {
+		return err
+	}
+
+	i, err := s.store.Image().Find(imageId)
+	if err != nil {
+		return err
+	}
+
+	if i.ProductID != productId {
+		return store.ErrRecordNotFound
+	}
+
+	if err = s.store.Image().Delete(imageId); err != nil {
+		return err
+	}
+
+	if s.storage != nil {
+		s.storage.Delete(i.FileKey)
+		s.storage.Delete(i.ThumbnailKey)
+	}
+
+	return nil
+}
+
+// OpenMedia opens a stored file by its key for serving
+func (s *ImageService) OpenMedia(key string) (io.ReadCloser, error) {
+	if s.storage == nil {
+		return nil, errImageStorageNotConfigured
+	}
+
+	return s.storage.Open(key)
+}
+
+func (s *ImageService) attachImages(p *model.Product) error {
+	images, err := s.GetProductImages(p.ProductID)
+	if err != nil {
+		return err
+	}
+
+	p.Images = images
+	return nil
+}
+
+func (s *ImageService) setURLs(i *model.ProductImage) {
+	if s.storage == nil {
+		return
+	}
+
+	i.URL = s.storage.URL(i.FileKey)
+	i.ThumbnailURL = s.storage.URL(i.ThumbnailKey)
+}
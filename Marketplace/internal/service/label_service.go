@@ -0,0 +1,149 @@
+package service
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/pkg/label"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store"
+)
+
+const maxLabels = 1000
+
+// LabelSizes are common thermal label sizes in millimetres, width x height
+var LabelSizes = map[string][2]float64{
+	"43x25":  {43, 25},
+	"58x30":  {58, 30},
+	"58x40":  {58, 40},
+	"75x120": {75, 120},
+}
+
+var (
+	errLabelItemsRequired = errors.New("at least one product with a positive quantity is required")
+	errTooManyLabels      = fmt.Errorf("no more than %d labels can be printed at once", maxLabels)
+)
+
+type LabelItem struct {
+	ProductID int    `json:"product_id"`
+	Quantity  int    `json:"quantity"`
+	Code      string `json:"code"`
+}
+
+type LabelRequest struct {
+	Items  []*LabelItem `json:"items"`
+	Size   string       `json:"size"`
+	Width  float64      `json:"width_mm"`
+	Height float64      `json:"height_mm"`
+	Sheet  bool         `json:"sheet"`
+}
+
+type LabelService struct {
+	store       store.Store
+	defaultSize string
+}
+
+func NewLabelService(store store.Store, defaultSize string) *LabelService {
+	return &LabelService{
+		store:       store,
+		defaultSize: defaultSize,
+	}
+}
+
+// RenderLabels returns a PDF with the requested number of labels per product.
+// Without an explicit code the first barcode of the product is printed.
+func (s *LabelService) RenderLabels(userId int, req *LabelRequest) ([]byte, error) {
+	layout, err := s.layout(req)
+	if err != nil {
+		return nil, err
+	}
+
+	labels := make([]*label.Label, 0)
+	for _, item := range req.Items {
+		if item.Quantity < 1 {
+			return nil, errLabelItemsRequired
+		}
+		if len(labels)+item.Quantity > maxLabels {
+			return nil, errTooManyLabels
+		}
+
+		l, err := s.productLabel(userId, item)
+		if err != nil {
+			return nil, err
+		}
+
+		for i := 0; i < item.Quantity; i++ {
+			labels = append(labels, l)
+		}
+	}
+
+	if len(labels) == 0 {
+		return nil, errLabelItemsRequired
+	}
+
+	buf := &bytes.Buffer{}
+	if err = label.Render(buf, labels, layout); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (s *LabelService) productLabel(userId int, item *LabelItem) (*label.Label, error) {
+	p, err := ownProduct(s.store, item.ProductID, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	code := strings.TrimSpace(item.Code)
+	if code == "" {
+		barcodes, err := s.store.Barcode().FindByProductId(p.ProductID)
+		if err != nil {
+			return nil, err
+		}
+		if len(barcodes) == 0 {
+			return nil, fmt.Errorf("product %d has no barcodes", p.ProductID)
+		}
+		code = barcodes[0].Code
+	}
+
+	skus := make([]string, 0, 2)
+	if p.OzonSKU != 0 {
+		skus = append(skus, fmt.Sprintf("OZ %d", p.OzonSKU))
+	}
+	if p.WildberriesSKU != 0 {
+		skus = append(skus, fmt.Sprintf("WB %d", p.WildberriesSKU))
+	}
+
+	return &label.Label{
+		Title:   p.ProductName,
+		Caption: strings.Join(skus, " / "),
+		Code:    code,
+	}, nil
+}
+
+func (s *LabelService) layout(req *LabelRequest) (label.Layout, error) {
+	layout := label.Layout{
+		Width:  req.Width,
+		Height: req.Height,
+		Sheet:  req.Sheet,
+	}
+
+	if layout.Width != 0 || layout.Height != 0 {
+		return layout, nil
+	}
+
+	name := req.Size
+	if name == "" {
+		name = s.defaultSize
+	}
+
+	size, ok := LabelSizes[name]
+	if !ok {
+		return layout, fmt.Errorf("unknown label size %q", name)
+	}
+	layout.Width, layout.Height = size[0], size[1]
+
+	return layout, nil
+}
@@ -0,0 +1,225 @@
+package service
+
+import (
+	"net"
+	"strings"
+	"time"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store"
+)
+
+const (
+	defaultSignInResetAfter     = 24 * time.Hour
+	defaultSignInAuditRetention = 90 * 24 * time.Hour
+	defaultSignInFailuresLimit  = 100
+	maxSignInFailuresLimit      = 1000
+)
+
+var (
+	DefaultAccountLockout = model.LockoutPolicy{
+		FreeAttempts:    3,
+		BaseDelay:       time.Second,
+		MaxDelay:        5 * time.Minute,
+		LockAfter:       10,
+		LockoutDuration: 30 * time.Minute,
+	}
+	// DefaultIPLockout allows more failures, many users may sign in from one address behind a NAT
+	DefaultIPLockout = model.LockoutPolicy{
+		FreeAttempts:    20,
+		BaseDelay:       time.Second,
+		MaxDelay:        5 * time.Minute,
+		LockAfter:       100,
+		LockoutDuration: time.Hour,
+	}
+)
+
+// LockoutService slows down and locks out password guessing. Failed sign-ins are counted per
+// account and per client address, the stricter of the two delays applies. Counters without
+// failures for resetAfter start over.
+type LockoutService struct {
+	store          store.Store
+	counters       store.AttemptCounterRepo
+	account        model.LockoutPolicy
+	ip             model.LockoutPolicy
+	resetAfter     time.Duration
+	auditRetention time.Duration
+}
+
+func NewLockoutService(store store.Store, counters store.AttemptCounterRepo, account model.LockoutPolicy, ip model.LockoutPolicy, resetAfter time.Duration, auditRetention time.Duration) *LockoutService {
+	if counters == nil {
+		counters = store.AttemptCounter()
+	}
+
+	return &LockoutService{
+		store:          store,
+		counters:       counters,
+		account:        account,
+		ip:             ip,
+		resetAfter:     resetAfter,
+		auditRetention: auditRetention,
+	}
+}
+
+// Check returns how long the client at ip has to wait before it may try to sign in as email
+func (s *LockoutService) Check(email string, ip string, at time.Time) (time.Duration, error) {
+	var wait time.Duration
+	for key, policy := range s.policies(email, ip) {
+		c, err := s.counters.Find(key)
+		if err == store.ErrRecordNotFound {
+			continue
+		} else if err != nil {
+			return 0, err
+		}
+
+		if c.LastFailureAt.Before(at.Add(-s.resetAfter)) {
+			continue
+		}
+
+		if retryAt := policy.RetryAt(c); retryAt.Sub(at) > wait {
+			wait = retryAt.Sub(at)
+		}
+	}
+
+	return wait, nil
+}
+
+// Fail counts a wrong password or an unknown email and saves it to the audit log
+func (s *LockoutService) Fail(email string, ip string, userAgent string, at time.Time) error {
+	f := &model.SignInFailure{
+		Email:     email,
+		IP:        ip,
+		UserAgent: userAgent,
+		Reason:    model.SignInFailureUnknownEmail,
+		CreatedAt: at.UTC(),
+	}
+
+	if u, err := s.store.User().FindByEmail(email); err == nil {
+		f.UserID = u.ID
+		f.Reason = model.SignInFailurePassword
+	} else if err != store.ErrRecordNotFound {
+		return err
+	}
+
+	return s.count(f, at)
+}
+
+// FailTwoFactor counts a wrong second factor of a user who entered the right password,
+// TOTP codes are guessed as easily as passwords
+func (s *LockoutService) FailTwoFactor(u *model.User, ip string, userAgent string, at time.Time) error {
+	return s.count(&model.SignInFailure{
+		UserID:    u.ID,
+		Email:     u.Email,
+		IP:        ip,
+		UserAgent: userAgent,
+		Reason:    model.SignInFailureTwoFactor,
+		CreatedAt: at.UTC(),
+	}, at)
+}
+
+// Refuse saves an attempt rejected by Check to the audit log, the attempt isn't counted
+// so the delay doesn't grow while the client waits
+func (s *LockoutService) Refuse(email string, ip string, userAgent string, at time.Time) error {
+	f := &model.SignInFailure{
+		Email:     email,
+		IP:        ip,
+		UserAgent: userAgent,
+		Reason:    model.SignInFailureThrottled,
+		CreatedAt: at.UTC(),
+	}
+
+	if u, err := s.store.User().FindByEmail(email); err == nil {
+		f.UserID = u.ID
+	} else if err != store.ErrRecordNotFound {
+		return err
+	}
+
+	return s.audit(f)
+}
+
+// Reset forgets failures of the account after a successful sign-in or a password reset. The
+// address keeps its failures, otherwise signing in to an own account would reset them.
+func (s *LockoutService) Reset(email string) error {
+	return s.counters.Reset(accountKey(email))
+}
+
+// Unlock lets the user sign in again after a lockout
+func (s *LockoutService) Unlock(userId int) error {
+	u, err := s.store.User().FindById(userId)
+	if err != nil {
+		return err
+	}
+
+	return s.Reset(u.Email)
+}
+
+// GetFailures returns the newest entries of the audit log, email and ip filter them when set
+func (s *LockoutService) GetFailures(email string, ip string, limit int) ([]*model.SignInFailure, error) {
+	if limit <= 0 {
+		limit = defaultSignInFailuresLimit
+	} else if limit > maxSignInFailuresLimit {
+		limit = maxSignInFailuresLimit
+	}
+
+	return s.store.SignInFailure().Find(email, ip, limit)
+}
+
+// DeleteExpired drops forgotten counters and audit log entries older than the retention
+func (s *LockoutService) DeleteExpired() (int, error) {
+	now := time.Now().UTC()
+
+	counters, err := s.counters.DeleteExpired(now.Add(-s.resetAfter))
+	if err != nil {
+		return 0, err
+	}
+
+	failures, err := s.store.SignInFailure().DeleteBefore(now.Add(-s.auditRetention))
+	return counters + failures, err
+}
+
+func (s *LockoutService) count(f *model.SignInFailure, at time.Time) error {
+	for key := range s.policies(f.Email, f.IP) {
+		if _, err := s.counters.Fail(key, at.UTC(), at.Add(-s.resetAfter).UTC()); err != nil {
+			return err
+		}
+	}
+
+	return s.audit(f)
+}
+
+func (s *LockoutService) audit(f *model.SignInFailure) error {
+	if len(f.Email) > 255 {
+		f.Email = f.Email[:255]
+	}
+	if len(f.UserAgent) > 255 {
+		f.UserAgent = f.UserAgent[:255]
+	}
+
+	return s.store.SignInFailure().Create(f)
+}
+
+func (s *LockoutService) policies(email string, ip string) map[string]model.LockoutPolicy {
+	return map[string]model.LockoutPolicy{
+		accountKey(email): s.account,
+		addressKey(ip):    s.ip,
+	}
+}
+
+func accountKey(email string) string {
+	email = strings.ToLower(strings.TrimSpace(email))
+	if len(email) > 255 {
+		email = email[:255]
+	}
+
+	return "account:" + email
+}
+
+// addressKey counts an IPv6 client by its /64 network, a single host usually gets the whole network
+func addressKey(ip string) string {
+	parsed := net.ParseIP(ip)
+	if parsed != nil && parsed.To4() == nil {
+		return "ip:" + parsed.Mask(net.CIDRMask(64, 128)).String() + "/64"
+	}
+
+	return "ip:" + ip
+}
@@ -0,0 +1,18 @@
+package service
+
+import "github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+
+type LogisticsService struct {
+	rules []*model.LogisticsRule
+}
+
+func NewLogisticsService(rules []*model.LogisticsRule) *LogisticsService {
+	return &LogisticsService{
+		rules: rules,
+	}
+}
+
+// Calculate returns package volume, weights and marketplace size checks of the product
+func (s *LogisticsService) Calculate(p *model.Product) *model.Logistics {
+	return model.CalculateLogistics(p, s.rules)
+}
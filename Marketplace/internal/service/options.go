@@ -0,0 +1,196 @@
+package service
+
+import (
+	"time"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/pkg/filestorage"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/pkg/jwt"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/pkg/mailer"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store"
+)
+
+const (
+	defaultMaxImageSize  = 10 << 20 // 10 MB
+	defaultThumbnailSize = 300
+	// GS1 prefixes 200-299 are reserved for restricted circulation within a company
+	defaultBarcodePrefix = "200"
+	defaultLabelSize     = "58x40"
+)
+
+type Option func(*options)
+
+type options struct {
+	fileStorage    filestorage.Storage
+	maxImageSize   int64
+	thumbnailSize  int
+	barcodePrefix  string
+	labelSize      string
+	logisticsRules []*model.LogisticsRule
+	tariffs        []*model.Tariff
+	tokenKeys      *jwt.KeySet
+	tokenIssuer    string
+	accessTTL      time.Duration
+	refreshTTL     time.Duration
+	mailer         mailer.Mailer
+	appURL         string
+
+	attemptCounters      store.AttemptCounterRepo
+	accountLockout       model.LockoutPolicy
+	ipLockout            model.LockoutPolicy
+	signInResetAfter     time.Duration
+	signInAuditRetention time.Duration
+
+	twoFactorIssuer       string
+	enforceAdminTwoFactor bool
+
+	passwordPolicy model.PasswordPolicy
+	passwordHasher model.PasswordHasher
+}
+
+func newOptions(opts ...Option) *options {
+	o := &options{
+		maxImageSize:   defaultMaxImageSize,
+		thumbnailSize:  defaultThumbnailSize,
+		barcodePrefix:  defaultBarcodePrefix,
+		labelSize:      defaultLabelSize,
+		logisticsRules: model.DefaultLogisticsRules,
+		tokenIssuer:    defaultTokenIssuer,
+		accessTTL:      defaultAccessTokenTTL,
+		refreshTTL:     defaultRefreshTokenTTL,
+
+		accountLockout:       DefaultAccountLockout,
+		ipLockout:            DefaultIPLockout,
+		signInResetAfter:     defaultSignInResetAfter,
+		signInAuditRetention: defaultSignInAuditRetention,
+
+		twoFactorIssuer: defaultTwoFactorIssuer,
+
+		passwordPolicy: DefaultPasswordPolicy,
+		passwordHasher: model.DefaultPasswordHasher,
+	}
+
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return o
+}
+
+// WithFileStorage sets the storage used for product images
+func WithFileStorage(fs filestorage.Storage) Option {
+	return func(o *options) {
+		o.fileStorage = fs
+	}
+}
+
+// WithImageLimits sets the maximum upload size in bytes and the thumbnail side in pixels
+func WithImageLimits(maxSize int64, thumbnailSize int) Option {
+	return func(o *options) {
+		if maxSize > 0 {
+			o.maxImageSize = maxSize
+		}
+		if thumbnailSize > 0 {
+			o.thumbnailSize = thumbnailSize
+		}
+	}
+}
+
+// WithBarcodePrefix sets the prefix of generated internal EAN-13 barcodes
+func WithBarcodePrefix(prefix string) Option {
+	return func(o *options) {
+		if prefix != "" {
+			o.barcodePrefix = prefix
+		}
+	}
+}
+
+// WithLabelSize sets the label size preset used when a request doesn't specify one
+func WithLabelSize(size string) Option {
+	return func(o *options) {
+		if size != "" {
+			o.labelSize = size
+		}
+	}
+}
+
+// WithLogisticsRules overrides marketplace volumetric weight and size limit rules
+func WithLogisticsRules(rules []*model.LogisticsRule) Option {
+	return func(o *options) {
+		if len(rules) > 0 {
+			o.logisticsRules = rules
+		}
+	}
+}
+
+// WithTariffs sets marketplace commission and logistics tariffs used for fee estimates
+func WithTariffs(tariffs []*model.Tariff) Option {
+	return func(o *options) {
+		o.tariffs = tariffs
+	}
+}
+
+// WithTokens enables JWT access tokens signed with the keys, zero durations keep the defaults
+func WithTokens(keys *jwt.KeySet, issuer string, accessTTL time.Duration, refreshTTL time.Duration) Option {
+	return func(o *options) {
+		o.tokenKeys = keys
+		if issuer != "" {
+			o.tokenIssuer = issuer
+		}
+		if accessTTL > 0 {
+			o.accessTTL = accessTTL
+		}
+		if refreshTTL > 0 {
+			o.refreshTTL = refreshTTL
+		}
+	}
+}
+
+// WithMailer enables email verification and password reset, the emailed links point to the web app at appURL
+func WithMailer(m mailer.Mailer, appURL string) Option {
+	return func(o *options) {
+		o.mailer = m
+		o.appURL = appURL
+	}
+}
+
+// WithSignInProtection sets the failed sign-in policies per account and per client address,
+// nil counters keep them in the store and zero durations keep the defaults
+func WithSignInProtection(counters store.AttemptCounterRepo, account model.LockoutPolicy, ip model.LockoutPolicy, resetAfter time.Duration, auditRetention time.Duration) Option {
+	return func(o *options) {
+		o.attemptCounters = counters
+		o.accountLockout = account
+		o.ipLockout = ip
+		if resetAfter > 0 {
+			o.signInResetAfter = resetAfter
+		}
+		if auditRetention > 0 {
+			o.signInAuditRetention = auditRetention
+		}
+	}
+}
+
+// WithTwoFactor sets the issuer shown in authenticator apps, with enforceAdmin administrators
+// have to enable two-factor authentication to use admin endpoints
+func WithTwoFactor(issuer string, enforceAdmin bool) Option {
+	return func(o *options) {
+		if issuer != "" {
+			o.twoFactorIssuer = issuer
+		}
+		o.enforceAdminTwoFactor = enforceAdmin
+	}
+}
+
+// WithPasswordPolicy sets the rules new passwords are checked against
+func WithPasswordPolicy(policy model.PasswordPolicy) Option {
+	return func(o *options) {
+		o.passwordPolicy = policy
+	}
+}
+
+// WithPasswordHasher sets the algorithm and the parameters of new password hashes
+func WithPasswordHasher(hasher model.PasswordHasher) Option {
+	return func(o *options) {
+		o.passwordHasher = hasher
+	}
+}
@@ -0,0 +1,49 @@
+package service
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+)
+
+// DefaultPasswordPolicy follows NIST guidance: a length limit instead of composition rules,
+// bcrypt uses only the first 72 bytes of a password
+var DefaultPasswordPolicy = model.PasswordPolicy{
+	MinLength:        8,
+	MaxLength:        72,
+	CharacterClasses: 1,
+}
+
+// LoadBreachedPasswords reads a list of common or leaked passwords, a line holds a password
+// or its SHA-1 hash in hex as in leaked password dumps, where ":count" after the hash is ignored.
+// Empty lines and lines starting with # are skipped.
+func LoadBreachedPasswords(path string) (model.BreachedPasswords, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	breached := model.BreachedPasswords{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if hash, _, ok := strings.Cut(line, ":"); ok && len(hash) == 40 {
+			line = hash
+		}
+		breached.Add(line)
+	}
+
+	if err = scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return breached, nil
+}
@@ -0,0 +1,143 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store"
+	validation "github.com/go-ozzo/ozzo-validation"
+)
+
+const maxPricesPerRequest = 1000
+
+var errNoPrices = errors.New("prices are required")
+
+type PriceService struct {
+	store   store.Store
+	tariffs *TariffService
+}
+
+func NewPriceService(store store.Store, tariffs *TariffService) *PriceService {
+	return &PriceService{
+		store:   store,
+		tariffs: tariffs,
+	}
+}
+
+// GetPrices returns the current price of the product on every marketplace
+func (s *PriceService) GetPrices(productId int, userId int) ([]*model.Price, error) {
+	if _, err := ownProduct(s.store, productId, userId); err != nil {
+		return nil, err
+	}
+
+	return s.store.Price().FindCurrent(productId)
+}
+
+// GetPriceHistory returns price changes of the product, newest first
+func (s *PriceService) GetPriceHistory(productId int, userId int, marketPlaceId int) ([]*model.Price, error) {
+	if _, err := ownProduct(s.store, productId, userId); err != nil {
+		return nil, err
+	}
+
+	return s.store.Price().FindHistory(productId, marketPlaceId)
+}
+
+// SetPrices saves new prices of several products at once. Nothing is saved when any of the prices
+// is invalid, errors are reported by the position of the price in the request.
+func (s *PriceService) SetPrices(userId int, prices []*model.Price) ([]*model.Price, error) {
+	if err := s.checkPrices(userId, prices); err != nil {
+		return nil, err
+	}
+
+	if err := s.store.Price().Create(prices); err != nil {
+		return nil, err
+	}
+
+	return prices, nil
+}
+
+// checkPrices fills in and validates new prices of the user, errors are reported by the position of the price
+func (s *PriceService) checkPrices(userId int, prices []*model.Price) error {
+	if len(prices) == 0 {
+		return errNoPrices
+	}
+	if len(prices) > maxPricesPerRequest {
+		return fmt.Errorf("at most %d prices can be set at once", maxPricesPerRequest)
+	}
+
+	errs := validation.Errors{}
+	seen := make(map[[2]int]bool)
+	products := make(map[int]*model.Product)
+	now := time.Now()
+	for i, p := range prices {
+		p.UserID = userId
+
+		key := [2]int{p.ProductID, p.MarketPlaceID}
+		if seen[key] {
+			errs[strconv.Itoa(i)] = errors.New("duplicate price of the product on the marketplace")
+			continue
+		}
+		seen[key] = true
+
+		product, ok := products[p.ProductID]
+		if !ok {
+			var err error
+			if product, err = ownProduct(s.store, p.ProductID, userId); err != nil {
+				errs[strconv.Itoa(i)] = err
+				continue
+			}
+			products[p.ProductID] = product
+		}
+
+		if err := s.preparePrice(product, p, now); err != nil {
+			errs[strconv.Itoa(i)] = err
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+// preparePrice keeps the landed cost of the current price when a new one isn't given
+// and checks that the price covers the landed cost and marketplace fees
+func (s *PriceService) preparePrice(product *model.Product, p *model.Price, at time.Time) error {
+	if p.LandedCost == 0 {
+		current, err := s.store.Price().FindCurrent(product.ProductID)
+		if err != nil {
+			return err
+		}
+
+		for _, c := range current {
+			if c.MarketPlaceID == p.MarketPlaceID {
+				p.LandedCost = c.LandedCost
+			}
+		}
+	}
+
+	if err := p.Validate(); err != nil {
+		return err
+	}
+
+	if p.Override {
+		return nil
+	}
+
+	fees, err := s.tariffs.estimate(product, p.FloorPrice(), at)
+	if err != nil {
+		return err
+	}
+
+	for _, f := range fees {
+		if f.MarketPlaceID == p.MarketPlaceID {
+			return p.CheckMargin(f)
+		}
+	}
+
+	return fmt.Errorf("no tariff of marketplace %d to check the price, set override to save it", p.MarketPlaceID)
+}
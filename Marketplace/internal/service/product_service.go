@@ -1,35 +1,55 @@
 package service
 
 import (
+	"encoding/json"
+	"errors"
+
 	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/pkg/mergepatch"
 	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store"
 )
 
+var (
+	errParentHasVariants  = errors.New("product with variants can't be a variant")
+	errVariantsCategory   = errors.New("product with variants can't change category")
+	errDeleteWithVariants = errors.New("product with variants can't be deleted, delete variants first")
+)
+
 type ProductService struct {
-	store store.Store
+	store      store.Store
+	images     *ImageService
+	attributes *AttributeService
+	logistics  *LogisticsService
 }
 
-func NewProductService(store store.Store) *ProductService {
+func NewProductService(store store.Store, images *ImageService, attributes *AttributeService, logistics *LogisticsService) *ProductService {
 	return &ProductService{
-		store: store,
+		store:      store,
+		images:     images,
+		attributes: attributes,
+		logistics:  logistics,
 	}
 }
 
 func (ps *ProductService) CreateProduct(p *model.Product) error {
+	p.Variants = nil
+	p.Logistics = nil
 	mpiList := &model.MarketPlaceItemsList{}
 	mpiList.GetMPIList(p)
 
+	if err := ps.validateVariant(p); err != nil {
+		return err
+	}
 	if err := p.Validate(); err != nil {
 		return err
 	}
-	if err := mpiList.ValidateMarketPlaceItems(); err != nil {
+	if err := ps.attributes.validateProduct(p); err != nil {
 		return err
 	}
-	if err := ps.store.Product().Create(p, mpiList); err != nil {
+	if err := mpiList.ValidateMarketPlaceItems(); err != nil {
 		return err
 	}
-
-	return nil
+	return ps.store.Product().Create(p, mpiList)
 }
 
 func (ps *ProductService) GetProductById(id int) (*model.Product, error) {
@@ -38,6 +58,16 @@ func (ps *ProductService) GetProductById(id int) (*model.Product, error) {
 		return nil, err
 	}
 
+	if err = ps.images.attachImages(product); err != nil {
+		return nil, err
+	}
+
+	if err = ps.attributes.attachAttributes(product); err != nil {
+		return nil, err
+	}
+
+	product.Logistics = ps.logistics.Calculate(product)
+
 	return product, nil
 }
 
@@ -47,9 +77,89 @@ func (ps *ProductService) GetProductsByUserId(userId int) ([]*model.Product, err
 		return nil, err
 	}
 
+	for _, p := range products {
+		if err = ps.images.attachImages(p); err != nil {
+			return nil, err
+		}
+
+		if err = ps.attributes.attachAttributes(p); err != nil {
+			return nil, err
+		}
+	}
+
 	return products, nil
 }
 
+// GetGroupedProductsByUserId returns user products with variants nested under their parents
+func (ps *ProductService) GetGroupedProductsByUserId(userId int) ([]*model.Product, error) {
+	products, err := ps.GetProductsByUserId(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	return model.GroupVariants(products), nil
+}
+
+func (ps *ProductService) GetProductVariants(productId int, userId int) ([]*model.Product, error) {
+	if _, err := ownProduct(ps.store, productId, userId); err != nil {
+		return nil, err
+	}
+
+	variants, err := ps.store.Product().FindVariants(productId)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, v := range variants {
+		if err = ps.images.attachImages(v); err != nil {
+			return nil, err
+		}
+
+		if err = ps.attributes.attachAttributes(v); err != nil {
+			return nil, err
+		}
+	}
+
+	return variants, nil
+}
+
+// CreateProductVariant creates a variant of the parent product, omitted fields are taken from the parent
+func (ps *ProductService) CreateProductVariant(parentProductId int, v *model.Product) error {
+	parent, err := ps.store.Product().GetProductById(parentProductId)
+	if err != nil {
+		return err
+	}
+
+	v.ParentProductID = parentProductId
+	if v.ProductName == "" {
+		v.ProductName = parent.ProductName
+	}
+	if v.MaterialID == 0 {
+		v.MaterialID = parent.MaterialID
+	}
+	if v.PiecesInPack == 0 {
+		v.PiecesInPack = parent.PiecesInPack
+	}
+	if v.Attributes == nil {
+		if err = ps.attributes.attachAttributes(parent); err != nil {
+			return err
+		}
+		v.Attributes = parent.Attributes
+	}
+
+	return ps.CreateProduct(v)
+}
+
+// GetProductByBarcode looks up a product of the user by one of its barcodes
+func (ps *ProductService) GetProductByBarcode(userId int, code string) (*model.Product, error) {
+	b, err := ps.store.Barcode().FindByCode(userId, code)
+	if err != nil {
+		return nil, err
+	}
+
+	return ps.GetProductById(b.ProductID)
+}
+
 func (ps *ProductService) GetProductCategories() ([]*model.Category, error) {
 	categories, err := ps.store.Product().GetCategories()
 	if err != nil {
@@ -70,26 +180,207 @@ func (ps *ProductService) GetProductMaterials() ([]*model.Material, error) {
 
 func (ps *ProductService) UpdateProduct(productId int, p *model.Product) error {
 	p.ProductID = productId
+	p.Images = nil
+	p.Variants = nil
+	p.Logistics = nil
 	mpiList := &model.MarketPlaceItemsList{}
 	mpiList.UpdateMPIList(p)
 
+	if err := ps.validateVariant(p); err != nil {
+		return err
+	}
 	if err := p.Validate(); err != nil {
 		return err
 	}
-	if err := mpiList.ValidateMarketPlaceItems(); err != nil {
+	if err := ps.attributes.validateProduct(p); err != nil {
 		return err
 	}
-	if err := ps.store.Product().Update(p, mpiList); err != nil {
+	if err := mpiList.ValidateMarketPlaceItems(); err != nil {
 		return err
 	}
+	return ps.store.Product().Update(p, mpiList)
+}
 
-	return nil
+// PatchProduct applies a JSON Merge Patch to the stored product. Marketplace items are
+// updated only for the SKUs present in the patch. A non-zero version overrides the one in the patch.
+func (ps *ProductService) PatchProduct(productId int, userId int, version int, patch mergepatch.Patch) (*model.Product, error) {
+	current, err := ownProduct(ps.store, productId, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = ps.attributes.attachAttributes(current); err != nil {
+		return nil, err
+	}
+
+	original, err := json.Marshal(current)
+	if err != nil {
+		return nil, err
+	}
+
+	merged, err := patch.Apply(original)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &model.Product{}
+	if err = json.Unmarshal(merged, p); err != nil {
+		return nil, err
+	}
+
+	p.ProductID = productId
+	p.UserID = userId
+	p.Active = current.Active
+	p.Images = nil
+	p.Variants = nil
+	p.Logistics = nil
+	if version != 0 {
+		p.Version = version
+	}
+
+	mpiList := &model.MarketPlaceItemsList{}
+	mpiList.UpdateMPIList(p)
+
+	marketPlaces := make([]int, 0)
+	if patch.Has("ozon_sku") {
+		marketPlaces = append(marketPlaces, model.MarketPlaceOzon)
+	}
+	if patch.Has("wildberries_sku") {
+		marketPlaces = append(marketPlaces, model.MarketPlaceWildberries)
+	}
+	mpiList.FilterMPIList(marketPlaces...)
+
+	if err = ps.validateVariant(p); err != nil {
+		return nil, err
+	}
+	if err = p.Validate(); err != nil {
+		return nil, err
+	}
+	if err = ps.attributes.validateProduct(p); err != nil {
+		return nil, err
+	}
+	if err = mpiList.ValidateMarketPlaceItems(); err != nil {
+		return nil, err
+	}
+	if err = ps.store.Product().Update(p, mpiList); err != nil {
+		return nil, err
+	}
+
+	return p, nil
 }
 
 func (ps *ProductService) DeleteProduct(productId int, userId int) error {
+	variants, err := ps.store.Product().FindVariants(productId)
+	if err != nil {
+		return err
+	}
+	if len(variants) > 0 {
+		return errDeleteWithVariants
+	}
+
 	if err := ps.store.Product().Delete(productId, userId); err != nil {
 		return err
 	}
 
 	return nil
 }
+
+func (ps *ProductService) GetProductRevisions(productId int, userId int) ([]*model.ProductRevision, error) {
+	if _, err := ownProduct(ps.store, productId, userId); err != nil {
+		return nil, err
+	}
+
+	revisions, err := ps.store.Product().GetRevisions(productId)
+	if err != nil {
+		return nil, err
+	}
+
+	return revisions, nil
+}
+
+func (ps *ProductService) DiffProductRevisions(productId int, userId int, from int, to int) ([]*model.FieldChange, error) {
+	if _, err := ownProduct(ps.store, productId, userId); err != nil {
+		return nil, err
+	}
+
+	fromRev, err := ps.store.Product().GetRevision(productId, from)
+	if err != nil {
+		return nil, err
+	}
+
+	toRev, err := ps.store.Product().GetRevision(productId, to)
+	if err != nil {
+		return nil, err
+	}
+
+	return model.DiffProducts(fromRev.Product, toRev.Product)
+}
+
+func (ps *ProductService) RollbackProduct(productId int, revision int, userId int) error {
+	current, err := ownProduct(ps.store, productId, userId)
+	if err != nil {
+		return err
+	}
+
+	rev, err := ps.store.Product().GetRevision(productId, revision)
+	if err != nil {
+		return err
+	}
+
+	p := *rev.Product
+	p.UserID = userId
+	p.Active = true
+	p.Version = current.Version
+
+	return ps.UpdateProduct(productId, &p)
+}
+
+// validateVariant checks the product against its parent and its own variants.
+// A variant without a category inherits the parent one.
+func (ps *ProductService) validateVariant(p *model.Product) error {
+	if p.ProductID != 0 {
+		variants, err := ps.store.Product().FindVariants(p.ProductID)
+		if err != nil {
+			return err
+		}
+
+		for _, v := range variants {
+			if p.ParentProductID != 0 {
+				return errParentHasVariants
+			}
+			if v.CategoryID != p.CategoryID {
+				return errVariantsCategory
+			}
+		}
+	}
+
+	if p.ParentProductID == 0 {
+		p.VariantAttributes = nil
+		return nil
+	}
+
+	parent, err := ps.store.Product().GetProductById(p.ParentProductID)
+	if err != nil {
+		return err
+	}
+
+	if p.CategoryID == 0 {
+		p.CategoryID = parent.CategoryID
+	}
+
+	return p.ValidateVariant(parent)
+}
+
+// ownProduct returns the product if it belongs to the user, products of other users are reported as not found
+func ownProduct(s store.Store, productId int, userId int) (*model.Product, error) {
+	p, err := s.Product().GetProductById(productId)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.UserID != userId {
+		return nil, store.ErrRecordNotFound
+	}
+
+	return p, nil
+}
@@ -0,0 +1,31 @@
+package service
+
+import (
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store"
+)
+
+// ProfileService changes the display name, the company and the regional settings of users,
+// the email, the password and the account state are changed by AuthService
+type ProfileService struct {
+	store store.Store
+}
+
+func NewProfileService(store store.Store) *ProfileService {
+	return &ProfileService{
+		store: store,
+	}
+}
+
+func (s *ProfileService) UpdateProfile(u *model.User, profile *model.UserProfile) error {
+	profile.Normalize()
+
+	changed := *u
+	changed.UserProfile = *profile
+	if err := s.store.User().UpdateProfile(&changed); err != nil {
+		return err
+	}
+
+	u.UserProfile = changed.UserProfile
+	return nil
+}
@@ -0,0 +1,432 @@
+package service
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store"
+)
+
+var (
+	errProposalNotPending = errors.New("only pending proposals can be approved or rejected")
+	errPriceChanged       = errors.New("price has changed since the proposal was made")
+	errCompetitorHeader   = errors.New("competitor prices file must have product_id, marketplace_id and price columns")
+)
+
+// RepricingErrors are the errors of sellers the repricing job failed for, prices of the other
+// sellers are still proposed
+type RepricingErrors []error
+
+func (e RepricingErrors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, err := range e {
+		messages = append(messages, err.Error())
+	}
+
+	return strings.Join(messages, "; ")
+}
+
+type RepricingService struct {
+	store   store.Store
+	tariffs *TariffService
+	prices  *PriceService
+}
+
+func NewRepricingService(store store.Store, tariffs *TariffService, prices *PriceService) *RepricingService {
+	return &RepricingService{
+		store:   store,
+		tariffs: tariffs,
+		prices:  prices,
+	}
+}
+
+func (s *RepricingService) GetRules(userId int) ([]*model.RepricingRule, error) {
+	return s.store.Repricing().FindRules(userId)
+}
+
+func (s *RepricingService) CreateRule(userId int, rule *model.RepricingRule) error {
+	if rule.ProductID != 0 {
+		if _, err := ownProduct(s.store, rule.ProductID, userId); err != nil {
+			return err
+		}
+	}
+
+	rule.UserID = userId
+	rule.Active = true
+
+	return s.store.Repricing().CreateRule(rule)
+}
+
+func (s *RepricingService) DeleteRule(ruleId int, userId int) error {
+	rule, err := s.store.Repricing().FindRule(ruleId)
+	if err != nil {
+		return err
+	}
+
+	if rule.UserID != userId {
+		return store.ErrRecordNotFound
+	}
+
+	return s.store.Repricing().DeleteRule(ruleId)
+}
+
+// ImportCompetitorPrices reads a CSV file with product_id, marketplace_id and price columns.
+// Nothing is imported when any of the lines is invalid.
+func (s *RepricingService) ImportCompetitorPrices(userId int, r io.Reader) ([]*model.CompetitorPrice, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, errCompetitorHeader
+	}
+
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range []string{"product_id", "marketplace_id", "price"} {
+		if _, ok := columns[name]; !ok {
+			return nil, errCompetitorHeader
+		}
+	}
+
+	owned := make(map[int]bool)
+	prices := make([]*model.CompetitorPrice, 0)
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		line, _ := reader.FieldPos(0)
+		p, err := parseCompetitorPrice(record, columns)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		if !owned[p.ProductID] {
+			if _, err = ownProduct(s.store, p.ProductID, userId); err != nil {
+				return nil, fmt.Errorf("line %d: product %d: %w", line, p.ProductID, err)
+			}
+			owned[p.ProductID] = true
+		}
+
+		p.UserID = userId
+		prices = append(prices, p)
+	}
+
+	if err = s.store.Repricing().SaveCompetitorPrices(prices); err != nil {
+		return nil, err
+	}
+
+	return prices, nil
+}
+
+// Run proposes prices by the rules of every seller, it is called by the scheduled repricing job.
+// A seller that fails doesn't stop the run, the errors are returned as RepricingErrors.
+func (s *RepricingService) Run(at time.Time) (int, error) {
+	rules, err := s.store.Repricing().FindActiveRules()
+	if err != nil {
+		return 0, err
+	}
+
+	users := make([]int, 0)
+	for i, rule := range rules {
+		if i == 0 || rules[i-1].UserID != rule.UserID {
+			users = append(users, rule.UserID)
+		}
+	}
+
+	count := 0
+	var errs RepricingErrors
+	for _, userId := range users {
+		proposals, err := s.RunForUser(userId, at)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("user %d: %w", userId, err))
+			continue
+		}
+		count += len(proposals)
+	}
+
+	if len(errs) > 0 {
+		return count, errs
+	}
+
+	return count, nil
+}
+
+// RunForUser replaces pending proposals of the seller with new ones. Every current price is repriced
+// by the most specific matching rule: product rules go before rules for all products.
+func (s *RepricingService) RunForUser(userId int, at time.Time) ([]*model.PriceProposal, error) {
+	rules, err := s.store.Repricing().FindRules(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	proposals := make([]*model.PriceProposal, 0)
+	if len(rules) == 0 {
+		return proposals, s.store.Repricing().CreateProposals(userId, proposals)
+	}
+
+	sort.SliceStable(rules, func(i, j int) bool {
+		return specificity(rules[i]) > specificity(rules[j])
+	})
+
+	products, err := s.store.Product().FindByUserId(userId)
+	if err != nil && err != store.ErrRecordNotFound {
+		return nil, err
+	}
+	sort.Slice(products, func(i, j int) bool {
+		return products[i].ProductID < products[j].ProductID
+	})
+
+	competitors := make(map[[2]int]float64)
+	competitorPrices, err := s.store.Repricing().FindCompetitorPrices(userId)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range competitorPrices {
+		competitors[[2]int{c.ProductID, c.MarketPlaceID}] = c.Price
+	}
+
+	for _, p := range products {
+		prices, err := s.store.Price().FindCurrent(p.ProductID)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, price := range prices {
+			proposal, err := s.propose(p, price, rules, competitors, at)
+			if err != nil {
+				return nil, err
+			}
+			if proposal != nil {
+				proposals = append(proposals, proposal)
+			}
+		}
+	}
+
+	if err = s.store.Repricing().CreateProposals(userId, proposals); err != nil {
+		return nil, err
+	}
+
+	return proposals, nil
+}
+
+func (s *RepricingService) GetProposals(userId int, status string) ([]*model.PriceProposal, error) {
+	return s.store.Repricing().FindProposals(userId, status)
+}
+
+func (s *RepricingService) ApproveProposal(proposalId int, userId int) (*model.PriceProposal, error) {
+	return s.decide(proposalId, userId, model.ProposalApproved)
+}
+
+func (s *RepricingService) RejectProposal(proposalId int, userId int) (*model.PriceProposal, error) {
+	return s.decide(proposalId, userId, model.ProposalRejected)
+}
+
+// ApplyProposals saves approved proposals as new prices and logs every change. The price, the proposal
+// status and the log entry are saved together. Proposals that can't be applied, for example because
+// the price doesn't cover costs anymore, are marked as failed.
+func (s *RepricingService) ApplyProposals(userId int) ([]*model.PriceProposal, error) {
+	proposals, err := s.store.Repricing().FindProposals(userId, model.ProposalApproved)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, p := range proposals {
+		now := time.Now()
+		p.DecidedAt = &now
+
+		if err = s.apply(p); err == nil {
+			continue
+		} else if err == store.ErrVersionConflict {
+			// another request has applied or changed the proposal meanwhile
+			current, err := s.store.Repricing().FindProposal(p.ProposalID)
+			if err != nil {
+				return nil, err
+			}
+			*p = *current
+			continue
+		}
+
+		p.Status = model.ProposalFailed
+		p.Error = err.Error()
+		if err = s.store.Repricing().UpdateProposal(p); err != nil {
+			return nil, err
+		}
+	}
+
+	return proposals, nil
+}
+
+func (s *RepricingService) GetLog(userId int) ([]*model.RepricingLogEntry, error) {
+	return s.store.Repricing().FindLog(userId)
+}
+
+func (s *RepricingService) propose(
+	p *model.Product,
+	price *model.Price,
+	rules []*model.RepricingRule,
+	competitors map[[2]int]float64,
+	at time.Time,
+) (*model.PriceProposal, error) {
+	var rule *model.RepricingRule
+	for _, r := range rules {
+		if r.Matches(p.ProductID, price.MarketPlaceID) {
+			rule = r
+			break
+		}
+	}
+	if rule == nil {
+		return nil, nil
+	}
+
+	fees, err := s.tariffs.estimate(p, price.SellingPrice(), at)
+	if err == errNoActiveTariff {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	for _, f := range fees {
+		if f.MarketPlaceID != price.MarketPlaceID {
+			continue
+		}
+
+		proposed, reason, err := rule.Propose(price, f, competitors[[2]int{p.ProductID, price.MarketPlaceID}])
+		if err != nil || proposed == 0 {
+			// a rule that can't be met for the product is skipped, the other prices are still repriced
+			return nil, nil
+		}
+
+		return &model.PriceProposal{
+			UserID:        rule.UserID,
+			RuleID:        rule.RuleID,
+			ProductID:     p.ProductID,
+			MarketPlaceID: price.MarketPlaceID,
+			CurrentPrice:  price.SellingPrice(),
+			ProposedPrice: proposed,
+			Reason:        reason,
+			Status:        model.ProposalPending,
+		}, nil
+	}
+
+	return nil, nil
+}
+
+func (s *RepricingService) decide(proposalId int, userId int, status string) (*model.PriceProposal, error) {
+	p, err := s.store.Repricing().FindProposal(proposalId)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.UserID != userId {
+		return nil, store.ErrRecordNotFound
+	}
+
+	if p.Status != model.ProposalPending {
+		return nil, errProposalNotPending
+	}
+
+	now := time.Now()
+	p.Status = status
+	p.DecidedAt = &now
+	if err = s.store.Repricing().UpdateProposal(p); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// apply saves the proposed price and marks the proposal applied
+func (s *RepricingService) apply(p *model.PriceProposal) error {
+	prices, err := s.store.Price().FindCurrent(p.ProductID)
+	if err != nil {
+		return err
+	}
+
+	for _, current := range prices {
+		if current.MarketPlaceID != p.MarketPlaceID {
+			continue
+		}
+
+		if current.SellingPrice() != p.CurrentPrice {
+			return errPriceChanged
+		}
+
+		price := current.Reprice(p.ProposedPrice)
+		if err = s.prices.checkPrices(p.UserID, []*model.Price{price}); err != nil {
+			return err
+		}
+
+		p.Status = model.ProposalApplied
+		entry := &model.RepricingLogEntry{
+			UserID:        p.UserID,
+			ProposalID:    p.ProposalID,
+			RuleID:        p.RuleID,
+			ProductID:     p.ProductID,
+			MarketPlaceID: p.MarketPlaceID,
+			OldPrice:      p.CurrentPrice,
+			NewPrice:      p.ProposedPrice,
+		}
+
+		return s.store.Repricing().ApplyProposal(p, price, entry)
+	}
+
+	return errPriceChanged
+}
+
+// specificity orders rules from the ones for a product on a marketplace to the ones for everything
+func specificity(r *model.RepricingRule) int {
+	s := 0
+	if r.ProductID != 0 {
+		s += 2
+	}
+	if r.MarketPlaceID != 0 {
+		s++
+	}
+
+	return s
+}
+
+func parseCompetitorPrice(record []string, columns map[string]int) (*model.CompetitorPrice, error) {
+	field := func(name string) string {
+		if i := columns[name]; i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	productId, err := strconv.Atoi(field("product_id"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid product_id %q", field("product_id"))
+	}
+
+	marketPlaceId, err := strconv.Atoi(field("marketplace_id"))
+	if err != nil || (marketPlaceId != model.MarketPlaceOzon && marketPlaceId != model.MarketPlaceWildberries) {
+		return nil, fmt.Errorf("invalid marketplace_id %q", field("marketplace_id"))
+	}
+
+	price, err := strconv.ParseFloat(strings.Replace(field("price"), ",", ".", 1), 64)
+	if err != nil || price <= 0 {
+		return nil, fmt.Errorf("invalid price %q", field("price"))
+	}
+
+	return &model.CompetitorPrice{
+		ProductID:     productId,
+		MarketPlaceID: marketPlaceId,
+		Price:         price,
+	}, nil
+}
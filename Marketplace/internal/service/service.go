@@ -3,15 +3,64 @@ package service
 import "github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store"
 
 type Service struct {
-	ProductService *ProductService
-	AuthService    *AuthService
+	ProductService   *ProductService
+	AuthService      *AuthService
+	ImageService     *ImageService
+	AttributeService *AttributeService
+	BarcodeService   *BarcodeService
+	LabelService     *LabelService
+	LogisticsService *LogisticsService
+	TariffService    *TariffService
+	PriceService     *PriceService
+	RepricingService *RepricingService
+	WarehouseService *WarehouseService
+	SupplyService    *SupplyService
+	ShipmentService  *ShipmentService
+	TokenService     *TokenService
+	APIKeyService    *APIKeyService
+	LockoutService   *LockoutService
+	TwoFactorService *TwoFactorService
+	ProfileService   *ProfileService
 }
 
-func NewService(store store.Store) *Service {
-	ProductService := NewProductService(store)
-	AuthService := NewAuthService(store)
+func NewService(store store.Store, opts ...Option) *Service {
+	o := newOptions(opts...)
+	ImageService := NewImageService(store, o.fileStorage, o.maxImageSize, o.thumbnailSize)
+	AttributeService := NewAttributeService(store)
+	LogisticsService := NewLogisticsService(o.logisticsRules)
+	ProductService := NewProductService(store, ImageService, AttributeService, LogisticsService)
+	AuthService := NewAuthService(store, o.mailer, o.appURL, o.passwordPolicy, o.passwordHasher)
+	BarcodeService := NewBarcodeService(store, o.barcodePrefix)
+	LabelService := NewLabelService(store, o.labelSize)
+	TariffService := NewTariffService(store, o.tariffs)
+	PriceService := NewPriceService(store, TariffService)
+	RepricingService := NewRepricingService(store, TariffService, PriceService)
+	WarehouseService := NewWarehouseService(store)
+	SupplyService := NewSupplyService(store, WarehouseService)
+	ShipmentService := NewShipmentService(store, WarehouseService, o.logisticsRules)
+	TokenService := NewTokenService(store, o.tokenKeys, o.tokenIssuer, o.accessTTL, o.refreshTTL)
+	APIKeyService := NewAPIKeyService(store)
+	LockoutService := NewLockoutService(store, o.attemptCounters, o.accountLockout, o.ipLockout, o.signInResetAfter, o.signInAuditRetention)
+	TwoFactorService := NewTwoFactorService(store, o.twoFactorIssuer, o.enforceAdminTwoFactor, o.passwordHasher)
+	ProfileService := NewProfileService(store)
 	return &Service{
-		ProductService: ProductService,
-		AuthService:    AuthService,
+		ProductService:   ProductService,
+		AuthService:      AuthService,
+		ImageService:     ImageService,
+		AttributeService: AttributeService,
+		BarcodeService:   BarcodeService,
+		LabelService:     LabelService,
+		LogisticsService: LogisticsService,
+		TariffService:    TariffService,
+		PriceService:     PriceService,
+		RepricingService: RepricingService,
+		WarehouseService: WarehouseService,
+		SupplyService:    SupplyService,
+		ShipmentService:  ShipmentService,
+		TokenService:     TokenService,
+		APIKeyService:    APIKeyService,
+		LockoutService:   LockoutService,
+		TwoFactorService: TwoFactorService,
+		ProfileService:   ProfileService,
 	}
 }
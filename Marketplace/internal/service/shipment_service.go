@@ -0,0 +1,259 @@
+package service
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/pkg/packinglist"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store"
+)
+
+const (
+	PackingListCSV = "csv"
+	PackingListPDF = "pdf"
+)
+
+var (
+	errNotMarketPlaceWarehouse = errors.New("shipments go to marketplace warehouses only")
+	errShipmentNotDraft        = errors.New("only draft shipments can be changed")
+	errShipmentStatus          = errors.New("shipment can't get the status in its current status")
+	errPackingListFormat       = errors.New("packing list format must be csv or pdf")
+)
+
+type ShipmentService struct {
+	store      store.Store
+	warehouses *WarehouseService
+	rules      []*model.LogisticsRule
+}
+
+func NewShipmentService(store store.Store, warehouses *WarehouseService, rules []*model.LogisticsRule) *ShipmentService {
+	return &ShipmentService{
+		store:      store,
+		warehouses: warehouses,
+		rules:      rules,
+	}
+}
+
+func (s *ShipmentService) GetShipments(userId int, status string) ([]*model.Shipment, error) {
+	return s.store.Shipment().FindByUserId(userId, status)
+}
+
+func (s *ShipmentService) GetShipment(shipmentId int, userId int) (*model.Shipment, error) {
+	sh, err := s.store.Shipment().Find(shipmentId)
+	if err != nil {
+		return nil, err
+	}
+
+	if sh.UserID != userId {
+		return nil, store.ErrRecordNotFound
+	}
+
+	return sh, nil
+}
+
+// CreateShipment creates a draft shipment to a marketplace warehouse of the seller
+func (s *ShipmentService) CreateShipment(userId int, sh *model.Shipment) error {
+	if err := s.prepare(userId, sh); err != nil {
+		return err
+	}
+
+	return s.store.Shipment().Create(sh)
+}
+
+// UpdateShipment replaces the destination, date and boxes of a draft shipment
+func (s *ShipmentService) UpdateShipment(shipmentId int, userId int, sh *model.Shipment) error {
+	current, err := s.GetShipment(shipmentId, userId)
+	if err != nil {
+		return err
+	}
+
+	if current.Status != model.ShipmentDraft {
+		return errShipmentNotDraft
+	}
+
+	if err = s.prepare(userId, sh); err != nil {
+		return err
+	}
+
+	sh.ShipmentID = current.ShipmentID
+	sh.Status = current.Status
+	sh.CreatedAt = current.CreatedAt
+
+	return s.store.Shipment().Update(sh)
+}
+
+// CheckPacking returns problems of the shipment boxes and pallets, no issues means it can be shipped
+func (s *ShipmentService) CheckPacking(shipmentId int, userId int) ([]*model.PackingIssue, error) {
+	sh, err := s.GetShipment(shipmentId, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.checkPacking(sh)
+}
+
+// SetStatus moves the shipment through its statuses, it becomes ready only with valid packing
+func (s *ShipmentService) SetStatus(shipmentId int, userId int, status string) (*model.Shipment, error) {
+	sh, err := s.GetShipment(shipmentId, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	if !sh.CanChange(status) {
+		return nil, errShipmentStatus
+	}
+
+	if status == model.ShipmentReady {
+		issues, err := s.checkPacking(sh)
+		if err != nil {
+			return nil, err
+		}
+		if len(issues) > 0 {
+			return nil, packingError(issues)
+		}
+	}
+
+	if err = s.store.Shipment().SetStatus(sh, status); err != nil {
+		return nil, err
+	}
+
+	return sh, nil
+}
+
+// PackingList renders the packing list of the shipment in the format, csv or pdf
+func (s *ShipmentService) PackingList(shipmentId int, userId int, format string) ([]byte, error) {
+	if format != PackingListCSV && format != PackingListPDF {
+		return nil, errPackingListFormat
+	}
+
+	sh, err := s.GetShipment(shipmentId, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	w, err := s.store.Warehouse().Find(sh.WarehouseID)
+	if err != nil {
+		return nil, err
+	}
+
+	list := &packinglist.List{
+		Title:    fmt.Sprintf("Shipment %d", sh.ShipmentID),
+		Subtitle: fmt.Sprintf("%s, %s", w.Name, sh.PlannedDate.Format("2006-01-02")),
+		Boxes:    len(sh.Boxes),
+		Pallets:  sh.Pallets(),
+	}
+
+	boxes := append([]*model.ShipmentBox{}, sh.Boxes...)
+	sort.Slice(boxes, func(i, j int) bool {
+		return boxes[i].BoxNumber < boxes[j].BoxNumber
+	})
+
+	products := make(map[int]*model.Product)
+	barcodes := make(map[int]string)
+	for _, b := range boxes {
+		for _, item := range b.Items {
+			p, ok := products[item.ProductID]
+			if !ok {
+				if p, err = s.store.Product().GetProductById(item.ProductID); err != nil {
+					return nil, err
+				}
+				products[p.ProductID] = p
+
+				codes, err := s.store.Barcode().FindByProductId(p.ProductID)
+				if err != nil {
+					return nil, err
+				}
+				if len(codes) > 0 {
+					barcodes[p.ProductID] = codes[0].Code
+				}
+			}
+
+			sku := p.OzonSKU
+			if sh.MarketPlaceID == model.MarketPlaceWildberries {
+				sku = p.WildberriesSKU
+			}
+
+			list.Lines = append(list.Lines, &packinglist.Line{
+				BoxNumber:    b.BoxNumber,
+				PalletNumber: b.PalletNumber,
+				ProductID:    p.ProductID,
+				ProductName:  p.ProductName,
+				SKU:          sku,
+				Barcode:      barcodes[p.ProductID],
+				Quantity:     item.Quantity,
+			})
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	if format == PackingListCSV {
+		err = packinglist.WriteCSV(buf, list)
+	} else {
+		err = packinglist.WritePDF(buf, list)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// prepare checks the destination warehouse and the products belong to the seller
+func (s *ShipmentService) prepare(userId int, sh *model.Shipment) error {
+	if sh.WarehouseID != 0 {
+		w, err := s.warehouses.ownWarehouse(sh.WarehouseID, userId)
+		if err != nil {
+			return err
+		}
+		if w.Type != model.WarehouseTypeMarketPlace {
+			return errNotMarketPlaceWarehouse
+		}
+		sh.MarketPlaceID = w.MarketPlaceID
+	}
+
+	owned := make(map[int]bool)
+	for productId := range sh.Quantities() {
+		if err := checkOwner(s.store, owned, productId, userId); err != nil {
+			return err
+		}
+	}
+
+	sh.UserID = userId
+	sh.PlannedDate = sh.PlannedDate.UTC().Truncate(24 * time.Hour)
+
+	return nil
+}
+
+func (s *ShipmentService) checkPacking(sh *model.Shipment) ([]*model.PackingIssue, error) {
+	products := make(map[int]*model.Product)
+	for productId := range sh.Quantities() {
+		p, err := s.store.Product().GetProductById(productId)
+		if err != nil {
+			return nil, err
+		}
+		products[productId] = p
+	}
+
+	var rule *model.LogisticsRule
+	for _, r := range s.rules {
+		if r.MarketPlaceID == sh.MarketPlaceID {
+			rule = r
+		}
+	}
+
+	return sh.CheckPacking(products, rule), nil
+}
+
+func packingError(issues []*model.PackingIssue) error {
+	i := issues[0]
+	where := fmt.Sprintf("box %d", i.BoxNumber)
+	if i.PalletNumber != 0 {
+		where = fmt.Sprintf("pallet %d", i.PalletNumber)
+	}
+
+	return fmt.Errorf("packing check found %d issues, %s: %s", len(issues), where, i.Message)
+}
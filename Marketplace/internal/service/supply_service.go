@@ -0,0 +1,211 @@
+package service
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store"
+)
+
+var errNothingToReorder = errors.New("no products have reached the reorder point")
+
+type SupplyService struct {
+	store      store.Store
+	warehouses *WarehouseService
+}
+
+func NewSupplyService(store store.Store, warehouses *WarehouseService) *SupplyService {
+	return &SupplyService{
+		store:      store,
+		warehouses: warehouses,
+	}
+}
+
+// DraftOrderRequest creates a draft order of the items or, when no items are given,
+// of the replenishment suggestions for products that have reached the reorder point
+type DraftOrderRequest struct {
+	SupplierID int                         `json:"supplier_id"`
+	CurrencyID int                         `json:"currency_id"`
+	Items      []*model.SupplyOrderProduct `json:"items"`
+	Params     *model.ReplenishmentParams  `json:"params"`
+}
+
+// ImportSales saves daily sales of the seller products
+func (s *SupplyService) ImportSales(userId int, sales []*model.Sale) error {
+	owned := make(map[int]bool)
+	for _, sale := range sales {
+		if err := checkOwner(s.store, owned, sale.ProductID, userId); err != nil {
+			return err
+		}
+		sale.UserID = userId
+	}
+
+	return s.store.Inventory().SaveSales(sales)
+}
+
+func (s *SupplyService) GetSuppliers(userId int) ([]*model.Supplier, error) {
+	return s.store.Supply().FindSuppliers(userId)
+}
+
+func (s *SupplyService) CreateSupplier(userId int, supplier *model.Supplier) error {
+	supplier.UserID = userId
+	return s.store.Supply().CreateSupplier(supplier)
+}
+
+func (s *SupplyService) GetOrder(orderId int, userId int) (*model.SupplyOrder, error) {
+	o, err := s.store.Supply().FindOrder(orderId)
+	if err != nil {
+		return nil, err
+	}
+
+	if o.UserID != userId {
+		return nil, store.ErrRecordNotFound
+	}
+
+	return o, nil
+}
+
+// ReplenishmentReport forecasts demand of every product of the seller from sales of the history period
+// before the date. Stock of all warehouses and in transit is available to cover the demand.
+// The lead time of the supplier replaces the one of the params.
+func (s *SupplyService) ReplenishmentReport(userId int, supplierId int, params model.ReplenishmentParams, at time.Time) ([]*model.ReplenishmentLine, error) {
+	if supplierId != 0 {
+		supplier, err := s.ownSupplier(supplierId, userId)
+		if err != nil {
+			return nil, err
+		}
+		params.LeadTimeDays = supplier.LeadTimeDays
+	}
+
+	if err := params.Validate(); err != nil {
+		return nil, err
+	}
+
+	products, err := s.store.Product().FindByUserId(userId)
+	if err != nil && err != store.ErrRecordNotFound {
+		return nil, err
+	}
+
+	to := at.UTC().Truncate(24 * time.Hour)
+	sales, err := s.store.Inventory().FindSales(userId, to.AddDate(0, 0, -params.HistoryDays))
+	if err != nil {
+		return nil, err
+	}
+
+	sold := make(map[int]int)
+	for _, sale := range sales {
+		if sale.SaleDate.Before(to) {
+			sold[sale.ProductID] += sale.Quantity
+		}
+	}
+
+	stock, err := s.warehouses.TotalStock(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := make([]*model.ReplenishmentLine, 0, len(products))
+	for _, p := range products {
+		lines = append(lines, model.Forecast(p, sold[p.ProductID], stock[p.ProductID], params))
+	}
+
+	sort.Slice(lines, func(i, j int) bool {
+		if lines[i].Reorder != lines[j].Reorder {
+			return lines[i].Reorder
+		}
+		return lines[i].ProductID < lines[j].ProductID
+	})
+
+	return lines, nil
+}
+
+// CreateDraftOrder creates a supply order in the draft status
+func (s *SupplyService) CreateDraftOrder(userId int, req *DraftOrderRequest, at time.Time) (*model.SupplyOrder, error) {
+	if _, err := s.ownSupplier(req.SupplierID, userId); err != nil {
+		return nil, err
+	}
+
+	currencyId := req.CurrencyID
+	if currencyId == 0 {
+		currencyId = model.CurrencyRUB
+	}
+
+	items := req.Items
+	if len(items) == 0 {
+		params := model.DefaultReplenishmentParams()
+		if req.Params != nil {
+			params = *req.Params
+		}
+
+		lines, err := s.ReplenishmentReport(userId, req.SupplierID, params, at)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, l := range lines {
+			if l.Reorder && l.ReorderQuantity > 0 {
+				items = append(items, &model.SupplyOrderProduct{
+					ProductID: l.ProductID,
+					Quantity:  float32(l.ReorderQuantity),
+				})
+			}
+		}
+
+		if len(items) == 0 {
+			return nil, errNothingToReorder
+		}
+	}
+
+	owned := make(map[int]bool)
+	for _, item := range items {
+		if err := checkOwner(s.store, owned, item.ProductID, userId); err != nil {
+			return nil, err
+		}
+		if item.CurrencyID == 0 {
+			item.CurrencyID = currencyId
+		}
+	}
+
+	o := &model.SupplyOrder{
+		OrderDate:  at,
+		SupplierID: req.SupplierID,
+		UserID:     userId,
+		StatusID:   model.SupplyOrderStatusDraft,
+		Products:   items,
+	}
+
+	if err := s.store.Supply().CreateOrder(o); err != nil {
+		return nil, err
+	}
+
+	return o, nil
+}
+
+func (s *SupplyService) ownSupplier(supplierId int, userId int) (*model.Supplier, error) {
+	supplier, err := s.store.Supply().FindSupplier(supplierId)
+	if err != nil {
+		return nil, err
+	}
+
+	if supplier.UserID != userId {
+		return nil, store.ErrRecordNotFound
+	}
+
+	return supplier, nil
+}
+
+// checkOwner checks the product belongs to the user once per product
+func checkOwner(s store.Store, owned map[int]bool, productId int, userId int) error {
+	if owned[productId] {
+		return nil
+	}
+
+	if _, err := ownProduct(s, productId, userId); err != nil {
+		return err
+	}
+	owned[productId] = true
+
+	return nil
+}
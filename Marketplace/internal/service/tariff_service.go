@@ -0,0 +1,97 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store"
+)
+
+var (
+	errInvalidPrice   = errors.New("sale price must be positive")
+	errNoActiveTariff = errors.New("no marketplace tariffs are effective at the date")
+)
+
+type TariffService struct {
+	store   store.Store
+	tariffs []*model.Tariff
+}
+
+func NewTariffService(store store.Store, tariffs []*model.Tariff) *TariffService {
+	return &TariffService{
+		store:   store,
+		tariffs: tariffs,
+	}
+}
+
+// LoadTariffs reads all *.toml tariff files of the directory, every file holds one version
+// of a marketplace tariff
+func LoadTariffs(dir string) ([]*model.Tariff, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.toml"))
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no tariff files in %s: %w", dir, os.ErrNotExist)
+	}
+	sort.Strings(files)
+
+	tariffs := make([]*model.Tariff, 0, len(files))
+	for _, file := range files {
+		t := &model.Tariff{}
+		if _, err := toml.DecodeFile(file, t); err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+
+		if err := t.Validate(); err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+
+		tariffs = append(tariffs, t)
+	}
+
+	return tariffs, nil
+}
+
+// EstimateFees returns commission, logistics and payout for every marketplace when the product
+// is sold at the price under tariffs effective at the date
+func (s *TariffService) EstimateFees(productId int, userId int, price float64, at time.Time) ([]*model.FeeEstimate, error) {
+	if price <= 0 {
+		return nil, errInvalidPrice
+	}
+
+	p, err := ownProduct(s.store, productId, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.estimate(p, price, at)
+}
+
+// estimate returns fees of the product on every marketplace with a tariff effective at the date
+func (s *TariffService) estimate(p *model.Product, price float64, at time.Time) ([]*model.FeeEstimate, error) {
+	tariffs := model.ActiveTariffs(s.tariffs, at)
+	if len(tariffs) == 0 {
+		return nil, errNoActiveTariff
+	}
+
+	categories, err := s.store.Product().GetCategories()
+	if err != nil && err != store.ErrRecordNotFound {
+		return nil, err
+	}
+	path := model.CategoryPath(p.CategoryID, categories)
+	volume := model.CalculateLogistics(p, nil).VolumeLiters
+
+	estimates := make([]*model.FeeEstimate, 0, len(tariffs))
+	for _, t := range tariffs {
+		estimates = append(estimates, t.Estimate(price, volume, path))
+	}
+
+	return estimates, nil
+}
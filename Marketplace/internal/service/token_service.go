@@ -0,0 +1,235 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/pkg/jwt"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultTokenIssuer     = "marketplace"
+	defaultAccessTokenTTL  = 15 * time.Minute
+	defaultRefreshTokenTTL = 30 * 24 * time.Hour
+	refreshTokenBytes      = 32
+)
+
+var (
+	errTokensDisabled      = errors.New("token authentication is not configured")
+	errInvalidAccessToken  = errors.New("access token is invalid or expired")
+	errInvalidRefreshToken = errors.New("refresh token is invalid or expired")
+)
+
+// TokenService issues short-lived JWT access tokens with refresh tokens for API clients.
+// A refresh token is used once, refreshing revokes it and issues a new pair. Reuse of an exchanged
+// token means it has leaked, so all refresh tokens of the user are revoked then.
+type TokenService struct {
+	store      store.Store
+	keys       *jwt.KeySet
+	issuer     string
+	accessTTL  time.Duration
+	refreshTTL time.Duration
+	now        func() time.Time
+}
+
+func NewTokenService(store store.Store, keys *jwt.KeySet, issuer string, accessTTL time.Duration, refreshTTL time.Duration) *TokenService {
+	return &TokenService{
+		store:      store,
+		keys:       keys,
+		issuer:     issuer,
+		accessTTL:  accessTTL,
+		refreshTTL: refreshTTL,
+		now:        time.Now,
+	}
+}
+
+// Enabled reports whether signing keys are configured
+func (s *TokenService) Enabled() bool {
+	return s.keys != nil
+}
+
+// PublicKeys returns the JWKS other services verify access tokens with
+func (s *TokenService) PublicKeys() (*jwt.JWKS, error) {
+	if !s.Enabled() {
+		return nil, errTokensDisabled
+	}
+
+	return s.keys.Public(), nil
+}
+
+// Issue creates a token pair of the user signed in, sessionId is the cookie session
+// of the sign-in, signing out of it revokes the refresh token
+func (s *TokenService) Issue(u *model.User, sessionId string) (*model.TokenPair, error) {
+	if !s.Enabled() {
+		return nil, errTokensDisabled
+	}
+
+	sessionHash := ""
+	if sessionId != "" {
+		sessionHash = hashToken(sessionId)
+	}
+
+	return s.issue(uuid.New().String(), u.ID, sessionHash)
+}
+
+// Refresh exchanges the refresh token for a new pair
+func (s *TokenService) Refresh(refreshToken string) (*model.TokenPair, error) {
+	if !s.Enabled() {
+		return nil, errTokensDisabled
+	}
+
+	t, err := s.store.RefreshToken().FindByHash(hashToken(refreshToken))
+	if err == store.ErrRecordNotFound {
+		return nil, errInvalidRefreshToken
+	} else if err != nil {
+		return nil, err
+	}
+
+	now := s.now().UTC()
+	if t.Rotated() {
+		if _, err = s.store.RefreshToken().RevokeByUserId(t.UserID, now); err != nil {
+			return nil, err
+		}
+		return nil, errInvalidRefreshToken
+	}
+	if !t.Usable(now) {
+		return nil, errInvalidRefreshToken
+	}
+
+	// a concurrent refresh with the same token has already revoked it
+	next := uuid.New().String()
+	if err = s.store.RefreshToken().Revoke(t.ID, next, now); err == store.ErrTokenUsed {
+		return nil, errInvalidRefreshToken
+	} else if err != nil {
+		return nil, err
+	}
+
+	if u, err := s.store.User().FindById(t.UserID); err != nil || !u.Active {
+		return nil, errInvalidRefreshToken
+	}
+
+	return s.issue(next, t.UserID, t.SessionHash)
+}
+
+// Authenticate returns the user of the access token and the id of its refresh token
+func (s *TokenService) Authenticate(accessToken string) (*model.User, string, error) {
+	if !s.Enabled() {
+		return nil, "", errTokensDisabled
+	}
+
+	claims, err := s.keys.Parse(accessToken, s.now())
+	if err != nil {
+		return nil, "", errInvalidAccessToken
+	}
+	if claims.Issuer != s.issuer {
+		return nil, "", errInvalidAccessToken
+	}
+
+	userId, err := strconv.Atoi(claims.Subject)
+	if err != nil {
+		return nil, "", errInvalidAccessToken
+	}
+
+	u, err := s.store.User().FindById(userId)
+	if err != nil || !u.Active {
+		return nil, "", errInvalidAccessToken
+	}
+
+	return u, claims.SessionID, nil
+}
+
+// Revoke revokes the refresh token by its id, the sid claim of access tokens
+func (s *TokenService) Revoke(tokenId string) error {
+	if !s.Enabled() || tokenId == "" {
+		return nil
+	}
+
+	if err := s.store.RefreshToken().Revoke(tokenId, "", s.now().UTC()); err != nil && err != store.ErrTokenUsed {
+		return err
+	}
+
+	return nil
+}
+
+// RevokeSession revokes refresh tokens issued with the cookie session
+func (s *TokenService) RevokeSession(sessionId string) error {
+	if !s.Enabled() || sessionId == "" {
+		return nil
+	}
+
+	_, err := s.store.RefreshToken().RevokeBySession(hashToken(sessionId), s.now().UTC())
+	return err
+}
+
+// RevokeAll revokes all refresh tokens of the user
+func (s *TokenService) RevokeAll(userId int) error {
+	if !s.Enabled() {
+		return nil
+	}
+
+	_, err := s.store.RefreshToken().RevokeByUserId(userId, s.now().UTC())
+	return err
+}
+
+// DeleteExpired removes expired refresh tokens and returns their number
+func (s *TokenService) DeleteExpired() (int, error) {
+	if !s.Enabled() {
+		return 0, nil
+	}
+
+	return s.store.RefreshToken().DeleteExpired(s.now().UTC())
+}
+
+func (s *TokenService) issue(tokenId string, userId int, sessionHash string) (*model.TokenPair, error) {
+	secret := make([]byte, refreshTokenBytes)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+	refreshToken := base64.RawURLEncoding.EncodeToString(secret)
+
+	now := s.now().UTC()
+	t := &model.RefreshToken{
+		ID:          tokenId,
+		UserID:      userId,
+		TokenHash:   hashToken(refreshToken),
+		SessionHash: sessionHash,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(s.refreshTTL),
+	}
+	if err := s.store.RefreshToken().Create(t); err != nil {
+		return nil, err
+	}
+
+	accessToken, err := s.keys.Sign(&jwt.Claims{
+		Issuer:    s.issuer,
+		Subject:   strconv.Itoa(userId),
+		IssuedAt:  now.Unix(),
+		ExpiresAt: now.Add(s.accessTTL).Unix(),
+		ID:        uuid.New().String(),
+		SessionID: t.ID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.TokenPair{
+		AccessToken:  accessToken,
+		TokenType:    model.TokenTypeBearer,
+		ExpiresIn:    int(s.accessTTL.Seconds()),
+		RefreshToken: refreshToken,
+	}, nil
+}
+
+// hashToken is the hex SHA-256 of a random token, such tokens need no salt
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,306 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/pkg/totp"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store"
+)
+
+const (
+	defaultTwoFactorIssuer = "Marketplace"
+	twoFactorChallengeTTL  = 5 * time.Minute
+	// totpSkew accepts codes of the neighbouring steps for clocks that are a little off
+	totpSkew                = 1
+	recoveryCodeCount       = 10
+	recoveryCodeBytes       = 10
+	recoveryCodeGroupLength = 4
+)
+
+var (
+	// ErrInvalidTwoFactorCode is returned for a wrong, reused or expired TOTP or recovery code
+	ErrInvalidTwoFactorCode = errors.New("invalid authentication code")
+
+	errTwoFactorEnabled     = errors.New("two-factor authentication is already enabled")
+	errTwoFactorNotEnrolled = errors.New("add an authenticator first")
+	errTwoFactorDisabled    = errors.New("two-factor authentication is not enabled")
+	errTwoFactorRequired    = errors.New("two-factor authentication is required for administrators")
+	errInvalidChallenge     = errors.New("sign-in challenge is invalid or expired")
+
+	recoveryCodeEncoding   = base32.StdEncoding.WithPadding(base32.NoPadding)
+	recoveryCodeNormalizer = strings.NewReplacer("-", "", " ", "")
+)
+
+// TwoFactorService manages TOTP authenticators and recovery codes and the second step of
+// the sign-in. With enforceAdmin administrators have to enable 2FA to use admin endpoints.
+type TwoFactorService struct {
+	store        store.Store
+	issuer       string
+	enforceAdmin bool
+	hasher       model.PasswordHasher
+}
+
+func NewTwoFactorService(store store.Store, issuer string, enforceAdmin bool, hasher model.PasswordHasher) *TwoFactorService {
+	return &TwoFactorService{
+		store:        store,
+		issuer:       issuer,
+		enforceAdmin: enforceAdmin,
+		hasher:       hasher,
+	}
+}
+
+func (s *TwoFactorService) Status(u *model.User) (*model.TwoFactorStatus, error) {
+	status := &model.TwoFactorStatus{Required: s.Required(u)}
+
+	t, err := s.store.TwoFactor().Find(u.ID)
+	if err == store.ErrRecordNotFound || err == nil && !t.Enabled() {
+		return status, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	status.Enabled = true
+	status.EnabledAt = t.EnabledAt
+	status.RecoveryCodesLeft, err = s.store.TwoFactor().CountRecoveryCodes(u.ID)
+
+	return status, err
+}
+
+// Enabled reports whether the user has to enter a code after the password
+func (s *TwoFactorService) Enabled(userId int) (bool, error) {
+	t, err := s.store.TwoFactor().Find(userId)
+	if err == store.ErrRecordNotFound {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	return t.Enabled(), nil
+}
+
+// Required reports whether the user has to enable 2FA
+func (s *TwoFactorService) Required(u *model.User) bool {
+	return s.enforceAdmin && u.UserRole == model.UserRoleAdmin
+}
+
+// Enroll creates a pending authenticator, the secret and its otpauth URI are shown this time only,
+// the client renders the URI as a QR code
+func (s *TwoFactorService) Enroll(u *model.User, at time.Time) (*model.TwoFactorEnrollment, error) {
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.store.TwoFactor().Save(&model.TwoFactor{
+		UserID:    u.ID,
+		Secret:    secret,
+		CreatedAt: at.UTC(),
+	}); err == store.ErrRecordExists {
+		return nil, errTwoFactorEnabled
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &model.TwoFactorEnrollment{
+		Secret: secret,
+		URI:    totp.URI(s.issuer, u.Email, secret),
+	}, nil
+}
+
+// Enable turns on the pending authenticator when the code from it is right and returns
+// new recovery codes, they are shown this time only
+func (s *TwoFactorService) Enable(userId int, code string, at time.Time) ([]string, error) {
+	t, err := s.store.TwoFactor().Find(userId)
+	if err == store.ErrRecordNotFound {
+		return nil, errTwoFactorNotEnrolled
+	} else if err != nil {
+		return nil, err
+	}
+
+	if t.Enabled() {
+		return nil, errTwoFactorEnabled
+	}
+
+	step, ok := totp.Validate(t.Secret, normalizeCode(code), at, totpSkew)
+	if !ok {
+		return nil, ErrInvalidTwoFactorCode
+	}
+
+	if err = s.store.TwoFactor().Enable(userId, step, at.UTC()); err == store.ErrRecordExists {
+		return nil, errTwoFactorEnabled
+	} else if err != nil {
+		return nil, err
+	}
+
+	return s.newRecoveryCodes(userId)
+}
+
+// Disable removes the authenticator and the recovery codes, it takes the password and a code
+func (s *TwoFactorService) Disable(u *model.User, password string, code string, at time.Time) error {
+	if s.Required(u) {
+		return errTwoFactorRequired
+	}
+
+	if !s.hasher.Verify(u.EncryptedPassword, password) {
+		return errIncorrectPassword
+	}
+
+	if err := s.verify(u.ID, code, at); err != nil {
+		return err
+	}
+
+	return s.store.TwoFactor().Delete(u.ID)
+}
+
+// Reset removes the authenticator of a user who lost it together with the recovery codes
+func (s *TwoFactorService) Reset(userId int) error {
+	if err := s.store.TwoFactor().Delete(userId); err == store.ErrRecordNotFound {
+		return errTwoFactorDisabled
+	} else if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes, earlier codes stop working
+func (s *TwoFactorService) RegenerateRecoveryCodes(userId int, code string, at time.Time) ([]string, error) {
+	if err := s.verify(userId, code, at); err != nil {
+		return nil, err
+	}
+
+	return s.newRecoveryCodes(userId)
+}
+
+// Challenge starts the second step of the sign-in of a user who entered the right password
+func (s *TwoFactorService) Challenge(u *model.User, at time.Time) (*model.TwoFactorChallenge, error) {
+	secret := make([]byte, userTokenBytes)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+	challenge := base64.RawURLEncoding.EncodeToString(secret)
+
+	if err := s.store.UserToken().Create(&model.UserToken{
+		TokenHash: hashToken(challenge),
+		UserID:    u.ID,
+		Purpose:   model.UserTokenTwoFactor,
+		Email:     u.Email,
+		CreatedAt: at.UTC(),
+		ExpiresAt: at.Add(twoFactorChallengeTTL).UTC(),
+	}); err != nil {
+		return nil, err
+	}
+
+	return &model.TwoFactorChallenge{
+		TwoFactorRequired: true,
+		ChallengeToken:    challenge,
+		ExpiresIn:         int(twoFactorChallengeTTL.Seconds()),
+	}, nil
+}
+
+// ChallengeUser returns the user of an unused challenge
+func (s *TwoFactorService) ChallengeUser(challenge string, at time.Time) (*model.User, error) {
+	t, err := s.store.UserToken().FindByHash(hashToken(challenge))
+	if err == store.ErrRecordNotFound {
+		return nil, errInvalidChallenge
+	} else if err != nil {
+		return nil, err
+	}
+
+	if t.Purpose != model.UserTokenTwoFactor || !t.Usable(at) {
+		return nil, errInvalidChallenge
+	}
+
+	u, err := s.store.User().FindById(t.UserID)
+	if err != nil {
+		return nil, errInvalidChallenge
+	}
+
+	return u, nil
+}
+
+// CompleteChallenge checks the code of the challenge, a wrong code leaves the challenge usable
+// until it expires so the user can correct a typo
+func (s *TwoFactorService) CompleteChallenge(challenge string, code string, at time.Time) (*model.User, error) {
+	u, err := s.ChallengeUser(challenge, at)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.verify(u.ID, code, at); err != nil {
+		return u, err
+	}
+
+	if err = s.store.UserToken().Use(hashToken(challenge), at.UTC()); err == store.ErrTokenUsed {
+		return u, errInvalidChallenge
+	} else if err != nil {
+		return u, err
+	}
+
+	return u, nil
+}
+
+// verify accepts a TOTP code of a step after the last accepted one or an unused recovery code
+func (s *TwoFactorService) verify(userId int, code string, at time.Time) error {
+	t, err := s.store.TwoFactor().Find(userId)
+	if err == store.ErrRecordNotFound || err == nil && !t.Enabled() {
+		return errTwoFactorDisabled
+	} else if err != nil {
+		return err
+	}
+
+	code = normalizeCode(code)
+	if len(code) == totp.Digits {
+		step, ok := totp.Validate(t.Secret, code, at, totpSkew)
+		if !ok {
+			return ErrInvalidTwoFactorCode
+		}
+
+		if err = s.store.TwoFactor().UseStep(userId, step); err == store.ErrTokenUsed {
+			return ErrInvalidTwoFactorCode
+		}
+		return err
+	}
+
+	if err = s.store.TwoFactor().UseRecoveryCode(userId, hashToken(code), at.UTC()); err == store.ErrRecordNotFound {
+		return ErrInvalidTwoFactorCode
+	}
+	return err
+}
+
+func (s *TwoFactorService) newRecoveryCodes(userId int) ([]string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		secret := make([]byte, recoveryCodeBytes)
+		if _, err := rand.Read(secret); err != nil {
+			return nil, err
+		}
+
+		code := strings.ToLower(recoveryCodeEncoding.EncodeToString(secret))
+		hashes = append(hashes, hashToken(code))
+
+		groups := make([]string, 0, len(code)/recoveryCodeGroupLength)
+		for j := 0; j < len(code); j += recoveryCodeGroupLength {
+			groups = append(groups, code[j:j+recoveryCodeGroupLength])
+		}
+		codes = append(codes, strings.Join(groups, "-"))
+	}
+
+	if err := s.store.TwoFactor().ReplaceRecoveryCodes(userId, hashes); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// normalizeCode drops spaces and dashes users type in codes, recovery codes are case insensitive
+func normalizeCode(code string) string {
+	return strings.ToLower(recoveryCodeNormalizer.Replace(strings.TrimSpace(code)))
+}
@@ -0,0 +1,315 @@
+package service
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store"
+)
+
+var (
+	errWarehouseNotEmpty = errors.New("warehouse has stock, open transfers or shipments")
+	errTransferStatus    = errors.New("transfer can't get the status in its current status")
+)
+
+type WarehouseService struct {
+	store store.Store
+}
+
+func NewWarehouseService(store store.Store) *WarehouseService {
+	return &WarehouseService{
+		store: store,
+	}
+}
+
+func (s *WarehouseService) GetWarehouses(userId int) ([]*model.Warehouse, error) {
+	return s.store.Warehouse().FindByUserId(userId)
+}
+
+func (s *WarehouseService) CreateWarehouse(userId int, w *model.Warehouse) error {
+	w.UserID = userId
+	if w.Type == model.WarehouseTypeOwn {
+		w.MarketPlaceID = 0
+	}
+
+	return s.store.Warehouse().Create(w)
+}
+
+// DeleteWarehouse deletes the warehouse without stock that no open transfer or shipment goes from or to
+func (s *WarehouseService) DeleteWarehouse(warehouseId int, userId int) error {
+	if _, err := s.ownWarehouse(warehouseId, userId); err != nil {
+		return err
+	}
+
+	levels, err := s.store.Inventory().FindStock(userId)
+	if err != nil {
+		return err
+	}
+	for _, l := range levels {
+		if l.WarehouseID == warehouseId && l.Quantity > 0 {
+			return errWarehouseNotEmpty
+		}
+	}
+
+	transfers, err := s.store.Transfer().FindByUserId(userId, "")
+	if err != nil {
+		return err
+	}
+	for _, t := range transfers {
+		open := t.Status == model.TransferDraft || t.Status == model.TransferInTransit
+		if open && (t.FromWarehouseID == warehouseId || t.ToWarehouseID == warehouseId) {
+			return errWarehouseNotEmpty
+		}
+	}
+
+	shipments, err := s.store.Shipment().FindByUserId(userId, "")
+	if err != nil {
+		return err
+	}
+	for _, sh := range shipments {
+		open := sh.Status != model.ShipmentAccepted && sh.Status != model.ShipmentCancelled
+		if open && sh.WarehouseID == warehouseId {
+			return errWarehouseNotEmpty
+		}
+	}
+
+	return s.store.Warehouse().Delete(warehouseId)
+}
+
+func (s *WarehouseService) GetStock(userId int) ([]*model.StockLevel, error) {
+	return s.store.Inventory().FindStock(userId)
+}
+
+// SetStock saves stock counts of the seller products. Levels without a warehouse go to the first
+// own warehouse of the seller, which is created when the seller has none.
+func (s *WarehouseService) SetStock(userId int, levels []*model.StockLevel) error {
+	owned := make(map[int]bool)
+	warehouses := make(map[int]bool)
+	defaultId := 0
+	for _, l := range levels {
+		if err := checkOwner(s.store, owned, l.ProductID, userId); err != nil {
+			return err
+		}
+
+		if l.WarehouseID == 0 {
+			if defaultId == 0 {
+				w, err := s.defaultWarehouse(userId)
+				if err != nil {
+					return err
+				}
+				defaultId = w.WarehouseID
+			}
+			l.WarehouseID = defaultId
+		} else if !warehouses[l.WarehouseID] {
+			if _, err := s.ownWarehouse(l.WarehouseID, userId); err != nil {
+				return err
+			}
+			warehouses[l.WarehouseID] = true
+		}
+
+		l.UserID = userId
+	}
+
+	return s.store.Inventory().SetStock(levels)
+}
+
+// StockReport returns the stock of every product of the seller by warehouse with the quantity in transit
+func (s *WarehouseService) StockReport(userId int) ([]*model.StockReportLine, error) {
+	products, err := s.store.Product().FindByUserId(userId)
+	if err != nil && err != store.ErrRecordNotFound {
+		return nil, err
+	}
+	sort.Slice(products, func(i, j int) bool {
+		return products[i].ProductID < products[j].ProductID
+	})
+
+	warehouses, err := s.store.Warehouse().FindByUserId(userId)
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[int]string)
+	for _, w := range warehouses {
+		names[w.WarehouseID] = w.Name
+	}
+
+	lines := make(map[int]*model.StockReportLine)
+	report := make([]*model.StockReportLine, 0, len(products))
+	for _, p := range products {
+		l := &model.StockReportLine{
+			ProductID:   p.ProductID,
+			ProductName: p.ProductName,
+			Warehouses:  make([]*model.WarehouseStock, 0),
+		}
+		lines[p.ProductID] = l
+		report = append(report, l)
+	}
+
+	levels, err := s.store.Inventory().FindStock(userId)
+	if err != nil {
+		return nil, err
+	}
+	for _, level := range levels {
+		l, ok := lines[level.ProductID]
+		if !ok {
+			continue
+		}
+
+		l.Warehouses = append(l.Warehouses, &model.WarehouseStock{
+			WarehouseID: level.WarehouseID,
+			Name:        names[level.WarehouseID],
+			Quantity:    level.Quantity,
+		})
+		l.Total += level.Quantity
+	}
+
+	transfers, err := s.store.Transfer().FindByUserId(userId, model.TransferInTransit)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range transfers {
+		for _, item := range t.Items {
+			if l, ok := lines[item.ProductID]; ok {
+				l.InTransit += item.Quantity
+				l.Total += item.Quantity
+			}
+		}
+	}
+
+	return report, nil
+}
+
+// TotalStock returns the stock of the seller products in all warehouses including the stock in transit
+func (s *WarehouseService) TotalStock(userId int) (map[int]int, error) {
+	levels, err := s.store.Inventory().FindStock(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	stock := make(map[int]int)
+	for _, l := range levels {
+		stock[l.ProductID] += l.Quantity
+	}
+
+	transfers, err := s.store.Transfer().FindByUserId(userId, model.TransferInTransit)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range transfers {
+		for _, item := range t.Items {
+			stock[item.ProductID] += item.Quantity
+		}
+	}
+
+	return stock, nil
+}
+
+func (s *WarehouseService) GetTransfers(userId int, status string) ([]*model.Transfer, error) {
+	return s.store.Transfer().FindByUserId(userId, status)
+}
+
+func (s *WarehouseService) GetTransfer(transferId int, userId int) (*model.Transfer, error) {
+	t, err := s.store.Transfer().Find(transferId)
+	if err != nil {
+		return nil, err
+	}
+
+	if t.UserID != userId {
+		return nil, store.ErrRecordNotFound
+	}
+
+	return t, nil
+}
+
+// CreateTransfer creates a draft transfer between warehouses of the seller, stock moves when it is shipped
+func (s *WarehouseService) CreateTransfer(userId int, t *model.Transfer) error {
+	for _, warehouseId := range []int{t.FromWarehouseID, t.ToWarehouseID} {
+		if warehouseId == 0 {
+			continue
+		}
+		if _, err := s.ownWarehouse(warehouseId, userId); err != nil {
+			return err
+		}
+	}
+
+	owned := make(map[int]bool)
+	for _, item := range t.Items {
+		if err := checkOwner(s.store, owned, item.ProductID, userId); err != nil {
+			return err
+		}
+	}
+
+	t.UserID = userId
+	return s.store.Transfer().Create(t)
+}
+
+func (s *WarehouseService) ShipTransfer(transferId int, userId int) (*model.Transfer, error) {
+	return s.changeTransfer(transferId, userId, model.TransferInTransit, s.store.Transfer().Ship)
+}
+
+func (s *WarehouseService) ReceiveTransfer(transferId int, userId int) (*model.Transfer, error) {
+	return s.changeTransfer(transferId, userId, model.TransferReceived, s.store.Transfer().Receive)
+}
+
+func (s *WarehouseService) CancelTransfer(transferId int, userId int) (*model.Transfer, error) {
+	return s.changeTransfer(transferId, userId, model.TransferCancelled, s.store.Transfer().Cancel)
+}
+
+func (s *WarehouseService) changeTransfer(
+	transferId int,
+	userId int,
+	status string,
+	change func(*model.Transfer) error,
+) (*model.Transfer, error) {
+	t, err := s.GetTransfer(transferId, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	if !t.CanChange(status) {
+		return nil, errTransferStatus
+	}
+
+	if err = change(t); err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+func (s *WarehouseService) defaultWarehouse(userId int) (*model.Warehouse, error) {
+	warehouses, err := s.store.Warehouse().FindByUserId(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, w := range warehouses {
+		if w.Type == model.WarehouseTypeOwn {
+			return w, nil
+		}
+	}
+
+	w := &model.Warehouse{
+		UserID: userId,
+		Name:   model.DefaultWarehouseName,
+		Type:   model.WarehouseTypeOwn,
+	}
+	if err = s.store.Warehouse().Create(w); err != nil {
+		return nil, err
+	}
+
+	return w, nil
+}
+
+func (s *WarehouseService) ownWarehouse(warehouseId int, userId int) (*model.Warehouse, error) {
+	w, err := s.store.Warehouse().Find(warehouseId)
+	if err != nil {
+		return nil, err
+	}
+
+	if w.UserID != userId {
+		return nil, store.ErrRecordNotFound
+	}
+
+	return w, nil
+}
@@ -3,5 +3,9 @@ package store
 import "errors"
 
 var (
-	ErrRecordNotFound = errors.New("Record not found")
+	ErrRecordNotFound  = errors.New("Record not found")
+	ErrVersionConflict = errors.New("Record was changed by another request")
+	ErrRecordExists    = errors.New("Record already exists")
+	ErrNotEnoughStock  = errors.New("Not enough stock")
+	ErrTokenUsed       = errors.New("Token was already used")
 )
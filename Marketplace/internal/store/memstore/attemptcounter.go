@@ -0,0 +1,76 @@
+// Package memstore keeps short-lived data in the process memory for a single API server,
+// the data is lost on restart
+package memstore
+
+import (
+	"sync"
+	"time"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store"
+)
+
+// AttemptCounterRepo is a store.AttemptCounterRepo in memory
+type AttemptCounterRepo struct {
+	mu       sync.Mutex
+	counters map[string]*model.AttemptCounter
+}
+
+func NewAttemptCounterRepo() *AttemptCounterRepo {
+	return &AttemptCounterRepo{
+		counters: make(map[string]*model.AttemptCounter),
+	}
+}
+
+func (r *AttemptCounterRepo) Find(key string) (*model.AttemptCounter, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c, ok := r.counters[key]
+	if !ok {
+		return nil, store.ErrRecordNotFound
+	}
+
+	found := *c
+	return &found, nil
+}
+
+func (r *AttemptCounterRepo) Fail(key string, at time.Time, since time.Time) (*model.AttemptCounter, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c, ok := r.counters[key]
+	if !ok || c.LastFailureAt.Before(since) {
+		c = &model.AttemptCounter{Key: key}
+		r.counters[key] = c
+	}
+
+	c.Failures++
+	c.LastFailureAt = at
+
+	found := *c
+	return &found, nil
+}
+
+func (r *AttemptCounterRepo) Reset(key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.counters, key)
+	return nil
+}
+
+func (r *AttemptCounterRepo) DeleteExpired(since time.Time) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	n := 0
+	for key, c := range r.counters {
+		if c.LastFailureAt.Before(since) {
+			delete(r.counters, key)
+			n++
+		}
+	}
+
+	return n, nil
+}
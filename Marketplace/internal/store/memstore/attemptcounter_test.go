@@ -0,0 +1,44 @@
+package memstore_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store/memstore"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAttemptCounterRepo(t *testing.T) {
+	r := memstore.NewAttemptCounterRepo()
+	at := time.Date(2022, 12, 8, 10, 0, 0, 0, time.UTC)
+	since := at.Add(-time.Hour)
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r.Fail("ip:192.0.2.1", at, since)
+		}()
+	}
+	wg.Wait()
+
+	c, err := r.Find("ip:192.0.2.1")
+	assert.NoError(t, err)
+	assert.Equal(t, 10, c.Failures)
+
+	// failures before since are forgotten
+	c, err = r.Fail("ip:192.0.2.1", at.Add(2*time.Hour), at.Add(time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, c.Failures)
+
+	n, err := r.DeleteExpired(at.Add(3 * time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	_, err = r.Find("ip:192.0.2.1")
+	assert.EqualError(t, err, store.ErrRecordNotFound.Error())
+	assert.NoError(t, r.Reset("ip:192.0.2.1"))
+}
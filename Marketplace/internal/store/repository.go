@@ -1,21 +1,203 @@
 package store
 
-import "github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+import (
+	"time"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+)
 
 type UserRepo interface {
 	Create(*model.User) error
 	FindById(int) (*model.User, error)
 	FindByEmail(string) (*model.User, error)
+	UpdatePassword(*model.User) error
+	VerifyEmail(int) error
+	UpdateEmail(*model.User) error
+	UpdateProfile(*model.User) error
+	UpdateActive(int, bool) error
 }
 
+// ProductRepo saves products with their marketplace items and attribute values in one transaction
 type ProductRepo interface {
 	Create(*model.Product, *model.MarketPlaceItemsList) error
 	Update(*model.Product, *model.MarketPlaceItemsList) error
 	FindByUserId(int) ([]*model.Product, error)
+	FindVariants(int) ([]*model.Product, error)
 	GetProductById(int) (*model.Product, error)
 	GetCategories() ([]*model.Category, error)
 	CreateCategory(*model.Category) error
 	CreateMaterial(*model.Material) error
 	GetMaterials() ([]*model.Material, error)
 	Delete(int, int) error
+	GetRevisions(int) ([]*model.ProductRevision, error)
+	GetRevision(int, int) (*model.ProductRevision, error)
+}
+
+type ImageRepo interface {
+	Create(*model.ProductImage) error
+	Find(int) (*model.ProductImage, error)
+	FindByProductId(int) ([]*model.ProductImage, error)
+	UpdatePositions(int, []int) error
+	Delete(int) error
+}
+
+type AttributeRepo interface {
+	Create(*model.CategoryAttribute) error
+	FindByCategoryIds([]int) ([]*model.CategoryAttribute, error)
+	Delete(int, int) error
+	FindProductValues(int) (map[string]string, error)
+}
+
+type BarcodeRepo interface {
+	Create(*model.Barcode) error
+	Find(int) (*model.Barcode, error)
+	FindByProductId(int) ([]*model.Barcode, error)
+	FindByCode(int, string) (*model.Barcode, error)
+	CountInternal(int) (int, error)
+	Delete(int) error
+}
+
+type PriceRepo interface {
+	Create([]*model.Price) error
+	FindCurrent(int) ([]*model.Price, error)
+	FindHistory(int, int) ([]*model.Price, error)
+}
+
+// RepricingRepo keeps repricing rules and proposals. ApplyProposal saves the new price, the proposal
+// status and the log entry with the id of the price in one transaction, it returns ErrVersionConflict
+// when the proposal is no longer approved.
+type RepricingRepo interface {
+	CreateRule(*model.RepricingRule) error
+	FindRule(int) (*model.RepricingRule, error)
+	FindRules(int) ([]*model.RepricingRule, error)
+	FindActiveRules() ([]*model.RepricingRule, error)
+	DeleteRule(int) error
+	SaveCompetitorPrices([]*model.CompetitorPrice) error
+	FindCompetitorPrices(int) ([]*model.CompetitorPrice, error)
+	CreateProposals(int, []*model.PriceProposal) error
+	FindProposal(int) (*model.PriceProposal, error)
+	FindProposals(int, string) ([]*model.PriceProposal, error)
+	UpdateProposal(*model.PriceProposal) error
+	ApplyProposal(*model.PriceProposal, *model.Price, *model.RepricingLogEntry) error
+	FindLog(int) ([]*model.RepricingLogEntry, error)
+}
+
+type InventoryRepo interface {
+	SaveSales([]*model.Sale) error
+	FindSales(int, time.Time) ([]*model.Sale, error)
+	SetStock([]*model.StockLevel) error
+	FindStock(int) ([]*model.StockLevel, error)
+}
+
+type SupplyRepo interface {
+	CreateSupplier(*model.Supplier) error
+	FindSupplier(int) (*model.Supplier, error)
+	FindSuppliers(int) ([]*model.Supplier, error)
+	CreateOrder(*model.SupplyOrder) error
+	FindOrder(int) (*model.SupplyOrder, error)
+}
+
+type WarehouseRepo interface {
+	Create(*model.Warehouse) error
+	Find(int) (*model.Warehouse, error)
+	FindByUserId(int) ([]*model.Warehouse, error)
+	Delete(int) error
+}
+
+// TransferRepo changes stock balances together with the transfer status: Ship takes items
+// from the source warehouse, Receive adds them to the destination one and Cancel returns
+// items in transit to the source warehouse
+type TransferRepo interface {
+	Create(*model.Transfer) error
+	Find(int) (*model.Transfer, error)
+	FindByUserId(int, string) ([]*model.Transfer, error)
+	Ship(*model.Transfer) error
+	Receive(*model.Transfer) error
+	Cancel(*model.Transfer) error
+}
+
+// ShipmentRepo saves shipments with their boxes. Update replaces the boxes of a draft shipment.
+type ShipmentRepo interface {
+	Create(*model.Shipment) error
+	Find(int) (*model.Shipment, error)
+	FindByUserId(int, string) ([]*model.Shipment, error)
+	Update(*model.Shipment) error
+	SetStatus(*model.Shipment, string) error
+}
+
+// SessionRepo keeps sessions of the API server when it runs without the external auth service.
+// Touch saves the time and the client of the last request. DeleteExpired and DeleteByUserId
+// return the number of removed sessions.
+type SessionRepo interface {
+	Create(*model.Session) error
+	Find(string) (*model.Session, error)
+	FindByUserId(int) ([]*model.Session, error)
+	Touch(*model.Session) error
+	Delete(string) error
+	DeleteByUserId(int) (int, error)
+	DeleteExpired(time.Time) (int, error)
+}
+
+// RefreshTokenRepo finds tokens by the hash. Revoke saves the id of the replacing token, empty
+// when there is none, and fails with ErrTokenUsed when the token is already revoked.
+// The bulk revokes return the number of revoked tokens.
+type RefreshTokenRepo interface {
+	Create(*model.RefreshToken) error
+	FindByHash(string) (*model.RefreshToken, error)
+	Revoke(string, string, time.Time) error
+	RevokeBySession(string, time.Time) (int, error)
+	RevokeByUserId(int, time.Time) (int, error)
+	DeleteExpired(time.Time) (int, error)
+}
+
+// APIKeyRepo finds keys by the hash of the key, Touch saves the time of the last use
+type APIKeyRepo interface {
+	Create(*model.APIKey) error
+	FindByHash(string) (*model.APIKey, error)
+	FindByUserId(int) ([]*model.APIKey, error)
+	Touch(int, time.Time) error
+	Delete(int) error
+}
+
+// UserTokenRepo keeps one-time tokens sent by email. Use fails with ErrTokenUsed when
+// the token is already used, DeleteByUserId drops the tokens of the user with the purpose.
+type UserTokenRepo interface {
+	Create(*model.UserToken) error
+	FindByHash(string) (*model.UserToken, error)
+	Use(string, time.Time) error
+	DeleteByUserId(int, string) error
+	DeleteExpired(time.Time) (int, error)
+}
+
+// AttemptCounterRepo counts failed sign-ins by key. Fail adds a failure at the first time and
+// returns the counter, a counter without failures since the second time starts over. Find fails
+// with ErrRecordNotFound for keys without failures, DeleteExpired drops counters not failed since the time.
+type AttemptCounterRepo interface {
+	Find(string) (*model.AttemptCounter, error)
+	Fail(string, time.Time, time.Time) (*model.AttemptCounter, error)
+	Reset(string) error
+	DeleteExpired(time.Time) (int, error)
+}
+
+// SignInFailureRepo keeps the audit log of failed sign-ins. Find filters entries by the email
+// and the address when they are not empty and returns at most limit newest entries.
+type SignInFailureRepo interface {
+	Create(*model.SignInFailure) error
+	Find(string, string, int) ([]*model.SignInFailure, error)
+	DeleteBefore(time.Time) (int, error)
+}
+
+// TwoFactorRepo keeps TOTP authenticators and recovery codes. Save replaces a pending
+// authenticator and fails with ErrRecordExists when the user has an enabled one, so does Enable.
+// UseStep fails with ErrTokenUsed for a step not newer than the last accepted one, UseRecoveryCode fails
+// with ErrRecordNotFound for unknown and used codes. Delete drops the recovery codes too.
+type TwoFactorRepo interface {
+	Save(*model.TwoFactor) error
+	Find(int) (*model.TwoFactor, error)
+	Enable(int, int64, time.Time) error
+	UseStep(int, int64) error
+	Delete(int) error
+	ReplaceRecoveryCodes(int, []string) error
+	UseRecoveryCode(int, string, time.Time) error
+	CountRecoveryCodes(int) (int, error)
 }
@@ -0,0 +1,119 @@
+package sqlstore
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store"
+	"github.com/lib/pq"
+)
+
+type APIKeyRepo struct {
+	store *Store
+}
+
+func (r *APIKeyRepo) Create(k *model.APIKey) error {
+	if err := k.Validate(); err != nil {
+		return err
+	}
+
+	if k.CreatedAt.IsZero() {
+		k.CreatedAt = time.Now().UTC()
+	}
+
+	return r.store.db.QueryRow(
+		`INSERT INTO public.apikey (user_id, key_name, prefix, key_hash, scopes, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING api_key_id`,
+		k.UserID,
+		k.Name,
+		k.Prefix,
+		k.KeyHash,
+		pq.Array(k.Scopes),
+		k.ExpiresAt,
+		k.CreatedAt,
+	).Scan(&k.APIKeyID)
+}
+
+func (r *APIKeyRepo) FindByHash(hash string) (*model.APIKey, error) {
+	k, err := scanAPIKey(r.store.db.QueryRow(
+		`SELECT api_key_id, user_id, key_name, prefix, key_hash, scopes, expires_at, last_used_at, created_at
+		FROM public.apikey WHERE key_hash = $1`,
+		hash,
+	))
+	if err == sql.ErrNoRows {
+		return nil, store.ErrRecordNotFound
+	}
+
+	return k, err
+}
+
+func (r *APIKeyRepo) FindByUserId(userId int) ([]*model.APIKey, error) {
+	keys := make([]*model.APIKey, 0)
+	rows, err := r.store.db.Query(
+		`SELECT api_key_id, user_id, key_name, prefix, key_hash, scopes, expires_at, last_used_at, created_at
+		FROM public.apikey WHERE user_id = $1 ORDER BY api_key_id`,
+		userId,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		k, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		keys = append(keys, k)
+	}
+
+	return keys, rows.Err()
+}
+
+func (r *APIKeyRepo) Touch(apiKeyId int, at time.Time) error {
+	res, err := r.store.db.Exec("UPDATE public.apikey SET last_used_at = $2 WHERE api_key_id = $1", apiKeyId, at)
+	if err != nil {
+		return err
+	}
+
+	return checkAffected(res)
+}
+
+func (r *APIKeyRepo) Delete(apiKeyId int) error {
+	res, err := r.store.db.Exec("DELETE FROM public.apikey WHERE api_key_id = $1", apiKeyId)
+	if err != nil {
+		return err
+	}
+
+	return checkAffected(res)
+}
+
+func scanAPIKey(row rowScanner) (*model.APIKey, error) {
+	k := &model.APIKey{}
+	expiresAt, lastUsedAt := sql.NullTime{}, sql.NullTime{}
+	if err := row.Scan(
+		&k.APIKeyID,
+		&k.UserID,
+		&k.Name,
+		&k.Prefix,
+		&k.KeyHash,
+		pq.Array(&k.Scopes),
+		&expiresAt,
+		&lastUsedAt,
+		&k.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	if expiresAt.Valid {
+		k.ExpiresAt = &expiresAt.Time
+	}
+	if lastUsedAt.Valid {
+		k.LastUsedAt = &lastUsedAt.Time
+	}
+
+	return k, nil
+}
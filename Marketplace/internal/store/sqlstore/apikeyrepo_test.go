@@ -0,0 +1,45 @@
+package sqlstore_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store/sqlstore"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIKeyRepo(t *testing.T) {
+	db, teardown := sqlstore.TestDB(t, databaseURL)
+	defer teardown("apikey", "users")
+
+	s := sqlstore.New(db)
+	u := model.TestUser(t)
+	assert.NoError(t, s.User().Create(u))
+
+	k := model.TestAPIKey(t)
+	k.UserID = u.ID
+	k.Scopes = []string{model.ScopeProductsRead, model.ScopeSupplyWrite}
+	expiresAt := k.CreatedAt.AddDate(1, 0, 0)
+	k.ExpiresAt = &expiresAt
+	assert.NoError(t, s.APIKey().Create(k))
+	assert.NotZero(t, k.APIKeyID)
+
+	at := k.CreatedAt.Add(time.Hour)
+	assert.NoError(t, s.APIKey().Touch(k.APIKeyID, at))
+
+	found, err := s.APIKey().FindByHash(k.KeyHash)
+	assert.NoError(t, err)
+	assert.Equal(t, k.Scopes, found.Scopes)
+	assert.True(t, found.ExpiresAt.Equal(expiresAt))
+	assert.True(t, found.LastUsedAt.Equal(at))
+
+	keys, err := s.APIKey().FindByUserId(u.ID)
+	assert.NoError(t, err)
+	assert.Len(t, keys, 1)
+
+	assert.NoError(t, s.APIKey().Delete(k.APIKeyID))
+	_, err = s.APIKey().FindByHash(k.KeyHash)
+	assert.EqualError(t, err, store.ErrRecordNotFound.Error())
+}
@@ -0,0 +1,151 @@
+package sqlstore
+
+import (
+	"database/sql"
+	"encoding/json"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store"
+	"github.com/lib/pq"
+)
+
+type AttributeRepo struct {
+	store *Store
+}
+
+func (r *AttributeRepo) Create(a *model.CategoryAttribute) error {
+	if err := a.Validate(); err != nil {
+		return err
+	}
+
+	allowedValues, err := json.Marshal(a.AllowedValues)
+	if err != nil {
+		return err
+	}
+
+	a.Active = true
+	return r.store.db.QueryRow(
+		`INSERT INTO public.categoryattribute
+		(category_id, code, attribute_name, attribute_type, unit, required, allowed_values, active)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING categoryattribute_id`,
+		a.CategoryID,
+		a.Code,
+		a.Name,
+		a.Type,
+		a.Unit,
+		a.Required,
+		allowedValues,
+		a.Active,
+	).Scan(&a.AttributeID)
+}
+
+func (r *AttributeRepo) FindByCategoryIds(categoryIds []int) ([]*model.CategoryAttribute, error) {
+	ids := make([]int64, 0, len(categoryIds))
+	for _, id := range categoryIds {
+		ids = append(ids, int64(id))
+	}
+
+	attributes := make([]*model.CategoryAttribute, 0)
+	rows, err := r.store.db.Query(
+		`SELECT categoryattribute_id, category_id, code, attribute_name, attribute_type, unit, required,
+		coalesce(allowed_values, 'null'), active
+		FROM public.categoryattribute WHERE active = true AND category_id = ANY($1) ORDER BY categoryattribute_id`,
+		pq.Array(ids),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		a := &model.CategoryAttribute{}
+		var allowedValues []byte
+		if err := rows.Scan(
+			&a.AttributeID,
+			&a.CategoryID,
+			&a.Code,
+			&a.Name,
+			&a.Type,
+			&a.Unit,
+			&a.Required,
+			&allowedValues,
+			&a.Active,
+		); err != nil {
+			return nil, err
+		}
+
+		if err := json.Unmarshal(allowedValues, &a.AllowedValues); err != nil {
+			return nil, err
+		}
+
+		attributes = append(attributes, a)
+	}
+
+	return attributes, rows.Err()
+}
+
+// Delete deactivates the attribute of the category, attributes of other categories are not found
+func (r *AttributeRepo) Delete(categoryId int, attributeId int) error {
+	res, err := r.store.db.Exec(
+		"UPDATE public.categoryattribute SET active = false WHERE categoryattribute_id = $1 AND category_id = $2 AND active = true",
+		attributeId,
+		categoryId,
+	)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return store.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// setProductValues replaces the attribute values in the transaction, products are saved with their values
+func setProductValues(tx *sql.Tx, productId int, values map[string]string) error {
+	if _, err := tx.Exec("DELETE FROM public.productattribute WHERE product_id = $1", productId); err != nil {
+		return err
+	}
+
+	for code, value := range values {
+		if _, err := tx.Exec(
+			"INSERT INTO public.productattribute (product_id, code, attribute_value) VALUES ($1, $2, $3)",
+			productId,
+			code,
+			value,
+		); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *AttributeRepo) FindProductValues(productId int) (map[string]string, error) {
+	values := make(map[string]string)
+	rows, err := r.store.db.Query(
+		"SELECT code, attribute_value FROM public.productattribute WHERE product_id = $1",
+		productId,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var code, value string
+		if err := rows.Scan(&code, &value); err != nil {
+			return nil, err
+		}
+		values[code] = value
+	}
+
+	return values, rows.Err()
+}
@@ -0,0 +1,54 @@
+package sqlstore_test
+
+import (
+	"testing"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store/sqlstore"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAttributeRepo_Create(t *testing.T) {
+	db, teardown := sqlstore.TestDB(t, databaseURL)
+	defer teardown("categoryattribute", "category")
+
+	s := sqlstore.New(db)
+	c := model.TestCategory(t)
+	s.Product().CreateCategory(c)
+
+	a := model.TestCategoryAttribute(t)
+	a.CategoryID = c.CategoryID
+	a.Type = model.AttributeTypeEnum
+	a.AllowedValues = []string{"cotton", "wool"}
+
+	assert.NoError(t, s.Attribute().Create(a))
+	assert.NotZero(t, a.AttributeID)
+
+	attributes, err := s.Attribute().FindByCategoryIds([]int{c.CategoryID})
+	assert.NoError(t, err)
+	assert.Len(t, attributes, 1)
+	assert.Equal(t, a.AllowedValues, attributes[0].AllowedValues)
+
+	assert.EqualError(t, s.Attribute().Delete(c.CategoryID+1, a.AttributeID), store.ErrRecordNotFound.Error())
+	assert.NoError(t, s.Attribute().Delete(c.CategoryID, a.AttributeID))
+	assert.EqualError(t, s.Attribute().Delete(c.CategoryID, a.AttributeID), store.ErrRecordNotFound.Error())
+}
+
+func TestAttributeRepo_ProductValues(t *testing.T) {
+	db, teardown := sqlstore.TestDB(t, databaseURL)
+	defer teardown("productattribute", "product", "users", "category", "material", "marketplaceitem", "productrevision")
+
+	s := sqlstore.New(db)
+	p := testImageProduct(t, s)
+
+	// products are saved together with their attribute values, an update replaces all of them
+	p.Attributes = map[string]string{"fabric": "cotton", "size": "M"}
+	assert.NoError(t, s.Product().Update(p, &model.MarketPlaceItemsList{}))
+	p.Attributes = map[string]string{"fabric": "wool"}
+	assert.NoError(t, s.Product().Update(p, &model.MarketPlaceItemsList{}))
+
+	values, err := s.Attribute().FindProductValues(p.ProductID)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"fabric": "wool"}, values)
+}
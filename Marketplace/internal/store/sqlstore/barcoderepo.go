@@ -0,0 +1,123 @@
+package sqlstore
+
+import (
+	"database/sql"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store"
+	"github.com/lib/pq"
+)
+
+const uniqueViolation = "23505"
+
+type BarcodeRepo struct {
+	store *Store
+}
+
+func (r *BarcodeRepo) Create(b *model.Barcode) error {
+	b.BeforeCreate()
+	if err := b.Validate(); err != nil {
+		return err
+	}
+
+	err := r.store.db.QueryRow(
+		`INSERT INTO public.barcode (product_id, user_id, code, barcode_type, internal)
+		VALUES ($1, $2, $3, $4, $5) RETURNING barcode_id, created_at`,
+		b.ProductID,
+		b.UserID,
+		b.Code,
+		b.Type,
+		b.Internal,
+	).Scan(&b.BarcodeID, &b.CreatedAt)
+	if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == uniqueViolation {
+		return store.ErrRecordExists
+	}
+
+	return err
+}
+
+func (r *BarcodeRepo) Find(barcodeId int) (*model.Barcode, error) {
+	return r.findOne(
+		`SELECT barcode_id, product_id, user_id, code, barcode_type, internal, created_at
+		FROM public.barcode WHERE barcode_id = $1`,
+		barcodeId,
+	)
+}
+
+func (r *BarcodeRepo) FindByCode(userId int, code string) (*model.Barcode, error) {
+	return r.findOne(
+		`SELECT barcode_id, product_id, user_id, code, barcode_type, internal, created_at
+		FROM public.barcode WHERE user_id = $1 AND code = $2`,
+		userId,
+		code,
+	)
+}
+
+func (r *BarcodeRepo) FindByProductId(productId int) ([]*model.Barcode, error) {
+	barcodes := make([]*model.Barcode, 0)
+	rows, err := r.store.db.Query(
+		`SELECT barcode_id, product_id, user_id, code, barcode_type, internal, created_at
+		FROM public.barcode WHERE product_id = $1 ORDER BY barcode_id`,
+		productId,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		b, err := scanBarcode(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		barcodes = append(barcodes, b)
+	}
+
+	return barcodes, rows.Err()
+}
+
+func (r *BarcodeRepo) CountInternal(userId int) (int, error) {
+	var count int
+	err := r.store.db.QueryRow(
+		"SELECT count(*) FROM public.barcode WHERE user_id = $1 AND internal = true",
+		userId,
+	).Scan(&count)
+
+	return count, err
+}
+
+func (r *BarcodeRepo) Delete(barcodeId int) error {
+	_, err := r.store.db.Exec("DELETE FROM public.barcode WHERE barcode_id = $1", barcodeId)
+	return err
+}
+
+func (r *BarcodeRepo) findOne(query string, args ...interface{}) (*model.Barcode, error) {
+	b, err := scanBarcode(r.store.db.QueryRow(query, args...))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, store.ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	return b, nil
+}
+
+func scanBarcode(row rowScanner) (*model.Barcode, error) {
+	b := &model.Barcode{}
+	if err := row.Scan(
+		&b.BarcodeID,
+		&b.ProductID,
+		&b.UserID,
+		&b.Code,
+		&b.Type,
+		&b.Internal,
+		&b.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
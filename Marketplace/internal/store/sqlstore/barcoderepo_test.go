@@ -0,0 +1,45 @@
+package sqlstore_test
+
+import (
+	"testing"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store/sqlstore"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBarcodeRepo_Create(t *testing.T) {
+	db, teardown := sqlstore.TestDB(t, databaseURL)
+	defer teardown("barcode", "product", "users", "category", "material", "marketplaceitem", "productrevision")
+
+	s := sqlstore.New(db)
+	p := testImageProduct(t, s)
+
+	b := model.TestBarcode(t)
+	b.ProductID = p.ProductID
+	b.UserID = p.UserID
+	assert.NoError(t, s.Barcode().Create(b))
+	assert.NotZero(t, b.BarcodeID)
+
+	duplicate := model.TestBarcode(t)
+	duplicate.ProductID = p.ProductID
+	duplicate.UserID = p.UserID
+	assert.EqualError(t, s.Barcode().Create(duplicate), store.ErrRecordExists.Error())
+
+	found, err := s.Barcode().FindByCode(p.UserID, b.Code)
+	assert.NoError(t, err)
+	assert.Equal(t, model.BarcodeTypeEAN13, found.Type)
+
+	barcodes, err := s.Barcode().FindByProductId(p.ProductID)
+	assert.NoError(t, err)
+	assert.Len(t, barcodes, 1)
+
+	count, err := s.Barcode().CountInternal(p.UserID)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, count)
+
+	assert.NoError(t, s.Barcode().Delete(b.BarcodeID))
+	_, err = s.Barcode().Find(b.BarcodeID)
+	assert.EqualError(t, err, store.ErrRecordNotFound.Error())
+}
@@ -0,0 +1,130 @@
+package sqlstore
+
+import (
+	"database/sql"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store"
+)
+
+type ImageRepo struct {
+	store *Store
+}
+
+func (r *ImageRepo) Create(i *model.ProductImage) error {
+	return r.store.db.QueryRow(
+		`INSERT INTO public.productimage
+		(product_id, user_id, file_key, thumbnail_key, content_type, file_size, width, height, position)
+		SELECT $1, $2, $3, $4, $5, $6, $7, $8, coalesce(max(position), 0) + 1
+		FROM public.productimage WHERE product_id = $1
+		RETURNING productimage_id, position, created_at`,
+		i.ProductID,
+		i.UserID,
+		i.FileKey,
+		i.ThumbnailKey,
+		i.ContentType,
+		i.Size,
+		i.Width,
+		i.Height,
+	).Scan(&i.ImageID, &i.Position, &i.CreatedAt)
+}
+
+func (r *ImageRepo) Find(imageId int) (*model.ProductImage, error) {
+	i, err := scanImage(r.store.db.QueryRow(
+		`SELECT productimage_id, product_id, user_id, file_key, thumbnail_key, content_type, file_size, width, height, position, created_at
+		FROM public.productimage WHERE productimage_id = $1`,
+		imageId,
+	))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, store.ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	return i, nil
+}
+
+func (r *ImageRepo) FindByProductId(productId int) ([]*model.ProductImage, error) {
+	images := make([]*model.ProductImage, 0)
+	rows, err := r.store.db.Query(
+		`SELECT productimage_id, product_id, user_id, file_key, thumbnail_key, content_type, file_size, width, height, position, created_at
+		FROM public.productimage WHERE product_id = $1 ORDER BY position`,
+		productId,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		i, err := scanImage(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		images = append(images, i)
+	}
+
+	return images, rows.Err()
+}
+
+func (r *ImageRepo) UpdatePositions(productId int, imageIds []int) error {
+	tx, err := r.store.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	for position, imageId := range imageIds {
+		res, err := tx.Exec(
+			"UPDATE public.productimage SET position = $1 WHERE productimage_id = $2 AND product_id = $3",
+			position+1,
+			imageId,
+			productId,
+		)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		updated, err := res.RowsAffected()
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		if updated == 0 {
+			tx.Rollback()
+			return store.ErrRecordNotFound
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *ImageRepo) Delete(imageId int) error {
+	_, err := r.store.db.Exec("DELETE FROM public.productimage WHERE productimage_id = $1", imageId)
+	return err
+}
+
+func scanImage(row rowScanner) (*model.ProductImage, error) {
+	i := &model.ProductImage{}
+	if err := row.Scan(
+		&i.ImageID,
+		&i.ProductID,
+		&i.UserID,
+		&i.FileKey,
+		&i.ThumbnailKey,
+		&i.ContentType,
+		&i.Size,
+		&i.Width,
+		&i.Height,
+		&i.Position,
+		&i.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	return i, nil
+}
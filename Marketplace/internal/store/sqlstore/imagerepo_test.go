@@ -0,0 +1,94 @@
+package sqlstore_test
+
+import (
+	"testing"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store/sqlstore"
+	"github.com/stretchr/testify/assert"
+)
+
+func testImageProduct(t *testing.T, s *sqlstore.Store) *model.Product {
+	u := model.TestUser(t)
+	s.User().Create(u)
+
+	c := model.TestCategory(t)
+	s.Product().CreateCategory(c)
+
+	m := model.TestMaterial(t)
+	s.Product().CreateMaterial(m)
+
+	p := model.TestProduct(t)
+	p.UserID = u.ID
+	p.CategoryID = c.CategoryID
+	p.MaterialID = m.MaterialID
+
+	mpi := &model.MarketPlaceItemsList{}
+	mpi.GetMPIList(p)
+	s.Product().Create(p, mpi)
+
+	return p
+}
+
+func TestImageRepo_Create(t *testing.T) {
+	db, teardown := sqlstore.TestDB(t, databaseURL)
+	defer teardown("productimage", "product", "users", "category", "material", "marketplaceitem", "productrevision")
+
+	s := sqlstore.New(db)
+	p := testImageProduct(t, s)
+
+	i1 := model.TestProductImage(t)
+	i1.ProductID = p.ProductID
+	i1.UserID = p.UserID
+	i2 := model.TestProductImage(t)
+	i2.ProductID = p.ProductID
+	i2.UserID = p.UserID
+
+	assert.NoError(t, s.Image().Create(i1))
+	assert.NoError(t, s.Image().Create(i2))
+	assert.Equal(t, 1, i1.Position)
+	assert.Equal(t, 2, i2.Position)
+}
+
+func TestImageRepo_UpdatePositions(t *testing.T) {
+	db, teardown := sqlstore.TestDB(t, databaseURL)
+	defer teardown("productimage", "product", "users", "category", "material", "marketplaceitem", "productrevision")
+
+	s := sqlstore.New(db)
+	p := testImageProduct(t, s)
+
+	i1 := model.TestProductImage(t)
+	i1.ProductID = p.ProductID
+	i1.UserID = p.UserID
+	s.Image().Create(i1)
+	i2 := model.TestProductImage(t)
+	i2.ProductID = p.ProductID
+	i2.UserID = p.UserID
+	s.Image().Create(i2)
+
+	assert.NoError(t, s.Image().UpdatePositions(p.ProductID, []int{i2.ImageID, i1.ImageID}))
+
+	images, err := s.Image().FindByProductId(p.ProductID)
+	assert.NoError(t, err)
+	assert.Equal(t, i2.ImageID, images[0].ImageID)
+	assert.Equal(t, i1.ImageID, images[1].ImageID)
+}
+
+func TestImageRepo_Delete(t *testing.T) {
+	db, teardown := sqlstore.TestDB(t, databaseURL)
+	defer teardown("productimage", "product", "users", "category", "material", "marketplaceitem", "productrevision")
+
+	s := sqlstore.New(db)
+	p := testImageProduct(t, s)
+
+	i := model.TestProductImage(t)
+	i.ProductID = p.ProductID
+	i.UserID = p.UserID
+	s.Image().Create(i)
+
+	assert.NoError(t, s.Image().Delete(i.ImageID))
+
+	_, err := s.Image().Find(i.ImageID)
+	assert.EqualError(t, err, store.ErrRecordNotFound.Error())
+}
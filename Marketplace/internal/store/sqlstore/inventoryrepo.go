@@ -0,0 +1,126 @@
+package sqlstore
+
+import (
+	"time"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+)
+
+type InventoryRepo struct {
+	store *Store
+}
+
+// SaveSales stores daily sales, a repeated import of the same day replaces the quantity
+func (r *InventoryRepo) SaveSales(sales []*model.Sale) error {
+	for _, s := range sales {
+		s.BeforeCreate()
+		if err := s.Validate(); err != nil {
+			return err
+		}
+	}
+
+	tx, err := r.store.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	for _, s := range sales {
+		if _, err = tx.Exec(
+			`INSERT INTO public.sale (product_id, marketplace_id, sale_date, user_id, quantity)
+			VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (product_id, marketplace_id, sale_date) DO UPDATE SET quantity = EXCLUDED.quantity`,
+			s.ProductID,
+			s.MarketPlaceID,
+			s.SaleDate,
+			s.UserID,
+			s.Quantity,
+		); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// FindSales returns sales of the seller starting from the date
+func (r *InventoryRepo) FindSales(userId int, from time.Time) ([]*model.Sale, error) {
+	sales := make([]*model.Sale, 0)
+	rows, err := r.store.db.Query(
+		`SELECT product_id, marketplace_id, sale_date, user_id, quantity
+		FROM public.sale WHERE user_id = $1 AND sale_date >= $2 ORDER BY sale_date, product_id`,
+		userId,
+		from,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		s := &model.Sale{}
+		if err = rows.Scan(&s.ProductID, &s.MarketPlaceID, &s.SaleDate, &s.UserID, &s.Quantity); err != nil {
+			return nil, err
+		}
+
+		sales = append(sales, s)
+	}
+
+	return sales, rows.Err()
+}
+
+func (r *InventoryRepo) SetStock(levels []*model.StockLevel) error {
+	for _, l := range levels {
+		if err := l.Validate(); err != nil {
+			return err
+		}
+	}
+
+	tx, err := r.store.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	for _, l := range levels {
+		if err = tx.QueryRow(
+			`INSERT INTO public.productstock (warehouse_id, product_id, user_id, quantity) VALUES ($1, $2, $3, $4)
+			ON CONFLICT (warehouse_id, product_id) DO UPDATE SET quantity = EXCLUDED.quantity, updated_at = now()
+			RETURNING updated_at`,
+			l.WarehouseID,
+			l.ProductID,
+			l.UserID,
+			l.Quantity,
+		).Scan(&l.UpdatedAt); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *InventoryRepo) FindStock(userId int) ([]*model.StockLevel, error) {
+	levels := make([]*model.StockLevel, 0)
+	rows, err := r.store.db.Query(
+		`SELECT warehouse_id, product_id, user_id, quantity, updated_at
+		FROM public.productstock WHERE user_id = $1 ORDER BY product_id, warehouse_id`,
+		userId,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		l := &model.StockLevel{}
+		if err = rows.Scan(&l.WarehouseID, &l.ProductID, &l.UserID, &l.Quantity, &l.UpdatedAt); err != nil {
+			return nil, err
+		}
+
+		levels = append(levels, l)
+	}
+
+	return levels, rows.Err()
+}
@@ -0,0 +1,105 @@
+package sqlstore
+
+import (
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+)
+
+const priceColumns = `price_id, product_id, marketplace_id, user_id, list_price, discount_price, min_price,
+	landed_cost, override, created_at`
+
+type PriceRepo struct {
+	store *Store
+}
+
+// Create saves new versions of prices in one transaction
+func (r *PriceRepo) Create(prices []*model.Price) error {
+	for _, p := range prices {
+		if err := p.Validate(); err != nil {
+			return err
+		}
+	}
+
+	tx, err := r.store.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	for _, p := range prices {
+		if err = insertPrice(tx, p); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func insertPrice(q queryer, p *model.Price) error {
+	return q.QueryRow(
+		`INSERT INTO public.productprice (product_id, marketplace_id, user_id, list_price, discount_price,
+		min_price, landed_cost, override) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING price_id, created_at`,
+		p.ProductID,
+		p.MarketPlaceID,
+		p.UserID,
+		p.ListPrice,
+		p.DiscountPrice,
+		p.MinPrice,
+		p.LandedCost,
+		p.Override,
+	).Scan(&p.PriceID, &p.CreatedAt)
+}
+
+// FindCurrent returns the latest price of the product on every marketplace
+func (r *PriceRepo) FindCurrent(productId int) ([]*model.Price, error) {
+	return r.findPrices(
+		`SELECT DISTINCT ON (marketplace_id) `+priceColumns+`
+		FROM public.productprice WHERE product_id = $1
+		ORDER BY marketplace_id, price_id DESC`,
+		productId,
+	)
+}
+
+// FindHistory returns all prices of the product starting from the latest one,
+// prices of all marketplaces are returned when marketPlaceId is zero
+func (r *PriceRepo) FindHistory(productId int, marketPlaceId int) ([]*model.Price, error) {
+	return r.findPrices(
+		`SELECT `+priceColumns+`
+		FROM public.productprice WHERE product_id = $1 AND ($2 = 0 OR marketplace_id = $2)
+		ORDER BY price_id DESC`,
+		productId,
+		marketPlaceId,
+	)
+}
+
+func (r *PriceRepo) findPrices(query string, args ...interface{}) ([]*model.Price, error) {
+	prices := make([]*model.Price, 0)
+	rows, err := r.store.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		p := &model.Price{}
+		if err = rows.Scan(
+			&p.PriceID,
+			&p.ProductID,
+			&p.MarketPlaceID,
+			&p.UserID,
+			&p.ListPrice,
+			&p.DiscountPrice,
+			&p.MinPrice,
+			&p.LandedCost,
+			&p.Override,
+			&p.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+
+		prices = append(prices, p)
+	}
+
+	return prices, rows.Err()
+}
@@ -0,0 +1,41 @@
+package sqlstore_test
+
+import (
+	"testing"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store/sqlstore"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPriceRepo_Create(t *testing.T) {
+	db, teardown := sqlstore.TestDB(t, databaseURL)
+	defer teardown("productprice", "product", "users", "category", "material", "marketplaceitem", "productrevision")
+
+	s := sqlstore.New(db)
+	p := testImageProduct(t, s)
+
+	old := model.TestPrice(t)
+	old.ProductID = p.ProductID
+	old.UserID = p.UserID
+	current := model.TestPrice(t)
+	current.ProductID = p.ProductID
+	current.UserID = p.UserID
+	current.ListPrice = 1500
+	assert.NoError(t, s.Price().Create([]*model.Price{old, current}))
+	assert.NotZero(t, current.PriceID)
+
+	prices, err := s.Price().FindCurrent(p.ProductID)
+	assert.NoError(t, err)
+	if assert.Len(t, prices, 1) {
+		assert.Equal(t, 1500.0, prices[0].ListPrice)
+	}
+
+	history, err := s.Price().FindHistory(p.ProductID, model.MarketPlaceOzon)
+	assert.NoError(t, err)
+	assert.Len(t, history, 2)
+
+	history, err = s.Price().FindHistory(p.ProductID, model.MarketPlaceWildberries)
+	assert.NoError(t, err)
+	assert.Empty(t, history)
+}
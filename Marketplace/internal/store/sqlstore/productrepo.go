@@ -2,6 +2,7 @@ package sqlstore
 
 import (
 	"database/sql"
+	"encoding/json"
 
 	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
 	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store"
@@ -17,20 +18,30 @@ func (r *ProductRepo) Create(p *model.Product, mpiList *model.MarketPlaceItemsLi
 		return err
 	}
 
+	variantAttributes, err := marshalVariantAttributes(p)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
 	p.Active = true
-	err = r.store.db.QueryRow(
-		"INSERT INTO public.product (product_name, category_id, pieces_in_pack, material_id, weight_gr, lenght_mm, width_mm, height_mm, product_description, user_id, active) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11) RETURNING product_id",
+	p.Version = 1
+	err = tx.QueryRow(
+		"INSERT INTO public.product (product_name, category_id, pieces_in_pack, material_id, weight_gr, lenght_mm, width_mm, height_mm, product_description, user_id, active, version, parent_product_id, variant_attributes) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14) RETURNING product_id",
 		p.ProductName,
 		p.CategoryID,
 		p.PiecesInPack,
 		p.MaterialID,
 		p.Weight,
 		p.Lenght,
-		p.Weight,
+		p.Width,
 		p.Height,
 		p.Description,
 		p.UserID,
 		p.Active,
+		p.Version,
+		NewNullInt(int64(p.ParentProductID)),
+		variantAttributes,
 	).Scan(&p.ProductID)
 
 	if err != nil {
@@ -43,7 +54,7 @@ func (r *ProductRepo) Create(p *model.Product, mpiList *model.MarketPlaceItemsLi
 
 	for _, mpi := range mpiList.MPIList {
 		mpi.ProductID = p.ProductID
-		err := r.store.db.QueryRow(
+		err := tx.QueryRow(
 			`INSERT INTO public.marketplaceitem 
 			(product_id, marketplace_id, sku, user_id, active) 
 			VALUES ($1, $2, $3, $4, $5) RETURNING marketplaceitem_id`,
@@ -63,27 +74,53 @@ func (r *ProductRepo) Create(p *model.Product, mpiList *model.MarketPlaceItemsLi
 		}
 	}
 
+	if err = setProductValues(tx, p.ProductID, p.Attributes); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err = r.createRevision(tx, model.RevisionActionCreate, p, p.UserID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
 	return tx.Commit()
 }
 
 func (r *ProductRepo) Delete(productId int, userId int) error {
+	p, err := r.GetProductById(productId)
+	if err != nil && err != store.ErrRecordNotFound {
+		return err
+	}
+
 	tx, err := r.store.db.Begin()
 	if err != nil {
 		return err
 	}
 
 	sqlQuery := `DELETE FROM public.marketplaceitem WHERE product_id = $1 and user_id = $2`
-	_, err = r.store.db.Exec(sqlQuery, productId, userId)
+	_, err = tx.Exec(sqlQuery, productId, userId)
 	if err != nil {
-		if err = tx.Rollback(); err != nil {
-			return err
-		}
+		tx.Rollback()
+		return err
 	}
 
 	sqlQuery = `DELETE FROM public.product WHERE product_id = $1 and  user_id = $2`
-	_, err = r.store.db.Exec(sqlQuery, productId, userId)
+	res, err := tx.Exec(sqlQuery, productId, userId)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	deleted, err := res.RowsAffected()
 	if err != nil {
-		if err = tx.Rollback(); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if deleted > 0 && p != nil {
+		if err = r.createRevision(tx, model.RevisionActionDelete, p, userId); err != nil {
+			tx.Rollback()
 			return err
 		}
 	}
@@ -92,12 +129,17 @@ func (r *ProductRepo) Delete(productId int, userId int) error {
 }
 
 func (r *ProductRepo) Update(p *model.Product, mpiList *model.MarketPlaceItemsList) error {
+	variantAttributes, err := marshalVariantAttributes(p)
+	if err != nil {
+		return err
+	}
+
 	tx, err := r.store.db.Begin()
 	if err != nil {
 		return err
 	}
 
-	_, err = r.store.db.Exec(
+	err = tx.QueryRow(
 		`UPDATE public.product 
 		SET product_name = $1,
 		category_id = $2,
@@ -109,21 +151,37 @@ func (r *ProductRepo) Update(p *model.Product, mpiList *model.MarketPlaceItemsLi
 		height_mm = $8, 
 		product_description = $9, 
 		user_id = $10, 
-		active = $11
-		WHERE product_id = $12`,
+		active = $11,
+		version = version + 1,
+		parent_product_id = $14,
+		variant_attributes = $15
+		WHERE product_id = $12
+		AND ($13 = 0 OR version = $13)
+		RETURNING version`,
 		p.ProductName,
 		p.CategoryID,
 		p.PiecesInPack,
 		p.MaterialID,
 		p.Weight,
 		p.Lenght,
-		p.Weight,
+		p.Width,
 		p.Height,
 		p.Description,
 		p.UserID,
 		p.Active,
 		p.ProductID,
-	)
+		p.Version,
+		NewNullInt(int64(p.ParentProductID)),
+		variantAttributes,
+	).Scan(&p.Version)
+
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		if _, err = r.GetProductById(p.ProductID); err != nil {
+			return err
+		}
+		return store.ErrVersionConflict
+	}
 
 	if err != nil {
 		err := tx.Rollback()
@@ -134,7 +192,7 @@ func (r *ProductRepo) Update(p *model.Product, mpiList *model.MarketPlaceItemsLi
 	}
 
 	for _, mpi := range mpiList.MPIList {
-		_, err := r.store.db.Exec(
+		_, err := tx.Exec(
 			`UPDATE public.marketplaceitem 
 				SET sku = $3, 
 					active = $5
@@ -157,36 +215,35 @@ func (r *ProductRepo) Update(p *model.Product, mpiList *model.MarketPlaceItemsLi
 			return err
 		}
 	}
+
+	if err = setProductValues(tx, p.ProductID, p.Attributes); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err = r.createRevision(tx, model.RevisionActionUpdate, p, p.UserID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
 	return tx.Commit()
 }
 
+const productColumns = `product_id, product_name, category_id, pieces_in_pack ,material_id, weight_gr, lenght_mm,
+	width_mm, height_mm, product_description, user_id, active, version,
+	coalesce(parent_product_id, 0), coalesce(variant_attributes, '{}'),
+	coalesce((select mpi.sku from public.marketplaceitem as mpi
+	WHERE mpi.active = true and mpi.product_id = p.product_id and mpi.marketplace_id = 1), 0)
+	, coalesce((select mpi.sku from public.marketplaceitem as mpi
+	WHERE mpi.active = true and mpi.product_id = p.product_id and mpi.marketplace_id = 2), 0)`
+
 func (r *ProductRepo) GetProductById(productId int) (*model.Product, error) {
-	p := &model.Product{}
-	if err := r.store.db.QueryRow(
-		`SELECT product_id, product_name, category_id, pieces_in_pack ,material_id, weight_gr, lenght_mm,
-			width_mm, height_mm, product_description, user_id, active, 
-			coalesce((select mpi.sku from public.marketplaceitem as mpi
-			WHERE mpi.active = true and mpi.product_id = p.product_id and mpi.marketplace_id = 1), 0)
-			, coalesce((select mpi.sku from public.marketplaceitem as mpi
-			WHERE mpi.active = true and mpi.product_id = p.product_id and mpi.marketplace_id = 2), 0)
+	p, err := scanProduct(r.store.db.QueryRow(
+		`SELECT `+productColumns+`
 			FROM public.product as p WHERE active = true and product_id = $1`,
 		productId,
-	).Scan(
-		&p.ProductID,
-		&p.ProductName,
-		&p.CategoryID,
-		&p.PiecesInPack,
-		&p.MaterialID,
-		&p.Weight,
-		&p.Lenght,
-		&p.Width,
-		&p.Height,
-		&p.Description,
-		&p.UserID,
-		&p.Active,
-		&p.OzonSKU,
-		&p.WildberriesSKU,
-	); err != nil {
+	))
+	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, store.ErrRecordNotFound
 		}
@@ -197,52 +254,41 @@ func (r *ProductRepo) GetProductById(productId int) (*model.Product, error) {
 }
 
 func (r *ProductRepo) FindByUserId(userId int) ([]*model.Product, error) {
-	var products []*model.Product
-	rows, err := r.store.db.Query(
-		`SELECT product_id, product_name, category_id, pieces_in_pack ,material_id, weight_gr, lenght_mm, width_mm, height_mm, product_description, user_id, active
-			, coalesce((select mpi.sku from public.marketplaceitem as mpi WHERE mpi.active = true and mpi.product_id = p.product_id and mpi.marketplace_id = 1), 0)
-			, coalesce((select mpi.sku from public.marketplaceitem as mpi WHERE mpi.active = true and mpi.product_id = p.product_id and mpi.marketplace_id = 2), 0)
+	return r.findProducts(
+		`SELECT `+productColumns+`
 			FROM public.product as p WHERE active = true and user_id = $1`,
 		userId,
 	)
+}
 
+func (r *ProductRepo) FindVariants(parentProductId int) ([]*model.Product, error) {
+	return r.findProducts(
+		`SELECT `+productColumns+`
+			FROM public.product as p WHERE active = true and parent_product_id = $1
+			ORDER BY product_id`,
+		parentProductId,
+	)
+}
+
+func (r *ProductRepo) findProducts(query string, args ...interface{}) ([]*model.Product, error) {
+	var products []*model.Product
+	rows, err := r.store.db.Query(query, args...)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, store.ErrRecordNotFound
-		}
 		return nil, err
 	}
 
 	defer rows.Close()
 
 	for rows.Next() {
-		p := new(model.Product)
-		if err := rows.Scan(
-			&p.ProductID,
-			&p.ProductName,
-			&p.CategoryID,
-			&p.PiecesInPack,
-			&p.MaterialID,
-			&p.Weight,
-			&p.Lenght,
-			&p.Width,
-			&p.Height,
-			&p.Description,
-			&p.UserID,
-			&p.Active,
-			&p.OzonSKU,
-			&p.WildberriesSKU,
-		); err != nil {
-			if err == sql.ErrNoRows {
-				return nil, store.ErrRecordNotFound
-			}
+		p, err := scanProduct(rows)
+		if err != nil {
 			return nil, err
 		}
 
 		products = append(products, p)
 	}
 
-	return products, nil
+	return products, rows.Err()
 }
 
 func (r *ProductRepo) CreateCategory(c *model.Category) error {
@@ -339,6 +385,148 @@ func (r *ProductRepo) GetMaterials() ([]*model.Material, error) {
 	return materials, nil
 }
 
+// createRevision numbers the revision after the last one of the product. The product row is
+// locked first, so concurrent writes of one product can't take the same number.
+func (r *ProductRepo) createRevision(tx *sql.Tx, action string, p *model.Product, userId int) error {
+	snapshot, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+
+	if _, err = tx.Exec("SELECT 1 FROM public.product WHERE product_id = $1 FOR UPDATE", p.ProductID); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(
+		`INSERT INTO public.productrevision (product_id, revision, revision_action, snapshot, user_id)
+		SELECT $1, coalesce(max(revision), 0) + 1, $2, $3, $4
+		FROM public.productrevision WHERE product_id = $1`,
+		p.ProductID,
+		action,
+		snapshot,
+		userId,
+	)
+
+	return err
+}
+
+func (r *ProductRepo) GetRevisions(productId int) ([]*model.ProductRevision, error) {
+	revisions := make([]*model.ProductRevision, 0)
+	rows, err := r.store.db.Query(
+		`SELECT productrevision_id, product_id, revision, revision_action, snapshot, user_id, created_at
+		FROM public.productrevision WHERE product_id = $1 ORDER BY revision`,
+		productId,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		rev, err := scanRevision(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		revisions = append(revisions, rev)
+	}
+
+	if len(revisions) < 1 {
+		return nil, store.ErrRecordNotFound
+	}
+
+	return revisions, rows.Err()
+}
+
+func (r *ProductRepo) GetRevision(productId int, revision int) (*model.ProductRevision, error) {
+	rev, err := scanRevision(r.store.db.QueryRow(
+		`SELECT productrevision_id, product_id, revision, revision_action, snapshot, user_id, created_at
+		FROM public.productrevision WHERE product_id = $1 and revision = $2`,
+		productId,
+		revision,
+	))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, store.ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	return rev, nil
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanProduct(row rowScanner) (*model.Product, error) {
+	p := &model.Product{}
+	var variantAttributes []byte
+	if err := row.Scan(
+		&p.ProductID,
+		&p.ProductName,
+		&p.CategoryID,
+		&p.PiecesInPack,
+		&p.MaterialID,
+		&p.Weight,
+		&p.Lenght,
+		&p.Width,
+		&p.Height,
+		&p.Description,
+		&p.UserID,
+		&p.Active,
+		&p.Version,
+		&p.ParentProductID,
+		&variantAttributes,
+		&p.OzonSKU,
+		&p.WildberriesSKU,
+	); err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(variantAttributes, &p.VariantAttributes); err != nil {
+		return nil, err
+	}
+
+	if len(p.VariantAttributes) == 0 {
+		p.VariantAttributes = nil
+	}
+
+	return p, nil
+}
+
+func marshalVariantAttributes(p *model.Product) (interface{}, error) {
+	if len(p.VariantAttributes) == 0 {
+		return nil, nil
+	}
+
+	return json.Marshal(p.VariantAttributes)
+}
+
+func scanRevision(row rowScanner) (*model.ProductRevision, error) {
+	rev := &model.ProductRevision{}
+	var snapshot []byte
+	if err := row.Scan(
+		&rev.RevisionID,
+		&rev.ProductID,
+		&rev.Revision,
+		&rev.Action,
+		&snapshot,
+		&rev.UserID,
+		&rev.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	rev.Product = &model.Product{}
+	if err := json.Unmarshal(snapshot, rev.Product); err != nil {
+		return nil, err
+	}
+
+	return rev, nil
+}
+
 func NewNullInt(v int64) sql.NullInt64 {
 	if v == 0 {
 		return sql.NullInt64{}
@@ -35,6 +35,11 @@ func TestProductRepo_Create(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NotNil(t, p)
 	assert.NotNil(t, mpi)
+
+	created, err := s.Product().GetProductById(p.ProductID)
+	assert.NoError(t, err)
+	assert.Equal(t, p.Width, created.Width)
+	assert.Equal(t, p.Weight, created.Weight)
 }
 
 func TestProductRepo_Update(t *testing.T) {
@@ -65,6 +70,7 @@ func TestProductRepo_Update(t *testing.T) {
 
 	newDescription := "new description"
 	p.Description = newDescription
+	p.Width = 250
 
 	mpi1 := &model.MarketPlaceItemsList{}
 	mpi1.UpdateMPIList(p)
@@ -76,6 +82,7 @@ func TestProductRepo_Update(t *testing.T) {
 
 	assert.Equal(t, newDescription, up.Description)
 	assert.Equal(t, newOzon, up.OzonSKU)
+	assert.Equal(t, float32(250), up.Width)
 }
 
 func TestProductRepo_Delete(t *testing.T) {
@@ -300,3 +307,119 @@ func TestProductRepo_GetMaterials(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, 2, len(materials))
 }
+
+func TestProductRepo_Revisions(t *testing.T) {
+	db, teardown := sqlstore.TestDB(t, databaseURL)
+	defer teardown("product", "users", "category", "material", "marketplaceitem", "productrevision")
+
+	s := sqlstore.New(db)
+	u := model.TestUser(t)
+	s.User().Create(u)
+
+	c := model.TestCategory(t)
+	s.Product().CreateCategory(c)
+
+	m := model.TestMaterial(t)
+	s.Product().CreateMaterial(m)
+
+	p := model.TestProduct(t)
+	p.UserID = u.ID
+	p.CategoryID = c.CategoryID
+	p.MaterialID = m.MaterialID
+
+	mpi := &model.MarketPlaceItemsList{}
+	mpi.GetMPIList(p)
+	_ = s.Product().Create(p, mpi)
+
+	p.Description = "new description"
+	mpi1 := &model.MarketPlaceItemsList{}
+	mpi1.UpdateMPIList(p)
+	_ = s.Product().Update(p, mpi1)
+
+	_ = s.Product().Delete(p.ProductID, u.ID)
+
+	revisions, err := s.Product().GetRevisions(p.ProductID)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(revisions))
+	assert.Equal(t, model.RevisionActionCreate, revisions[0].Action)
+	assert.Equal(t, model.RevisionActionDelete, revisions[2].Action)
+	assert.Equal(t, u.ID, revisions[2].UserID)
+
+	rev, err := s.Product().GetRevision(p.ProductID, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, "new description", rev.Product.Description)
+}
+
+func TestProductRepo_UpdateVersionConflict(t *testing.T) {
+	db, teardown := sqlstore.TestDB(t, databaseURL)
+	defer teardown("product", "users", "category", "material", "marketplaceitem", "productrevision")
+
+	s := sqlstore.New(db)
+	u := model.TestUser(t)
+	s.User().Create(u)
+
+	c := model.TestCategory(t)
+	s.Product().CreateCategory(c)
+
+	m := model.TestMaterial(t)
+	s.Product().CreateMaterial(m)
+
+	p := model.TestProduct(t)
+	p.UserID = u.ID
+	p.CategoryID = c.CategoryID
+	p.MaterialID = m.MaterialID
+
+	mpi := &model.MarketPlaceItemsList{}
+	mpi.GetMPIList(p)
+	_ = s.Product().Create(p, mpi)
+
+	p1 := *p
+	p1.Description = "first"
+	assert.NoError(t, s.Product().Update(&p1, &model.MarketPlaceItemsList{}))
+	assert.Equal(t, 2, p1.Version)
+
+	p2 := *p
+	p2.Description = "second"
+	assert.EqualError(t, s.Product().Update(&p2, &model.MarketPlaceItemsList{}), store2.ErrVersionConflict.Error())
+
+	up, _ := s.Product().GetProductById(p.ProductID)
+	assert.Equal(t, "first", up.Description)
+	assert.Equal(t, 2, up.Version)
+}
+
+func TestProductRepo_FindVariants(t *testing.T) {
+	db, teardown := sqlstore.TestDB(t, databaseURL)
+	defer teardown("product", "users", "category", "material", "marketplaceitem", "productrevision")
+
+	s := sqlstore.New(db)
+	u := model.TestUser(t)
+	s.User().Create(u)
+
+	c := model.TestCategory(t)
+	s.Product().CreateCategory(c)
+
+	m := model.TestMaterial(t)
+	s.Product().CreateMaterial(m)
+
+	p := model.TestProduct(t)
+	p.UserID = u.ID
+	p.CategoryID = c.CategoryID
+	p.MaterialID = m.MaterialID
+	s.Product().Create(p, &model.MarketPlaceItemsList{})
+
+	v := model.TestProductVariant(t, p)
+	mpi := &model.MarketPlaceItemsList{}
+	mpi.GetMPIList(v)
+	assert.NoError(t, s.Product().Create(v, mpi))
+
+	variants, err := s.Product().FindVariants(p.ProductID)
+	assert.NoError(t, err)
+	assert.Len(t, variants, 1)
+	assert.Equal(t, p.ProductID, variants[0].ParentProductID)
+	assert.Equal(t, v.VariantAttributes, variants[0].VariantAttributes)
+
+	parent, err := s.Product().GetProductById(p.ProductID)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, parent.ParentProductID)
+	assert.Nil(t, parent.VariantAttributes)
+}
@@ -0,0 +1,121 @@
+package sqlstore
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store"
+)
+
+type RefreshTokenRepo struct {
+	store *Store
+}
+
+func (r *RefreshTokenRepo) Create(t *model.RefreshToken) error {
+	if err := t.Validate(); err != nil {
+		return err
+	}
+
+	if t.CreatedAt.IsZero() {
+		t.CreatedAt = time.Now().UTC()
+	}
+
+	_, err := r.store.db.Exec(
+		`INSERT INTO public.refreshtoken (token_id, user_id, token_hash, session_hash, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)`,
+		t.ID,
+		t.UserID,
+		t.TokenHash,
+		t.SessionHash,
+		t.CreatedAt,
+		t.ExpiresAt,
+	)
+	return err
+}
+
+func (r *RefreshTokenRepo) FindByHash(hash string) (*model.RefreshToken, error) {
+	t := &model.RefreshToken{}
+	revokedAt := sql.NullTime{}
+	if err := r.store.db.QueryRow(
+		`SELECT token_id, user_id, token_hash, session_hash, created_at, expires_at, revoked_at, replaced_by
+		FROM public.refreshtoken WHERE token_hash = $1`,
+		hash,
+	).Scan(
+		&t.ID,
+		&t.UserID,
+		&t.TokenHash,
+		&t.SessionHash,
+		&t.CreatedAt,
+		&t.ExpiresAt,
+		&revokedAt,
+		&t.ReplacedBy,
+	); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, store.ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	if revokedAt.Valid {
+		t.RevokedAt = &revokedAt.Time
+	}
+
+	return t, nil
+}
+
+func (r *RefreshTokenRepo) Revoke(tokenId string, replacedBy string, at time.Time) error {
+	res, err := r.store.db.Exec(
+		"UPDATE public.refreshtoken SET revoked_at = $2, replaced_by = $3 WHERE token_id = $1 AND revoked_at IS NULL",
+		tokenId,
+		at,
+		replacedBy,
+	)
+	if err != nil {
+		return err
+	}
+
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return store.ErrTokenUsed
+	}
+
+	return nil
+}
+
+func (r *RefreshTokenRepo) RevokeBySession(sessionHash string, at time.Time) (int, error) {
+	if sessionHash == "" {
+		return 0, nil
+	}
+
+	return r.revoke("session_hash = $1", sessionHash, at)
+}
+
+func (r *RefreshTokenRepo) RevokeByUserId(userId int, at time.Time) (int, error) {
+	return r.revoke("user_id = $1", userId, at)
+}
+
+func (r *RefreshTokenRepo) DeleteExpired(at time.Time) (int, error) {
+	res, err := r.store.db.Exec("DELETE FROM public.refreshtoken WHERE expires_at <= $1", at)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := res.RowsAffected()
+	return int(n), err
+}
+
+func (r *RefreshTokenRepo) revoke(where string, arg interface{}, at time.Time) (int, error) {
+	res, err := r.store.db.Exec(
+		"UPDATE public.refreshtoken SET revoked_at = $2 WHERE "+where+" AND revoked_at IS NULL",
+		arg,
+		at,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := res.RowsAffected()
+	return int(n), err
+}
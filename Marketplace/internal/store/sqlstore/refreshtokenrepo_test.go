@@ -0,0 +1,49 @@
+package sqlstore_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store/sqlstore"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRefreshTokenRepo(t *testing.T) {
+	db, teardown := sqlstore.TestDB(t, databaseURL)
+	defer teardown("refreshtoken", "users")
+
+	s := sqlstore.New(db)
+	u := model.TestUser(t)
+	assert.NoError(t, s.User().Create(u))
+
+	token := model.TestRefreshToken(t)
+	token.UserID = u.ID
+	token.SessionHash = strings.Repeat("s", 64)
+	assert.NoError(t, s.RefreshToken().Create(token))
+
+	found, err := s.RefreshToken().FindByHash(token.TokenHash)
+	assert.NoError(t, err)
+	assert.Nil(t, found.RevokedAt)
+	assert.Equal(t, token.SessionHash, found.SessionHash)
+
+	at := token.CreatedAt.Add(time.Hour)
+	n, err := s.RefreshToken().RevokeBySession(token.SessionHash, at)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.EqualError(t, s.RefreshToken().Revoke(token.ID, "", at), store.ErrTokenUsed.Error())
+
+	found, err = s.RefreshToken().FindByHash(token.TokenHash)
+	assert.NoError(t, err)
+	assert.NotNil(t, found.RevokedAt)
+	assert.False(t, found.Rotated())
+
+	n, err = s.RefreshToken().DeleteExpired(token.ExpiresAt)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	_, err = s.RefreshToken().FindByHash(token.TokenHash)
+	assert.EqualError(t, err, store.ErrRecordNotFound.Error())
+}
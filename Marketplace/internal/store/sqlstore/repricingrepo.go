@@ -0,0 +1,395 @@
+package sqlstore
+
+import (
+	"database/sql"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store"
+)
+
+const ruleColumns = `rule_id, user_id, rule_name, COALESCE(product_id, 0), COALESCE(marketplace_id, 0), strategy,
+	target_margin_percent, competitor_offset, floor_price, ceiling_price, active, created_at`
+
+const proposalColumns = `proposal_id, user_id, rule_id, product_id, marketplace_id, current_price, proposed_price,
+	reason, status, error, created_at, decided_at`
+
+type RepricingRepo struct {
+	store *Store
+}
+
+func (r *RepricingRepo) CreateRule(rule *model.RepricingRule) error {
+	if err := rule.Validate(); err != nil {
+		return err
+	}
+
+	return r.store.db.QueryRow(
+		`INSERT INTO public.repricingrule (user_id, rule_name, product_id, marketplace_id, strategy,
+		target_margin_percent, competitor_offset, floor_price, ceiling_price, active)
+		VALUES ($1, $2, NULLIF($3, 0), NULLIF($4, 0), $5, $6, $7, $8, $9, $10) RETURNING rule_id, created_at`,
+		rule.UserID,
+		rule.Name,
+		rule.ProductID,
+		rule.MarketPlaceID,
+		rule.Strategy,
+		rule.TargetMarginPercent,
+		rule.CompetitorOffset,
+		rule.FloorPrice,
+		rule.CeilingPrice,
+		rule.Active,
+	).Scan(&rule.RuleID, &rule.CreatedAt)
+}
+
+func (r *RepricingRepo) FindRule(ruleId int) (*model.RepricingRule, error) {
+	rule, err := scanRule(r.store.db.QueryRow(
+		"SELECT "+ruleColumns+" FROM public.repricingrule WHERE rule_id = $1 AND active = true",
+		ruleId,
+	))
+	if err == sql.ErrNoRows {
+		return nil, store.ErrRecordNotFound
+	}
+
+	return rule, err
+}
+
+func (r *RepricingRepo) FindRules(userId int) ([]*model.RepricingRule, error) {
+	return r.findRules(
+		"SELECT "+ruleColumns+" FROM public.repricingrule WHERE user_id = $1 AND active = true ORDER BY rule_id",
+		userId,
+	)
+}
+
+// FindActiveRules returns rules of all sellers for the scheduled repricing run
+func (r *RepricingRepo) FindActiveRules() ([]*model.RepricingRule, error) {
+	return r.findRules(
+		"SELECT " + ruleColumns + " FROM public.repricingrule WHERE active = true ORDER BY user_id, rule_id",
+	)
+}
+
+// DeleteRule deactivates the rule, proposals and log entries keep referencing it
+func (r *RepricingRepo) DeleteRule(ruleId int) error {
+	res, err := r.store.db.Exec("UPDATE public.repricingrule SET active = false WHERE rule_id = $1", ruleId)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return store.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// SaveCompetitorPrices replaces known competitor prices of the products
+func (r *RepricingRepo) SaveCompetitorPrices(prices []*model.CompetitorPrice) error {
+	tx, err := r.store.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	for _, p := range prices {
+		if err = tx.QueryRow(
+			`INSERT INTO public.competitorprice (product_id, marketplace_id, user_id, price)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT (product_id, marketplace_id) DO UPDATE SET price = EXCLUDED.price, imported_at = now()
+			RETURNING imported_at`,
+			p.ProductID,
+			p.MarketPlaceID,
+			p.UserID,
+			p.Price,
+		).Scan(&p.ImportedAt); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *RepricingRepo) FindCompetitorPrices(userId int) ([]*model.CompetitorPrice, error) {
+	prices := make([]*model.CompetitorPrice, 0)
+	rows, err := r.store.db.Query(
+		`SELECT product_id, marketplace_id, user_id, price, imported_at
+		FROM public.competitorprice WHERE user_id = $1 ORDER BY product_id, marketplace_id`,
+		userId,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		p := &model.CompetitorPrice{}
+		if err = rows.Scan(&p.ProductID, &p.MarketPlaceID, &p.UserID, &p.Price, &p.ImportedAt); err != nil {
+			return nil, err
+		}
+
+		prices = append(prices, p)
+	}
+
+	return prices, rows.Err()
+}
+
+// CreateProposals expires pending proposals of the seller and saves the new ones
+func (r *RepricingRepo) CreateProposals(userId int, proposals []*model.PriceProposal) error {
+	tx, err := r.store.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	if _, err = tx.Exec(
+		"UPDATE public.priceproposal SET status = $1, decided_at = now() WHERE user_id = $2 AND status = $3",
+		model.ProposalExpired,
+		userId,
+		model.ProposalPending,
+	); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	for _, p := range proposals {
+		if err = tx.QueryRow(
+			`INSERT INTO public.priceproposal (user_id, rule_id, product_id, marketplace_id, current_price,
+			proposed_price, reason, status) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			RETURNING proposal_id, created_at`,
+			p.UserID,
+			p.RuleID,
+			p.ProductID,
+			p.MarketPlaceID,
+			p.CurrentPrice,
+			p.ProposedPrice,
+			p.Reason,
+			p.Status,
+		).Scan(&p.ProposalID, &p.CreatedAt); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *RepricingRepo) FindProposal(proposalId int) (*model.PriceProposal, error) {
+	p, err := scanProposal(r.store.db.QueryRow(
+		"SELECT "+proposalColumns+" FROM public.priceproposal WHERE proposal_id = $1",
+		proposalId,
+	))
+	if err == sql.ErrNoRows {
+		return nil, store.ErrRecordNotFound
+	}
+
+	return p, err
+}
+
+// FindProposals returns proposals of the seller with the status, all proposals when the status is empty
+func (r *RepricingRepo) FindProposals(userId int, status string) ([]*model.PriceProposal, error) {
+	proposals := make([]*model.PriceProposal, 0)
+	rows, err := r.store.db.Query(
+		"SELECT "+proposalColumns+` FROM public.priceproposal
+		WHERE user_id = $1 AND ($2 = '' OR status = $2) ORDER BY proposal_id`,
+		userId,
+		status,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		p, err := scanProposal(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		proposals = append(proposals, p)
+	}
+
+	return proposals, rows.Err()
+}
+
+func (r *RepricingRepo) UpdateProposal(p *model.PriceProposal) error {
+	_, err := r.store.db.Exec(
+		"UPDATE public.priceproposal SET status = $1, error = $2, decided_at = $3 WHERE proposal_id = $4",
+		p.Status,
+		p.Error,
+		p.DecidedAt,
+		p.ProposalID,
+	)
+
+	return err
+}
+
+func (r *RepricingRepo) ApplyProposal(p *model.PriceProposal, price *model.Price, e *model.RepricingLogEntry) error {
+	if err := price.Validate(); err != nil {
+		return err
+	}
+
+	tx, err := r.store.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	// the status changes only from approved, so a proposal applied by a concurrent request is not applied twice
+	res, err := tx.Exec(
+		"UPDATE public.priceproposal SET status = $1, error = $2, decided_at = $3 WHERE proposal_id = $4 AND status = $5",
+		p.Status,
+		p.Error,
+		p.DecidedAt,
+		p.ProposalID,
+		model.ProposalApproved,
+	)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err = checkAffected(res); err != nil {
+		tx.Rollback()
+		if err != store.ErrRecordNotFound {
+			return err
+		}
+		if _, err = r.FindProposal(p.ProposalID); err != nil {
+			return err
+		}
+		return store.ErrVersionConflict
+	}
+
+	if err = insertPrice(tx, price); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	e.PriceID = price.PriceID
+	if err = createLogEntry(tx, e); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func createLogEntry(q queryer, e *model.RepricingLogEntry) error {
+	return q.QueryRow(
+		`INSERT INTO public.repricinglog (user_id, proposal_id, rule_id, product_id, marketplace_id, price_id,
+		old_price, new_price) VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING log_id, created_at`,
+		e.UserID,
+		e.ProposalID,
+		e.RuleID,
+		e.ProductID,
+		e.MarketPlaceID,
+		e.PriceID,
+		e.OldPrice,
+		e.NewPrice,
+	).Scan(&e.LogID, &e.CreatedAt)
+}
+
+func (r *RepricingRepo) FindLog(userId int) ([]*model.RepricingLogEntry, error) {
+	entries := make([]*model.RepricingLogEntry, 0)
+	rows, err := r.store.db.Query(
+		`SELECT log_id, user_id, proposal_id, rule_id, product_id, marketplace_id, price_id, old_price, new_price,
+		created_at FROM public.repricinglog WHERE user_id = $1 ORDER BY log_id DESC`,
+		userId,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		e := &model.RepricingLogEntry{}
+		if err = rows.Scan(
+			&e.LogID,
+			&e.UserID,
+			&e.ProposalID,
+			&e.RuleID,
+			&e.ProductID,
+			&e.MarketPlaceID,
+			&e.PriceID,
+			&e.OldPrice,
+			&e.NewPrice,
+			&e.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, e)
+	}
+
+	return entries, rows.Err()
+}
+
+func (r *RepricingRepo) findRules(query string, args ...interface{}) ([]*model.RepricingRule, error) {
+	rules := make([]*model.RepricingRule, 0)
+	rows, err := r.store.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		rule, err := scanRule(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		rules = append(rules, rule)
+	}
+
+	return rules, rows.Err()
+}
+
+func scanRule(row rowScanner) (*model.RepricingRule, error) {
+	rule := &model.RepricingRule{}
+	if err := row.Scan(
+		&rule.RuleID,
+		&rule.UserID,
+		&rule.Name,
+		&rule.ProductID,
+		&rule.MarketPlaceID,
+		&rule.Strategy,
+		&rule.TargetMarginPercent,
+		&rule.CompetitorOffset,
+		&rule.FloorPrice,
+		&rule.CeilingPrice,
+		&rule.Active,
+		&rule.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	return rule, nil
+}
+
+func scanProposal(row rowScanner) (*model.PriceProposal, error) {
+	p := &model.PriceProposal{}
+	var decidedAt sql.NullTime
+	if err := row.Scan(
+		&p.ProposalID,
+		&p.UserID,
+		&p.RuleID,
+		&p.ProductID,
+		&p.MarketPlaceID,
+		&p.CurrentPrice,
+		&p.ProposedPrice,
+		&p.Reason,
+		&p.Status,
+		&p.Error,
+		&p.CreatedAt,
+		&decidedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	if decidedAt.Valid {
+		p.DecidedAt = &decidedAt.Time
+	}
+
+	return p, nil
+}
@@ -0,0 +1,126 @@
+package sqlstore_test
+
+import (
+	"testing"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store/sqlstore"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepricingRepo_Rules(t *testing.T) {
+	db, teardown := sqlstore.TestDB(t, databaseURL)
+	defer teardown("repricingrule", "product", "users", "category", "material", "marketplaceitem", "productrevision")
+
+	s := sqlstore.New(db)
+	p := testImageProduct(t, s)
+
+	r := model.TestRepricingRule(t)
+	r.UserID = p.UserID
+	assert.NoError(t, s.Repricing().CreateRule(r))
+
+	productRule := model.TestRepricingRule(t)
+	productRule.UserID = p.UserID
+	productRule.ProductID = p.ProductID
+	productRule.MarketPlaceID = model.MarketPlaceOzon
+	assert.NoError(t, s.Repricing().CreateRule(productRule))
+
+	found, err := s.Repricing().FindRule(productRule.RuleID)
+	assert.NoError(t, err)
+	assert.Equal(t, p.ProductID, found.ProductID)
+
+	rules, err := s.Repricing().FindRules(p.UserID)
+	assert.NoError(t, err)
+	if assert.Len(t, rules, 2) {
+		assert.Zero(t, rules[0].ProductID)
+	}
+
+	assert.NoError(t, s.Repricing().DeleteRule(r.RuleID))
+	_, err = s.Repricing().FindRule(r.RuleID)
+	assert.EqualError(t, err, store.ErrRecordNotFound.Error())
+}
+
+func TestRepricingRepo_Proposals(t *testing.T) {
+	db, teardown := sqlstore.TestDB(t, databaseURL)
+	defer teardown("repricinglog", "priceproposal", "competitorprice", "repricingrule", "productprice", "product", "users", "category", "material", "marketplaceitem", "productrevision")
+
+	s := sqlstore.New(db)
+	p := testImageProduct(t, s)
+
+	r := model.TestRepricingRule(t)
+	r.UserID = p.UserID
+	s.Repricing().CreateRule(r)
+
+	competitor := &model.CompetitorPrice{ProductID: p.ProductID, MarketPlaceID: model.MarketPlaceOzon, UserID: p.UserID, Price: 950}
+	assert.NoError(t, s.Repricing().SaveCompetitorPrices([]*model.CompetitorPrice{competitor}))
+	competitor.Price = 930
+	assert.NoError(t, s.Repricing().SaveCompetitorPrices([]*model.CompetitorPrice{competitor}))
+	competitors, err := s.Repricing().FindCompetitorPrices(p.UserID)
+	assert.NoError(t, err)
+	if assert.Len(t, competitors, 1) {
+		assert.Equal(t, 930.0, competitors[0].Price)
+	}
+
+	proposal := &model.PriceProposal{
+		UserID:        p.UserID,
+		RuleID:        r.RuleID,
+		ProductID:     p.ProductID,
+		MarketPlaceID: model.MarketPlaceOzon,
+		CurrentPrice:  1000,
+		ProposedPrice: 920,
+		Status:        model.ProposalPending,
+	}
+	assert.NoError(t, s.Repricing().CreateProposals(p.UserID, []*model.PriceProposal{proposal}))
+	assert.NotZero(t, proposal.ProposalID)
+
+	proposal.Status = model.ProposalApplied
+	assert.NoError(t, s.Repricing().UpdateProposal(proposal))
+
+	found, err := s.Repricing().FindProposal(proposal.ProposalID)
+	assert.NoError(t, err)
+	assert.Equal(t, model.ProposalApplied, found.Status)
+
+	// the price, the proposal status and the log entry are saved together
+	approved := &model.PriceProposal{
+		UserID:        p.UserID,
+		RuleID:        r.RuleID,
+		ProductID:     p.ProductID,
+		MarketPlaceID: model.MarketPlaceOzon,
+		CurrentPrice:  1000,
+		ProposedPrice: 950,
+		Status:        model.ProposalApproved,
+	}
+	assert.NoError(t, s.Repricing().CreateProposals(p.UserID, []*model.PriceProposal{approved}))
+
+	approved.Status = model.ProposalApplied
+	newPrice := model.TestPrice(t)
+	newPrice.ProductID = p.ProductID
+	newPrice.UserID = p.UserID
+	newPrice.DiscountPrice = 950
+	applied := &model.RepricingLogEntry{
+		UserID:        p.UserID,
+		ProposalID:    approved.ProposalID,
+		RuleID:        r.RuleID,
+		ProductID:     p.ProductID,
+		MarketPlaceID: model.MarketPlaceOzon,
+		OldPrice:      1000,
+		NewPrice:      950,
+	}
+	assert.NoError(t, s.Repricing().ApplyProposal(approved, newPrice, applied))
+	assert.Equal(t, newPrice.PriceID, applied.PriceID)
+
+	found, err = s.Repricing().FindProposal(approved.ProposalID)
+	assert.NoError(t, err)
+	assert.Equal(t, model.ProposalApplied, found.Status)
+
+	entries, err := s.Repricing().FindLog(p.UserID)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	// a proposal is applied once
+	assert.EqualError(t, s.Repricing().ApplyProposal(approved, newPrice, applied), store.ErrVersionConflict.Error())
+	entries, err = s.Repricing().FindLog(p.UserID)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
@@ -0,0 +1,152 @@
+package sqlstore
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store"
+)
+
+type SessionRepo struct {
+	store *Store
+}
+
+func (r *SessionRepo) Create(s *model.Session) error {
+	if err := s.Validate(); err != nil {
+		return err
+	}
+
+	if s.CreatedAt.IsZero() {
+		s.CreatedAt = time.Now().UTC()
+	}
+	if s.LastSeenAt.IsZero() {
+		s.LastSeenAt = s.CreatedAt
+	}
+
+	_, err := r.store.db.Exec(
+		`INSERT INTO public.session (session_id, user_id, created_at, expires_at, last_seen_at, user_agent, ip)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		s.ID,
+		s.UserID,
+		s.CreatedAt,
+		s.ExpiresAt,
+		s.LastSeenAt,
+		s.UserAgent,
+		s.IP,
+	)
+	return err
+}
+
+func (r *SessionRepo) Find(sessionId string) (*model.Session, error) {
+	s, err := scanSession(r.store.db.QueryRow(
+		`SELECT session_id, user_id, created_at, expires_at, last_seen_at, user_agent, ip
+		FROM public.session WHERE session_id = $1`,
+		sessionId,
+	))
+	if err == sql.ErrNoRows {
+		return nil, store.ErrRecordNotFound
+	}
+
+	return s, err
+}
+
+func (r *SessionRepo) FindByUserId(userId int) ([]*model.Session, error) {
+	sessions := make([]*model.Session, 0)
+	rows, err := r.store.db.Query(
+		`SELECT session_id, user_id, created_at, expires_at, last_seen_at, user_agent, ip
+		FROM public.session WHERE user_id = $1 ORDER BY last_seen_at DESC`,
+		userId,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		s, err := scanSession(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		sessions = append(sessions, s)
+	}
+
+	return sessions, rows.Err()
+}
+
+func (r *SessionRepo) Touch(s *model.Session) error {
+	if err := s.Validate(); err != nil {
+		return err
+	}
+
+	res, err := r.store.db.Exec(
+		"UPDATE public.session SET last_seen_at = $2, user_agent = $3, ip = $4 WHERE session_id = $1",
+		s.ID,
+		s.LastSeenAt,
+		s.UserAgent,
+		s.IP,
+	)
+	if err != nil {
+		return err
+	}
+
+	return checkAffected(res)
+}
+
+func (r *SessionRepo) Delete(sessionId string) error {
+	res, err := r.store.db.Exec("DELETE FROM public.session WHERE session_id = $1", sessionId)
+	if err != nil {
+		return err
+	}
+
+	return checkAffected(res)
+}
+
+func (r *SessionRepo) DeleteByUserId(userId int) (int, error) {
+	res, err := r.store.db.Exec("DELETE FROM public.session WHERE user_id = $1", userId)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := res.RowsAffected()
+	return int(n), err
+}
+
+func (r *SessionRepo) DeleteExpired(at time.Time) (int, error) {
+	res, err := r.store.db.Exec("DELETE FROM public.session WHERE expires_at <= $1", at)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := res.RowsAffected()
+	return int(n), err
+}
+
+func scanSession(row rowScanner) (*model.Session, error) {
+	s := &model.Session{}
+	if err := row.Scan(
+		&s.ID,
+		&s.UserID,
+		&s.CreatedAt,
+		&s.ExpiresAt,
+		&s.LastSeenAt,
+		&s.UserAgent,
+		&s.IP,
+	); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func checkAffected(res sql.Result) error {
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return store.ErrRecordNotFound
+	}
+
+	return nil
+}
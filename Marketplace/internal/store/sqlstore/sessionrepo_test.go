@@ -0,0 +1,80 @@
+package sqlstore_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store/sqlstore"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSessionRepo(t *testing.T) {
+	db, teardown := sqlstore.TestDB(t, databaseURL)
+	defer teardown("session", "users")
+
+	s := sqlstore.New(db)
+	u := model.TestUser(t)
+	assert.NoError(t, s.User().Create(u))
+
+	expired := model.TestSession(t)
+	expired.UserID = u.ID
+	active := model.TestSession(t)
+	active.ID = "active"
+	active.UserID = u.ID
+	active.ExpiresAt = expired.ExpiresAt.Add(time.Hour)
+	assert.NoError(t, s.Session().Create(expired))
+	assert.NoError(t, s.Session().Create(active))
+
+	found, err := s.Session().Find(active.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, u.ID, found.UserID)
+	assert.True(t, found.ExpiresAt.Equal(active.ExpiresAt))
+
+	n, err := s.Session().DeleteExpired(expired.ExpiresAt)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	_, err = s.Session().Find(expired.ID)
+	assert.EqualError(t, err, store.ErrRecordNotFound.Error())
+
+	active.LastSeenAt = active.CreatedAt.Add(time.Hour)
+	active.UserAgent = "Mozilla/5.0"
+	active.IP = "192.0.2.1"
+	assert.NoError(t, s.Session().Touch(active))
+
+	sessions, err := s.Session().FindByUserId(u.ID)
+	assert.NoError(t, err)
+	if assert.Len(t, sessions, 1) {
+		assert.Equal(t, active.UserAgent, sessions[0].UserAgent)
+		assert.Equal(t, active.IP, sessions[0].IP)
+	}
+
+	assert.NoError(t, s.Session().Delete(active.ID))
+	assert.EqualError(t, s.Session().Delete(active.ID), store.ErrRecordNotFound.Error())
+}
+
+func TestSessionRepo_DeleteByUserId(t *testing.T) {
+	db, teardown := sqlstore.TestDB(t, databaseURL)
+	defer teardown("session", "users")
+
+	s := sqlstore.New(db)
+	u := model.TestUser(t)
+	assert.NoError(t, s.User().Create(u))
+
+	for _, id := range []string{"first", "second"} {
+		session := model.TestSession(t)
+		session.ID = id
+		session.UserID = u.ID
+		assert.NoError(t, s.Session().Create(session))
+	}
+
+	n, err := s.Session().DeleteByUserId(u.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	sessions, err := s.Session().FindByUserId(u.ID)
+	assert.NoError(t, err)
+	assert.Empty(t, sessions)
+}
@@ -0,0 +1,241 @@
+package sqlstore
+
+import (
+	"database/sql"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store"
+)
+
+type ShipmentRepo struct {
+	store *Store
+}
+
+func (r *ShipmentRepo) Create(s *model.Shipment) error {
+	if err := s.Validate(); err != nil {
+		return err
+	}
+
+	tx, err := r.store.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	s.Status = model.ShipmentDraft
+	if err = tx.QueryRow(
+		`INSERT INTO public.shipment (user_id, warehouse_id, marketplace_id, planned_date, status)
+		VALUES ($1, $2, $3, $4, $5) RETURNING shipment_id, created_at, updated_at`,
+		s.UserID,
+		s.WarehouseID,
+		s.MarketPlaceID,
+		s.PlannedDate,
+		s.Status,
+	).Scan(&s.ShipmentID, &s.CreatedAt, &s.UpdatedAt); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err = saveBoxes(tx, s); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *ShipmentRepo) Find(shipmentId int) (*model.Shipment, error) {
+	s, err := scanShipment(r.store.db.QueryRow(
+		`SELECT shipment_id, user_id, warehouse_id, marketplace_id, planned_date, status, created_at, updated_at
+		FROM public.shipment WHERE shipment_id = $1`,
+		shipmentId,
+	))
+	if err == sql.ErrNoRows {
+		return nil, store.ErrRecordNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	if s.Boxes, err = r.findBoxes(s.ShipmentID); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// FindByUserId returns shipments of the seller in the status or all of them when the status is empty
+func (r *ShipmentRepo) FindByUserId(userId int, status string) ([]*model.Shipment, error) {
+	shipments := make([]*model.Shipment, 0)
+	rows, err := r.store.db.Query(
+		`SELECT shipment_id, user_id, warehouse_id, marketplace_id, planned_date, status, created_at, updated_at
+		FROM public.shipment WHERE user_id = $1 AND ($2 = '' OR status = $2) ORDER BY planned_date, shipment_id`,
+		userId,
+		status,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		s, err := scanShipment(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		shipments = append(shipments, s)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, s := range shipments {
+		if s.Boxes, err = r.findBoxes(s.ShipmentID); err != nil {
+			return nil, err
+		}
+	}
+
+	return shipments, nil
+}
+
+// Update saves the destination and the planned date and replaces the boxes of the shipment in the draft status
+func (r *ShipmentRepo) Update(s *model.Shipment) error {
+	if err := s.Validate(); err != nil {
+		return err
+	}
+
+	tx, err := r.store.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	if err = tx.QueryRow(
+		`UPDATE public.shipment SET warehouse_id = $1, marketplace_id = $2, planned_date = $3, updated_at = now()
+		WHERE shipment_id = $4 AND status = $5 RETURNING updated_at`,
+		s.WarehouseID,
+		s.MarketPlaceID,
+		s.PlannedDate,
+		s.ShipmentID,
+		model.ShipmentDraft,
+	).Scan(&s.UpdatedAt); err != nil {
+		tx.Rollback()
+		if err == sql.ErrNoRows {
+			return store.ErrVersionConflict
+		}
+		return err
+	}
+
+	if _, err = tx.Exec("DELETE FROM public.shipmentbox WHERE shipment_id = $1", s.ShipmentID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err = saveBoxes(tx, s); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// SetStatus changes the status of the shipment unless someone else changed it since it was read
+func (r *ShipmentRepo) SetStatus(s *model.Shipment, status string) error {
+	if err := r.store.db.QueryRow(
+		`UPDATE public.shipment SET status = $1, updated_at = now()
+		WHERE shipment_id = $2 AND status = $3 RETURNING updated_at`,
+		status,
+		s.ShipmentID,
+		s.Status,
+	).Scan(&s.UpdatedAt); err != nil {
+		if err == sql.ErrNoRows {
+			return store.ErrVersionConflict
+		}
+		return err
+	}
+
+	s.Status = status
+	return nil
+}
+
+func (r *ShipmentRepo) findBoxes(shipmentId int) ([]*model.ShipmentBox, error) {
+	rows, err := r.store.db.Query(
+		`SELECT b.box_number, b.pallet_number, b.length, b.width, b.height, i.product_id, i.quantity
+		FROM public.shipmentbox b
+		JOIN public.shipmentboxitem i ON i.shipment_id = b.shipment_id AND i.box_number = b.box_number
+		WHERE b.shipment_id = $1 ORDER BY b.box_number, i.product_id`,
+		shipmentId,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	boxes := make([]*model.ShipmentBox, 0)
+	for rows.Next() {
+		b := &model.ShipmentBox{}
+		item := &model.ShipmentItem{}
+		if err = rows.Scan(&b.BoxNumber, &b.PalletNumber, &b.Length, &b.Width, &b.Height, &item.ProductID, &item.Quantity); err != nil {
+			return nil, err
+		}
+
+		if n := len(boxes); n > 0 && boxes[n-1].BoxNumber == b.BoxNumber {
+			b = boxes[n-1]
+		} else {
+			boxes = append(boxes, b)
+		}
+		b.Items = append(b.Items, item)
+	}
+
+	return boxes, rows.Err()
+}
+
+func saveBoxes(tx *sql.Tx, s *model.Shipment) error {
+	for _, b := range s.Boxes {
+		if _, err := tx.Exec(
+			`INSERT INTO public.shipmentbox (shipment_id, box_number, pallet_number, length, width, height)
+			VALUES ($1, $2, $3, $4, $5, $6)`,
+			s.ShipmentID,
+			b.BoxNumber,
+			b.PalletNumber,
+			b.Length,
+			b.Width,
+			b.Height,
+		); err != nil {
+			return err
+		}
+
+		for _, item := range b.Items {
+			if _, err := tx.Exec(
+				"INSERT INTO public.shipmentboxitem (shipment_id, box_number, product_id, quantity) VALUES ($1, $2, $3, $4)",
+				s.ShipmentID,
+				b.BoxNumber,
+				item.ProductID,
+				item.Quantity,
+			); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func scanShipment(row rowScanner) (*model.Shipment, error) {
+	s := &model.Shipment{}
+	if err := row.Scan(
+		&s.ShipmentID,
+		&s.UserID,
+		&s.WarehouseID,
+		&s.MarketPlaceID,
+		&s.PlannedDate,
+		&s.Status,
+		&s.CreatedAt,
+		&s.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
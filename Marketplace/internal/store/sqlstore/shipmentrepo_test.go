@@ -0,0 +1,61 @@
+package sqlstore_test
+
+import (
+	"testing"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store/sqlstore"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShipmentRepo_Update(t *testing.T) {
+	db, teardown := sqlstore.TestDB(t, databaseURL)
+	defer teardown("shipmentboxitem", "shipmentbox", "shipment", "warehouse", "product", "users", "category", "material", "marketplaceitem", "productrevision")
+
+	s := sqlstore.New(db)
+	p := testImageProduct(t, s)
+
+	w := model.TestWarehouse(t)
+	w.UserID = p.UserID
+	w.Type = model.WarehouseTypeMarketPlace
+	w.MarketPlaceID = model.MarketPlaceOzon
+	assert.NoError(t, s.Warehouse().Create(w))
+
+	sh := model.TestShipment(t)
+	sh.UserID = p.UserID
+	sh.WarehouseID = w.WarehouseID
+	sh.Boxes[0].Items[0].ProductID = p.ProductID
+	assert.NoError(t, s.Shipment().Create(sh))
+
+	other := model.TestWarehouse(t)
+	other.UserID = p.UserID
+	other.Name = "Склад Коледино"
+	other.Type = model.WarehouseTypeMarketPlace
+	other.MarketPlaceID = model.MarketPlaceWildberries
+	assert.NoError(t, s.Warehouse().Create(other))
+
+	sh.WarehouseID = other.WarehouseID
+	sh.MarketPlaceID = other.MarketPlaceID
+	sh.Boxes = append(sh.Boxes, &model.ShipmentBox{
+		BoxNumber: 2,
+		Length:    400,
+		Width:     300,
+		Height:    200,
+		Items:     []*model.ShipmentItem{{ProductID: p.ProductID, Quantity: 5}},
+	})
+	assert.NoError(t, s.Shipment().Update(sh))
+
+	found, err := s.Shipment().Find(sh.ShipmentID)
+	assert.NoError(t, err)
+	assert.Equal(t, other.WarehouseID, found.WarehouseID)
+	assert.Equal(t, model.MarketPlaceWildberries, found.MarketPlaceID)
+	if assert.Len(t, found.Boxes, 2) {
+		assert.Equal(t, 1, found.Boxes[0].PalletNumber)
+		assert.Equal(t, 5, found.Boxes[1].Items[0].Quantity)
+	}
+
+	assert.NoError(t, s.Shipment().SetStatus(found, model.ShipmentReady))
+	assert.EqualError(t, s.Shipment().SetStatus(sh, model.ShipmentCancelled), store.ErrVersionConflict.Error())
+	assert.EqualError(t, s.Shipment().Update(sh), store.ErrVersionConflict.Error())
+}
@@ -0,0 +1,135 @@
+package sqlstore
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store"
+)
+
+type AttemptCounterRepo struct {
+	store *Store
+}
+
+type SignInFailureRepo struct {
+	store *Store
+}
+
+func (r *AttemptCounterRepo) Find(key string) (*model.AttemptCounter, error) {
+	c := &model.AttemptCounter{}
+	if err := r.store.db.QueryRow(
+		"SELECT counter_key, failures, last_failure_at FROM public.attemptcounter WHERE counter_key = $1",
+		key,
+	).Scan(
+		&c.Key,
+		&c.Failures,
+		&c.LastFailureAt,
+	); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, store.ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// Fail counts the failure in one statement, so concurrent attempts are all counted
+func (r *AttemptCounterRepo) Fail(key string, at time.Time, since time.Time) (*model.AttemptCounter, error) {
+	c := &model.AttemptCounter{}
+	if err := r.store.db.QueryRow(
+		`INSERT INTO public.attemptcounter AS c (counter_key, failures, last_failure_at) VALUES ($1, 1, $2)
+		ON CONFLICT (counter_key) DO UPDATE SET
+			failures = CASE WHEN c.last_failure_at < $3 THEN 1 ELSE c.failures + 1 END,
+			last_failure_at = $2
+		RETURNING counter_key, failures, last_failure_at`,
+		key,
+		at,
+		since,
+	).Scan(
+		&c.Key,
+		&c.Failures,
+		&c.LastFailureAt,
+	); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+func (r *AttemptCounterRepo) Reset(key string) error {
+	_, err := r.store.db.Exec("DELETE FROM public.attemptcounter WHERE counter_key = $1", key)
+	return err
+}
+
+func (r *AttemptCounterRepo) DeleteExpired(since time.Time) (int, error) {
+	res, err := r.store.db.Exec("DELETE FROM public.attemptcounter WHERE last_failure_at < $1", since)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := res.RowsAffected()
+	return int(n), err
+}
+
+func (r *SignInFailureRepo) Create(f *model.SignInFailure) error {
+	if f.CreatedAt.IsZero() {
+		f.CreatedAt = time.Now().UTC()
+	}
+
+	return r.store.db.QueryRow(
+		`INSERT INTO public.signinfailure (user_id, email, ip, user_agent, reason, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6) RETURNING failure_id`,
+		f.UserID,
+		f.Email,
+		f.IP,
+		f.UserAgent,
+		f.Reason,
+		f.CreatedAt,
+	).Scan(&f.ID)
+}
+
+func (r *SignInFailureRepo) Find(email string, ip string, limit int) ([]*model.SignInFailure, error) {
+	rows, err := r.store.db.Query(
+		`SELECT failure_id, user_id, email, ip, user_agent, reason, created_at FROM public.signinfailure
+		WHERE ($1 = '' OR email = $1) AND ($2 = '' OR ip = $2)
+		ORDER BY created_at DESC, failure_id DESC LIMIT $3`,
+		email,
+		ip,
+		limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	failures := make([]*model.SignInFailure, 0)
+	for rows.Next() {
+		f := &model.SignInFailure{}
+		if err = rows.Scan(
+			&f.ID,
+			&f.UserID,
+			&f.Email,
+			&f.IP,
+			&f.UserAgent,
+			&f.Reason,
+			&f.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		failures = append(failures, f)
+	}
+
+	return failures, rows.Err()
+}
+
+func (r *SignInFailureRepo) DeleteBefore(before time.Time) (int, error) {
+	res, err := r.store.db.Exec("DELETE FROM public.signinfailure WHERE created_at < $1", before)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := res.RowsAffected()
+	return int(n), err
+}
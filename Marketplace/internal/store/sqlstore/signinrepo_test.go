@@ -0,0 +1,60 @@
+package sqlstore_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store/sqlstore"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAttemptCounterRepo(t *testing.T) {
+	db, teardown := sqlstore.TestDB(t, databaseURL)
+	defer teardown("attemptcounter")
+
+	s := sqlstore.New(db)
+	at := time.Date(2022, 12, 8, 10, 0, 0, 0, time.UTC)
+
+	for i := 0; i < 3; i++ {
+		_, err := s.AttemptCounter().Fail("account:ex@test.org", at, at.Add(-time.Hour))
+		assert.NoError(t, err)
+	}
+	c, err := s.AttemptCounter().Find("account:ex@test.org")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, c.Failures)
+
+	c, err = s.AttemptCounter().Fail("account:ex@test.org", at.Add(2*time.Hour), at.Add(time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, c.Failures)
+
+	assert.NoError(t, s.AttemptCounter().Reset("account:ex@test.org"))
+	_, err = s.AttemptCounter().Find("account:ex@test.org")
+	assert.EqualError(t, err, store.ErrRecordNotFound.Error())
+}
+
+func TestSignInFailureRepo(t *testing.T) {
+	db, teardown := sqlstore.TestDB(t, databaseURL)
+	defer teardown("signinfailure")
+
+	s := sqlstore.New(db)
+	at := time.Date(2022, 12, 8, 10, 0, 0, 0, time.UTC)
+
+	for i, email := range []string{"ex@test.org", "other@test.org", "ex@test.org"} {
+		assert.NoError(t, s.SignInFailure().Create(&model.SignInFailure{
+			Email:     email,
+			IP:        "192.0.2.1",
+			Reason:    model.SignInFailurePassword,
+			CreatedAt: at.Add(time.Duration(i) * time.Minute),
+		}))
+	}
+
+	failures, err := s.SignInFailure().Find("ex@test.org", "", 10)
+	assert.NoError(t, err)
+	assert.Len(t, failures, 2)
+
+	n, err := s.SignInFailure().DeleteBefore(at.Add(time.Minute))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+}
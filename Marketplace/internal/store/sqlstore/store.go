@@ -7,11 +7,34 @@ import (
 	_ "github.com/lib/pq"
 )
 
+// queryer runs statements on the database or in a transaction
+type queryer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
 // Store
 type Store struct {
-	db          *sql.DB
-	userRepo    *UserRepo
-	productRepo *ProductRepo
+	db            *sql.DB
+	userRepo      *UserRepo
+	productRepo   *ProductRepo
+	imageRepo     *ImageRepo
+	attributeRepo *AttributeRepo
+	barcodeRepo   *BarcodeRepo
+	priceRepo     *PriceRepo
+	repricingRepo *RepricingRepo
+	inventoryRepo *InventoryRepo
+	supplyRepo    *SupplyRepo
+	warehouseRepo *WarehouseRepo
+	transferRepo  *TransferRepo
+	shipmentRepo  *ShipmentRepo
+	sessionRepo   *SessionRepo
+	tokenRepo     *RefreshTokenRepo
+	apiKeyRepo    *APIKeyRepo
+	userTokenRepo *UserTokenRepo
+	counterRepo   *AttemptCounterRepo
+	failureRepo   *SignInFailureRepo
+	twoFactorRepo *TwoFactorRepo
 }
 
 // Store constructor
@@ -42,3 +65,190 @@ func (s *Store) Product() store.ProductRepo {
 	}
 	return s.productRepo
 }
+
+func (s *Store) Image() store.ImageRepo {
+	if s.imageRepo != nil {
+		return s.imageRepo
+	}
+
+	s.imageRepo = &ImageRepo{
+		store: s,
+	}
+	return s.imageRepo
+}
+
+func (s *Store) Attribute() store.AttributeRepo {
+	if s.attributeRepo != nil {
+		return s.attributeRepo
+	}
+
+	s.attributeRepo = &AttributeRepo{
+		store: s,
+	}
+	return s.attributeRepo
+}
+
+func (s *Store) Barcode() store.BarcodeRepo {
+	if s.barcodeRepo != nil {
+		return s.barcodeRepo
+	}
+
+	s.barcodeRepo = &BarcodeRepo{
+		store: s,
+	}
+	return s.barcodeRepo
+}
+
+func (s *Store) Price() store.PriceRepo {
+	if s.priceRepo != nil {
+		return s.priceRepo
+	}
+
+	s.priceRepo = &PriceRepo{
+		store: s,
+	}
+	return s.priceRepo
+}
+
+func (s *Store) Repricing() store.RepricingRepo {
+	if s.repricingRepo != nil {
+		return s.repricingRepo
+	}
+
+	s.repricingRepo = &RepricingRepo{
+		store: s,
+	}
+	return s.repricingRepo
+}
+
+func (s *Store) Inventory() store.InventoryRepo {
+	if s.inventoryRepo != nil {
+		return s.inventoryRepo
+	}
+
+	s.inventoryRepo = &InventoryRepo{
+		store: s,
+	}
+	return s.inventoryRepo
+}
+
+func (s *Store) Supply() store.SupplyRepo {
+	if s.supplyRepo != nil {
+		return s.supplyRepo
+	}
+
+	s.supplyRepo = &SupplyRepo{
+		store: s,
+	}
+	return s.supplyRepo
+}
+
+func (s *Store) Warehouse() store.WarehouseRepo {
+	if s.warehouseRepo != nil {
+		return s.warehouseRepo
+	}
+
+	s.warehouseRepo = &WarehouseRepo{
+		store: s,
+	}
+	return s.warehouseRepo
+}
+
+func (s *Store) Transfer() store.TransferRepo {
+	if s.transferRepo != nil {
+		return s.transferRepo
+	}
+
+	s.transferRepo = &TransferRepo{
+		store: s,
+	}
+	return s.transferRepo
+}
+
+func (s *Store) Shipment() store.ShipmentRepo {
+	if s.shipmentRepo != nil {
+		return s.shipmentRepo
+	}
+
+	s.shipmentRepo = &ShipmentRepo{
+		store: s,
+	}
+	return s.shipmentRepo
+}
+
+func (s *Store) Session() store.SessionRepo {
+	if s.sessionRepo != nil {
+		return s.sessionRepo
+	}
+
+	s.sessionRepo = &SessionRepo{
+		store: s,
+	}
+	return s.sessionRepo
+}
+
+func (s *Store) RefreshToken() store.RefreshTokenRepo {
+	if s.tokenRepo != nil {
+		return s.tokenRepo
+	}
+
+	s.tokenRepo = &RefreshTokenRepo{
+		store: s,
+	}
+	return s.tokenRepo
+}
+
+func (s *Store) APIKey() store.APIKeyRepo {
+	if s.apiKeyRepo != nil {
+		return s.apiKeyRepo
+	}
+
+	s.apiKeyRepo = &APIKeyRepo{
+		store: s,
+	}
+	return s.apiKeyRepo
+}
+
+func (s *Store) UserToken() store.UserTokenRepo {
+	if s.userTokenRepo != nil {
+		return s.userTokenRepo
+	}
+
+	s.userTokenRepo = &UserTokenRepo{
+		store: s,
+	}
+	return s.userTokenRepo
+}
+
+func (s *Store) AttemptCounter() store.AttemptCounterRepo {
+	if s.counterRepo != nil {
+		return s.counterRepo
+	}
+
+	s.counterRepo = &AttemptCounterRepo{
+		store: s,
+	}
+	return s.counterRepo
+}
+
+func (s *Store) SignInFailure() store.SignInFailureRepo {
+	if s.failureRepo != nil {
+		return s.failureRepo
+	}
+
+	s.failureRepo = &SignInFailureRepo{
+		store: s,
+	}
+	return s.failureRepo
+}
+
+func (s *Store) TwoFactor() store.TwoFactorRepo {
+	if s.twoFactorRepo != nil {
+		return s.twoFactorRepo
+	}
+
+	s.twoFactorRepo = &TwoFactorRepo{
+		store: s,
+	}
+	return s.twoFactorRepo
+}
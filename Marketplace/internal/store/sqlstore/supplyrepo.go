@@ -0,0 +1,197 @@
+package sqlstore
+
+import (
+	"database/sql"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store"
+)
+
+type SupplyRepo struct {
+	store *Store
+}
+
+func (r *SupplyRepo) CreateSupplier(s *model.Supplier) error {
+	if err := s.Validate(); err != nil {
+		return err
+	}
+
+	s.Active = true
+	return r.store.db.QueryRow(
+		`INSERT INTO public.supplier (supplier_name, supplier_address, supplier_country_id, supplier_swift,
+		supplier_account_number, user_id, lead_time_days, active)
+		VALUES ($1, $2, NULLIF($3, 0), $4, $5, $6, $7, $8) RETURNING supplier_id`,
+		s.SupplierName,
+		s.SupplierAddress,
+		s.SupplierCountryID,
+		s.SupplierSWIFT,
+		s.SupplierAccountNumber,
+		s.UserID,
+		s.LeadTimeDays,
+		s.Active,
+	).Scan(&s.SupplierID)
+}
+
+func (r *SupplyRepo) FindSupplier(supplierId int) (*model.Supplier, error) {
+	s, err := scanSupplier(r.store.db.QueryRow(
+		`SELECT supplier_id, supplier_name, COALESCE(supplier_address, ''), COALESCE(supplier_country_id, 0),
+		COALESCE(supplier_swift, ''), COALESCE(supplier_account_number, 0), user_id, lead_time_days, active
+		FROM public.supplier WHERE supplier_id = $1 AND active = true`,
+		supplierId,
+	))
+	if err == sql.ErrNoRows {
+		return nil, store.ErrRecordNotFound
+	}
+
+	return s, err
+}
+
+func (r *SupplyRepo) FindSuppliers(userId int) ([]*model.Supplier, error) {
+	suppliers := make([]*model.Supplier, 0)
+	rows, err := r.store.db.Query(
+		`SELECT supplier_id, supplier_name, COALESCE(supplier_address, ''), COALESCE(supplier_country_id, 0),
+		COALESCE(supplier_swift, ''), COALESCE(supplier_account_number, 0), user_id, lead_time_days, active
+		FROM public.supplier WHERE user_id = $1 AND active = true ORDER BY supplier_id`,
+		userId,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		s, err := scanSupplier(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		suppliers = append(suppliers, s)
+	}
+
+	return suppliers, rows.Err()
+}
+
+// CreateOrder saves the order with its products and records the initial status in the audit
+func (r *SupplyRepo) CreateOrder(o *model.SupplyOrder) error {
+	if err := o.Validate(); err != nil {
+		return err
+	}
+
+	tx, err := r.store.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	o.Active = true
+	if err = tx.QueryRow(
+		`INSERT INTO public.supplyorder (supplyorder_date, supplier_id, shippingcost_to_logistic,
+		shippingcost_by_logistic, user_id, active, supplyorderstatus_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING supplyorder_id`,
+		o.OrderDate,
+		o.SupplierID,
+		o.ShippingCostToLogistic,
+		o.ShippingCostByLogistic,
+		o.UserID,
+		o.Active,
+		o.StatusID,
+	).Scan(&o.SupplyOrderID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	for _, p := range o.Products {
+		p.SupplyOrderID = o.SupplyOrderID
+		if err = tx.QueryRow(
+			`INSERT INTO public.supplyorderproduct (supplyorder_id, product_id, unitprice, quantity, currency_id)
+			VALUES ($1, $2, $3, $4, $5) RETURNING supplyorderproduct_id`,
+			p.SupplyOrderID,
+			p.ProductID,
+			p.UnitPrice,
+			p.Quantity,
+			p.CurrencyID,
+		).Scan(&p.SupplyOrderProductID); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	if _, err = tx.Exec(
+		`INSERT INTO public.supplyorderaudit (supplyorder_id, supplyorderaudit_date, supplyorderstatus_id,
+		audit_user_id, active) VALUES ($1, now(), $2, $3, true)`,
+		o.SupplyOrderID,
+		o.StatusID,
+		o.UserID,
+	); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *SupplyRepo) FindOrder(orderId int) (*model.SupplyOrder, error) {
+	o := &model.SupplyOrder{}
+	if err := r.store.db.QueryRow(
+		`SELECT supplyorder_id, supplyorder_date, supplier_id, shippingcost_to_logistic, shippingcost_by_logistic,
+		user_id, active, COALESCE(supplyorderstatus_id, 0)
+		FROM public.supplyorder WHERE supplyorder_id = $1 AND active = true`,
+		orderId,
+	).Scan(
+		&o.SupplyOrderID,
+		&o.OrderDate,
+		&o.SupplierID,
+		&o.ShippingCostToLogistic,
+		&o.ShippingCostByLogistic,
+		&o.UserID,
+		&o.Active,
+		&o.StatusID,
+	); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, store.ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	rows, err := r.store.db.Query(
+		`SELECT supplyorderproduct_id, supplyorder_id, product_id, unitprice, quantity, currency_id
+		FROM public.supplyorderproduct WHERE supplyorder_id = $1 ORDER BY supplyorderproduct_id`,
+		orderId,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	o.Products = make([]*model.SupplyOrderProduct, 0)
+	for rows.Next() {
+		p := &model.SupplyOrderProduct{}
+		if err = rows.Scan(&p.SupplyOrderProductID, &p.SupplyOrderID, &p.ProductID, &p.UnitPrice, &p.Quantity, &p.CurrencyID); err != nil {
+			return nil, err
+		}
+
+		o.Products = append(o.Products, p)
+	}
+
+	return o, rows.Err()
+}
+
+func scanSupplier(row rowScanner) (*model.Supplier, error) {
+	s := &model.Supplier{}
+	if err := row.Scan(
+		&s.SupplierID,
+		&s.SupplierName,
+		&s.SupplierAddress,
+		&s.SupplierCountryID,
+		&s.SupplierSWIFT,
+		&s.SupplierAccountNumber,
+		&s.UserID,
+		&s.LeadTimeDays,
+		&s.Active,
+	); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
@@ -0,0 +1,131 @@
+package sqlstore_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store/sqlstore"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSupplyRepo_CreateOrder(t *testing.T) {
+	db, teardown := sqlstore.TestDB(t, databaseURL)
+	defer teardown("supplyorderaudit", "supplyorderproduct", "supplyorder", "supplier", "product", "users", "category", "material", "marketplaceitem", "productrevision")
+
+	s := sqlstore.New(db)
+	p := testImageProduct(t, s)
+
+	supplier := model.TestSupplier(t)
+	supplier.UserID = p.UserID
+	assert.NoError(t, s.Supply().CreateSupplier(supplier))
+
+	suppliers, err := s.Supply().FindSuppliers(p.UserID)
+	assert.NoError(t, err)
+	if assert.Len(t, suppliers, 1) {
+		assert.Equal(t, 21, suppliers[0].LeadTimeDays)
+	}
+
+	o := &model.SupplyOrder{
+		OrderDate:  time.Now(),
+		SupplierID: supplier.SupplierID,
+		UserID:     p.UserID,
+		StatusID:   model.SupplyOrderStatusDraft,
+		Products:   []*model.SupplyOrderProduct{{ProductID: p.ProductID, Quantity: 10, CurrencyID: model.CurrencyRUB}},
+	}
+	assert.NoError(t, s.Supply().CreateOrder(o))
+
+	found, err := s.Supply().FindOrder(o.SupplyOrderID)
+	assert.NoError(t, err)
+	assert.Equal(t, model.SupplyOrderStatusDraft, found.StatusID)
+	assert.Len(t, found.Products, 1)
+}
+
+func TestInventoryRepo_SalesAndStock(t *testing.T) {
+	db, teardown := sqlstore.TestDB(t, databaseURL)
+	defer teardown("sale", "productstock", "warehouse", "product", "users", "category", "material", "marketplaceitem", "productrevision")
+
+	s := sqlstore.New(db)
+	p := testImageProduct(t, s)
+
+	day := time.Date(2022, 11, 20, 10, 0, 0, 0, time.UTC)
+	sale := &model.Sale{ProductID: p.ProductID, MarketPlaceID: model.MarketPlaceOzon, UserID: p.UserID, SaleDate: day, Quantity: 3}
+	assert.NoError(t, s.Inventory().SaveSales([]*model.Sale{sale}))
+	sale.Quantity = 5
+	assert.NoError(t, s.Inventory().SaveSales([]*model.Sale{sale}))
+
+	sales, err := s.Inventory().FindSales(p.UserID, day.AddDate(0, 0, -1))
+	assert.NoError(t, err)
+	if assert.Len(t, sales, 1) {
+		assert.Equal(t, 5, sales[0].Quantity)
+	}
+
+	w := model.TestWarehouse(t)
+	w.UserID = p.UserID
+	assert.NoError(t, s.Warehouse().Create(w))
+
+	stock := &model.StockLevel{WarehouseID: w.WarehouseID, ProductID: p.ProductID, UserID: p.UserID, Quantity: 30}
+	assert.NoError(t, s.Inventory().SetStock([]*model.StockLevel{stock}))
+	stock.Quantity = 40
+	assert.NoError(t, s.Inventory().SetStock([]*model.StockLevel{stock}))
+
+	levels, err := s.Inventory().FindStock(p.UserID)
+	assert.NoError(t, err)
+	if assert.Len(t, levels, 1) {
+		assert.Equal(t, 40, levels[0].Quantity)
+	}
+}
+
+func TestTransferRepo_ShipAndReceive(t *testing.T) {
+	db, teardown := sqlstore.TestDB(t, databaseURL)
+	defer teardown("stocktransferitem", "stocktransfer", "productstock", "warehouse", "product", "users", "category", "material", "marketplaceitem", "productrevision")
+
+	s := sqlstore.New(db)
+	p := testImageProduct(t, s)
+
+	from := model.TestWarehouse(t)
+	from.UserID = p.UserID
+	assert.NoError(t, s.Warehouse().Create(from))
+	to := model.TestWarehouse(t)
+	to.UserID = p.UserID
+	to.Type = model.WarehouseTypeMarketPlace
+	to.MarketPlaceID = model.MarketPlaceOzon
+	assert.NoError(t, s.Warehouse().Create(to))
+
+	assert.NoError(t, s.Inventory().SetStock([]*model.StockLevel{
+		{WarehouseID: from.WarehouseID, ProductID: p.ProductID, UserID: p.UserID, Quantity: 10},
+	}))
+
+	tr := &model.Transfer{
+		UserID:          p.UserID,
+		FromWarehouseID: from.WarehouseID,
+		ToWarehouseID:   to.WarehouseID,
+		Items:           []*model.TransferItem{{ProductID: p.ProductID, Quantity: 15}},
+	}
+	assert.NoError(t, s.Transfer().Create(tr))
+	assert.EqualError(t, s.Transfer().Ship(tr), store.ErrNotEnoughStock.Error())
+	assert.Equal(t, model.TransferDraft, tr.Status)
+
+	tr.Items[0].Quantity = 4
+	assert.NoError(t, s.Transfer().Ship(tr))
+	assert.NotNil(t, tr.ShippedAt)
+
+	stale := *tr
+	stale.Status = model.TransferDraft
+	assert.EqualError(t, s.Transfer().Ship(&stale), store.ErrVersionConflict.Error())
+
+	assert.NoError(t, s.Transfer().Receive(tr))
+
+	found, err := s.Transfer().Find(tr.TransferID)
+	assert.NoError(t, err)
+	assert.Equal(t, model.TransferReceived, found.Status)
+	assert.Len(t, found.Items, 1)
+
+	levels, err := s.Inventory().FindStock(p.UserID)
+	assert.NoError(t, err)
+	if assert.Len(t, levels, 2) {
+		assert.Equal(t, 6, levels[0].Quantity)
+		assert.Equal(t, 4, levels[1].Quantity)
+	}
+}
@@ -0,0 +1,280 @@
+package sqlstore
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store"
+)
+
+type TransferRepo struct {
+	store *Store
+}
+
+func (r *TransferRepo) Create(t *model.Transfer) error {
+	if err := t.Validate(); err != nil {
+		return err
+	}
+
+	tx, err := r.store.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	t.Status = model.TransferDraft
+	if err = tx.QueryRow(
+		`INSERT INTO public.stocktransfer (user_id, from_warehouse_id, to_warehouse_id, status)
+		VALUES ($1, $2, $3, $4) RETURNING transfer_id, created_at`,
+		t.UserID,
+		t.FromWarehouseID,
+		t.ToWarehouseID,
+		t.Status,
+	).Scan(&t.TransferID, &t.CreatedAt); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	for _, item := range t.Items {
+		if _, err = tx.Exec(
+			"INSERT INTO public.stocktransferitem (transfer_id, product_id, quantity) VALUES ($1, $2, $3)",
+			t.TransferID,
+			item.ProductID,
+			item.Quantity,
+		); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *TransferRepo) Find(transferId int) (*model.Transfer, error) {
+	t, err := scanTransfer(r.store.db.QueryRow(
+		`SELECT transfer_id, user_id, from_warehouse_id, to_warehouse_id, status, created_at, shipped_at, received_at
+		FROM public.stocktransfer WHERE transfer_id = $1`,
+		transferId,
+	))
+	if err == sql.ErrNoRows {
+		return nil, store.ErrRecordNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	if t.Items, err = r.findItems(t.TransferID); err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// FindByUserId returns transfers of the seller in the status or all of them when the status is empty
+func (r *TransferRepo) FindByUserId(userId int, status string) ([]*model.Transfer, error) {
+	transfers := make([]*model.Transfer, 0)
+	rows, err := r.store.db.Query(
+		`SELECT transfer_id, user_id, from_warehouse_id, to_warehouse_id, status, created_at, shipped_at, received_at
+		FROM public.stocktransfer WHERE user_id = $1 AND ($2 = '' OR status = $2) ORDER BY transfer_id`,
+		userId,
+		status,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		t, err := scanTransfer(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		transfers = append(transfers, t)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, t := range transfers {
+		if t.Items, err = r.findItems(t.TransferID); err != nil {
+			return nil, err
+		}
+	}
+
+	return transfers, nil
+}
+
+// Ship takes the items from the source warehouse, nothing is taken when any of them is short
+func (r *TransferRepo) Ship(t *model.Transfer) error {
+	return r.change(t, model.TransferInTransit, func(tx *sql.Tx) error {
+		for _, item := range t.Items {
+			if err := addStock(tx, t.UserID, t.FromWarehouseID, item.ProductID, -item.Quantity); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (r *TransferRepo) Receive(t *model.Transfer) error {
+	return r.change(t, model.TransferReceived, func(tx *sql.Tx) error {
+		for _, item := range t.Items {
+			if err := addStock(tx, t.UserID, t.ToWarehouseID, item.ProductID, item.Quantity); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// Cancel returns items of a shipped transfer to the source warehouse
+func (r *TransferRepo) Cancel(t *model.Transfer) error {
+	shipped := t.Status == model.TransferInTransit
+	return r.change(t, model.TransferCancelled, func(tx *sql.Tx) error {
+		if !shipped {
+			return nil
+		}
+
+		for _, item := range t.Items {
+			if err := addStock(tx, t.UserID, t.FromWarehouseID, item.ProductID, item.Quantity); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// change sets the status of the transfer and moves the stock in one transaction.
+// The transfer changed by someone else since it was read is a version conflict.
+func (r *TransferRepo) change(t *model.Transfer, status string, move func(*sql.Tx) error) error {
+	tx, err := r.store.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	var shippedAt, receivedAt sql.NullTime
+	if err = tx.QueryRow(
+		`UPDATE public.stocktransfer SET status = $1,
+		shipped_at = CASE WHEN $1 = 'in_transit' THEN now() ELSE shipped_at END,
+		received_at = CASE WHEN $1 = 'received' THEN now() ELSE received_at END
+		WHERE transfer_id = $2 AND status = $3 RETURNING shipped_at, received_at`,
+		status,
+		t.TransferID,
+		t.Status,
+	).Scan(&shippedAt, &receivedAt); err != nil {
+		tx.Rollback()
+		if err == sql.ErrNoRows {
+			return store.ErrVersionConflict
+		}
+		return err
+	}
+
+	if err = move(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+
+	t.Status = status
+	t.ShippedAt = nullTime(shippedAt)
+	t.ReceivedAt = nullTime(receivedAt)
+
+	return nil
+}
+
+func (r *TransferRepo) findItems(transferId int) ([]*model.TransferItem, error) {
+	items := make([]*model.TransferItem, 0)
+	rows, err := r.store.db.Query(
+		"SELECT product_id, quantity FROM public.stocktransferitem WHERE transfer_id = $1 ORDER BY product_id",
+		transferId,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		item := &model.TransferItem{}
+		if err = rows.Scan(&item.ProductID, &item.Quantity); err != nil {
+			return nil, err
+		}
+
+		items = append(items, item)
+	}
+
+	return items, rows.Err()
+}
+
+// addStock changes the stock of the product in the warehouse by the quantity, the stock can't become negative
+func addStock(tx *sql.Tx, userId int, warehouseId int, productId int, quantity int) error {
+	if quantity >= 0 {
+		_, err := tx.Exec(
+			`INSERT INTO public.productstock (warehouse_id, product_id, user_id, quantity) VALUES ($1, $2, $3, $4)
+			ON CONFLICT (warehouse_id, product_id) DO UPDATE
+			SET quantity = productstock.quantity + EXCLUDED.quantity, updated_at = now()`,
+			warehouseId,
+			productId,
+			userId,
+			quantity,
+		)
+		return err
+	}
+
+	res, err := tx.Exec(
+		`UPDATE public.productstock SET quantity = quantity + $1, updated_at = now()
+		WHERE warehouse_id = $2 AND product_id = $3 AND quantity >= -$1`,
+		quantity,
+		warehouseId,
+		productId,
+	)
+	if err != nil {
+		return err
+	}
+
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return store.ErrNotEnoughStock
+	}
+
+	return nil
+}
+
+func scanTransfer(row rowScanner) (*model.Transfer, error) {
+	t := &model.Transfer{}
+	var shippedAt, receivedAt sql.NullTime
+	if err := row.Scan(
+		&t.TransferID,
+		&t.UserID,
+		&t.FromWarehouseID,
+		&t.ToWarehouseID,
+		&t.Status,
+		&t.CreatedAt,
+		&shippedAt,
+		&receivedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	t.ShippedAt = nullTime(shippedAt)
+	t.ReceivedAt = nullTime(receivedAt)
+
+	return t, nil
+}
+
+func nullTime(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+
+	return &t.Time
+}
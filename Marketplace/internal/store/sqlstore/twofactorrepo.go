@@ -0,0 +1,187 @@
+package sqlstore
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store"
+)
+
+type TwoFactorRepo struct {
+	store *Store
+}
+
+func (r *TwoFactorRepo) Save(t *model.TwoFactor) error {
+	if err := t.Validate(); err != nil {
+		return err
+	}
+
+	if t.CreatedAt.IsZero() {
+		t.CreatedAt = time.Now().UTC()
+	}
+
+	res, err := r.store.db.Exec(
+		`INSERT INTO public.twofactor AS t (user_id, secret, last_step, created_at) VALUES ($1, $2, 0, $3)
+		ON CONFLICT (user_id) DO UPDATE SET secret = $2, last_step = 0, created_at = $3, enabled_at = NULL
+		WHERE t.enabled_at IS NULL`,
+		t.UserID,
+		t.Secret,
+		t.CreatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return store.ErrRecordExists
+	}
+
+	t.LastStep = 0
+	t.EnabledAt = nil
+	return nil
+}
+
+func (r *TwoFactorRepo) Find(userId int) (*model.TwoFactor, error) {
+	t := &model.TwoFactor{}
+	enabledAt := sql.NullTime{}
+	if err := r.store.db.QueryRow(
+		"SELECT user_id, secret, last_step, created_at, enabled_at FROM public.twofactor WHERE user_id = $1",
+		userId,
+	).Scan(
+		&t.UserID,
+		&t.Secret,
+		&t.LastStep,
+		&t.CreatedAt,
+		&enabledAt,
+	); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, store.ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	if enabledAt.Valid {
+		t.EnabledAt = &enabledAt.Time
+	}
+
+	return t, nil
+}
+
+func (r *TwoFactorRepo) Enable(userId int, step int64, at time.Time) error {
+	res, err := r.store.db.Exec(
+		"UPDATE public.twofactor SET enabled_at = $3, last_step = $2 WHERE user_id = $1 AND enabled_at IS NULL",
+		userId,
+		step,
+		at,
+	)
+	if err != nil {
+		return err
+	}
+
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		if _, err = r.Find(userId); err != nil {
+			return err
+		}
+		return store.ErrRecordExists
+	}
+
+	return nil
+}
+
+func (r *TwoFactorRepo) UseStep(userId int, step int64) error {
+	res, err := r.store.db.Exec(
+		"UPDATE public.twofactor SET last_step = $2 WHERE user_id = $1 AND last_step < $2",
+		userId,
+		step,
+	)
+	if err != nil {
+		return err
+	}
+
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return store.ErrTokenUsed
+	}
+
+	return nil
+}
+
+func (r *TwoFactorRepo) Delete(userId int) error {
+	tx, err := r.store.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	if _, err = tx.Exec("DELETE FROM public.recoverycode WHERE user_id = $1", userId); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	res, err := tx.Exec("DELETE FROM public.twofactor WHERE user_id = $1", userId)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err = checkAffected(res); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *TwoFactorRepo) ReplaceRecoveryCodes(userId int, hashes []string) error {
+	tx, err := r.store.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	if _, err = tx.Exec("DELETE FROM public.recoverycode WHERE user_id = $1", userId); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	for _, hash := range hashes {
+		if _, err = tx.Exec(
+			"INSERT INTO public.recoverycode (user_id, code_hash) VALUES ($1, $2)",
+			userId,
+			hash,
+		); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *TwoFactorRepo) UseRecoveryCode(userId int, hash string, at time.Time) error {
+	res, err := r.store.db.Exec(
+		"UPDATE public.recoverycode SET used_at = $3 WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL",
+		userId,
+		hash,
+		at,
+	)
+	if err != nil {
+		return err
+	}
+
+	return checkAffected(res)
+}
+
+func (r *TwoFactorRepo) CountRecoveryCodes(userId int) (int, error) {
+	n := 0
+	err := r.store.db.QueryRow(
+		"SELECT count(*) FROM public.recoverycode WHERE user_id = $1 AND used_at IS NULL",
+		userId,
+	).Scan(&n)
+
+	return n, err
+}
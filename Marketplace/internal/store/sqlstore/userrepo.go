@@ -5,6 +5,7 @@ import (
 
 	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
 	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store"
+	"github.com/lib/pq"
 )
 
 type UserRepo struct {
@@ -21,19 +22,29 @@ func (r *UserRepo) Create(u *model.User) error {
 		return err
 	}
 
+	u.BeforeCreate()
+
 	return r.store.db.QueryRow(
-		"INSERT INTO public.users (email, encryptedpassword, userrole, active) VALUES ($1, $2, $3, $4) RETURNING id",
+		"INSERT INTO public.users (email, encryptedpassword, userrole, active, emailverified, displayname, companyname, timezone, basecurrency, locale) "+
+			"VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING id",
 		u.Email,
 		u.EncryptedPassword,
 		u.UserRole,
 		u.Active,
+		u.EmailVerified,
+		u.DisplayName,
+		u.CompanyName,
+		u.Timezone,
+		u.BaseCurrency,
+		u.Locale,
 	).Scan(&u.ID)
 }
 
 func (r *UserRepo) FindByEmail(email string) (*model.User, error) {
 	u := &model.User{}
 	if err := r.store.db.QueryRow(
-		"SELECT id, email, encryptedpassword, userrole, active FROM public.users WHERE email = $1",
+		"SELECT id, email, encryptedpassword, userrole, active, emailverified, displayname, companyname, timezone, basecurrency, locale "+
+			"FROM public.users WHERE email = $1",
 		email,
 	).Scan(
 		&u.ID,
@@ -41,6 +52,12 @@ func (r *UserRepo) FindByEmail(email string) (*model.User, error) {
 		&u.EncryptedPassword,
 		&u.UserRole,
 		&u.Active,
+		&u.EmailVerified,
+		&u.DisplayName,
+		&u.CompanyName,
+		&u.Timezone,
+		&u.BaseCurrency,
+		&u.Locale,
 	); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, store.ErrRecordNotFound
@@ -54,7 +71,8 @@ func (r *UserRepo) FindByEmail(email string) (*model.User, error) {
 func (r *UserRepo) FindById(id int) (*model.User, error) {
 	u := &model.User{}
 	if err := r.store.db.QueryRow(
-		"SELECT id, email, encryptedpassword, userrole, active FROM public.users WHERE id = $1",
+		"SELECT id, email, encryptedpassword, userrole, active, emailverified, displayname, companyname, timezone, basecurrency, locale "+
+			"FROM public.users WHERE id = $1",
 		id,
 	).Scan(
 		&u.ID,
@@ -62,6 +80,12 @@ func (r *UserRepo) FindById(id int) (*model.User, error) {
 		&u.EncryptedPassword,
 		&u.UserRole,
 		&u.Active,
+		&u.EmailVerified,
+		&u.DisplayName,
+		&u.CompanyName,
+		&u.Timezone,
+		&u.BaseCurrency,
+		&u.Locale,
 	); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, store.ErrRecordNotFound
@@ -71,3 +95,86 @@ func (r *UserRepo) FindById(id int) (*model.User, error) {
 
 	return u, nil
 }
+
+// UpdatePassword saves the new password hash of the user, a plain Password is encrypted first
+func (r *UserRepo) UpdatePassword(u *model.User) error {
+	if err := u.ValidatePassword(); err != nil {
+		return err
+	}
+
+	if err := u.EncryptPasswordBeforeCreate(); err != nil {
+		return err
+	}
+
+	res, err := r.store.db.Exec(
+		"UPDATE public.users SET encryptedpassword = $2 WHERE id = $1",
+		u.ID,
+		u.EncryptedPassword,
+	)
+	if err != nil {
+		return err
+	}
+
+	return checkAffected(res)
+}
+
+func (r *UserRepo) VerifyEmail(id int) error {
+	res, err := r.store.db.Exec("UPDATE public.users SET emailverified = true WHERE id = $1", id)
+	if err != nil {
+		return err
+	}
+
+	return checkAffected(res)
+}
+
+// UpdateEmail saves the new email of the user and whether it is verified, ErrRecordExists
+// is returned when another user has the email
+func (r *UserRepo) UpdateEmail(u *model.User) error {
+	if err := u.ValidateEmail(); err != nil {
+		return err
+	}
+
+	res, err := r.store.db.Exec(
+		"UPDATE public.users SET email = $2, emailverified = $3 WHERE id = $1",
+		u.ID,
+		u.Email,
+		u.EmailVerified,
+	)
+	if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == uniqueViolation {
+		return store.ErrRecordExists
+	} else if err != nil {
+		return err
+	}
+
+	return checkAffected(res)
+}
+
+func (r *UserRepo) UpdateProfile(u *model.User) error {
+	if err := u.UserProfile.Validate(); err != nil {
+		return err
+	}
+
+	res, err := r.store.db.Exec(
+		"UPDATE public.users SET displayname = $2, companyname = $3, timezone = $4, basecurrency = $5, locale = $6 WHERE id = $1",
+		u.ID,
+		u.DisplayName,
+		u.CompanyName,
+		u.Timezone,
+		u.BaseCurrency,
+		u.Locale,
+	)
+	if err != nil {
+		return err
+	}
+
+	return checkAffected(res)
+}
+
+func (r *UserRepo) UpdateActive(id int, active bool) error {
+	res, err := r.store.db.Exec("UPDATE public.users SET active = $2 WHERE id = $1", id, active)
+	if err != nil {
+		return err
+	}
+
+	return checkAffected(res)
+}
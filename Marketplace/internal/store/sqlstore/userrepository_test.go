@@ -54,3 +54,96 @@ func TestFindById(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NotNil(t, u2)
 }
+
+func TestUserRepo_UpdatePassword(t *testing.T) {
+	db, teardown := sqlstore.TestDB(t, databaseURL)
+	defer teardown("users")
+
+	s := sqlstore.New(db)
+	u := model.TestUser(t)
+	u.EmailVerified = false
+	assert.NoError(t, s.User().Create(u))
+
+	u.Password = "new password"
+	assert.NoError(t, s.User().UpdatePassword(u))
+	assert.NoError(t, s.User().VerifyEmail(u.ID))
+
+	found, err := s.User().FindById(u.ID)
+	assert.NoError(t, err)
+	assert.True(t, found.ComparePassword("new password"))
+	assert.True(t, found.EmailVerified)
+
+	// a password encrypted by the service is saved as it is
+	u.Password = "argon2 password"
+	assert.NoError(t, u.EncryptPassword(model.PasswordHasher{Algorithm: model.PasswordHashArgon2id, Argon2Time: 1, Argon2Memory: 1024, Argon2Threads: 1}))
+	u.Sanitize()
+	assert.NoError(t, s.User().UpdatePassword(u))
+
+	found, err = s.User().FindById(u.ID)
+	assert.NoError(t, err)
+	assert.True(t, found.ComparePassword("argon2 password"))
+}
+
+func TestUserRepo_UpdateEmail(t *testing.T) {
+	db, teardown := sqlstore.TestDB(t, databaseURL)
+	defer teardown("users")
+
+	s := sqlstore.New(db)
+	u := model.TestUser(t)
+	assert.NoError(t, s.User().Create(u))
+	other := model.TestUser(t)
+	other.Email = "other@test.org"
+	assert.NoError(t, s.User().Create(other))
+
+	assert.Error(t, s.User().UpdateEmail(&model.User{ID: u.ID, Email: "invalid"}))
+	assert.Equal(t, store.ErrRecordExists, s.User().UpdateEmail(&model.User{ID: u.ID, Email: other.Email}))
+	assert.Equal(t, store.ErrRecordNotFound, s.User().UpdateEmail(&model.User{ID: 100, Email: "new@test.org"}))
+	assert.NoError(t, s.User().UpdateEmail(&model.User{ID: u.ID, Email: "new@test.org"}))
+
+	found, err := s.User().FindByEmail("new@test.org")
+	assert.NoError(t, err)
+	assert.Equal(t, u.ID, found.ID)
+	assert.False(t, found.EmailVerified)
+}
+
+func TestUserRepo_UpdateProfile(t *testing.T) {
+	db, teardown := sqlstore.TestDB(t, databaseURL)
+	defer teardown("users")
+
+	s := sqlstore.New(db)
+	u := model.TestUser(t)
+	assert.NoError(t, s.User().Create(u))
+	assert.Equal(t, model.DefaultTimezone, u.Timezone)
+
+	u.UserProfile = model.UserProfile{
+		DisplayName:  "Ivan",
+		CompanyName:  "Tableware LLC",
+		Timezone:     "Asia/Yekaterinburg",
+		BaseCurrency: "USD",
+		Locale:       "en-US",
+	}
+	assert.NoError(t, s.User().UpdateProfile(u))
+
+	found, err := s.User().FindById(u.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, u.UserProfile, found.UserProfile)
+
+	u.Timezone = "Mars/Olympus"
+	assert.Error(t, s.User().UpdateProfile(u))
+}
+
+func TestUserRepo_UpdateActive(t *testing.T) {
+	db, teardown := sqlstore.TestDB(t, databaseURL)
+	defer teardown("users")
+
+	s := sqlstore.New(db)
+	u := model.TestUser(t)
+	assert.NoError(t, s.User().Create(u))
+
+	assert.NoError(t, s.User().UpdateActive(u.ID, false))
+	found, err := s.User().FindById(u.ID)
+	assert.NoError(t, err)
+	assert.False(t, found.Active)
+
+	assert.Equal(t, store.ErrRecordNotFound, s.User().UpdateActive(100, false))
+}
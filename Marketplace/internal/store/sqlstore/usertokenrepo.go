@@ -0,0 +1,98 @@
+package sqlstore
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store"
+)
+
+type UserTokenRepo struct {
+	store *Store
+}
+
+func (r *UserTokenRepo) Create(t *model.UserToken) error {
+	if err := t.Validate(); err != nil {
+		return err
+	}
+
+	if t.CreatedAt.IsZero() {
+		t.CreatedAt = time.Now().UTC()
+	}
+
+	_, err := r.store.db.Exec(
+		`INSERT INTO public.usertoken (token_hash, user_id, purpose, email, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)`,
+		t.TokenHash,
+		t.UserID,
+		t.Purpose,
+		t.Email,
+		t.CreatedAt,
+		t.ExpiresAt,
+	)
+	return err
+}
+
+func (r *UserTokenRepo) FindByHash(hash string) (*model.UserToken, error) {
+	t := &model.UserToken{}
+	usedAt := sql.NullTime{}
+	if err := r.store.db.QueryRow(
+		`SELECT token_hash, user_id, purpose, email, created_at, expires_at, used_at
+		FROM public.usertoken WHERE token_hash = $1`,
+		hash,
+	).Scan(
+		&t.TokenHash,
+		&t.UserID,
+		&t.Purpose,
+		&t.Email,
+		&t.CreatedAt,
+		&t.ExpiresAt,
+		&usedAt,
+	); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, store.ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	if usedAt.Valid {
+		t.UsedAt = &usedAt.Time
+	}
+
+	return t, nil
+}
+
+func (r *UserTokenRepo) Use(hash string, at time.Time) error {
+	res, err := r.store.db.Exec(
+		"UPDATE public.usertoken SET used_at = $2 WHERE token_hash = $1 AND used_at IS NULL",
+		hash,
+		at,
+	)
+	if err != nil {
+		return err
+	}
+
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return store.ErrTokenUsed
+	}
+
+	return nil
+}
+
+func (r *UserTokenRepo) DeleteByUserId(userId int, purpose string) error {
+	_, err := r.store.db.Exec("DELETE FROM public.usertoken WHERE user_id = $1 AND purpose = $2", userId, purpose)
+	return err
+}
+
+func (r *UserTokenRepo) DeleteExpired(at time.Time) (int, error) {
+	res, err := r.store.db.Exec("DELETE FROM public.usertoken WHERE expires_at <= $1", at)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := res.RowsAffected()
+	return int(n), err
+}
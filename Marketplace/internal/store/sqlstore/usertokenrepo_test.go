@@ -0,0 +1,36 @@
+package sqlstore_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store/sqlstore"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserTokenRepo(t *testing.T) {
+	db, teardown := sqlstore.TestDB(t, databaseURL)
+	defer teardown("usertoken", "users")
+
+	s := sqlstore.New(db)
+	u := model.TestUser(t)
+	assert.NoError(t, s.User().Create(u))
+
+	ut := model.TestUserToken(t)
+	ut.UserID = u.ID
+	assert.NoError(t, s.UserToken().Create(ut))
+
+	at := ut.CreatedAt.Add(time.Hour)
+	assert.NoError(t, s.UserToken().Use(ut.TokenHash, at))
+	assert.EqualError(t, s.UserToken().Use(ut.TokenHash, at), store.ErrTokenUsed.Error())
+
+	found, err := s.UserToken().FindByHash(ut.TokenHash)
+	assert.NoError(t, err)
+	assert.True(t, found.UsedAt.Equal(at))
+
+	assert.NoError(t, s.UserToken().DeleteByUserId(u.ID, model.UserTokenVerifyEmail))
+	_, err = s.UserToken().FindByHash(ut.TokenHash)
+	assert.EqualError(t, err, store.ErrRecordNotFound.Error())
+}
@@ -0,0 +1,103 @@
+package sqlstore
+
+import (
+	"database/sql"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store"
+)
+
+type WarehouseRepo struct {
+	store *Store
+}
+
+func (r *WarehouseRepo) Create(w *model.Warehouse) error {
+	if err := w.Validate(); err != nil {
+		return err
+	}
+
+	w.Active = true
+	return r.store.db.QueryRow(
+		`INSERT INTO public.warehouse (user_id, warehouse_name, warehouse_type, marketplace_id, address, active)
+		VALUES ($1, $2, $3, NULLIF($4, 0), $5, $6) RETURNING warehouse_id`,
+		w.UserID,
+		w.Name,
+		w.Type,
+		w.MarketPlaceID,
+		w.Address,
+		w.Active,
+	).Scan(&w.WarehouseID)
+}
+
+func (r *WarehouseRepo) Find(warehouseId int) (*model.Warehouse, error) {
+	w, err := scanWarehouse(r.store.db.QueryRow(
+		`SELECT warehouse_id, user_id, warehouse_name, warehouse_type, COALESCE(marketplace_id, 0), address, active
+		FROM public.warehouse WHERE warehouse_id = $1 AND active = true`,
+		warehouseId,
+	))
+	if err == sql.ErrNoRows {
+		return nil, store.ErrRecordNotFound
+	}
+
+	return w, err
+}
+
+func (r *WarehouseRepo) FindByUserId(userId int) ([]*model.Warehouse, error) {
+	warehouses := make([]*model.Warehouse, 0)
+	rows, err := r.store.db.Query(
+		`SELECT warehouse_id, user_id, warehouse_name, warehouse_type, COALESCE(marketplace_id, 0), address, active
+		FROM public.warehouse WHERE user_id = $1 AND active = true ORDER BY warehouse_id`,
+		userId,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		w, err := scanWarehouse(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		warehouses = append(warehouses, w)
+	}
+
+	return warehouses, rows.Err()
+}
+
+func (r *WarehouseRepo) Delete(warehouseId int) error {
+	res, err := r.store.db.Exec(
+		"UPDATE public.warehouse SET active = false WHERE warehouse_id = $1 AND active = true",
+		warehouseId,
+	)
+	if err != nil {
+		return err
+	}
+
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return store.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func scanWarehouse(row rowScanner) (*model.Warehouse, error) {
+	w := &model.Warehouse{}
+	if err := row.Scan(
+		&w.WarehouseID,
+		&w.UserID,
+		&w.Name,
+		&w.Type,
+		&w.MarketPlaceID,
+		&w.Address,
+		&w.Active,
+	); err != nil {
+		return nil, err
+	}
+
+	return w, nil
+}
@@ -3,5 +3,21 @@ package store
 type Store interface {
 	User() UserRepo
 	Product() ProductRepo
+	Image() ImageRepo
+	Attribute() AttributeRepo
+	Barcode() BarcodeRepo
+	Price() PriceRepo
+	Repricing() RepricingRepo
+	Inventory() InventoryRepo
+	Supply() SupplyRepo
+	Warehouse() WarehouseRepo
+	Transfer() TransferRepo
+	Shipment() ShipmentRepo
+	Session() SessionRepo
+	RefreshToken() RefreshTokenRepo
+	APIKey() APIKeyRepo
+	UserToken() UserTokenRepo
+	AttemptCounter() AttemptCounterRepo
+	SignInFailure() SignInFailureRepo
+	TwoFactor() TwoFactorRepo
 }
-
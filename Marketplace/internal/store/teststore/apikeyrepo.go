@@ -0,0 +1,80 @@
+package teststore
+
+import (
+	"time"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store"
+)
+
+type APIKeyRepo struct {
+	store  *Store
+	keys   map[int]*model.APIKey
+	lastId int
+}
+
+func (r *APIKeyRepo) Create(k *model.APIKey) error {
+	if err := k.Validate(); err != nil {
+		return err
+	}
+
+	for _, stored := range r.keys {
+		if stored.KeyHash == k.KeyHash {
+			return store.ErrRecordExists
+		}
+	}
+
+	if k.CreatedAt.IsZero() {
+		k.CreatedAt = time.Now().UTC()
+	}
+
+	r.lastId++
+	k.APIKeyID = r.lastId
+	stored := *k
+	stored.Key = ""
+	r.keys[k.APIKeyID] = &stored
+
+	return nil
+}
+
+func (r *APIKeyRepo) FindByHash(hash string) (*model.APIKey, error) {
+	for _, k := range r.keys {
+		if k.KeyHash == hash {
+			found := *k
+			return &found, nil
+		}
+	}
+
+	return nil, store.ErrRecordNotFound
+}
+
+func (r *APIKeyRepo) FindByUserId(userId int) ([]*model.APIKey, error) {
+	keys := make([]*model.APIKey, 0)
+	for id := 1; id <= r.lastId; id++ {
+		if k, ok := r.keys[id]; ok && k.UserID == userId {
+			found := *k
+			keys = append(keys, &found)
+		}
+	}
+
+	return keys, nil
+}
+
+func (r *APIKeyRepo) Touch(apiKeyId int, at time.Time) error {
+	k, ok := r.keys[apiKeyId]
+	if !ok {
+		return store.ErrRecordNotFound
+	}
+
+	k.LastUsedAt = &at
+	return nil
+}
+
+func (r *APIKeyRepo) Delete(apiKeyId int) error {
+	if _, ok := r.keys[apiKeyId]; !ok {
+		return store.ErrRecordNotFound
+	}
+
+	delete(r.keys, apiKeyId)
+	return nil
+}
@@ -0,0 +1,32 @@
+package teststore_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store/teststore"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIKeyRepo(t *testing.T) {
+	s := teststore.New()
+	k := model.TestAPIKey(t)
+	k.Key = "mpk_secret"
+	assert.NoError(t, s.APIKey().Create(k))
+	assert.EqualError(t, s.APIKey().Create(model.TestAPIKey(t)), store.ErrRecordExists.Error())
+
+	at := k.CreatedAt.Add(time.Hour)
+	assert.NoError(t, s.APIKey().Touch(k.APIKeyID, at))
+
+	found, err := s.APIKey().FindByHash(k.KeyHash)
+	assert.NoError(t, err)
+	assert.Empty(t, found.Key)
+	assert.Equal(t, at, *found.LastUsedAt)
+
+	assert.NoError(t, s.APIKey().Delete(k.APIKeyID))
+	keys, err := s.APIKey().FindByUserId(k.UserID)
+	assert.NoError(t, err)
+	assert.Empty(t, keys)
+}
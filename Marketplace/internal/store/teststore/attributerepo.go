@@ -0,0 +1,70 @@
+package teststore
+
+import (
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store"
+)
+
+type AttributeRepo struct {
+	store      *Store
+	attributes map[int]*model.CategoryAttribute
+	values     map[int]map[string]string
+}
+
+func (r *AttributeRepo) Create(a *model.CategoryAttribute) error {
+	if err := a.Validate(); err != nil {
+		return err
+	}
+
+	a.AttributeID = len(r.attributes) + 1
+	a.Active = true
+	r.attributes[a.AttributeID] = a
+
+	return nil
+}
+
+func (r *AttributeRepo) FindByCategoryIds(categoryIds []int) ([]*model.CategoryAttribute, error) {
+	attributes := make([]*model.CategoryAttribute, 0)
+	for id := 1; id <= len(r.attributes); id++ {
+		a := r.attributes[id]
+		if !a.Active {
+			continue
+		}
+
+		for _, categoryId := range categoryIds {
+			if a.CategoryID == categoryId {
+				attributes = append(attributes, a)
+			}
+		}
+	}
+
+	return attributes, nil
+}
+
+func (r *AttributeRepo) Delete(categoryId int, attributeId int) error {
+	a, ok := r.attributes[attributeId]
+	if !ok || !a.Active || a.CategoryID != categoryId {
+		return store.ErrRecordNotFound
+	}
+
+	a.Active = false
+
+	return nil
+}
+
+// setProductValues replaces all attribute values of the product, products are saved with their values
+func (r *AttributeRepo) setProductValues(productId int, values map[string]string) {
+	r.values[productId] = make(map[string]string, len(values))
+	for code, value := range values {
+		r.values[productId][code] = value
+	}
+}
+
+func (r *AttributeRepo) FindProductValues(productId int) (map[string]string, error) {
+	values := make(map[string]string, len(r.values[productId]))
+	for code, value := range r.values[productId] {
+		values[code] = value
+	}
+
+	return values, nil
+}
@@ -0,0 +1,64 @@
+package teststore_test
+
+import (
+	"testing"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store/teststore"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAttributeRepo_Create(t *testing.T) {
+	s := teststore.New()
+	a := model.TestCategoryAttribute(t)
+
+	assert.NoError(t, s.Attribute().Create(a))
+	assert.NotZero(t, a.AttributeID)
+
+	invalid := model.TestCategoryAttribute(t)
+	invalid.Type = ""
+	assert.Error(t, s.Attribute().Create(invalid))
+}
+
+func TestAttributeRepo_FindByCategoryIds(t *testing.T) {
+	s := teststore.New()
+	a1 := model.TestCategoryAttribute(t)
+	a2 := model.TestCategoryAttribute(t)
+	a2.CategoryID = 3
+	s.Attribute().Create(a1)
+	s.Attribute().Create(a2)
+
+	attributes, err := s.Attribute().FindByCategoryIds([]int{a1.CategoryID})
+	assert.NoError(t, err)
+	assert.Len(t, attributes, 1)
+
+	assert.EqualError(t, s.Attribute().Delete(a2.CategoryID, a1.AttributeID), store.ErrRecordNotFound.Error())
+	assert.NoError(t, s.Attribute().Delete(a1.CategoryID, a1.AttributeID))
+	assert.EqualError(t, s.Attribute().Delete(a1.CategoryID, a1.AttributeID), store.ErrRecordNotFound.Error())
+
+	attributes, err = s.Attribute().FindByCategoryIds([]int{a1.CategoryID, a2.CategoryID})
+	assert.NoError(t, err)
+	assert.Len(t, attributes, 1)
+	assert.Equal(t, a2.AttributeID, attributes[0].AttributeID)
+}
+
+func TestAttributeRepo_ProductValues(t *testing.T) {
+	s := teststore.New()
+
+	// products are saved together with their attribute values, an update replaces all of them
+	p := model.TestProduct(t)
+	p.Attributes = map[string]string{"fabric": "cotton", "size": "M"}
+	assert.NoError(t, s.Product().Create(p, &model.MarketPlaceItemsList{}))
+
+	values, err := s.Attribute().FindProductValues(p.ProductID)
+	assert.NoError(t, err)
+	assert.Equal(t, p.Attributes, values)
+
+	p.Attributes = map[string]string{"fabric": "wool"}
+	assert.NoError(t, s.Product().Update(p, &model.MarketPlaceItemsList{}))
+
+	values, err = s.Attribute().FindProductValues(p.ProductID)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"fabric": "wool"}, values)
+}
@@ -0,0 +1,83 @@
+package teststore
+
+import (
+	"sort"
+	"time"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store"
+)
+
+type BarcodeRepo struct {
+	store    *Store
+	barcodes map[int]*model.Barcode
+	lastID   int
+}
+
+func (r *BarcodeRepo) Create(b *model.Barcode) error {
+	b.BeforeCreate()
+	if err := b.Validate(); err != nil {
+		return err
+	}
+
+	if _, err := r.FindByCode(b.UserID, b.Code); err == nil {
+		return store.ErrRecordExists
+	}
+
+	r.lastID++
+	b.BarcodeID = r.lastID
+	b.CreatedAt = time.Now()
+	r.barcodes[b.BarcodeID] = b
+
+	return nil
+}
+
+func (r *BarcodeRepo) Find(barcodeId int) (*model.Barcode, error) {
+	b, ok := r.barcodes[barcodeId]
+	if !ok {
+		return nil, store.ErrRecordNotFound
+	}
+
+	return b, nil
+}
+
+func (r *BarcodeRepo) FindByProductId(productId int) ([]*model.Barcode, error) {
+	barcodes := make([]*model.Barcode, 0)
+	for _, b := range r.barcodes {
+		if b.ProductID == productId {
+			barcodes = append(barcodes, b)
+		}
+	}
+
+	sort.Slice(barcodes, func(i, j int) bool {
+		return barcodes[i].BarcodeID < barcodes[j].BarcodeID
+	})
+
+	return barcodes, nil
+}
+
+func (r *BarcodeRepo) FindByCode(userId int, code string) (*model.Barcode, error) {
+	for _, b := range r.barcodes {
+		if b.UserID == userId && b.Code == code {
+			return b, nil
+		}
+	}
+
+	return nil, store.ErrRecordNotFound
+}
+
+func (r *BarcodeRepo) CountInternal(userId int) (int, error) {
+	count := 0
+	for _, b := range r.barcodes {
+		if b.UserID == userId && b.Internal {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+func (r *BarcodeRepo) Delete(barcodeId int) error {
+	delete(r.barcodes, barcodeId)
+	return nil
+}
@@ -0,0 +1,41 @@
+package teststore_test
+
+import (
+	"testing"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store/teststore"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBarcodeRepo_Create(t *testing.T) {
+	s := teststore.New()
+	b := model.TestBarcode(t)
+
+	assert.NoError(t, s.Barcode().Create(b))
+	assert.Equal(t, model.BarcodeTypeEAN13, b.Type)
+
+	assert.EqualError(t, s.Barcode().Create(model.TestBarcode(t)), store.ErrRecordExists.Error())
+
+	other := model.TestBarcode(t)
+	other.UserID = 2
+	assert.NoError(t, s.Barcode().Create(other))
+}
+
+func TestBarcodeRepo_FindByCode(t *testing.T) {
+	s := teststore.New()
+	b := model.TestBarcode(t)
+	s.Barcode().Create(b)
+
+	found, err := s.Barcode().FindByCode(b.UserID, b.Code)
+	assert.NoError(t, err)
+	assert.Equal(t, b.BarcodeID, found.BarcodeID)
+
+	_, err = s.Barcode().FindByCode(b.UserID+1, b.Code)
+	assert.EqualError(t, err, store.ErrRecordNotFound.Error())
+
+	assert.NoError(t, s.Barcode().Delete(b.BarcodeID))
+	_, err = s.Barcode().Find(b.BarcodeID)
+	assert.EqualError(t, err, store.ErrRecordNotFound.Error())
+}
@@ -0,0 +1,76 @@
+package teststore
+
+import (
+	"sort"
+	"time"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store"
+)
+
+type ImageRepo struct {
+	store  *Store
+	images map[int]*model.ProductImage
+	lastID int
+}
+
+func (r *ImageRepo) Create(i *model.ProductImage) error {
+	position := 0
+	for _, image := range r.images {
+		if image.ProductID == i.ProductID && image.Position > position {
+			position = image.Position
+		}
+	}
+
+	r.lastID++
+	i.ImageID = r.lastID
+	i.Position = position + 1
+	i.CreatedAt = time.Now()
+	r.images[i.ImageID] = i
+
+	return nil
+}
+
+func (r *ImageRepo) Find(imageId int) (*model.ProductImage, error) {
+	i, ok := r.images[imageId]
+	if !ok {
+		return nil, store.ErrRecordNotFound
+	}
+
+	return i, nil
+}
+
+func (r *ImageRepo) FindByProductId(productId int) ([]*model.ProductImage, error) {
+	images := make([]*model.ProductImage, 0)
+	for _, i := range r.images {
+		if i.ProductID == productId {
+			images = append(images, i)
+		}
+	}
+
+	sort.Slice(images, func(a, b int) bool {
+		return images[a].Position < images[b].Position
+	})
+
+	return images, nil
+}
+
+func (r *ImageRepo) UpdatePositions(productId int, imageIds []int) error {
+	for _, imageId := range imageIds {
+		i, ok := r.images[imageId]
+		if !ok || i.ProductID != productId {
+			return store.ErrRecordNotFound
+		}
+	}
+
+	for position, imageId := range imageIds {
+		r.images[imageId].Position = position + 1
+	}
+
+	return nil
+}
+
+func (r *ImageRepo) Delete(imageId int) error {
+	delete(r.images, imageId)
+	return nil
+}
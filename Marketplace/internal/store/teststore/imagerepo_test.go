@@ -0,0 +1,49 @@
+package teststore_test
+
+import (
+	"testing"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store/teststore"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImageRepo_Create(t *testing.T) {
+	s := teststore.New()
+	i1 := model.TestProductImage(t)
+	i2 := model.TestProductImage(t)
+
+	assert.NoError(t, s.Image().Create(i1))
+	assert.NoError(t, s.Image().Create(i2))
+	assert.Equal(t, 1, i1.Position)
+	assert.Equal(t, 2, i2.Position)
+}
+
+func TestImageRepo_UpdatePositions(t *testing.T) {
+	s := teststore.New()
+	i1 := model.TestProductImage(t)
+	i2 := model.TestProductImage(t)
+	s.Image().Create(i1)
+	s.Image().Create(i2)
+
+	assert.NoError(t, s.Image().UpdatePositions(i1.ProductID, []int{i2.ImageID, i1.ImageID}))
+
+	images, err := s.Image().FindByProductId(i1.ProductID)
+	assert.NoError(t, err)
+	assert.Equal(t, i2.ImageID, images[0].ImageID)
+	assert.Equal(t, i1.ImageID, images[1].ImageID)
+
+	assert.EqualError(t, s.Image().UpdatePositions(i1.ProductID+1, []int{i1.ImageID}), store.ErrRecordNotFound.Error())
+}
+
+func TestImageRepo_Delete(t *testing.T) {
+	s := teststore.New()
+	i := model.TestProductImage(t)
+	s.Image().Create(i)
+
+	assert.NoError(t, s.Image().Delete(i.ImageID))
+
+	_, err := s.Image().Find(i.ImageID)
+	assert.EqualError(t, err, store.ErrRecordNotFound.Error())
+}
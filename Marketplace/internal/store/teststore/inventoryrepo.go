@@ -0,0 +1,109 @@
+package teststore
+
+import (
+	"sort"
+	"time"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store"
+)
+
+type inventorySaleKey struct {
+	productID     int
+	marketPlaceID int
+	date          time.Time
+}
+
+type InventoryRepo struct {
+	store *Store
+	sales map[inventorySaleKey]*model.Sale
+	stock map[[2]int]*model.StockLevel
+}
+
+func (r *InventoryRepo) SaveSales(sales []*model.Sale) error {
+	for _, s := range sales {
+		s.BeforeCreate()
+		if err := s.Validate(); err != nil {
+			return err
+		}
+	}
+
+	for _, s := range sales {
+		r.sales[inventorySaleKey{s.ProductID, s.MarketPlaceID, s.SaleDate}] = s
+	}
+
+	return nil
+}
+
+func (r *InventoryRepo) FindSales(userId int, from time.Time) ([]*model.Sale, error) {
+	sales := make([]*model.Sale, 0)
+	for _, s := range r.sales {
+		if s.UserID == userId && !s.SaleDate.Before(from) {
+			sales = append(sales, s)
+		}
+	}
+
+	sort.Slice(sales, func(i, j int) bool {
+		if !sales[i].SaleDate.Equal(sales[j].SaleDate) {
+			return sales[i].SaleDate.Before(sales[j].SaleDate)
+		}
+		return sales[i].ProductID < sales[j].ProductID
+	})
+
+	return sales, nil
+}
+
+func (r *InventoryRepo) SetStock(levels []*model.StockLevel) error {
+	for _, l := range levels {
+		if err := l.Validate(); err != nil {
+			return err
+		}
+	}
+
+	for _, l := range levels {
+		l.UpdatedAt = time.Now()
+		r.stock[[2]int{l.WarehouseID, l.ProductID}] = l
+	}
+
+	return nil
+}
+
+func (r *InventoryRepo) FindStock(userId int) ([]*model.StockLevel, error) {
+	levels := make([]*model.StockLevel, 0)
+	for _, l := range r.stock {
+		if l.UserID == userId {
+			levels = append(levels, l)
+		}
+	}
+
+	sort.Slice(levels, func(i, j int) bool {
+		if levels[i].ProductID != levels[j].ProductID {
+			return levels[i].ProductID < levels[j].ProductID
+		}
+		return levels[i].WarehouseID < levels[j].WarehouseID
+	})
+
+	return levels, nil
+}
+
+// move adds the quantity to the stock of the product in the warehouse, the stock can't become negative
+func (r *InventoryRepo) move(userId int, warehouseId int, productId int, quantity int) error {
+	l, ok := r.stock[[2]int{warehouseId, productId}]
+	if !ok {
+		l = &model.StockLevel{
+			WarehouseID: warehouseId,
+			ProductID:   productId,
+			UserID:      userId,
+		}
+	}
+
+	if l.Quantity+quantity < 0 {
+		return store.ErrNotEnoughStock
+	}
+
+	l.Quantity += quantity
+	l.UpdatedAt = time.Now()
+	r.stock[[2]int{warehouseId, productId}] = l
+
+	return nil
+}
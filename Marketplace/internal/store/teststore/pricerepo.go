@@ -0,0 +1,70 @@
+package teststore
+
+import (
+	"sort"
+	"time"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+)
+
+type PriceRepo struct {
+	store  *Store
+	prices map[int]*model.Price
+	lastID int
+}
+
+func (r *PriceRepo) Create(prices []*model.Price) error {
+	for _, p := range prices {
+		if err := p.Validate(); err != nil {
+			return err
+		}
+	}
+
+	for _, p := range prices {
+		r.lastID++
+		p.PriceID = r.lastID
+		p.CreatedAt = time.Now()
+		r.prices[p.PriceID] = p
+	}
+
+	return nil
+}
+
+func (r *PriceRepo) FindCurrent(productId int) ([]*model.Price, error) {
+	current := make(map[int]*model.Price)
+	for _, p := range r.prices {
+		if p.ProductID != productId {
+			continue
+		}
+
+		if c, ok := current[p.MarketPlaceID]; !ok || p.PriceID > c.PriceID {
+			current[p.MarketPlaceID] = p
+		}
+	}
+
+	prices := make([]*model.Price, 0, len(current))
+	for _, p := range current {
+		prices = append(prices, p)
+	}
+
+	sort.Slice(prices, func(i, j int) bool {
+		return prices[i].MarketPlaceID < prices[j].MarketPlaceID
+	})
+
+	return prices, nil
+}
+
+func (r *PriceRepo) FindHistory(productId int, marketPlaceId int) ([]*model.Price, error) {
+	prices := make([]*model.Price, 0)
+	for _, p := range r.prices {
+		if p.ProductID == productId && (marketPlaceId == 0 || p.MarketPlaceID == marketPlaceId) {
+			prices = append(prices, p)
+		}
+	}
+
+	sort.Slice(prices, func(i, j int) bool {
+		return prices[i].PriceID > prices[j].PriceID
+	})
+
+	return prices, nil
+}
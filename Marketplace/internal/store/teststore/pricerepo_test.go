@@ -0,0 +1,48 @@
+package teststore_test
+
+import (
+	"testing"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store/teststore"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPriceRepo_Create(t *testing.T) {
+	s := teststore.New()
+	p := model.TestPrice(t)
+
+	assert.NoError(t, s.Price().Create([]*model.Price{p}))
+	assert.NotZero(t, p.PriceID)
+
+	invalid := model.TestPrice(t)
+	invalid.ListPrice = 0
+	assert.Error(t, s.Price().Create([]*model.Price{model.TestPrice(t), invalid}))
+
+	history, _ := s.Price().FindHistory(p.ProductID, 0)
+	assert.Len(t, history, 1)
+}
+
+func TestPriceRepo_FindCurrent(t *testing.T) {
+	s := teststore.New()
+
+	old := model.TestPrice(t)
+	current := model.TestPrice(t)
+	current.ListPrice = 1500
+	wb := model.TestPrice(t)
+	wb.MarketPlaceID = model.MarketPlaceWildberries
+	s.Price().Create([]*model.Price{old, current, wb})
+
+	prices, err := s.Price().FindCurrent(old.ProductID)
+	assert.NoError(t, err)
+	if assert.Len(t, prices, 2) {
+		assert.Equal(t, current.PriceID, prices[0].PriceID)
+		assert.Equal(t, wb.PriceID, prices[1].PriceID)
+	}
+
+	history, err := s.Price().FindHistory(old.ProductID, model.MarketPlaceOzon)
+	assert.NoError(t, err)
+	if assert.Len(t, history, 2) {
+		assert.Equal(t, current.PriceID, history[0].PriceID)
+	}
+}
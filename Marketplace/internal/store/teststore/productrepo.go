@@ -2,6 +2,8 @@ package teststore
 
 import (
 	"errors"
+	"sort"
+	"time"
 
 	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
 	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store"
@@ -13,10 +15,12 @@ type ProductRepo struct {
 	categories       map[int]*model.Category
 	materials        map[int]*model.Material
 	marketPlaceItems map[int]*model.MarketPlaceItem
+	revisions        map[int][]*model.ProductRevision
 }
 
 func (r *ProductRepo) Create(p *model.Product, mpiList *model.MarketPlaceItemsList) error {
 	p.ProductID = len(r.Products) + 1
+	p.Version = 1
 	r.Products[p.ProductID] = p
 
 	for _, mpi := range mpiList.MPIList {
@@ -25,18 +29,41 @@ func (r *ProductRepo) Create(p *model.Product, mpiList *model.MarketPlaceItemsLi
 		r.marketPlaceItems[mpi.MarketPlaceItemID] = mpi
 	}
 
+	r.store.Attribute().(*AttributeRepo).setProductValues(p.ProductID, p.Attributes)
+	r.createRevision(model.RevisionActionCreate, p, p.UserID)
+
 	return nil
 }
 
 func (r *ProductRepo) Update(p *model.Product, mpiList *model.MarketPlaceItemsList) error {
+	current, ok := r.Products[p.ProductID]
+	if !ok {
+		return store.ErrRecordNotFound
+	}
+
+	if p.Version != 0 && p.Version != current.Version {
+		return store.ErrVersionConflict
+	}
+
+	p.Version = current.Version + 1
 	r.Products[p.ProductID] = p
 
 	for _, mpi := range mpiList.MPIList {
-		if mpi.ProductID == p.ProductID {
-			r.marketPlaceItems[mpi.MarketPlaceItemID] = mpi
+		if mpi.ProductID != p.ProductID {
+			continue
+		}
+
+		for id, existing := range r.marketPlaceItems {
+			if existing.ProductID == mpi.ProductID && existing.MarketPlaceID == mpi.MarketPlaceID {
+				mpi.MarketPlaceItemID = id
+				r.marketPlaceItems[id] = mpi
+			}
 		}
 	}
 
+	r.store.Attribute().(*AttributeRepo).setProductValues(p.ProductID, p.Attributes)
+	r.createRevision(model.RevisionActionUpdate, p, p.UserID)
+
 	return nil
 }
 
@@ -55,6 +82,7 @@ func (r *ProductRepo) GetProductById(productId int) (*model.Product, error) {
 func (r *ProductRepo) Delete(productId int, userId int) error {
 	for _, product := range r.Products {
 		if product.UserID == userId && product.ProductID == productId {
+			r.createRevision(model.RevisionActionDelete, product, userId)
 			delete(r.Products, product.ProductID)
 		}
 	}
@@ -101,6 +129,24 @@ func (r *ProductRepo) FindByUserId(userId int) ([]*model.Product, error) {
 	return productsList, nil
 }
 
+func (r *ProductRepo) FindVariants(parentProductId int) ([]*model.Product, error) {
+	variants := make([]*model.Product, 0)
+	for _, product := range r.Products {
+		if product.ParentProductID == parentProductId {
+			for _, mpi := range r.marketPlaceItems {
+				GetProductIdMarketPlaceItem(product, mpi)
+			}
+			variants = append(variants, product)
+		}
+	}
+
+	sort.Slice(variants, func(i, j int) bool {
+		return variants[i].ProductID < variants[j].ProductID
+	})
+
+	return variants, nil
+}
+
 func (r *ProductRepo) GetCategories() ([]*model.Category, error) {
 	categories := make([]*model.Category, 0)
 	for _, category := range r.categories {
@@ -149,3 +195,42 @@ func (r *ProductRepo) GetMaterials() ([]*model.Material, error) {
 
 	return materials, nil
 }
+
+func (r *ProductRepo) createRevision(action string, p *model.Product, userId int) {
+	snapshot := *p
+	revisionID := 1
+	for _, revisions := range r.revisions {
+		revisionID += len(revisions)
+	}
+
+	rev := &model.ProductRevision{
+		RevisionID: revisionID,
+		ProductID:  p.ProductID,
+		Revision:   len(r.revisions[p.ProductID]) + 1,
+		Action:     action,
+		Product:    &snapshot,
+		UserID:     userId,
+		CreatedAt:  time.Now(),
+	}
+
+	r.revisions[p.ProductID] = append(r.revisions[p.ProductID], rev)
+}
+
+func (r *ProductRepo) GetRevisions(productId int) ([]*model.ProductRevision, error) {
+	revisions, ok := r.revisions[productId]
+	if !ok {
+		return nil, store.ErrRecordNotFound
+	}
+
+	return revisions, nil
+}
+
+func (r *ProductRepo) GetRevision(productId int, revision int) (*model.ProductRevision, error) {
+	for _, rev := range r.revisions[productId] {
+		if rev.Revision == revision {
+			return rev, nil
+		}
+	}
+
+	return nil, store.ErrRecordNotFound
+}
@@ -147,3 +147,84 @@ func TestProductRepo_GetMaterials(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, 2, len(materials))
 }
+
+func TestProductRepo_Revisions(t *testing.T) {
+	s := teststore.New()
+	u := model.TestUser(t)
+	s.User().Create(u)
+
+	p := model.TestProduct(t)
+	p.UserID = u.ID
+	mpiList := &model.MarketPlaceItemsList{}
+	mpiList.GetMPIList(p)
+	s.Product().Create(p, mpiList)
+
+	p.Description = "new description"
+	mpiList1 := &model.MarketPlaceItemsList{}
+	mpiList1.UpdateMPIList(p)
+	s.Product().Update(p, mpiList1)
+
+	s.Product().Delete(p.ProductID, u.ID)
+
+	revisions, err := s.Product().GetRevisions(p.ProductID)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(revisions))
+	assert.Equal(t, model.RevisionActionCreate, revisions[0].Action)
+	assert.Equal(t, model.RevisionActionUpdate, revisions[1].Action)
+	assert.Equal(t, model.RevisionActionDelete, revisions[2].Action)
+	assert.Equal(t, "описание", revisions[0].Product.Description)
+
+	rev, err := s.Product().GetRevision(p.ProductID, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, "new description", rev.Product.Description)
+
+	_, err = s.Product().GetRevision(p.ProductID, 4)
+	assert.EqualError(t, err, store2.ErrRecordNotFound.Error())
+}
+
+func TestProductRepo_UpdateVersionConflict(t *testing.T) {
+	s := teststore.New()
+	u := model.TestUser(t)
+	s.User().Create(u)
+
+	p := model.TestProduct(t)
+	p.UserID = u.ID
+	mpiList := &model.MarketPlaceItemsList{}
+	mpiList.GetMPIList(p)
+	s.Product().Create(p, mpiList)
+	assert.Equal(t, 1, p.Version)
+
+	p1 := *p
+	p1.Description = "first"
+	assert.NoError(t, s.Product().Update(&p1, &model.MarketPlaceItemsList{}))
+	assert.Equal(t, 2, p1.Version)
+
+	p2 := *p
+	p2.Description = "second"
+	p2.Version = 1
+	assert.EqualError(t, s.Product().Update(&p2, &model.MarketPlaceItemsList{}), store2.ErrVersionConflict.Error())
+
+	p3 := *p
+	p3.ProductID = p.ProductID + 1
+	assert.EqualError(t, s.Product().Update(&p3, &model.MarketPlaceItemsList{}), store2.ErrRecordNotFound.Error())
+}
+
+func TestProductRepo_FindVariants(t *testing.T) {
+	s := teststore.New()
+	p := model.TestProduct(t)
+	s.Product().Create(p, &model.MarketPlaceItemsList{})
+
+	v := model.TestProductVariant(t, p)
+	mpiList := &model.MarketPlaceItemsList{}
+	mpiList.GetMPIList(v)
+	s.Product().Create(v, mpiList)
+
+	variants, err := s.Product().FindVariants(p.ProductID)
+	assert.NoError(t, err)
+	assert.Len(t, variants, 1)
+	assert.Equal(t, v.ProductID, variants[0].ProductID)
+
+	variants, err = s.Product().FindVariants(v.ProductID)
+	assert.NoError(t, err)
+	assert.Len(t, variants, 0)
+}
@@ -0,0 +1,98 @@
+package teststore
+
+import (
+	"time"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store"
+)
+
+type RefreshTokenRepo struct {
+	store  *Store
+	tokens map[string]*model.RefreshToken
+}
+
+func (r *RefreshTokenRepo) Create(t *model.RefreshToken) error {
+	if err := t.Validate(); err != nil {
+		return err
+	}
+
+	for _, stored := range r.tokens {
+		if stored.ID == t.ID {
+			return store.ErrRecordExists
+		}
+	}
+	if _, ok := r.tokens[t.TokenHash]; ok {
+		return store.ErrRecordExists
+	}
+
+	if t.CreatedAt.IsZero() {
+		t.CreatedAt = time.Now().UTC()
+	}
+	r.tokens[t.TokenHash] = t
+
+	return nil
+}
+
+func (r *RefreshTokenRepo) FindByHash(hash string) (*model.RefreshToken, error) {
+	t, ok := r.tokens[hash]
+	if !ok {
+		return nil, store.ErrRecordNotFound
+	}
+
+	return t, nil
+}
+
+func (r *RefreshTokenRepo) Revoke(tokenId string, replacedBy string, at time.Time) error {
+	for _, t := range r.tokens {
+		if t.ID != tokenId {
+			continue
+		}
+		if t.RevokedAt != nil {
+			return store.ErrTokenUsed
+		}
+
+		t.RevokedAt = &at
+		t.ReplacedBy = replacedBy
+		return nil
+	}
+
+	return store.ErrTokenUsed
+}
+
+func (r *RefreshTokenRepo) RevokeBySession(sessionHash string, at time.Time) (int, error) {
+	if sessionHash == "" {
+		return 0, nil
+	}
+
+	return r.revoke(func(t *model.RefreshToken) bool { return t.SessionHash == sessionHash }, at)
+}
+
+func (r *RefreshTokenRepo) RevokeByUserId(userId int, at time.Time) (int, error) {
+	return r.revoke(func(t *model.RefreshToken) bool { return t.UserID == userId }, at)
+}
+
+func (r *RefreshTokenRepo) DeleteExpired(at time.Time) (int, error) {
+	n := 0
+	for hash, t := range r.tokens {
+		if !at.Before(t.ExpiresAt) {
+			delete(r.tokens, hash)
+			n++
+		}
+	}
+
+	return n, nil
+}
+
+func (r *RefreshTokenRepo) revoke(match func(*model.RefreshToken) bool, at time.Time) (int, error) {
+	n := 0
+	for _, t := range r.tokens {
+		if t.RevokedAt == nil && match(t) {
+			revokedAt := at
+			t.RevokedAt = &revokedAt
+			n++
+		}
+	}
+
+	return n, nil
+}
@@ -0,0 +1,45 @@
+package teststore_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store/teststore"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRefreshTokenRepo_Revoke(t *testing.T) {
+	s := teststore.New()
+	token := model.TestRefreshToken(t)
+	token.SessionHash = strings.Repeat("s", 64)
+	other := model.TestRefreshToken(t)
+	other.ID = "0b7a1bc4-7441-4a5e-a5c6-5d3b3fa7a3c1"
+	other.TokenHash = strings.Repeat("b", 64)
+	assert.NoError(t, s.RefreshToken().Create(token))
+	assert.NoError(t, s.RefreshToken().Create(other))
+
+	at := token.CreatedAt.Add(time.Hour)
+	assert.NoError(t, s.RefreshToken().Revoke(token.ID, other.ID, at))
+	assert.EqualError(t, s.RefreshToken().Revoke(token.ID, "", at), store.ErrTokenUsed.Error())
+
+	found, err := s.RefreshToken().FindByHash(token.TokenHash)
+	assert.NoError(t, err)
+	assert.True(t, found.Rotated())
+	assert.False(t, found.Usable(at))
+
+	n, err := s.RefreshToken().RevokeBySession("", at)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, n)
+
+	n, err = s.RefreshToken().RevokeByUserId(token.UserID, at)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	found, err = s.RefreshToken().FindByHash(other.TokenHash)
+	assert.NoError(t, err)
+	assert.False(t, found.Rotated())
+	assert.NotNil(t, found.RevokedAt)
+}
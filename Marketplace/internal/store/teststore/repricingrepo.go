@@ -0,0 +1,205 @@
+package teststore
+
+import (
+	"sort"
+	"time"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store"
+)
+
+type RepricingRepo struct {
+	store            *Store
+	rules            map[int]*model.RepricingRule
+	competitorPrices map[[2]int]*model.CompetitorPrice
+	proposals        map[int]*model.PriceProposal
+	log              []*model.RepricingLogEntry
+	lastRuleID       int
+	lastProposalID   int
+}
+
+func (r *RepricingRepo) CreateRule(rule *model.RepricingRule) error {
+	if err := rule.Validate(); err != nil {
+		return err
+	}
+
+	r.lastRuleID++
+	rule.RuleID = r.lastRuleID
+	rule.CreatedAt = time.Now()
+	r.rules[rule.RuleID] = rule
+
+	return nil
+}
+
+func (r *RepricingRepo) FindRule(ruleId int) (*model.RepricingRule, error) {
+	rule, ok := r.rules[ruleId]
+	if !ok || !rule.Active {
+		return nil, store.ErrRecordNotFound
+	}
+
+	return rule, nil
+}
+
+func (r *RepricingRepo) FindRules(userId int) ([]*model.RepricingRule, error) {
+	rules := make([]*model.RepricingRule, 0)
+	for _, rule := range r.rules {
+		if rule.UserID == userId && rule.Active {
+			rules = append(rules, rule)
+		}
+	}
+
+	sort.Slice(rules, func(i, j int) bool {
+		return rules[i].RuleID < rules[j].RuleID
+	})
+
+	return rules, nil
+}
+
+func (r *RepricingRepo) FindActiveRules() ([]*model.RepricingRule, error) {
+	rules := make([]*model.RepricingRule, 0)
+	for _, rule := range r.rules {
+		if rule.Active {
+			rules = append(rules, rule)
+		}
+	}
+
+	sort.Slice(rules, func(i, j int) bool {
+		if rules[i].UserID != rules[j].UserID {
+			return rules[i].UserID < rules[j].UserID
+		}
+		return rules[i].RuleID < rules[j].RuleID
+	})
+
+	return rules, nil
+}
+
+func (r *RepricingRepo) DeleteRule(ruleId int) error {
+	rule, ok := r.rules[ruleId]
+	if !ok || !rule.Active {
+		return store.ErrRecordNotFound
+	}
+
+	rule.Active = false
+	return nil
+}
+
+func (r *RepricingRepo) SaveCompetitorPrices(prices []*model.CompetitorPrice) error {
+	for _, p := range prices {
+		p.ImportedAt = time.Now()
+		r.competitorPrices[[2]int{p.ProductID, p.MarketPlaceID}] = p
+	}
+
+	return nil
+}
+
+func (r *RepricingRepo) FindCompetitorPrices(userId int) ([]*model.CompetitorPrice, error) {
+	prices := make([]*model.CompetitorPrice, 0)
+	for _, p := range r.competitorPrices {
+		if p.UserID == userId {
+			prices = append(prices, p)
+		}
+	}
+
+	sort.Slice(prices, func(i, j int) bool {
+		if prices[i].ProductID != prices[j].ProductID {
+			return prices[i].ProductID < prices[j].ProductID
+		}
+		return prices[i].MarketPlaceID < prices[j].MarketPlaceID
+	})
+
+	return prices, nil
+}
+
+func (r *RepricingRepo) CreateProposals(userId int, proposals []*model.PriceProposal) error {
+	now := time.Now()
+	for _, p := range r.proposals {
+		if p.UserID == userId && p.Status == model.ProposalPending {
+			p.Status = model.ProposalExpired
+			p.DecidedAt = &now
+		}
+	}
+
+	for _, p := range proposals {
+		r.lastProposalID++
+		p.ProposalID = r.lastProposalID
+		p.CreatedAt = now
+		stored := *p
+		r.proposals[p.ProposalID] = &stored
+	}
+
+	return nil
+}
+
+func (r *RepricingRepo) FindProposal(proposalId int) (*model.PriceProposal, error) {
+	p, ok := r.proposals[proposalId]
+	if !ok {
+		return nil, store.ErrRecordNotFound
+	}
+
+	found := *p
+	return &found, nil
+}
+
+func (r *RepricingRepo) FindProposals(userId int, status string) ([]*model.PriceProposal, error) {
+	proposals := make([]*model.PriceProposal, 0)
+	for _, p := range r.proposals {
+		if p.UserID == userId && (status == "" || p.Status == status) {
+			found := *p
+			proposals = append(proposals, &found)
+		}
+	}
+
+	sort.Slice(proposals, func(i, j int) bool {
+		return proposals[i].ProposalID < proposals[j].ProposalID
+	})
+
+	return proposals, nil
+}
+
+func (r *RepricingRepo) UpdateProposal(p *model.PriceProposal) error {
+	if _, ok := r.proposals[p.ProposalID]; !ok {
+		return store.ErrRecordNotFound
+	}
+
+	stored := *p
+	r.proposals[p.ProposalID] = &stored
+	return nil
+}
+
+func (r *RepricingRepo) ApplyProposal(p *model.PriceProposal, price *model.Price, e *model.RepricingLogEntry) error {
+	current, ok := r.proposals[p.ProposalID]
+	if !ok {
+		return store.ErrRecordNotFound
+	}
+	if current.Status != model.ProposalApproved {
+		return store.ErrVersionConflict
+	}
+
+	if err := r.store.Price().Create([]*model.Price{price}); err != nil {
+		return err
+	}
+
+	stored := *p
+	r.proposals[p.ProposalID] = &stored
+	e.PriceID = price.PriceID
+	r.createLogEntry(e)
+
+	return nil
+}
+
+func (r *RepricingRepo) createLogEntry(e *model.RepricingLogEntry) {
+	e.LogID = len(r.log) + 1
+	e.CreatedAt = time.Now()
+	r.log = append(r.log, e)
+}
+
+func (r *RepricingRepo) FindLog(userId int) ([]*model.RepricingLogEntry, error) {
+	entries := make([]*model.RepricingLogEntry, 0)
+	for i := len(r.log) - 1; i >= 0; i-- {
+		if r.log[i].UserID == userId {
+			entries = append(entries, r.log[i])
+		}
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,85 @@
+package teststore_test
+
+import (
+	"testing"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store/teststore"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepricingRepo_Rules(t *testing.T) {
+	s := teststore.New()
+	r := model.TestRepricingRule(t)
+	assert.NoError(t, s.Repricing().CreateRule(r))
+	assert.NotZero(t, r.RuleID)
+
+	invalid := model.TestRepricingRule(t)
+	invalid.Strategy = ""
+	assert.Error(t, s.Repricing().CreateRule(invalid))
+
+	rules, _ := s.Repricing().FindActiveRules()
+	assert.Len(t, rules, 1)
+
+	assert.NoError(t, s.Repricing().DeleteRule(r.RuleID))
+	assert.EqualError(t, s.Repricing().DeleteRule(r.RuleID), store.ErrRecordNotFound.Error())
+
+	rules, _ = s.Repricing().FindRules(r.UserID)
+	assert.Empty(t, rules)
+}
+
+func TestRepricingRepo_CreateProposals(t *testing.T) {
+	s := teststore.New()
+
+	first := &model.PriceProposal{UserID: 1, ProductID: 1, Status: model.ProposalPending}
+	approved := &model.PriceProposal{UserID: 1, ProductID: 2, Status: model.ProposalApproved}
+	assert.NoError(t, s.Repricing().CreateProposals(1, []*model.PriceProposal{first, approved}))
+
+	second := &model.PriceProposal{UserID: 1, ProductID: 1, Status: model.ProposalPending}
+	assert.NoError(t, s.Repricing().CreateProposals(1, []*model.PriceProposal{second}))
+
+	pending, _ := s.Repricing().FindProposals(1, model.ProposalPending)
+	if assert.Len(t, pending, 1) {
+		assert.Equal(t, second.ProposalID, pending[0].ProposalID)
+	}
+
+	expired, _ := s.Repricing().FindProposal(first.ProposalID)
+	assert.Equal(t, model.ProposalExpired, expired.Status)
+
+	all, _ := s.Repricing().FindProposals(1, "")
+	assert.Len(t, all, 3)
+}
+
+func TestRepricingRepo_ApplyProposal(t *testing.T) {
+	s := teststore.New()
+
+	proposal := &model.PriceProposal{UserID: 1, ProductID: 1, MarketPlaceID: model.MarketPlaceOzon, Status: model.ProposalApproved}
+	assert.NoError(t, s.Repricing().CreateProposals(1, []*model.PriceProposal{proposal}))
+
+	// nothing is saved when the price is invalid
+	invalid := model.TestPrice(t)
+	invalid.ListPrice = 0
+	proposal.Status = model.ProposalApplied
+	assert.Error(t, s.Repricing().ApplyProposal(proposal, invalid, &model.RepricingLogEntry{UserID: 1}))
+	entries, _ := s.Repricing().FindLog(1)
+	assert.Empty(t, entries)
+
+	price := model.TestPrice(t)
+	entry := &model.RepricingLogEntry{UserID: 1, ProposalID: proposal.ProposalID, ProductID: 1, MarketPlaceID: model.MarketPlaceOzon}
+	assert.NoError(t, s.Repricing().ApplyProposal(proposal, price, entry))
+	assert.NotZero(t, price.PriceID)
+	assert.Equal(t, price.PriceID, entry.PriceID)
+
+	found, _ := s.Repricing().FindProposal(proposal.ProposalID)
+	assert.Equal(t, model.ProposalApplied, found.Status)
+	entries, _ = s.Repricing().FindLog(1)
+	assert.Len(t, entries, 1)
+
+	// a proposal is applied once
+	assert.EqualError(t, s.Repricing().ApplyProposal(proposal, model.TestPrice(t), &model.RepricingLogEntry{UserID: 1}), store.ErrVersionConflict.Error())
+	entries, _ = s.Repricing().FindLog(1)
+	assert.Len(t, entries, 1)
+
+	assert.EqualError(t, s.Repricing().ApplyProposal(&model.PriceProposal{ProposalID: 100}, model.TestPrice(t), &model.RepricingLogEntry{}), store.ErrRecordNotFound.Error())
+}
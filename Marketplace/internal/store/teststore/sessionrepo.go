@@ -0,0 +1,108 @@
+package teststore
+
+import (
+	"sort"
+	"time"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store"
+)
+
+type SessionRepo struct {
+	store    *Store
+	sessions map[string]*model.Session
+}
+
+func (r *SessionRepo) Create(s *model.Session) error {
+	if err := s.Validate(); err != nil {
+		return err
+	}
+
+	if _, ok := r.sessions[s.ID]; ok {
+		return store.ErrRecordExists
+	}
+
+	if s.CreatedAt.IsZero() {
+		s.CreatedAt = time.Now().UTC()
+	}
+	if s.LastSeenAt.IsZero() {
+		s.LastSeenAt = s.CreatedAt
+	}
+	r.sessions[s.ID] = s
+
+	return nil
+}
+
+func (r *SessionRepo) Find(sessionId string) (*model.Session, error) {
+	s, ok := r.sessions[sessionId]
+	if !ok {
+		return nil, store.ErrRecordNotFound
+	}
+
+	return s, nil
+}
+
+func (r *SessionRepo) FindByUserId(userId int) ([]*model.Session, error) {
+	sessions := make([]*model.Session, 0)
+	for _, s := range r.sessions {
+		if s.UserID == userId {
+			sessions = append(sessions, s)
+		}
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].LastSeenAt.After(sessions[j].LastSeenAt)
+	})
+
+	return sessions, nil
+}
+
+func (r *SessionRepo) Touch(s *model.Session) error {
+	if err := s.Validate(); err != nil {
+		return err
+	}
+
+	stored, ok := r.sessions[s.ID]
+	if !ok {
+		return store.ErrRecordNotFound
+	}
+
+	stored.LastSeenAt = s.LastSeenAt
+	stored.UserAgent = s.UserAgent
+	stored.IP = s.IP
+
+	return nil
+}
+
+func (r *SessionRepo) Delete(sessionId string) error {
+	if _, ok := r.sessions[sessionId]; !ok {
+		return store.ErrRecordNotFound
+	}
+
+	delete(r.sessions, sessionId)
+	return nil
+}
+
+func (r *SessionRepo) DeleteByUserId(userId int) (int, error) {
+	n := 0
+	for id, s := range r.sessions {
+		if s.UserID == userId {
+			delete(r.sessions, id)
+			n++
+		}
+	}
+
+	return n, nil
+}
+
+func (r *SessionRepo) DeleteExpired(at time.Time) (int, error) {
+	n := 0
+	for id, s := range r.sessions {
+		if s.Expired(at) {
+			delete(r.sessions, id)
+			n++
+		}
+	}
+
+	return n, nil
+}
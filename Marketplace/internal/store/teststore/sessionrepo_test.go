@@ -0,0 +1,61 @@
+package teststore_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store/teststore"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSessionRepo_DeleteExpired(t *testing.T) {
+	s := teststore.New()
+	expired := model.TestSession(t)
+	active := model.TestSession(t)
+	active.ID = "active"
+	active.ExpiresAt = expired.ExpiresAt.Add(time.Hour)
+	assert.NoError(t, s.Session().Create(expired))
+	assert.NoError(t, s.Session().Create(active))
+	assert.EqualError(t, s.Session().Create(model.TestSession(t)), store.ErrRecordExists.Error())
+
+	n, err := s.Session().DeleteExpired(expired.ExpiresAt)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	_, err = s.Session().Find(expired.ID)
+	assert.EqualError(t, err, store.ErrRecordNotFound.Error())
+
+	found, err := s.Session().Find(active.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, active.UserID, found.UserID)
+}
+
+func TestSessionRepo_FindByUserId(t *testing.T) {
+	s := teststore.New()
+	older := model.TestSession(t)
+	recent := model.TestSession(t)
+	recent.ID = "recent"
+	recent.LastSeenAt = older.CreatedAt.Add(time.Hour)
+	other := model.TestSession(t)
+	other.ID = "other"
+	other.UserID = 2
+	for _, session := range []*model.Session{older, recent, other} {
+		assert.NoError(t, s.Session().Create(session))
+	}
+
+	sessions, err := s.Session().FindByUserId(1)
+	assert.NoError(t, err)
+	if assert.Len(t, sessions, 2) {
+		assert.Equal(t, recent.ID, sessions[0].ID)
+	}
+
+	n, err := s.Session().DeleteByUserId(1)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	sessions, err = s.Session().FindByUserId(2)
+	assert.NoError(t, err)
+	assert.Len(t, sessions, 1)
+}
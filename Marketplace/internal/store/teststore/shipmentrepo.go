@@ -0,0 +1,90 @@
+package teststore
+
+import (
+	"sort"
+	"time"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store"
+)
+
+type ShipmentRepo struct {
+	store     *Store
+	shipments map[int]*model.Shipment
+}
+
+func (r *ShipmentRepo) Create(s *model.Shipment) error {
+	if err := s.Validate(); err != nil {
+		return err
+	}
+
+	s.ShipmentID = len(r.shipments) + 1
+	s.Status = model.ShipmentDraft
+	s.CreatedAt = time.Now()
+	s.UpdatedAt = s.CreatedAt
+	r.shipments[s.ShipmentID] = s
+
+	return nil
+}
+
+func (r *ShipmentRepo) Find(shipmentId int) (*model.Shipment, error) {
+	s, ok := r.shipments[shipmentId]
+	if !ok {
+		return nil, store.ErrRecordNotFound
+	}
+
+	return s, nil
+}
+
+func (r *ShipmentRepo) FindByUserId(userId int, status string) ([]*model.Shipment, error) {
+	shipments := make([]*model.Shipment, 0)
+	for _, s := range r.shipments {
+		if s.UserID == userId && (status == "" || s.Status == status) {
+			shipments = append(shipments, s)
+		}
+	}
+
+	sort.Slice(shipments, func(i, j int) bool {
+		if !shipments[i].PlannedDate.Equal(shipments[j].PlannedDate) {
+			return shipments[i].PlannedDate.Before(shipments[j].PlannedDate)
+		}
+		return shipments[i].ShipmentID < shipments[j].ShipmentID
+	})
+
+	return shipments, nil
+}
+
+func (r *ShipmentRepo) Update(s *model.Shipment) error {
+	if err := s.Validate(); err != nil {
+		return err
+	}
+
+	current, ok := r.shipments[s.ShipmentID]
+	if !ok {
+		return store.ErrRecordNotFound
+	}
+	if current.Status != model.ShipmentDraft {
+		return store.ErrVersionConflict
+	}
+
+	s.UpdatedAt = time.Now()
+	r.shipments[s.ShipmentID] = s
+
+	return nil
+}
+
+func (r *ShipmentRepo) SetStatus(s *model.Shipment, status string) error {
+	current, ok := r.shipments[s.ShipmentID]
+	if !ok {
+		return store.ErrRecordNotFound
+	}
+	if current.Status != s.Status {
+		return store.ErrVersionConflict
+	}
+
+	s.Status = status
+	s.UpdatedAt = time.Now()
+	r.shipments[s.ShipmentID] = s
+
+	return nil
+}
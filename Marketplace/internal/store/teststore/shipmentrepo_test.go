@@ -0,0 +1,29 @@
+package teststore_test
+
+import (
+	"testing"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store/teststore"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShipmentRepo_Update(t *testing.T) {
+	s := teststore.New()
+	sh := model.TestShipment(t)
+	assert.NoError(t, s.Shipment().Create(sh))
+	assert.Equal(t, model.ShipmentDraft, sh.Status)
+
+	sh.Boxes[0].Items[0].Quantity = 10
+	assert.NoError(t, s.Shipment().Update(sh))
+
+	assert.NoError(t, s.Shipment().SetStatus(sh, model.ShipmentReady))
+	assert.EqualError(t, s.Shipment().Update(sh), store.ErrVersionConflict.Error())
+
+	shipments, err := s.Shipment().FindByUserId(sh.UserID, model.ShipmentReady)
+	assert.NoError(t, err)
+	if assert.Len(t, shipments, 1) {
+		assert.Equal(t, 10, shipments[0].Boxes[0].Items[0].Quantity)
+	}
+}
@@ -0,0 +1,100 @@
+package teststore
+
+import (
+	"time"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store"
+)
+
+type AttemptCounterRepo struct {
+	store    *Store
+	counters map[string]*model.AttemptCounter
+}
+
+type SignInFailureRepo struct {
+	store    *Store
+	failures []*model.SignInFailure
+	lastId   int
+}
+
+func (r *AttemptCounterRepo) Find(key string) (*model.AttemptCounter, error) {
+	c, ok := r.counters[key]
+	if !ok {
+		return nil, store.ErrRecordNotFound
+	}
+
+	found := *c
+	return &found, nil
+}
+
+func (r *AttemptCounterRepo) Fail(key string, at time.Time, since time.Time) (*model.AttemptCounter, error) {
+	c, ok := r.counters[key]
+	if !ok || c.LastFailureAt.Before(since) {
+		c = &model.AttemptCounter{Key: key}
+		r.counters[key] = c
+	}
+
+	c.Failures++
+	c.LastFailureAt = at
+
+	found := *c
+	return &found, nil
+}
+
+func (r *AttemptCounterRepo) Reset(key string) error {
+	delete(r.counters, key)
+	return nil
+}
+
+func (r *AttemptCounterRepo) DeleteExpired(since time.Time) (int, error) {
+	n := 0
+	for key, c := range r.counters {
+		if c.LastFailureAt.Before(since) {
+			delete(r.counters, key)
+			n++
+		}
+	}
+
+	return n, nil
+}
+
+func (r *SignInFailureRepo) Create(f *model.SignInFailure) error {
+	if f.CreatedAt.IsZero() {
+		f.CreatedAt = time.Now().UTC()
+	}
+
+	r.lastId++
+	f.ID = r.lastId
+	stored := *f
+	r.failures = append(r.failures, &stored)
+
+	return nil
+}
+
+func (r *SignInFailureRepo) Find(email string, ip string, limit int) ([]*model.SignInFailure, error) {
+	failures := make([]*model.SignInFailure, 0)
+	for i := len(r.failures) - 1; i >= 0 && len(failures) < limit; i-- {
+		f := r.failures[i]
+		if (email == "" || f.Email == email) && (ip == "" || f.IP == ip) {
+			found := *f
+			failures = append(failures, &found)
+		}
+	}
+
+	return failures, nil
+}
+
+func (r *SignInFailureRepo) DeleteBefore(before time.Time) (int, error) {
+	kept := make([]*model.SignInFailure, 0, len(r.failures))
+	for _, f := range r.failures {
+		if !f.CreatedAt.Before(before) {
+			kept = append(kept, f)
+		}
+	}
+
+	n := len(r.failures) - len(kept)
+	r.failures = kept
+
+	return n, nil
+}
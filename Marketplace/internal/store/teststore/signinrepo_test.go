@@ -0,0 +1,55 @@
+package teststore_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store/teststore"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAttemptCounterRepo(t *testing.T) {
+	s := teststore.New()
+	at := time.Date(2022, 12, 8, 10, 0, 0, 0, time.UTC)
+
+	for i := 0; i < 3; i++ {
+		s.AttemptCounter().Fail("account:ex@test.org", at, at.Add(-time.Hour))
+	}
+	c, err := s.AttemptCounter().Find("account:ex@test.org")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, c.Failures)
+
+	assert.NoError(t, s.AttemptCounter().Reset("account:ex@test.org"))
+	_, err = s.AttemptCounter().Find("account:ex@test.org")
+	assert.EqualError(t, err, store.ErrRecordNotFound.Error())
+}
+
+func TestSignInFailureRepo(t *testing.T) {
+	s := teststore.New()
+	at := time.Date(2022, 12, 8, 10, 0, 0, 0, time.UTC)
+
+	for i, email := range []string{"ex@test.org", "other@test.org", "ex@test.org"} {
+		assert.NoError(t, s.SignInFailure().Create(&model.SignInFailure{
+			Email:     email,
+			IP:        "192.0.2.1",
+			Reason:    model.SignInFailurePassword,
+			CreatedAt: at.Add(time.Duration(i) * time.Minute),
+		}))
+	}
+
+	failures, err := s.SignInFailure().Find("ex@test.org", "", 10)
+	assert.NoError(t, err)
+	if assert.Len(t, failures, 2) {
+		assert.Equal(t, 3, failures[0].ID)
+	}
+
+	failures, err = s.SignInFailure().Find("", "192.0.2.1", 1)
+	assert.NoError(t, err)
+	assert.Len(t, failures, 1)
+
+	n, err := s.SignInFailure().DeleteBefore(at.Add(time.Minute))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+}
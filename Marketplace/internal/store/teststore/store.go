@@ -8,8 +8,25 @@ import (
 
 // Store
 type Store struct {
-	userRepo    *UserRepo
-	ProductRepo *ProductRepo
+	userRepo      *UserRepo
+	ProductRepo   *ProductRepo
+	imageRepo     *ImageRepo
+	attributeRepo *AttributeRepo
+	barcodeRepo   *BarcodeRepo
+	priceRepo     *PriceRepo
+	repricingRepo *RepricingRepo
+	inventoryRepo *InventoryRepo
+	supplyRepo    *SupplyRepo
+	warehouseRepo *WarehouseRepo
+	transferRepo  *TransferRepo
+	shipmentRepo  *ShipmentRepo
+	sessionRepo   *SessionRepo
+	tokenRepo     *RefreshTokenRepo
+	apiKeyRepo    *APIKeyRepo
+	userTokenRepo *UserTokenRepo
+	counterRepo   *AttemptCounterRepo
+	failureRepo   *SignInFailureRepo
+	twoFactorRepo *TwoFactorRepo
 }
 
 // Store constructor
@@ -40,6 +57,218 @@ func (s *Store) Product() store.ProductRepo {
 		categories:       make(map[int]*model.Category),
 		materials:        make(map[int]*model.Material),
 		marketPlaceItems: make(map[int]*model.MarketPlaceItem),
+		revisions:        make(map[int][]*model.ProductRevision),
 	}
 	return s.ProductRepo
 }
+
+func (s *Store) Image() store.ImageRepo {
+	if s.imageRepo != nil {
+		return s.imageRepo
+	}
+
+	s.imageRepo = &ImageRepo{
+		store:  s,
+		images: make(map[int]*model.ProductImage),
+	}
+	return s.imageRepo
+}
+
+func (s *Store) Attribute() store.AttributeRepo {
+	if s.attributeRepo != nil {
+		return s.attributeRepo
+	}
+
+	s.attributeRepo = &AttributeRepo{
+		store:      s,
+		attributes: make(map[int]*model.CategoryAttribute),
+		values:     make(map[int]map[string]string),
+	}
+	return s.attributeRepo
+}
+
+func (s *Store) Barcode() store.BarcodeRepo {
+	if s.barcodeRepo != nil {
+		return s.barcodeRepo
+	}
+
+	s.barcodeRepo = &BarcodeRepo{
+		store:    s,
+		barcodes: make(map[int]*model.Barcode),
+	}
+	return s.barcodeRepo
+}
+
+func (s *Store) Price() store.PriceRepo {
+	if s.priceRepo != nil {
+		return s.priceRepo
+	}
+
+	s.priceRepo = &PriceRepo{
+		store:  s,
+		prices: make(map[int]*model.Price),
+	}
+	return s.priceRepo
+}
+
+func (s *Store) Repricing() store.RepricingRepo {
+	if s.repricingRepo != nil {
+		return s.repricingRepo
+	}
+
+	s.repricingRepo = &RepricingRepo{
+		store:            s,
+		rules:            make(map[int]*model.RepricingRule),
+		competitorPrices: make(map[[2]int]*model.CompetitorPrice),
+		proposals:        make(map[int]*model.PriceProposal),
+		log:              make([]*model.RepricingLogEntry, 0),
+	}
+	return s.repricingRepo
+}
+
+func (s *Store) Inventory() store.InventoryRepo {
+	if s.inventoryRepo != nil {
+		return s.inventoryRepo
+	}
+
+	s.inventoryRepo = &InventoryRepo{
+		store: s,
+		sales: make(map[inventorySaleKey]*model.Sale),
+		stock: make(map[[2]int]*model.StockLevel),
+	}
+	return s.inventoryRepo
+}
+
+func (s *Store) Supply() store.SupplyRepo {
+	if s.supplyRepo != nil {
+		return s.supplyRepo
+	}
+
+	s.supplyRepo = &SupplyRepo{
+		store:     s,
+		suppliers: make(map[int]*model.Supplier),
+		orders:    make(map[int]*model.SupplyOrder),
+	}
+	return s.supplyRepo
+}
+
+func (s *Store) Warehouse() store.WarehouseRepo {
+	if s.warehouseRepo != nil {
+		return s.warehouseRepo
+	}
+
+	s.warehouseRepo = &WarehouseRepo{
+		store:      s,
+		warehouses: make(map[int]*model.Warehouse),
+	}
+	return s.warehouseRepo
+}
+
+func (s *Store) Transfer() store.TransferRepo {
+	if s.transferRepo != nil {
+		return s.transferRepo
+	}
+
+	s.transferRepo = &TransferRepo{
+		store:     s,
+		transfers: make(map[int]*model.Transfer),
+	}
+	return s.transferRepo
+}
+
+func (s *Store) Shipment() store.ShipmentRepo {
+	if s.shipmentRepo != nil {
+		return s.shipmentRepo
+	}
+
+	s.shipmentRepo = &ShipmentRepo{
+		store:     s,
+		shipments: make(map[int]*model.Shipment),
+	}
+	return s.shipmentRepo
+}
+
+func (s *Store) Session() store.SessionRepo {
+	if s.sessionRepo != nil {
+		return s.sessionRepo
+	}
+
+	s.sessionRepo = &SessionRepo{
+		store:    s,
+		sessions: make(map[string]*model.Session),
+	}
+	return s.sessionRepo
+}
+
+func (s *Store) RefreshToken() store.RefreshTokenRepo {
+	if s.tokenRepo != nil {
+		return s.tokenRepo
+	}
+
+	s.tokenRepo = &RefreshTokenRepo{
+		store:  s,
+		tokens: make(map[string]*model.RefreshToken),
+	}
+	return s.tokenRepo
+}
+
+func (s *Store) APIKey() store.APIKeyRepo {
+	if s.apiKeyRepo != nil {
+		return s.apiKeyRepo
+	}
+
+	s.apiKeyRepo = &APIKeyRepo{
+		store: s,
+		keys:  make(map[int]*model.APIKey),
+	}
+	return s.apiKeyRepo
+}
+
+func (s *Store) UserToken() store.UserTokenRepo {
+	if s.userTokenRepo != nil {
+		return s.userTokenRepo
+	}
+
+	s.userTokenRepo = &UserTokenRepo{
+		store:  s,
+		tokens: make(map[string]*model.UserToken),
+	}
+	return s.userTokenRepo
+}
+
+func (s *Store) AttemptCounter() store.AttemptCounterRepo {
+	if s.counterRepo != nil {
+		return s.counterRepo
+	}
+
+	s.counterRepo = &AttemptCounterRepo{
+		store:    s,
+		counters: make(map[string]*model.AttemptCounter),
+	}
+	return s.counterRepo
+}
+
+func (s *Store) SignInFailure() store.SignInFailureRepo {
+	if s.failureRepo != nil {
+		return s.failureRepo
+	}
+
+	s.failureRepo = &SignInFailureRepo{
+		store:    s,
+		failures: make([]*model.SignInFailure, 0),
+	}
+	return s.failureRepo
+}
+
+func (s *Store) TwoFactor() store.TwoFactorRepo {
+	if s.twoFactorRepo != nil {
+		return s.twoFactorRepo
+	}
+
+	s.twoFactorRepo = &TwoFactorRepo{
+		store:    s,
+		factors:  make(map[int]*model.TwoFactor),
+		recovery: make(map[int][]*model.RecoveryCode),
+	}
+	return s.twoFactorRepo
+}
@@ -0,0 +1,75 @@
+package teststore
+
+import (
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store"
+)
+
+type SupplyRepo struct {
+	store          *Store
+	suppliers      map[int]*model.Supplier
+	orders         map[int]*model.SupplyOrder
+	lastSupplierID int
+	lastOrderID    int
+	lastProductID  int
+}
+
+func (r *SupplyRepo) CreateSupplier(s *model.Supplier) error {
+	if err := s.Validate(); err != nil {
+		return err
+	}
+
+	r.lastSupplierID++
+	s.SupplierID = r.lastSupplierID
+	s.Active = true
+	r.suppliers[s.SupplierID] = s
+
+	return nil
+}
+
+func (r *SupplyRepo) FindSupplier(supplierId int) (*model.Supplier, error) {
+	s, ok := r.suppliers[supplierId]
+	if !ok || !s.Active {
+		return nil, store.ErrRecordNotFound
+	}
+
+	return s, nil
+}
+
+func (r *SupplyRepo) FindSuppliers(userId int) ([]*model.Supplier, error) {
+	suppliers := make([]*model.Supplier, 0)
+	for id := 1; id <= r.lastSupplierID; id++ {
+		if s, ok := r.suppliers[id]; ok && s.UserID == userId && s.Active {
+			suppliers = append(suppliers, s)
+		}
+	}
+
+	return suppliers, nil
+}
+
+func (r *SupplyRepo) CreateOrder(o *model.SupplyOrder) error {
+	if err := o.Validate(); err != nil {
+		return err
+	}
+
+	r.lastOrderID++
+	o.SupplyOrderID = r.lastOrderID
+	o.Active = true
+	for _, p := range o.Products {
+		r.lastProductID++
+		p.SupplyOrderProductID = r.lastProductID
+		p.SupplyOrderID = o.SupplyOrderID
+	}
+	r.orders[o.SupplyOrderID] = o
+
+	return nil
+}
+
+func (r *SupplyRepo) FindOrder(orderId int) (*model.SupplyOrder, error) {
+	o, ok := r.orders[orderId]
+	if !ok || !o.Active {
+		return nil, store.ErrRecordNotFound
+	}
+
+	return o, nil
+}
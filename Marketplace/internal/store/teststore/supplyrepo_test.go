@@ -0,0 +1,53 @@
+package teststore_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store/teststore"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSupplyRepo_CreateOrder(t *testing.T) {
+	s := teststore.New()
+	supplier := model.TestSupplier(t)
+	assert.NoError(t, s.Supply().CreateSupplier(supplier))
+
+	o := &model.SupplyOrder{
+		OrderDate:  time.Now(),
+		SupplierID: supplier.SupplierID,
+		UserID:     supplier.UserID,
+		StatusID:   model.SupplyOrderStatusDraft,
+	}
+	assert.Error(t, s.Supply().CreateOrder(o))
+
+	o.Products = []*model.SupplyOrderProduct{{ProductID: 1, Quantity: 10, CurrencyID: model.CurrencyRUB}}
+	assert.NoError(t, s.Supply().CreateOrder(o))
+
+	found, err := s.Supply().FindOrder(o.SupplyOrderID)
+	assert.NoError(t, err)
+	assert.Equal(t, o.SupplyOrderID, found.Products[0].SupplyOrderID)
+
+	_, err = s.Supply().FindOrder(o.SupplyOrderID + 1)
+	assert.EqualError(t, err, store.ErrRecordNotFound.Error())
+}
+
+func TestInventoryRepo_SaveSales(t *testing.T) {
+	s := teststore.New()
+	day := time.Date(2022, 11, 20, 10, 0, 0, 0, time.UTC)
+	sale := &model.Sale{ProductID: 1, MarketPlaceID: model.MarketPlaceOzon, UserID: 1, SaleDate: day, Quantity: 3}
+	assert.NoError(t, s.Inventory().SaveSales([]*model.Sale{sale}))
+
+	again := &model.Sale{ProductID: 1, MarketPlaceID: model.MarketPlaceOzon, UserID: 1, SaleDate: day.Add(time.Hour), Quantity: 5}
+	assert.NoError(t, s.Inventory().SaveSales([]*model.Sale{again}))
+
+	sales, _ := s.Inventory().FindSales(1, day.AddDate(0, 0, -1))
+	if assert.Len(t, sales, 1) {
+		assert.Equal(t, 5, sales[0].Quantity)
+	}
+
+	sales, _ = s.Inventory().FindSales(1, day.AddDate(0, 0, 1))
+	assert.Empty(t, sales)
+}
@@ -0,0 +1,106 @@
+package teststore
+
+import (
+	"sort"
+	"time"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store"
+)
+
+type TransferRepo struct {
+	store     *Store
+	transfers map[int]*model.Transfer
+}
+
+func (r *TransferRepo) Create(t *model.Transfer) error {
+	if err := t.Validate(); err != nil {
+		return err
+	}
+
+	t.TransferID = len(r.transfers) + 1
+	t.Status = model.TransferDraft
+	t.CreatedAt = time.Now()
+	r.transfers[t.TransferID] = t
+
+	return nil
+}
+
+func (r *TransferRepo) Find(transferId int) (*model.Transfer, error) {
+	t, ok := r.transfers[transferId]
+	if !ok {
+		return nil, store.ErrRecordNotFound
+	}
+
+	return t, nil
+}
+
+func (r *TransferRepo) FindByUserId(userId int, status string) ([]*model.Transfer, error) {
+	transfers := make([]*model.Transfer, 0)
+	for _, t := range r.transfers {
+		if t.UserID == userId && (status == "" || t.Status == status) {
+			transfers = append(transfers, t)
+		}
+	}
+
+	sort.Slice(transfers, func(i, j int) bool {
+		return transfers[i].TransferID < transfers[j].TransferID
+	})
+
+	return transfers, nil
+}
+
+func (r *TransferRepo) Ship(t *model.Transfer) error {
+	inventory := r.inventory()
+	for _, item := range t.Items {
+		l, ok := inventory.stock[[2]int{t.FromWarehouseID, item.ProductID}]
+		if !ok || l.Quantity < item.Quantity {
+			return store.ErrNotEnoughStock
+		}
+	}
+
+	for _, item := range t.Items {
+		if err := inventory.move(t.UserID, t.FromWarehouseID, item.ProductID, -item.Quantity); err != nil {
+			return err
+		}
+	}
+
+	now := time.Now()
+	t.Status = model.TransferInTransit
+	t.ShippedAt = &now
+
+	return nil
+}
+
+func (r *TransferRepo) Receive(t *model.Transfer) error {
+	inventory := r.inventory()
+	for _, item := range t.Items {
+		if err := inventory.move(t.UserID, t.ToWarehouseID, item.ProductID, item.Quantity); err != nil {
+			return err
+		}
+	}
+
+	now := time.Now()
+	t.Status = model.TransferReceived
+	t.ReceivedAt = &now
+
+	return nil
+}
+
+func (r *TransferRepo) Cancel(t *model.Transfer) error {
+	if t.Status == model.TransferInTransit {
+		inventory := r.inventory()
+		for _, item := range t.Items {
+			if err := inventory.move(t.UserID, t.FromWarehouseID, item.ProductID, item.Quantity); err != nil {
+				return err
+			}
+		}
+	}
+
+	t.Status = model.TransferCancelled
+	return nil
+}
+
+func (r *TransferRepo) inventory() *InventoryRepo {
+	return r.store.Inventory().(*InventoryRepo)
+}
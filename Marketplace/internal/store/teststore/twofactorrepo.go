@@ -0,0 +1,115 @@
+package teststore
+
+import (
+	"time"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store"
+)
+
+type TwoFactorRepo struct {
+	store    *Store
+	factors  map[int]*model.TwoFactor
+	recovery map[int][]*model.RecoveryCode
+}
+
+func (r *TwoFactorRepo) Save(t *model.TwoFactor) error {
+	if err := t.Validate(); err != nil {
+		return err
+	}
+
+	if stored, ok := r.factors[t.UserID]; ok && stored.Enabled() {
+		return store.ErrRecordExists
+	}
+
+	if t.CreatedAt.IsZero() {
+		t.CreatedAt = time.Now().UTC()
+	}
+	t.LastStep = 0
+	t.EnabledAt = nil
+
+	stored := *t
+	r.factors[t.UserID] = &stored
+
+	return nil
+}
+
+func (r *TwoFactorRepo) Find(userId int) (*model.TwoFactor, error) {
+	t, ok := r.factors[userId]
+	if !ok {
+		return nil, store.ErrRecordNotFound
+	}
+
+	found := *t
+	return &found, nil
+}
+
+func (r *TwoFactorRepo) Enable(userId int, step int64, at time.Time) error {
+	t, ok := r.factors[userId]
+	if !ok {
+		return store.ErrRecordNotFound
+	}
+	if t.Enabled() {
+		return store.ErrRecordExists
+	}
+
+	t.EnabledAt = &at
+	t.LastStep = step
+	return nil
+}
+
+func (r *TwoFactorRepo) UseStep(userId int, step int64) error {
+	t, ok := r.factors[userId]
+	if !ok || t.LastStep >= step {
+		return store.ErrTokenUsed
+	}
+
+	t.LastStep = step
+	return nil
+}
+
+func (r *TwoFactorRepo) Delete(userId int) error {
+	if _, ok := r.factors[userId]; !ok {
+		return store.ErrRecordNotFound
+	}
+
+	delete(r.factors, userId)
+	delete(r.recovery, userId)
+	return nil
+}
+
+func (r *TwoFactorRepo) ReplaceRecoveryCodes(userId int, hashes []string) error {
+	codes := make([]*model.RecoveryCode, 0, len(hashes))
+	for i, hash := range hashes {
+		codes = append(codes, &model.RecoveryCode{
+			ID:       i + 1,
+			UserID:   userId,
+			CodeHash: hash,
+		})
+	}
+
+	r.recovery[userId] = codes
+	return nil
+}
+
+func (r *TwoFactorRepo) UseRecoveryCode(userId int, hash string, at time.Time) error {
+	for _, c := range r.recovery[userId] {
+		if c.CodeHash == hash && c.UsedAt == nil {
+			c.UsedAt = &at
+			return nil
+		}
+	}
+
+	return store.ErrRecordNotFound
+}
+
+func (r *TwoFactorRepo) CountRecoveryCodes(userId int) (int, error) {
+	n := 0
+	for _, c := range r.recovery[userId] {
+		if c.UsedAt == nil {
+			n++
+		}
+	}
+
+	return n, nil
+}
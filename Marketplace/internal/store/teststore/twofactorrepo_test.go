@@ -0,0 +1,46 @@
+package teststore_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store/teststore"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTwoFactorRepo(t *testing.T) {
+	s := teststore.New()
+	tf := model.TestTwoFactor(t)
+	assert.NoError(t, s.TwoFactor().Save(tf))
+	// a pending authenticator is replaced
+	assert.NoError(t, s.TwoFactor().Save(model.TestTwoFactor(t)))
+
+	at := tf.CreatedAt.Add(time.Minute)
+	assert.NoError(t, s.TwoFactor().Enable(tf.UserID, 100, at))
+	assert.EqualError(t, s.TwoFactor().Enable(tf.UserID, 100, at), store.ErrRecordExists.Error())
+	assert.EqualError(t, s.TwoFactor().Save(model.TestTwoFactor(t)), store.ErrRecordExists.Error())
+
+	assert.EqualError(t, s.TwoFactor().UseStep(tf.UserID, 100), store.ErrTokenUsed.Error())
+	assert.NoError(t, s.TwoFactor().UseStep(tf.UserID, 101))
+
+	found, err := s.TwoFactor().Find(tf.UserID)
+	assert.NoError(t, err)
+	assert.True(t, found.Enabled())
+	assert.Equal(t, int64(101), found.LastStep)
+
+	assert.NoError(t, s.TwoFactor().ReplaceRecoveryCodes(tf.UserID, []string{strings.Repeat("e", 64), strings.Repeat("f", 64)}))
+	assert.NoError(t, s.TwoFactor().UseRecoveryCode(tf.UserID, strings.Repeat("e", 64), at))
+	assert.EqualError(t, s.TwoFactor().UseRecoveryCode(tf.UserID, strings.Repeat("e", 64), at), store.ErrRecordNotFound.Error())
+	n, err := s.TwoFactor().CountRecoveryCodes(tf.UserID)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	assert.NoError(t, s.TwoFactor().Delete(tf.UserID))
+	n, _ = s.TwoFactor().CountRecoveryCodes(tf.UserID)
+	assert.Zero(t, n)
+	_, err = s.TwoFactor().Find(tf.UserID)
+	assert.EqualError(t, err, store.ErrRecordNotFound.Error())
+}
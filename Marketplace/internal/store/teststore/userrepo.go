@@ -20,6 +20,8 @@ func (r *UserRepo) Create(u *model.User) error {
 		return err
 	}
 
+	u.BeforeCreate()
+
 	u.ID = len(r.users) + 1
 	r.users[u.ID] = u
 
@@ -45,3 +47,76 @@ func (r *UserRepo) FindById(id int) (*model.User, error) {
 
 	return u, nil
 }
+
+func (r *UserRepo) UpdatePassword(u *model.User) error {
+	if err := u.ValidatePassword(); err != nil {
+		return err
+	}
+
+	stored, ok := r.users[u.ID]
+	if !ok {
+		return store.ErrRecordNotFound
+	}
+
+	if err := u.EncryptPasswordBeforeCreate(); err != nil {
+		return err
+	}
+	stored.EncryptedPassword = u.EncryptedPassword
+
+	return nil
+}
+
+func (r *UserRepo) VerifyEmail(id int) error {
+	u, ok := r.users[id]
+	if !ok {
+		return store.ErrRecordNotFound
+	}
+
+	u.EmailVerified = true
+	return nil
+}
+
+func (r *UserRepo) UpdateEmail(u *model.User) error {
+	if err := u.ValidateEmail(); err != nil {
+		return err
+	}
+
+	stored, ok := r.users[u.ID]
+	if !ok {
+		return store.ErrRecordNotFound
+	}
+
+	for _, other := range r.users {
+		if other.ID != u.ID && other.Email == u.Email {
+			return store.ErrRecordExists
+		}
+	}
+
+	stored.Email = u.Email
+	stored.EmailVerified = u.EmailVerified
+	return nil
+}
+
+func (r *UserRepo) UpdateProfile(u *model.User) error {
+	if err := u.UserProfile.Validate(); err != nil {
+		return err
+	}
+
+	stored, ok := r.users[u.ID]
+	if !ok {
+		return store.ErrRecordNotFound
+	}
+
+	stored.UserProfile = u.UserProfile
+	return nil
+}
+
+func (r *UserRepo) UpdateActive(id int, active bool) error {
+	u, ok := r.users[id]
+	if !ok {
+		return store.ErrRecordNotFound
+	}
+
+	u.Active = active
+	return nil
+}
@@ -0,0 +1,73 @@
+package teststore
+
+import (
+	"time"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store"
+)
+
+type UserTokenRepo struct {
+	store  *Store
+	tokens map[string]*model.UserToken
+}
+
+func (r *UserTokenRepo) Create(t *model.UserToken) error {
+	if err := t.Validate(); err != nil {
+		return err
+	}
+
+	if _, ok := r.tokens[t.TokenHash]; ok {
+		return store.ErrRecordExists
+	}
+
+	if t.CreatedAt.IsZero() {
+		t.CreatedAt = time.Now().UTC()
+	}
+	stored := *t
+	r.tokens[t.TokenHash] = &stored
+
+	return nil
+}
+
+func (r *UserTokenRepo) FindByHash(hash string) (*model.UserToken, error) {
+	t, ok := r.tokens[hash]
+	if !ok {
+		return nil, store.ErrRecordNotFound
+	}
+
+	found := *t
+	return &found, nil
+}
+
+func (r *UserTokenRepo) Use(hash string, at time.Time) error {
+	t, ok := r.tokens[hash]
+	if !ok || t.UsedAt != nil {
+		return store.ErrTokenUsed
+	}
+
+	t.UsedAt = &at
+	return nil
+}
+
+func (r *UserTokenRepo) DeleteByUserId(userId int, purpose string) error {
+	for hash, t := range r.tokens {
+		if t.UserID == userId && t.Purpose == purpose {
+			delete(r.tokens, hash)
+		}
+	}
+
+	return nil
+}
+
+func (r *UserTokenRepo) DeleteExpired(at time.Time) (int, error) {
+	n := 0
+	for hash, t := range r.tokens {
+		if !at.Before(t.ExpiresAt) {
+			delete(r.tokens, hash)
+			n++
+		}
+	}
+
+	return n, nil
+}
@@ -0,0 +1,32 @@
+package teststore_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store/teststore"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserTokenRepo(t *testing.T) {
+	s := teststore.New()
+	ut := model.TestUserToken(t)
+	assert.NoError(t, s.UserToken().Create(ut))
+	assert.EqualError(t, s.UserToken().Create(model.TestUserToken(t)), store.ErrRecordExists.Error())
+
+	at := ut.CreatedAt.Add(time.Hour)
+	assert.NoError(t, s.UserToken().Use(ut.TokenHash, at))
+	assert.EqualError(t, s.UserToken().Use(ut.TokenHash, at), store.ErrTokenUsed.Error())
+
+	found, err := s.UserToken().FindByHash(ut.TokenHash)
+	assert.NoError(t, err)
+	assert.Equal(t, at, *found.UsedAt)
+
+	n, err := s.UserToken().DeleteExpired(ut.ExpiresAt)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+	_, err = s.UserToken().FindByHash(ut.TokenHash)
+	assert.EqualError(t, err, store.ErrRecordNotFound.Error())
+}
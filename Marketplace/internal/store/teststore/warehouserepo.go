@@ -0,0 +1,53 @@
+package teststore
+
+import (
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store"
+)
+
+type WarehouseRepo struct {
+	store      *Store
+	warehouses map[int]*model.Warehouse
+}
+
+func (r *WarehouseRepo) Create(w *model.Warehouse) error {
+	if err := w.Validate(); err != nil {
+		return err
+	}
+
+	w.WarehouseID = len(r.warehouses) + 1
+	w.Active = true
+	r.warehouses[w.WarehouseID] = w
+
+	return nil
+}
+
+func (r *WarehouseRepo) Find(warehouseId int) (*model.Warehouse, error) {
+	w, ok := r.warehouses[warehouseId]
+	if !ok || !w.Active {
+		return nil, store.ErrRecordNotFound
+	}
+
+	return w, nil
+}
+
+func (r *WarehouseRepo) FindByUserId(userId int) ([]*model.Warehouse, error) {
+	warehouses := make([]*model.Warehouse, 0)
+	for id := 1; id <= len(r.warehouses); id++ {
+		if w := r.warehouses[id]; w.UserID == userId && w.Active {
+			warehouses = append(warehouses, w)
+		}
+	}
+
+	return warehouses, nil
+}
+
+func (r *WarehouseRepo) Delete(warehouseId int) error {
+	w, ok := r.warehouses[warehouseId]
+	if !ok || !w.Active {
+		return store.ErrRecordNotFound
+	}
+
+	w.Active = false
+	return nil
+}
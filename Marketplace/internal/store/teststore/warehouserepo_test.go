@@ -0,0 +1,62 @@
+package teststore_test
+
+import (
+	"testing"
+
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/model"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store"
+	"github.com/VladimirBlinov/MarketPlace/MarketPlace/internal/store/teststore"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWarehouseRepo_Delete(t *testing.T) {
+	s := teststore.New()
+	w := model.TestWarehouse(t)
+	assert.NoError(t, s.Warehouse().Create(w))
+
+	assert.NoError(t, s.Warehouse().Delete(w.WarehouseID))
+	_, err := s.Warehouse().Find(w.WarehouseID)
+	assert.EqualError(t, err, store.ErrRecordNotFound.Error())
+
+	warehouses, err := s.Warehouse().FindByUserId(w.UserID)
+	assert.NoError(t, err)
+	assert.Empty(t, warehouses)
+}
+
+func TestTransferRepo_ShipAndReceive(t *testing.T) {
+	s := teststore.New()
+	from := model.TestWarehouse(t)
+	to := model.TestWarehouse(t)
+	assert.NoError(t, s.Warehouse().Create(from))
+	assert.NoError(t, s.Warehouse().Create(to))
+	assert.NoError(t, s.Inventory().SetStock([]*model.StockLevel{
+		{WarehouseID: from.WarehouseID, ProductID: 1, UserID: 1, Quantity: 10},
+	}))
+
+	tr := &model.Transfer{
+		UserID:          1,
+		FromWarehouseID: from.WarehouseID,
+		ToWarehouseID:   to.WarehouseID,
+		Items:           []*model.TransferItem{{ProductID: 1, Quantity: 15}},
+	}
+	assert.NoError(t, s.Transfer().Create(tr))
+	assert.Equal(t, model.TransferDraft, tr.Status)
+	assert.EqualError(t, s.Transfer().Ship(tr), store.ErrNotEnoughStock.Error())
+
+	tr.Items[0].Quantity = 4
+	assert.NoError(t, s.Transfer().Ship(tr))
+	assert.Equal(t, model.TransferInTransit, tr.Status)
+	assert.NotNil(t, tr.ShippedAt)
+
+	assert.NoError(t, s.Transfer().Receive(tr))
+	levels, err := s.Inventory().FindStock(1)
+	assert.NoError(t, err)
+	if assert.Len(t, levels, 2) {
+		assert.Equal(t, 6, levels[0].Quantity)
+		assert.Equal(t, 4, levels[1].Quantity)
+	}
+
+	transfers, err := s.Transfer().FindByUserId(1, model.TransferReceived)
+	assert.NoError(t, err)
+	assert.Len(t, transfers, 1)
+}
@@ -45,7 +45,13 @@ const EditProduct = () => {
         if(product.wildberries_sku == null)
             product.ozon_sku = 0
 
-        updateProduct(product).then(() => navigate('/products', {replace: true}))
+        updateProduct(product)
+            .then(() => navigate('/products', {replace: true}))
+            .catch((error) => {
+                if (error.response?.status === 409) {
+                    alert("Товар был изменён другим пользователем, обновите страницу")
+                }
+            })
     }
 
     const formProductCategories: IFormCategory[] = [];
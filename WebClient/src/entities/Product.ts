@@ -12,6 +12,11 @@ export interface IProduct {
     user_id:  number,
     ozon_sku: number,
     wildberries_sku: number,
+    version: number,
+    attributes?: Record<string, string>,
+    parent_product_id?: number,
+    variant_attributes?: Record<string, string>,
+    variants?: IProduct[],
 }
 
 export  interface ICategory {
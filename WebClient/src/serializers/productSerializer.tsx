@@ -16,6 +16,10 @@ export function serializeProduct(product: IProduct) {
         "user_id": product.user_id,
         "wildberries_sku": product.wildberries_sku,
         "ozon_sku": product.ozon_sku,
+        "version": product.version,
+        "attributes": product.attributes,
+        "parent_product_id": product.parent_product_id,
+        "variant_attributes": product.variant_attributes,
     })
         
     return data